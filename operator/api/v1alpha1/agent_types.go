@@ -1,10 +1,35 @@
 package v1alpha1
 
 import (
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// DebugImageAnnotation, when set on an Agent, makes the operator inject an ephemeral debug
+// container running this image into each of the agent's running pods
+const DebugImageAnnotation = "kaos.agentic/debug-image"
+
+// AllowReservedEnvAnnotation, set to "true" on an Agent, permits config.env entries named
+// after env vars the operator sets, such as MODEL_API_URL. Those entries then take precedence
+// over the operator's values
+const AllowReservedEnvAnnotation = "kaos.agentic/allow-reserved-env"
+
+// TraceSampleRatioAnnotation, set on an Agent with telemetry enabled to a ratio between 0 and
+// 1, samples that fraction of new traces. Changing it rolls the agent pods, so tracing can be
+// dialled up during an incident without editing the spec
+const TraceSampleRatioAnnotation = "kaos.agentic/trace-sample-ratio"
+
+// AgentWorkloadType selects the workload that runs the agent pods
+type AgentWorkloadType string
+
+const (
+	// AgentWorkloadTypeDeployment runs interchangeable agent pods from a Deployment
+	AgentWorkloadTypeDeployment AgentWorkloadType = "Deployment"
+	// AgentWorkloadTypeStatefulSet runs agent pods with stable identities and per-pod volumes
+	AgentWorkloadTypeStatefulSet AgentWorkloadType = "StatefulSet"
+)
+
 // +kubebuilder:object:generate=true
 
 // AgentNetworkConfig defines A2A communication settings
@@ -83,10 +108,83 @@ type AgentConfig struct {
 
 // +kubebuilder:object:generate=true
 
+// AgentRuntimeConfig defines request handling limits for the agent runtime
+type AgentRuntimeConfig struct {
+	// MaxConcurrency is the maximum number of requests the agent processes concurrently
+	// Must be positive
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	MaxConcurrency *int32 `json:"maxConcurrency,omitempty"`
+
+	// RequestTimeout is the maximum duration of a single request (e.g., "30s", "2m")
+	// Must be a positive duration
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:XValidation:rule="duration(self) > duration('0s')",message="must be a positive duration such as \"30s\" or \"2m\""
+	RequestTimeout string `json:"requestTimeout,omitempty"`
+}
+
+// +kubebuilder:object:generate=true
+
+// ProjectedServiceAccountTokenConfig mounts a bound service account token for an audience
+type ProjectedServiceAccountTokenConfig struct {
+	// Audience is the intended audience of the token, checked by its recipient
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Audience string `json:"audience"`
+
+	// ExpirationSeconds is the requested lifetime of the token. The kubelet refreshes it
+	// before it expires (default: 3600)
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=600
+	ExpirationSeconds *int64 `json:"expirationSeconds,omitempty"`
+}
+
+// +kubebuilder:object:generate=true
+
+// SecretKeyMapping exposes a key of a Secret as an env var of the agent
+type SecretKeyMapping struct {
+	// SecretName is the name of the Secret in the Agent's namespace
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	SecretName string `json:"secretName"`
+
+	// Key is the key of the Secret to read
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Key string `json:"key"`
+
+	// EnvName is the name of the env var the agent reads the value from
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	EnvName string `json:"envName"`
+}
+
+// +kubebuilder:object:generate=true
+
+// EmbeddedModelConfig runs a ModelAPI as a sidecar of the agent pods
+type EmbeddedModelConfig struct {
+	// ModelAPI is the name of the ModelAPI whose spec is the template of the sidecar
+	// (default: spec.modelAPI). Its own pods are not used, so it may be scaled to zero
+	// +kubebuilder:validation:Optional
+	ModelAPI string `json:"modelAPI,omitempty"`
+}
+
+// +kubebuilder:object:generate=true
+
 // AgentSpec defines the desired state of Agent
+// +kubebuilder:validation:XValidation:rule="has(self.modelAPI) != has(self.modelAPISelector)",message="exactly one of modelAPI and modelAPISelector must be set"
 type AgentSpec struct {
-	// ModelAPI is the name of the ModelAPI resource this agent uses
-	ModelAPI string `json:"modelAPI"`
+	// ModelAPI is the name of the ModelAPI resource this agent uses. Exactly one of modelAPI
+	// and modelAPISelector is required
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MinLength=1
+	ModelAPI string `json:"modelAPI,omitempty"`
+
+	// ModelAPISelector selects the ModelAPI this agent uses by label, for environments where
+	// ModelAPI names are generated. It must match exactly one Ready ModelAPI in the Agent's
+	// namespace; the Agent waits while none is Ready and fails while several are
+	// +kubebuilder:validation:Optional
+	ModelAPISelector *metav1.LabelSelector `json:"modelAPISelector,omitempty"`
 
 	// Model is the model identifier this agent uses (e.g., "openai/gpt-4", "ollama/smollm2:135m")
 	// Must be supported by the referenced ModelAPI
@@ -104,6 +202,14 @@ type AgentSpec struct {
 	// +kubebuilder:validation:Optional
 	Config *AgentConfig `json:"config,omitempty"`
 
+	// Runtime configures request concurrency and timeouts of the agent runtime
+	// +kubebuilder:validation:Optional
+	Runtime *AgentRuntimeConfig `json:"runtime,omitempty"`
+
+	// Telemetry configures OpenTelemetry export from the agent runtime
+	// +kubebuilder:validation:Optional
+	Telemetry *TelemetryConfig `json:"telemetry,omitempty"`
+
 	// WaitForDependencies controls whether the agent waits for ModelAPI and MCPServers to be ready
 	// before creating the deployment. Default is true.
 	// +kubebuilder:default=true
@@ -116,6 +222,170 @@ type AgentSpec struct {
 	// PodSpec allows overriding the generated pod spec using strategic merge patch
 	// +kubebuilder:validation:Optional
 	PodSpec *corev1.PodSpec `json:"podSpec,omitempty"`
+
+	// Command overrides the agent container's entrypoint. Entries may reference
+	// {{ .ModelEndpoint }} and {{ .MCPEndpoints }} (comma-separated, ordered by MCPServer
+	// name), which are expanded from the resolved references at reconcile time
+	// +kubebuilder:validation:Optional
+	Command []string `json:"command,omitempty"`
+
+	// Args overrides the agent container's arguments, with the same placeholders as Command
+	// +kubebuilder:validation:Optional
+	Args []string `json:"args,omitempty"`
+
+	// PinDigest resolves the container image tag to its digest at reconcile time and
+	// deploys the digest form, so that pods never silently pick up a re-pushed tag.
+	// The resolved digest is reused until the image tag changes.
+	// +kubebuilder:validation:Optional
+	PinDigest bool `json:"pinDigest,omitempty"`
+
+	// HostAliases adds entries to the pod's /etc/hosts for hostnames that must resolve
+	// to fixed IPs (e.g., external services with unreliable DNS)
+	// +kubebuilder:validation:Optional
+	HostAliases []corev1.HostAlias `json:"hostAliases,omitempty"`
+
+	// LogLevel overrides the log level of this resource's pods via the LOG_LEVEL env var
+	// (one of DEBUG, INFO, WARNING, ERROR, CRITICAL)
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:XValidation:rule="self.upperAscii() in ['DEBUG', 'INFO', 'WARNING', 'ERROR', 'CRITICAL']",message="must be one of DEBUG, INFO, WARNING, ERROR or CRITICAL"
+	LogLevel string `json:"logLevel,omitempty"`
+
+	// MeshInjection sets the service mesh sidecar injection annotation on the pods
+	// (sidecar.istio.io/inject or linkerd.io/inject, depending on the operator's MESH_TYPE)
+	// +kubebuilder:validation:Optional
+	MeshInjection MeshInjection `json:"meshInjection,omitempty"`
+
+	// Size sets the requests and limits of the agent container from an operator-configured
+	// preset when podSpec sets no resources for it. It is expanded by the defaulting webhook.
+	// +kubebuilder:validation:Optional
+	Size Size `json:"size,omitempty"`
+
+	// RuntimeClassName selects the RuntimeClass for the pods, e.g. a sandboxed (gVisor, Kata)
+	// or GPU runtime
+	// +kubebuilder:validation:Optional
+	RuntimeClassName *string `json:"runtimeClassName,omitempty"`
+
+	// Overhead is the resource overhead of the pod sandbox, such as a Kata or gVisor VM, that
+	// the scheduler and resource quotas add to the container requests. It must match the
+	// RuntimeClass overhead where the RuntimeClass admission controller is enabled, which
+	// fills it in otherwise
+	// +kubebuilder:validation:Optional
+	Overhead corev1.ResourceList `json:"overhead,omitempty"`
+
+	// SchedulerName dispatches the pods to a custom scheduler, e.g. a gang scheduler for
+	// multi-GPU workloads. Defaults to the cluster's default scheduler
+	// +kubebuilder:validation:Optional
+	SchedulerName string `json:"schedulerName,omitempty"`
+
+	// ShareProcessNamespace runs all containers of the pod in a single process namespace,
+	// so debugging sidecars can see and signal the agent process (default: false)
+	// +kubebuilder:validation:Optional
+	ShareProcessNamespace *bool `json:"shareProcessNamespace,omitempty"`
+
+	// EnableServiceLinks injects the environment variables Docker links would set for every
+	// Service in the namespace into the pods. Off by default, as they bloat the environment
+	// and expose the namespace's Services (default: false)
+	// +kubebuilder:validation:Optional
+	EnableServiceLinks *bool `json:"enableServiceLinks,omitempty"`
+
+	// AutomountServiceAccountToken mounts a token of the pods' service account, which the
+	// agent runtime does not need unless it calls the Kubernetes API. Off by default to reduce
+	// token exposure, except with leaderLease, whose pods renew the Lease through the API
+	// (default: false, or true with leaderLease)
+	// +kubebuilder:validation:Optional
+	AutomountServiceAccountToken *bool `json:"automountServiceAccountToken,omitempty"`
+
+	// ImagePullPolicy of the resource's main container (Always, IfNotPresent or Never).
+	// Defaults to Always for untagged or :latest images and IfNotPresent otherwise
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Always;IfNotPresent;Never
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// WorkingDir is the working directory of the resource's main container, for images whose
+	// entrypoint expects a specific one. Defaults to the image's working directory
+	// +kubebuilder:validation:Optional
+	WorkingDir string `json:"workingDir,omitempty"`
+
+	// Replicas is the number of agent pods (default: 1). The Agent supports the scale
+	// subresource, so kubectl scale and HorizontalPodAutoscalers can target it directly
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// LeaderLease creates a coordination.k8s.io Lease named agent-<name>-leader and passes
+	// its name to the pods in AGENT_LEADER_LEASE, so replicas that need a single leader can
+	// elect one. The pods' service account needs get and update on leases (default: false)
+	// +kubebuilder:validation:Optional
+	LeaderLease bool `json:"leaderLease,omitempty"`
+
+	// MTLS mounts a TLS Secret into the agent pods for mutual TLS with the ModelAPI, which
+	// must reference the same Secret in its spec.mtls
+	// +kubebuilder:validation:Optional
+	MTLS *MTLSConfig `json:"mtls,omitempty"`
+
+	// ProjectedServiceAccountToken mounts a short-lived token of the pods' service account,
+	// bound to the given audience, for agents that call the Kubernetes API or another
+	// service accepting service account tokens
+	// +kubebuilder:validation:Optional
+	ProjectedServiceAccountToken *ProjectedServiceAccountTokenConfig `json:"projectedServiceAccountToken,omitempty"`
+
+	// EmbeddedModel runs the model server of a ModelAPI in the agent pods, next to the agent,
+	// which then calls it over the pod's loopback instead of the ModelAPI Service. The
+	// containers share an emptyDir for a Unix domain socket at MODEL_API_SOCKET
+	// +kubebuilder:validation:Optional
+	EmbeddedModel *EmbeddedModelConfig `json:"embeddedModel,omitempty"`
+
+	// SecretKeyMappings expose keys of existing Secrets, such as a provider's credentials, as
+	// env vars under the names the agent expects, so the Secrets need not be reshaped
+	// +kubebuilder:validation:Optional
+	SecretKeyMappings []SecretKeyMapping `json:"secretKeyMappings,omitempty"`
+
+	// RevisionHistoryLimit is the number of old revisions the agent's Deployment or
+	// StatefulSet keeps for rollbacks (default: 3)
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
+
+	// ProgressDeadlineSeconds is how long a rollout of the agent's Deployment may make no
+	// progress before the Agent is marked Degraded with reason RolloutStuck (default: 600).
+	// StatefulSets have no progress deadline, so it is ignored for workloadType StatefulSet
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	ProgressDeadlineSeconds *int32 `json:"progressDeadlineSeconds,omitempty"`
+
+	// ActiveDeadlineSeconds is how long an agent pod may run before the operator evicts it
+	// so the Deployment replaces it, for agents that can hang. Pods are replaced one at a
+	// time, honouring PodDisruptionBudgets. Unset means no deadline.
+	// Only applies to workloadType Deployment
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty"`
+
+	// HeadlessService creates an additional clusterIP: None Service, agent-<name>-headless,
+	// so each pod gets a stable DNS record and is individually addressable
+	// +kubebuilder:validation:Optional
+	HeadlessService bool `json:"headlessService,omitempty"`
+
+	// WorkloadType selects whether the agent pods run from a Deployment (default) or a
+	// StatefulSet. StatefulSet agents get stable pod names, the headless Service as their
+	// governing Service, and a PersistentVolumeClaim per pod from volumeClaimTemplates
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Deployment;StatefulSet
+	// +kubebuilder:default=Deployment
+	WorkloadType AgentWorkloadType `json:"workloadType,omitempty"`
+
+	// VolumeClaimTemplates are the per-pod PersistentVolumeClaims of a StatefulSet agent.
+	// Mount them into the agent container by name through podSpec. They are fixed once the
+	// StatefulSet exists
+	// +kubebuilder:validation:Optional
+	VolumeClaimTemplates []corev1.PersistentVolumeClaim `json:"volumeClaimTemplates,omitempty"`
+
+	// PodManagementPolicy controls how a StatefulSet agent's pods are started and stopped:
+	// OrderedReady (default) one at a time in ordinal order, Parallel all at once. It is fixed
+	// once the StatefulSet exists
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=OrderedReady;Parallel
+	PodManagementPolicy appsv1.PodManagementPolicyType `json:"podManagementPolicy,omitempty"`
 }
 
 // +kubebuilder:object:generate=true
@@ -140,15 +410,47 @@ type AgentStatus struct {
 	// Message provides additional status information
 	Message string `json:"message,omitempty"`
 
+	// LastReconcileTime is when the operator last completed a full reconcile of the resource.
+	// A timestamp that stops advancing while the spec changes points to a stuck resource
+	// +kubebuilder:validation:Optional
+	LastReconcileTime *metav1.Time `json:"lastReconcileTime,omitempty"`
+
 	// Deployment contains status information from the underlying Deployment
 	// +kubebuilder:validation:Optional
 	Deployment *DeploymentStatus `json:"deployment,omitempty"`
+
+	// ResolvedImage is the digest-pinned image deployed when spec.pinDigest is enabled
+	// +kubebuilder:validation:Optional
+	ResolvedImage string `json:"resolvedImage,omitempty"`
+
+	// Replicas is the number of pods of the underlying Deployment, reported by the scale subresource
+	// +kubebuilder:validation:Optional
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// ReadyReplicas is the number of ready pods of the underlying Deployment
+	// +kubebuilder:validation:Optional
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+
+	// Selector is the pod label selector in string form, used by the scale subresource
+	// +kubebuilder:validation:Optional
+	Selector string `json:"selector,omitempty"`
+
+	// Conditions represent the latest available observations of the Agent state
+	// +kubebuilder:validation:Optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// ConditionHistory lists the latest status changes of the conditions, oldest first, up
+	// to the operator's --condition-history-limit, to diagnose flapping conditions
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxItems=100
+	ConditionHistory []ConditionTransition `json:"conditionHistory,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:subresource:scale:specpath=.spec.replicas,statuspath=.status.replicas,selectorpath=.status.selector
 // +kubebuilder:resource:shortName=agent;agents
-// +kubebuilder:printcolumn:name="ModelAPI",type=string,JSONPath=`.spec.modelAPI`
+// +kubebuilder:printcolumn:name="ModelAPI",type=string,JSONPath=`.status.linkedResources.modelapi`
 // +kubebuilder:printcolumn:name="Model",type=string,JSONPath=`.spec.model`
 // +kubebuilder:printcolumn:name="Ready",type=boolean,JSONPath=`.status.ready`
 // +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
@@ -0,0 +1,187 @@
+package v1alpha1
+
+import (
+	"slices"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ResetCircuitAnnotation closes an open CircuitOpen condition: setting it to a new value,
+// such as the current time, makes the operator retry the resource immediately
+const ResetCircuitAnnotation = "kaos.agentic/reset-circuit"
+
+// Condition types set in status.conditions of Agent, ModelAPI and MCPServer resources.
+// Reasons are stable CamelCase identifiers so dashboards and alerts can match on them;
+// the accompanying message carries the human-readable detail.
+const (
+	// ConditionReady reports whether the resource's workload is serving. It mirrors
+	// status.phase, with the reason explaining why the resource is not ready.
+	ConditionReady = "Ready"
+	// ConditionDegraded reports that the underlying Deployment cannot reach its desired replicas
+	// or that its rollout is stuck
+	ConditionDegraded = "Degraded"
+	// ConditionSharedCacheReady reports whether the shared model cache claim of a Hosted ModelAPI
+	// exists and can be mounted by several pods
+	ConditionSharedCacheReady = "SharedCacheReady"
+	// ConditionCircuitOpen reports that reconciling the resource failed repeatedly at its
+	// current generation, so the operator retries it at a long interval only
+	ConditionCircuitOpen = "CircuitOpen"
+	// ConditionHTTPRouteApplied reports whether the HTTPRoute of a ModelAPI's spec.httpRoute exists
+	ConditionHTTPRouteApplied = "HTTPRouteApplied"
+	// ConditionInferenceServiceApplied reports whether the KServe InferenceService of a
+	// ModelAPI with spec.backend KServe runs its pods
+	ConditionInferenceServiceApplied = "InferenceServiceApplied"
+	// ConditionReplicasCapped reports that the requested replicas exceed the operator's
+	// --max-replicas-per-cr cap, so fewer pods run than requested
+	ConditionReplicasCapped = "ReplicasCapped"
+	// ConditionMinReplicasHeld reports that a ModelAPI is kept at one replica or more, whatever
+	// spec.replicas or an autoscaler ask for, because Agents reference it
+	ConditionMinReplicasHeld = "MinReplicasHeld"
+	// ConditionReconcilePaused reports that the operator shut down, so the resource is not
+	// reconciled until it restarts. The operator removes it when it next reconciles the resource.
+	ConditionReconcilePaused = "ReconcilePaused"
+	// MCPServerConditionToolsDiscovered reports whether the advertised tools could be discovered
+	MCPServerConditionToolsDiscovered = "ToolsDiscovered"
+)
+
+// Reasons of the Ready condition
+const (
+	// ReasonDeploymentReady means the workload has at least one ready replica
+	ReasonDeploymentReady = "DeploymentReady"
+	// ReasonDeploymentNotReady means the workload exists but has no ready replicas yet
+	ReasonDeploymentNotReady = "DeploymentNotReady"
+	// ReasonEndpointsNotReady means the Deployment has ready pods but the Service has no ready
+	// endpoints yet
+	ReasonEndpointsNotReady = "EndpointsNotReady"
+	// ReasonDependencyNotReady means a referenced ModelAPI, MCPServer or ConfigMap is missing or not ready
+	ReasonDependencyNotReady = "DependencyNotReady"
+	// ReasonReconcileFailed means the operator could not reconcile the resource; see the message
+	ReasonReconcileFailed = "ReconcileFailed"
+)
+
+// Reasons of the CircuitOpen condition
+const (
+	// ReasonRepeatedFailures means the failure threshold was reached at the current generation
+	ReasonRepeatedFailures = "RepeatedFailures"
+)
+
+// Reasons of the Degraded condition
+const (
+	// ReasonQuotaExceeded means a ResourceQuota or LimitRange rejects pod creation
+	ReasonQuotaExceeded = "QuotaExceeded"
+	// ReasonRolloutStuck means the Deployment exceeded its progress deadline
+	ReasonRolloutStuck = "RolloutStuck"
+	// ReasonModelDownloadFailed means a Hosted ModelAPI's pods keep failing to download the model
+	ReasonModelDownloadFailed = "ModelDownloadFailed"
+	// ReasonWarningEvent means the Deployment has unavailable replicas and the message is the
+	// latest Warning event of the Deployment, its ReplicaSets or their pods, e.g. FailedScheduling
+	ReasonWarningEvent = "WarningEvent"
+)
+
+// Reasons of the ReplicasCapped condition
+const (
+	// ReasonMaxReplicasExceeded means spec.replicas or a HorizontalPodAutoscaler asked for more
+	// replicas than the cap
+	ReasonMaxReplicasExceeded = "MaxReplicasExceeded"
+)
+
+// Reasons of the ReconcilePaused condition
+const (
+	// ReasonOperatorShutdown means the operator received a termination signal, e.g. during an
+	// upgrade or a node drain
+	ReasonOperatorShutdown = "OperatorShutdown"
+)
+
+// Reasons of the MinReplicasHeld condition
+const (
+	// ReasonReferencedByAgents means at least one Agent uses the ModelAPI
+	ReasonReferencedByAgents = "ReferencedByAgents"
+)
+
+// Reasons of the SharedCacheReady condition
+const (
+	// ReasonClaimShared means the claim can be mounted read-only across nodes
+	ReasonClaimShared = "ClaimShared"
+	// ReasonClaimNotFound means the claim does not exist
+	ReasonClaimNotFound = "ClaimNotFound"
+	// ReasonClaimNotShared means the claim is neither ReadWriteMany nor ReadOnlyMany
+	ReasonClaimNotShared = "ClaimNotShared"
+)
+
+// Reasons of the HTTPRouteApplied condition
+const (
+	// ReasonRouteApplied means the HTTPRoute was created or updated
+	ReasonRouteApplied = "RouteApplied"
+	// ReasonGatewayAPIUnavailable means the cluster does not serve the Gateway API HTTPRoute kind
+	ReasonGatewayAPIUnavailable = "GatewayAPIUnavailable"
+)
+
+// Reasons of the InferenceServiceApplied condition
+const (
+	// ReasonInferenceServiceApplied means the InferenceService was created or updated
+	ReasonInferenceServiceApplied = "InferenceServiceApplied"
+	// ReasonKServeUnavailable means the cluster does not serve the KServe InferenceService
+	// kind, so the pods run from a Deployment
+	ReasonKServeUnavailable = "KServeUnavailable"
+)
+
+// Reasons of the ToolsDiscovered condition
+const (
+	// ReasonDiscovered means the MCP server listed its tools
+	ReasonDiscovered = "Discovered"
+	// ReasonDiscoveryFailed means the MCP server could not be reached or did not list its tools
+	ReasonDiscoveryFailed = "DiscoveryFailed"
+)
+
+// +kubebuilder:object:generate=true
+
+// ConditionTransition is an entry of status.conditionHistory: a condition changing status
+type ConditionTransition struct {
+	// Type of the condition, e.g. Ready
+	Type string `json:"type"`
+
+	// Status the condition changed to
+	Status metav1.ConditionStatus `json:"status"`
+
+	// Reason of the condition after the change
+	// +kubebuilder:validation:Optional
+	Reason string `json:"reason,omitempty"`
+
+	// Message of the condition after the change
+	// +kubebuilder:validation:Optional
+	Message string `json:"message,omitempty"`
+
+	// LastTransitionTime is when the condition changed status
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+}
+
+// conditionReasons lists every reason the operator sets on a condition
+var conditionReasons = []string{
+	ReasonDeploymentReady,
+	ReasonDeploymentNotReady,
+	ReasonEndpointsNotReady,
+	ReasonDependencyNotReady,
+	ReasonReconcileFailed,
+	ReasonRepeatedFailures,
+	ReasonQuotaExceeded,
+	ReasonRolloutStuck,
+	ReasonModelDownloadFailed,
+	ReasonWarningEvent,
+	ReasonMaxReplicasExceeded,
+	ReasonReferencedByAgents,
+	ReasonOperatorShutdown,
+	ReasonClaimShared,
+	ReasonClaimNotFound,
+	ReasonClaimNotShared,
+	ReasonRouteApplied,
+	ReasonGatewayAPIUnavailable,
+	ReasonInferenceServiceApplied,
+	ReasonKServeUnavailable,
+	ReasonDiscovered,
+	ReasonDiscoveryFailed,
+}
+
+// IsConditionReason reports whether reason is one of the condition reasons defined above
+func IsConditionReason(reason string) bool {
+	return slices.Contains(conditionReasons, reason)
+}
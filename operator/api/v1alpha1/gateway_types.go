@@ -14,3 +14,38 @@ type GatewayRoute struct {
 	// +kubebuilder:validation:Pattern=`^([0-9]+(h|m|s|ms)){1,4}$`
 	Timeout string `json:"timeout,omitempty"`
 }
+
+// +kubebuilder:object:generate=true
+
+// HTTPRouteConfig attaches a dedicated Gateway API HTTPRoute for a resource's Service to a
+// Gateway of the user's choice, independently of the operator-wide Gateway API integration.
+type HTTPRouteConfig struct {
+	// GatewayRef is the Gateway the route attaches to
+	// +kubebuilder:validation:Required
+	GatewayRef GatewayReference `json:"gatewayRef"`
+
+	// Hostnames the route matches. Empty matches every hostname of the Gateway listener
+	// +kubebuilder:validation:Optional
+	Hostnames []string `json:"hostnames,omitempty"`
+
+	// PathPrefix the route matches, passed to the backend unchanged (default: "/")
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^/`
+	PathPrefix string `json:"pathPrefix,omitempty"`
+}
+
+// GatewayReference identifies a Gateway, and optionally one of its listeners
+type GatewayReference struct {
+	// Name of the Gateway
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Namespace of the Gateway. Defaults to the namespace of the resource
+	// +kubebuilder:validation:Optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// SectionName selects a single listener of the Gateway
+	// +kubebuilder:validation:Optional
+	SectionName string `json:"sectionName,omitempty"`
+}
@@ -3,6 +3,7 @@ package v1alpha1
 import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // MCPServerType defines the type of MCP server runtime
@@ -18,6 +19,7 @@ const (
 // +kubebuilder:object:generate=true
 
 // MCPToolsConfig defines the tools configuration for MCP server
+// +kubebuilder:validation:XValidation:rule="(has(self.fromPackage) ? 1 : 0) + (has(self.fromString) ? 1 : 0) + (has(self.fromSecretKeyRef) ? 1 : 0) <= 1",message="only one of fromPackage, fromString and fromSecretKeyRef may be set"
 type MCPToolsConfig struct {
 	// FromPackage is the package name to run with uvx (e.g., "mcp-server-calculator")
 	// For python-runtime type: runs as "uvx <package-name>"
@@ -46,6 +48,24 @@ type MCPServerConfig struct {
 	// Env variables to pass to the MCP server
 	// +kubebuilder:validation:Optional
 	Env []corev1.EnvVar `json:"env,omitempty"`
+
+	// StdioBridge runs a server that only speaks stdio behind a bridge sidecar
+	// that exposes it over SSE
+	// +kubebuilder:validation:Optional
+	StdioBridge *StdioBridgeConfig `json:"stdioBridge,omitempty"`
+}
+
+// +kubebuilder:object:generate=true
+
+// StdioBridgeConfig configures the stdio-to-SSE bridge sidecar
+type StdioBridgeConfig struct {
+	// Enabled runs the server with its stdio connected to the bridge sidecar
+	// +kubebuilder:validation:Optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// Image overrides the bridge image (default: the operator's DEFAULT_MCP_BRIDGE_IMAGE)
+	// +kubebuilder:validation:Optional
+	Image string `json:"image,omitempty"`
 }
 
 // +kubebuilder:object:generate=true
@@ -66,6 +86,88 @@ type MCPServerSpec struct {
 	// PodSpec allows overriding the generated pod spec using strategic merge patch
 	// +kubebuilder:validation:Optional
 	PodSpec *corev1.PodSpec `json:"podSpec,omitempty"`
+
+	// PinDigest resolves the container image tag to its digest at reconcile time and
+	// deploys the digest form, so that pods never silently pick up a re-pushed tag.
+	// The resolved digest is reused until the image tag changes.
+	// +kubebuilder:validation:Optional
+	PinDigest bool `json:"pinDigest,omitempty"`
+
+	// LogLevel overrides the log level of this resource's pods via the LOG_LEVEL env var
+	// (one of DEBUG, INFO, WARNING, ERROR, CRITICAL)
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:XValidation:rule="self.upperAscii() in ['DEBUG', 'INFO', 'WARNING', 'ERROR', 'CRITICAL']",message="must be one of DEBUG, INFO, WARNING, ERROR or CRITICAL"
+	LogLevel string `json:"logLevel,omitempty"`
+
+	// MeshInjection sets the service mesh sidecar injection annotation on the pods
+	// (sidecar.istio.io/inject or linkerd.io/inject, depending on the operator's MESH_TYPE)
+	// +kubebuilder:validation:Optional
+	MeshInjection MeshInjection `json:"meshInjection,omitempty"`
+
+	// Size sets the requests and limits of the mcp-server container from an operator-configured
+	// preset when podSpec sets no resources for it. It is expanded by the defaulting webhook.
+	// +kubebuilder:validation:Optional
+	Size Size `json:"size,omitempty"`
+
+	// RuntimeClassName selects the RuntimeClass for the pods, e.g. a sandboxed (gVisor, Kata)
+	// or GPU runtime
+	// +kubebuilder:validation:Optional
+	RuntimeClassName *string `json:"runtimeClassName,omitempty"`
+
+	// Overhead is the resource overhead of the pod sandbox, such as a Kata or gVisor VM, that
+	// the scheduler and resource quotas add to the container requests. It must match the
+	// RuntimeClass overhead where the RuntimeClass admission controller is enabled, which
+	// fills it in otherwise
+	// +kubebuilder:validation:Optional
+	Overhead corev1.ResourceList `json:"overhead,omitempty"`
+
+	// EnableServiceLinks injects the environment variables Docker links would set for every
+	// Service in the namespace into the pods. Off by default, as they bloat the environment
+	// and expose the namespace's Services (default: false)
+	// +kubebuilder:validation:Optional
+	EnableServiceLinks *bool `json:"enableServiceLinks,omitempty"`
+
+	// AutomountServiceAccountToken mounts a token of the pods' service account. Off by
+	// default to reduce token exposure; enable it for servers that call the Kubernetes API
+	// (default: false)
+	// +kubebuilder:validation:Optional
+	AutomountServiceAccountToken *bool `json:"automountServiceAccountToken,omitempty"`
+
+	// ImagePullPolicy of the resource's main container (Always, IfNotPresent or Never).
+	// Defaults to Always for untagged or :latest images and IfNotPresent otherwise
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Always;IfNotPresent;Never
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// WorkingDir is the working directory of the resource's main container, for images whose
+	// entrypoint expects a specific one. Defaults to the image's working directory
+	// +kubebuilder:validation:Optional
+	WorkingDir string `json:"workingDir,omitempty"`
+
+	// ToolsConfigMapRef mounts a ConfigMap of tool definition files at /etc/mcp/tools and
+	// points MCP_TOOLS_CONFIG_PATH at it. Editing the ConfigMap rolls the pods.
+	// +kubebuilder:validation:Optional
+	ToolsConfigMapRef *corev1.LocalObjectReference `json:"toolsConfigMapRef,omitempty"`
+
+	// ServicePort sets the name and appProtocol of the generated Service port
+	// +kubebuilder:validation:Optional
+	ServicePort *ServicePortConfig `json:"servicePort,omitempty"`
+
+	// MetricsPort is the port the server serves Prometheus metrics on, at /metrics. It is
+	// exposed as the metrics port of the container and Service, and reported in
+	// status.metricsEndpoint so dashboards and ServiceMonitors can discover it
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	MetricsPort *int32 `json:"metricsPort,omitempty"`
+
+	// SessionAffinityTimeout is how long, in seconds, the Service keeps routing a client to the
+	// same pod. It applies to servers behind the stdio bridge, whose SSE sessions live in one
+	// pod, so their Service uses ClientIP session affinity (default: 10800, 3 hours)
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=86400
+	SessionAffinityTimeout *int32 `json:"sessionAffinityTimeout,omitempty"`
 }
 
 // +kubebuilder:object:generate=true
@@ -82,6 +184,10 @@ type MCPServerStatus struct {
 	// Endpoint is the service endpoint for the MCP server
 	Endpoint string `json:"endpoint,omitempty"`
 
+	// MetricsEndpoint is the URL of the server's Prometheus metrics, set when spec.metricsPort is
+	// +kubebuilder:validation:Optional
+	MetricsEndpoint string `json:"metricsEndpoint,omitempty"`
+
 	// AvailableTools lists tools exposed by this server
 	// +kubebuilder:validation:Optional
 	AvailableTools []string `json:"availableTools,omitempty"`
@@ -89,9 +195,52 @@ type MCPServerStatus struct {
 	// Message provides additional status information
 	Message string `json:"message,omitempty"`
 
+	// LastReconcileTime is when the operator last completed a full reconcile of the resource.
+	// A timestamp that stops advancing while the spec changes points to a stuck resource
+	// +kubebuilder:validation:Optional
+	LastReconcileTime *metav1.Time `json:"lastReconcileTime,omitempty"`
+
 	// Deployment contains status information from the underlying Deployment
 	// +kubebuilder:validation:Optional
 	Deployment *DeploymentStatus `json:"deployment,omitempty"`
+
+	// ResolvedImage is the digest-pinned image deployed when spec.pinDigest is enabled
+	// +kubebuilder:validation:Optional
+	ResolvedImage string `json:"resolvedImage,omitempty"`
+
+	// Selector is the pod label selector in string form, matching the pods of the underlying
+	// Deployment, e.g. for kubectl get pods -l or a HorizontalPodAutoscaler scaling it
+	// +kubebuilder:validation:Optional
+	Selector string `json:"selector,omitempty"`
+
+	// DiscoveredTools lists the tools advertised by the running server via MCP discovery
+	// +kubebuilder:validation:Optional
+	DiscoveredTools []DiscoveredTool `json:"discoveredTools,omitempty"`
+
+	// Conditions represent the latest available observations of the MCPServer state
+	// +kubebuilder:validation:Optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// ConditionHistory lists the latest status changes of the conditions, oldest first, up
+	// to the operator's --condition-history-limit, to diagnose flapping conditions
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxItems=100
+	ConditionHistory []ConditionTransition `json:"conditionHistory,omitempty"`
+}
+
+// DiscoveredTool describes a tool advertised by an MCP server
+type DiscoveredTool struct {
+	// Name of the tool
+	Name string `json:"name"`
+
+	// Description of the tool
+	// +kubebuilder:validation:Optional
+	Description string `json:"description,omitempty"`
+
+	// InputSchema is the JSON schema of the tool arguments
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	InputSchema *runtime.RawExtension `json:"inputSchema,omitempty"`
 }
 
 // +kubebuilder:object:root=true
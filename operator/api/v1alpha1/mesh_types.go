@@ -0,0 +1,12 @@
+package v1alpha1
+
+// MeshInjection toggles service mesh sidecar injection for a resource's pods
+// +kubebuilder:validation:Enum=enabled;disabled
+type MeshInjection string
+
+const (
+	// MeshInjectionEnabled asks the mesh to inject its sidecar proxy
+	MeshInjectionEnabled MeshInjection = "enabled"
+	// MeshInjectionDisabled opts the pods out of sidecar injection
+	MeshInjectionDisabled MeshInjection = "disabled"
+)
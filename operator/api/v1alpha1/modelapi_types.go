@@ -5,6 +5,10 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// AllowModeMigrationAnnotation, set to "true" on a ModelAPI, permits changing spec.mode. The
+// operator then deletes the Deployment, Service and ConfigMap of the old mode and recreates them
+const AllowModeMigrationAnnotation = "kaos.agentic/allow-mode-migration"
+
 // ModelAPIMode defines the mode for model API deployment
 type ModelAPIMode string
 
@@ -15,9 +19,20 @@ const (
 	ModelAPIModeHosted ModelAPIMode = "Hosted"
 )
 
+// ModelAPIBackend selects what runs the ModelAPI pods
+type ModelAPIBackend string
+
+const (
+	// ModelAPIBackendDeployment runs the pods from a Deployment managed by the operator
+	ModelAPIBackendDeployment ModelAPIBackend = "Deployment"
+	// ModelAPIBackendKServe runs the pods from a KServe InferenceService
+	ModelAPIBackendKServe ModelAPIBackend = "KServe"
+)
+
 // +kubebuilder:object:generate=true
 
 // ConfigYamlSource defines the source of LiteLLM config YAML
+// +kubebuilder:validation:XValidation:rule="!(has(self.fromString) && has(self.fromSecretKeyRef))",message="only one of fromString and fromSecretKeyRef may be set"
 type ConfigYamlSource struct {
 	// FromString is the config YAML as a literal string
 	// +kubebuilder:validation:Optional
@@ -31,6 +46,7 @@ type ConfigYamlSource struct {
 // +kubebuilder:object:generate=true
 
 // ApiKeyValueFrom defines sources for API key values
+// +kubebuilder:validation:XValidation:rule="!(has(self.secretKeyRef) && has(self.configMapKeyRef))",message="only one of secretKeyRef and configMapKeyRef may be set"
 type ApiKeyValueFrom struct {
 	// SecretKeyRef is a reference to a secret key
 	// +kubebuilder:validation:Optional
@@ -44,6 +60,7 @@ type ApiKeyValueFrom struct {
 // +kubebuilder:object:generate=true
 
 // ApiKeySource defines the source of an API key
+// +kubebuilder:validation:XValidation:rule="!(has(self.value) && has(self.valueFrom))",message="only one of value and valueFrom may be set"
 type ApiKeySource struct {
 	// Value is a direct string value (not recommended for production)
 	// +kubebuilder:validation:Optional
@@ -77,6 +94,12 @@ type ProxyConfig struct {
 	// +kubebuilder:validation:Optional
 	APIBase string `json:"apiBase,omitempty"`
 
+	// Upstreams is a prioritized list of backend LLM API base URLs, used instead of apiBase
+	// to fail over when the primary is down. Requests go to the first available upstream in
+	// order. Set as the comma-separated PROXY_API_BASES environment variable
+	// +kubebuilder:validation:Optional
+	Upstreams []string `json:"upstreams,omitempty"`
+
 	// APIKey for authentication with the backend LLM API
 	// Set as PROXY_API_KEY environment variable
 	// +kubebuilder:validation:Optional
@@ -102,16 +125,137 @@ type HostedConfig struct {
 	// Env variables to pass to the Ollama server
 	// +kubebuilder:validation:Optional
 	Env []corev1.EnvVar `json:"env,omitempty"`
+
+	// SharedCacheClaim names an existing ReadWriteMany PersistentVolumeClaim holding a
+	// pre-populated model cache shared by several ModelAPIs. It is mounted read-only as the
+	// Ollama models directory instead of pulling the model into each pod. The operator never
+	// creates the claim and reports its access modes in the SharedCacheReady condition
+	// +kubebuilder:validation:Optional
+	SharedCacheClaim string `json:"sharedCacheClaim,omitempty"`
+
+	// DeletePVCOnDelete deletes the sharedCacheClaim when the ModelAPI is deleted and no
+	// other ModelAPI in the namespace still uses it, so unused model caches do not keep
+	// accruing storage cost. Only claims labelled kaos.tools/managed-cache=true are deleted
+	// (default: false, the claim is retained)
+	// +kubebuilder:validation:Optional
+	DeletePVCOnDelete bool `json:"deletePVCOnDelete,omitempty"`
+
+	// Command replaces the entrypoint of the Ollama image, e.g. to launch a different server.
+	// Entries may reference {{ .Model }} and {{ .ModelsDir }}, the directory models are
+	// stored in; any other placeholder is rejected
+	// +kubebuilder:validation:Optional
+	Command []string `json:"command,omitempty"`
+
+	// Args are passed to command, or to the image entrypoint when command is unset, and may
+	// reference the same placeholders
+	// +kubebuilder:validation:Optional
+	Args []string `json:"args,omitempty"`
+}
+
+// HealthCheckType selects how the ModelAPI pods are probed
+// +kubebuilder:validation:Enum=http;grpc
+type HealthCheckType string
+
+const (
+	// HealthCheckTypeHTTP probes the server's HTTP health endpoint (default)
+	HealthCheckTypeHTTP HealthCheckType = "http"
+	// HealthCheckTypeGRPC probes the server through the gRPC health checking protocol
+	HealthCheckTypeGRPC HealthCheckType = "grpc"
+)
+
+// +kubebuilder:object:generate=true
+
+// HealthCheckConfig configures the liveness and readiness probes of ModelAPI pods
+type HealthCheckConfig struct {
+	// Type is http (default) or grpc, for model servers such as Triton or vLLM that expose
+	// the grpc.health.v1 service. Clusters older than Kubernetes 1.24 have no native gRPC
+	// probes and run grpc_health_probe from the server image instead
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=http
+	Type HealthCheckType `json:"type,omitempty"`
+
+	// Port is the gRPC port to probe (default: the server's container port)
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port *int32 `json:"port,omitempty"`
+
+	// Service is the service name sent in the gRPC health check request (default: the
+	// server's overall health)
+	// +kubebuilder:validation:Optional
+	Service string `json:"service,omitempty"`
+
+	// InitialDelaySeconds is how long both probes wait after the container starts
+	// (default: 30 for liveness, 15 for readiness). Must be positive
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	InitialDelaySeconds *int32 `json:"initialDelaySeconds,omitempty"`
+
+	// PeriodSeconds is how often both probes run (default: 10 for liveness, 5 for
+	// readiness). Must be positive and more than the timeoutSeconds of both probes
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	PeriodSeconds *int32 `json:"periodSeconds,omitempty"`
+
+	// TimeoutSeconds is how long a probe may take before it fails (default: 5 for liveness,
+	// 3 for readiness). Must be positive and less than the periodSeconds of both probes
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+}
+
+// +kubebuilder:object:generate=true
+
+// RateLimitConfig limits the request rate forwarded to the upstream provider
+type RateLimitConfig struct {
+	// RequestsPerSecond is the sustained number of requests per second let through
+	// +kubebuilder:validation:Minimum=1
+	RequestsPerSecond int32 `json:"requestsPerSecond"`
+
+	// Burst is the number of requests above the rate that are admitted immediately
+	// before further requests are rejected with 429 (default: requestsPerSecond)
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	Burst *int32 `json:"burst,omitempty"`
+}
+
+// +kubebuilder:object:generate=true
+
+// SurgeReplicasConfig keeps extra warm replicas during a known traffic spike
+type SurgeReplicasConfig struct {
+	// Replicas is the number of replicas added to the minReplicas of the
+	// HorizontalPodAutoscalers scaling the ModelAPI while the surge window is open
+	// +kubebuilder:validation:Minimum=1
+	Replicas int32 `json:"replicas"`
+
+	// Start is when the surge window opens (default: immediately)
+	// +kubebuilder:validation:Optional
+	Start *metav1.Time `json:"start,omitempty"`
+
+	// End is when the surge window closes and minReplicas is restored
+	// +kubebuilder:validation:Required
+	End metav1.Time `json:"end"`
 }
 
 // +kubebuilder:object:generate=true
 
 // ModelAPISpec defines the desired state of ModelAPI
+// +kubebuilder:validation:XValidation:rule="!(has(self.proxyConfig) && has(self.hostedConfig))",message="only one of proxyConfig and hostedConfig may be set; mode Proxy only uses proxyConfig and mode Hosted only hostedConfig"
+// +kubebuilder:validation:XValidation:rule="!has(self.mtls) || self.mode == 'Proxy'",message="mtls requires mode Proxy, as the Hosted Ollama server does not serve TLS"
+// +kubebuilder:validation:XValidation:rule="!has(self.externalTrafficPolicy) || (has(self.serviceType) && self.serviceType in ['NodePort', 'LoadBalancer'])",message="externalTrafficPolicy only applies to NodePort and LoadBalancer service types"
 type ModelAPISpec struct {
 	// Mode specifies the deployment mode (Proxy or Hosted)
 	// +kubebuilder:validation:Enum=Proxy;Hosted
 	Mode ModelAPIMode `json:"mode"`
 
+	// Backend selects whether the pods run from a Deployment (default) or, for clusters
+	// standardized on KServe, a serving.kserve.io InferenceService named modelapi-<name>.
+	// Without the KServe CRDs the ModelAPI falls back to a Deployment
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Deployment;KServe
+	// +kubebuilder:default=Deployment
+	Backend ModelAPIBackend `json:"backend,omitempty"`
+
 	// ProxyConfig contains configuration for Proxy mode
 	// +kubebuilder:validation:Optional
 	ProxyConfig *ProxyConfig `json:"proxyConfig,omitempty"`
@@ -124,9 +268,172 @@ type ModelAPISpec struct {
 	// +kubebuilder:validation:Optional
 	GatewayRoute *GatewayRoute `json:"gatewayRoute,omitempty"`
 
+	// HTTPRoute creates a Gateway API HTTPRoute named modelapi-<name>-route that attaches to
+	// the referenced Gateway and routes to the ModelAPI Service. Requires the Gateway API CRDs
+	// +kubebuilder:validation:Optional
+	HTTPRoute *HTTPRouteConfig `json:"httpRoute,omitempty"`
+
+	// MTLS mounts a TLS Secret into the ModelAPI pods for mutual TLS with the Agents using
+	// it, which must reference the same Secret in their spec.mtls. The model server then
+	// serves HTTPS with the Secret's certificate. Proxy mode only
+	// +kubebuilder:validation:Optional
+	MTLS *MTLSConfig `json:"mtls,omitempty"`
+
 	// PodSpec allows overriding the generated pod spec using strategic merge patch
 	// +kubebuilder:validation:Optional
 	PodSpec *corev1.PodSpec `json:"podSpec,omitempty"`
+
+	// PinDigest resolves the container image tag to its digest at reconcile time and
+	// deploys the digest form, so that pods never silently pick up a re-pushed tag.
+	// The resolved digest is reused until the image tag changes.
+	// +kubebuilder:validation:Optional
+	PinDigest bool `json:"pinDigest,omitempty"`
+
+	// HostAliases adds entries to the pod's /etc/hosts for hostnames that must resolve
+	// to fixed IPs (e.g., external services with unreliable DNS)
+	// +kubebuilder:validation:Optional
+	HostAliases []corev1.HostAlias `json:"hostAliases,omitempty"`
+
+	// ServiceType is the type of the ModelAPI Service (default: ClusterIP)
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=ClusterIP;NodePort;LoadBalancer
+	ServiceType corev1.ServiceType `json:"serviceType,omitempty"`
+
+	// ExternalTrafficPolicy controls routing of external traffic for NodePort and
+	// LoadBalancer services. Local preserves client source IPs (default: Cluster)
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Cluster;Local
+	ExternalTrafficPolicy corev1.ServiceExternalTrafficPolicy `json:"externalTrafficPolicy,omitempty"`
+
+	// LogLevel overrides the log level of this resource's pods via the LOG_LEVEL env var
+	// (one of DEBUG, INFO, WARNING, ERROR, CRITICAL)
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:XValidation:rule="self.upperAscii() in ['DEBUG', 'INFO', 'WARNING', 'ERROR', 'CRITICAL']",message="must be one of DEBUG, INFO, WARNING, ERROR or CRITICAL"
+	LogLevel string `json:"logLevel,omitempty"`
+
+	// RateLimit runs a rate-limiting proxy in front of the LiteLLM container so that
+	// upstream providers are not overwhelmed (Proxy mode only)
+	// +kubebuilder:validation:Optional
+	RateLimit *RateLimitConfig `json:"rateLimit,omitempty"`
+
+	// MeshInjection sets the service mesh sidecar injection annotation on the pods
+	// (sidecar.istio.io/inject or linkerd.io/inject, depending on the operator's MESH_TYPE)
+	// +kubebuilder:validation:Optional
+	MeshInjection MeshInjection `json:"meshInjection,omitempty"`
+
+	// Size sets the requests and limits of the model-api container from an operator-configured
+	// preset when podSpec sets no resources for it. It is expanded by the defaulting webhook.
+	// +kubebuilder:validation:Optional
+	Size Size `json:"size,omitempty"`
+
+	// DrainSeconds is how long a terminating pod keeps serving in-flight requests after it
+	// is removed from the Service endpoints, via a preStop sleep and the kaos.tools/serving
+	// readiness gate (unset or 0 disables draining)
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=600
+	DrainSeconds *int32 `json:"drainSeconds,omitempty"`
+
+	// ServiceAnnotations are added to the generated Service, e.g. to configure cloud load
+	// balancers. Keys in the kaos.tools/ domain are reserved for the operator and ignored.
+	// +kubebuilder:validation:Optional
+	ServiceAnnotations map[string]string `json:"serviceAnnotations,omitempty"`
+
+	// ServicePort sets the name and appProtocol of the generated Service port
+	// +kubebuilder:validation:Optional
+	ServicePort *ServicePortConfig `json:"servicePort,omitempty"`
+
+	// Extra passes provider-specific options to the model-api container as a JSON object
+	// in the KAOS_EXTRA env var, for settings the schema does not model
+	// +kubebuilder:validation:Optional
+	Extra map[string]string `json:"extra,omitempty"`
+
+	// RuntimeClassName selects the RuntimeClass for the pods, e.g. a sandboxed (gVisor, Kata)
+	// or GPU runtime. GPU pods default to the operator's DEFAULT_GPU_RUNTIME_CLASS
+	// +kubebuilder:validation:Optional
+	RuntimeClassName *string `json:"runtimeClassName,omitempty"`
+
+	// Overhead is the resource overhead of the pod sandbox, such as a Kata or gVisor VM, that
+	// the scheduler and resource quotas add to the container requests. It must match the
+	// RuntimeClass overhead where the RuntimeClass admission controller is enabled, which
+	// fills it in otherwise
+	// +kubebuilder:validation:Optional
+	Overhead corev1.ResourceList `json:"overhead,omitempty"`
+
+	// SchedulerName dispatches the pods to a custom scheduler, e.g. a gang scheduler for
+	// multi-GPU workloads. Defaults to the cluster's default scheduler
+	// +kubebuilder:validation:Optional
+	SchedulerName string `json:"schedulerName,omitempty"`
+
+	// Architecture pins the pods to nodes of one CPU architecture through a kubernetes.io/arch
+	// node selector, for model server images built for arm64 or amd64 only. Defaults to any
+	// architecture, which suits multi-arch images
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=amd64;arm64;ppc64le;s390x
+	Architecture string `json:"architecture,omitempty"`
+
+	// EnableServiceLinks injects the environment variables Docker links would set for every
+	// Service in the namespace into the pods. Off by default, as they bloat the environment
+	// and expose the namespace's Services (default: false)
+	// +kubebuilder:validation:Optional
+	EnableServiceLinks *bool `json:"enableServiceLinks,omitempty"`
+
+	// AutomountServiceAccountToken mounts a token of the pods' service account. Off by
+	// default to reduce token exposure; enable it for servers that call the Kubernetes API
+	// (default: false)
+	// +kubebuilder:validation:Optional
+	AutomountServiceAccountToken *bool `json:"automountServiceAccountToken,omitempty"`
+
+	// ImagePullPolicy of the resource's main container (Always, IfNotPresent or Never).
+	// Defaults to Always for untagged or :latest images and IfNotPresent otherwise
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Always;IfNotPresent;Never
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// WorkingDir is the working directory of the resource's main container, for images whose
+	// entrypoint expects a specific one. Defaults to the image's working directory
+	// +kubebuilder:validation:Optional
+	WorkingDir string `json:"workingDir,omitempty"`
+
+	// Replicas is the number of ModelAPI pods (default: 1). The ModelAPI supports the scale
+	// subresource, so kubectl scale and HorizontalPodAutoscalers can target it directly
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// SurgeReplicas temporarily raises the minReplicas of the HorizontalPodAutoscalers
+	// scaling the ModelAPI or its Deployment, keeping warm replicas ready for a known
+	// traffic spike. The original minReplicas is restored when the window closes
+	// +kubebuilder:validation:Optional
+	SurgeReplicas *SurgeReplicasConfig `json:"surgeReplicas,omitempty"`
+
+	// RevisionHistoryLimit is the number of old ReplicaSets the Deployment keeps for
+	// rollbacks (default: 3)
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
+
+	// ProgressDeadlineSeconds is how long a rollout of the Deployment may make no progress
+	// before the ModelAPI is marked Degraded with reason RolloutStuck (default: 600)
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	ProgressDeadlineSeconds *int32 `json:"progressDeadlineSeconds,omitempty"`
+
+	// MinReadySeconds is how long a new pod must be ready before it counts as available. With
+	// the BlueGreenRollout feature gate, old pods keep running until every new pod has been
+	// available this long. Must be less than progressDeadlineSeconds.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	MinReadySeconds *int32 `json:"minReadySeconds,omitempty"`
+
+	// SpreadReplicas adds a preferred pod anti-affinity spreading replicas across nodes when
+	// replicas > 1 and podSpec sets no affinity (default: true)
+	// +kubebuilder:validation:Optional
+	SpreadReplicas *bool `json:"spreadReplicas,omitempty"`
+
+	// HealthCheck configures how the pods are probed. Type grpc requires Hosted mode
+	// +kubebuilder:validation:Optional
+	HealthCheck *HealthCheckConfig `json:"healthCheck,omitempty"`
 }
 
 // +kubebuilder:object:generate=true
@@ -146,13 +453,45 @@ type ModelAPIStatus struct {
 	// Message provides additional status information
 	Message string `json:"message,omitempty"`
 
+	// LastReconcileTime is when the operator last completed a full reconcile of the resource.
+	// A timestamp that stops advancing while the spec changes points to a stuck resource
+	// +kubebuilder:validation:Optional
+	LastReconcileTime *metav1.Time `json:"lastReconcileTime,omitempty"`
+
 	// Deployment contains status information from the underlying Deployment
 	// +kubebuilder:validation:Optional
 	Deployment *DeploymentStatus `json:"deployment,omitempty"`
+
+	// ResolvedImage is the digest-pinned image deployed when spec.pinDigest is enabled
+	// +kubebuilder:validation:Optional
+	ResolvedImage string `json:"resolvedImage,omitempty"`
+
+	// Replicas is the number of pods of the underlying Deployment, reported by the scale subresource
+	// +kubebuilder:validation:Optional
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// ReadyReplicas is the number of ready pods of the underlying Deployment
+	// +kubebuilder:validation:Optional
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+
+	// Selector is the pod label selector in string form, used by the scale subresource
+	// +kubebuilder:validation:Optional
+	Selector string `json:"selector,omitempty"`
+
+	// Conditions represent the latest available observations of the ModelAPI state
+	// +kubebuilder:validation:Optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// ConditionHistory lists the latest status changes of the conditions, oldest first, up
+	// to the operator's --condition-history-limit, to diagnose flapping conditions
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxItems=100
+	ConditionHistory []ConditionTransition `json:"conditionHistory,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:subresource:scale:specpath=.spec.replicas,statuspath=.status.replicas,selectorpath=.status.selector
 // +kubebuilder:resource:shortName=api;apis
 // +kubebuilder:printcolumn:name="Mode",type=string,JSONPath=`.spec.mode`
 // +kubebuilder:printcolumn:name="Ready",type=boolean,JSONPath=`.status.ready`
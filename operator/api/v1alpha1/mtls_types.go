@@ -0,0 +1,13 @@
+package v1alpha1
+
+// +kubebuilder:object:generate=true
+
+// MTLSConfig mounts a TLS Secret for mutual TLS between an Agent and its ModelAPI.
+// This is a shared type used by Agent and ModelAPI; both must reference the same Secret.
+type MTLSConfig struct {
+	// SecretName is a kubernetes.io/tls Secret in the resource's namespace holding tls.crt,
+	// tls.key and the ca.crt that signs the peer's certificate
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	SecretName string `json:"secretName"`
+}
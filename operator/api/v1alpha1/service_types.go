@@ -0,0 +1,21 @@
+package v1alpha1
+
+// +kubebuilder:object:generate=true
+
+// ServicePortConfig customizes the port of the generated Service.
+// This is a shared type used by ModelAPI and MCPServer.
+type ServicePortConfig struct {
+	// Name is the name of the Service port (default: http)
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxLength=15
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name,omitempty"`
+
+	// AppProtocol is the application protocol of the port, such as http, grpc, h2c or
+	// kubernetes.io/h2c, which meshes and gateways use to route it. Defaults to grpc for a
+	// ModelAPI probed over gRPC on its serving port, and http otherwise
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	AppProtocol string `json:"appProtocol,omitempty"`
+}
@@ -0,0 +1,15 @@
+package v1alpha1
+
+// Size is a t-shirt size that the defaulting webhooks expand into the resources of a
+// resource's main container
+// +kubebuilder:validation:Enum=small;medium;large
+type Size string
+
+const (
+	// SizeSmall suits development and light workloads
+	SizeSmall Size = "small"
+	// SizeMedium suits typical production workloads
+	SizeMedium Size = "medium"
+	// SizeLarge suits heavy workloads
+	SizeLarge Size = "large"
+)
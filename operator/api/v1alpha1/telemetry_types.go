@@ -0,0 +1,40 @@
+package v1alpha1
+
+// +kubebuilder:object:generate=true
+
+// TelemetryConfig defines OpenTelemetry export settings for a resource's pods.
+// The settings are passed to the runtime as standard OTEL_* environment variables.
+type TelemetryConfig struct {
+	// Enabled turns on OpenTelemetry export
+	// +kubebuilder:validation:Optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// Endpoint is the OTLP collector endpoint (e.g., "http://otel-collector:4317")
+	// +kubebuilder:validation:Optional
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// TracesEndpoint overrides Endpoint for traces, e.g. to send them to a separate backend.
+	// Per-signal endpoints are used as-is, so OTLP/HTTP URLs must include the /v1/traces path
+	// +kubebuilder:validation:Optional
+	TracesEndpoint string `json:"tracesEndpoint,omitempty"`
+
+	// MetricsEndpoint overrides Endpoint for metrics
+	// +kubebuilder:validation:Optional
+	MetricsEndpoint string `json:"metricsEndpoint,omitempty"`
+
+	// LogsEndpoint overrides Endpoint for logs
+	// +kubebuilder:validation:Optional
+	LogsEndpoint string `json:"logsEndpoint,omitempty"`
+
+	// Headers are sent with every OTLP export, e.g. an authentication header for a hosted
+	// backend. They are passed in OTEL_EXPORTER_OTLP_HEADERS, so names must be valid HTTP
+	// header names and values may not contain commas or newlines
+	// +kubebuilder:validation:Optional
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// FailFast leaves the SDK export defaults untouched. When false (default), short
+	// export timeouts and bounded batch settings are applied so that an unreachable
+	// collector drops telemetry instead of blocking the application.
+	// +kubebuilder:validation:Optional
+	FailFast bool `json:"failFast,omitempty"`
+}
@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	"fmt"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+	"github.com/axsaucedo/kaos/operator/pkg/util"
+)
+
+// validateAgentCommand reports the first spec.command or spec.args entry using an
+// unsupported placeholder, for clusters running without the admission webhook
+func validateAgentCommand(agent *kaosv1alpha1.Agent) error {
+	for i, value := range agent.Spec.Command {
+		if err := util.ValidateCommandTemplate(value); err != nil {
+			return fmt.Errorf("spec.command[%d]: %w", i, err)
+		}
+	}
+	for i, value := range agent.Spec.Args {
+		if err := util.ValidateCommandTemplate(value); err != nil {
+			return fmt.Errorf("spec.args[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// expandAgentCommand expands the placeholders of spec.command or spec.args from the
+// resolved ModelAPI and MCPServer endpoints. Entries that fail to expand are kept as
+// written; validateAgentCommand rejects them before the Deployment is built.
+func expandAgentCommand(values []string, data util.CommandTemplateData) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	expanded := make([]string, len(values))
+	for i, value := range values {
+		out, err := util.ExpandCommandTemplate(value, data)
+		if err != nil {
+			out = value
+		}
+		expanded[i] = out
+	}
+	return expanded
+}
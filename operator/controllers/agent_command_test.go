@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+var _ = Describe("Agent command templating", func() {
+	var (
+		agent    *kaosv1alpha1.Agent
+		modelapi *kaosv1alpha1.ModelAPI
+	)
+
+	BeforeEach(func() {
+		modelapi = &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+			Status:     kaosv1alpha1.ModelAPIStatus{Endpoint: "http://modelapi-api.default.svc.cluster.local:8000"},
+		}
+		agent = &kaosv1alpha1.Agent{
+			ObjectMeta: metav1.ObjectMeta{Name: "flags", Namespace: "default"},
+			Spec: kaosv1alpha1.AgentSpec{
+				ModelAPI: "api",
+				Model:    "openai/gpt-4",
+				Command:  []string{"my-agent"},
+				Args:     []string{"--model-url={{ .ModelEndpoint }}", "--mcp={{ .MCPEndpoints }}", "--verbose"},
+			},
+		}
+	})
+
+	It("should expand the model and MCP endpoints into args", func() {
+		Expect(validateAgentCommand(agent)).To(Succeed())
+		mcpServers := map[string]string{
+			"search":     "http://mcpserver-search.default.svc.cluster.local:8000",
+			"calculator": "http://mcpserver-calculator.default.svc.cluster.local:8000",
+		}
+
+		container := (&AgentReconciler{}).constructDeployment(agent, modelapi, mcpServers, nil, nil).Spec.Template.Spec.Containers[0]
+		Expect(container.Command).To(Equal([]string{"my-agent"}))
+		Expect(container.Args).To(Equal([]string{
+			"--model-url=http://modelapi-api.default.svc.cluster.local:8000",
+			"--mcp=http://mcpserver-calculator.default.svc.cluster.local:8000,http://mcpserver-search.default.svc.cluster.local:8000",
+			"--verbose",
+		}))
+	})
+
+	It("should keep the image entrypoint when command and args are unset", func() {
+		agent.Spec.Command, agent.Spec.Args = nil, nil
+		container := (&AgentReconciler{}).constructDeployment(agent, modelapi, nil, nil, nil).Spec.Template.Spec.Containers[0]
+		Expect(container.Command).To(BeNil())
+		Expect(container.Args).To(BeNil())
+	})
+
+	It("should reject unsupported placeholders", func() {
+		agent.Spec.Args = []string{"--model-url={{ .ModelEndpoint }}", "--token={{ .Token }}"}
+		Expect(validateAgentCommand(agent)).To(MatchError(ContainSubstring("spec.args[1]")))
+	})
+})
@@ -2,24 +2,35 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
+	coordinationv1 "k8s.io/api/coordination/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlbuilder "sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
@@ -34,6 +45,31 @@ type AgentReconciler struct {
 	client.Client
 	Log    logr.Logger
 	Scheme *runtime.Scheme
+	// ImageResolver resolves image digests when spec.pinDigest is enabled
+	ImageResolver util.ImageResolver
+	// ReconcileCache short-circuits reconciles that observe no spec or child changes
+	ReconcileCache *util.ReconcileCache
+	// Recorder records events such as DriftCorrected on the reconciled resources
+	Recorder record.EventRecorder
+	// CircuitBreaker backs off from resources that fail repeatedly at the same generation
+	CircuitBreaker *util.CircuitBreaker
+	// Metrics counts reconciles and stamps the last reconcile timestamp of each resource
+	Metrics *util.ReconcileMetrics
+	// PodTemplatePatch is the platform-wide patch applied last to every generated pod template
+	PodTemplatePatch *util.PodTemplatePatch
+	// MaxReplicas caps the replicas of every generated workload, whatever spec.replicas or a
+	// HorizontalPodAutoscaler ask for; 0 means no cap
+	MaxReplicas int32
+	// Clock stamps status.lastReconcileTime; defaults to the real clock
+	Clock clock.PassiveClock
+	// ConditionHistoryLimit is the number of condition transitions kept in
+	// status.conditionHistory; 0 disables the history
+	ConditionHistoryLimit int
+	// RateLimiter paces the retries of failed reconciles; defaults to controller-runtime's
+	RateLimiter workqueue.TypedRateLimiter[reconcile.Request]
+	// DefaultAgentEgress is the baseline egress of agent pods: AgentEgressDeny applies a
+	// default-deny egress NetworkPolicy to every agent, anything else leaves egress open
+	DefaultAgentEgress string
 }
 
 //+kubebuilder:rbac:groups=kaos.tools,resources=agents,verbs=get;list;watch;create;update;patch;delete
@@ -42,11 +78,29 @@ type AgentReconciler struct {
 //+kubebuilder:rbac:groups=kaos.tools,resources=modelapis,verbs=get;list;watch
 //+kubebuilder:rbac:groups=kaos.tools,resources=mcpservers,verbs=get;list;watch
 //+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=apps,resources=replicasets,verbs=get;list;watch
+//+kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch;list
 //+kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;delete
+//+kubebuilder:rbac:groups="",resources=pods/eviction,verbs=create
+//+kubebuilder:rbac:groups="",resources=pods/ephemeralcontainers,verbs=update;patch
+//+kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;list;watch;create;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
 func (r *AgentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	result, err := r.reconcile(ctx, req)
+	r.CircuitBreaker.Record(req.NamespacedName, err)
+	recordConditionHistory(ctx, r.Client, &kaosv1alpha1.Agent{}, req.NamespacedName, r.ConditionHistoryLimit)
+	observeReconcile(ctx, r.Client, r.Metrics, "Agent", &kaosv1alpha1.Agent{}, req.NamespacedName, err)
+	return result, err
+}
+
+// reconcile performs a single reconcile of the Agent; Reconcile feeds its outcome to the circuit breaker
+func (r *AgentReconciler) reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := log.FromContext(ctx)
 
 	agent := &kaosv1alpha1.Agent{}
@@ -58,7 +112,14 @@ func (r *AgentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 	// Handle deletion with finalizer
 	if agent.ObjectMeta.DeletionTimestamp != nil {
 		if controllerutil.ContainsFinalizer(agent, agentFinalizerName) {
+			// Integrations registered through the extra-finalizers annotation clean up first
+			if pending := util.PendingExtraFinalizers(agent); len(pending) > 0 {
+				log.Info("Waiting for extra finalizers", "finalizers", pending)
+				return ctrl.Result{}, nil
+			}
 			log.Info("Deleting Agent", "name", agent.Name)
+			r.ReconcileCache.Forget(agent)
+			r.CircuitBreaker.Forget(req.NamespacedName)
 			controllerutil.RemoveFinalizer(agent, agentFinalizerName)
 			if err := r.Update(ctx, agent); err != nil {
 				log.Error(err, "failed to remove finalizer")
@@ -68,8 +129,8 @@ func (r *AgentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		return ctrl.Result{}, nil
 	}
 
-	// Add finalizer if not present
-	if !controllerutil.ContainsFinalizer(agent, agentFinalizerName) {
+	// Add finalizer, and any extra finalizers registered by integrations, if not present
+	if util.AddExtraFinalizers(agent) || !controllerutil.ContainsFinalizer(agent, agentFinalizerName) {
 		controllerutil.AddFinalizer(agent, agentFinalizerName)
 		if err := r.Update(ctx, agent); err != nil {
 			log.Error(err, "failed to add finalizer")
@@ -88,24 +149,68 @@ func (r *AgentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		}
 	}
 
-	// Resolve ModelAPI reference
+	// Reconciling again ends the pause recorded when the operator last shut down
+	if meta.RemoveStatusCondition(&agent.Status.Conditions, kaosv1alpha1.ConditionReconcilePaused) {
+		if err := r.Status().Update(ctx, agent); err != nil {
+			log.Error(err, "failed to update status")
+			return ctrl.Result{}, err
+		}
+	}
+
+	// Back off from resources that keep failing at the same generation
+	if circuitOpen(r.CircuitBreaker, agent, &agent.Status.Conditions) {
+		log.Info("circuit open, delaying reconcile", "interval", r.CircuitBreaker.Interval())
+		if err := r.Status().Update(ctx, agent); err != nil {
+			log.Error(err, "failed to update status")
+		}
+		return ctrl.Result{RequeueAfter: util.JitteredRequeue(r.CircuitBreaker.Interval())}, nil
+	}
+
+	// Resolve ModelAPI reference, the template of an embedded model, or the ModelAPI selected by labels
 	modelapi := &kaosv1alpha1.ModelAPI{}
-	err := r.Get(ctx, types.NamespacedName{Name: agent.Spec.ModelAPI, Namespace: agent.Namespace}, modelapi)
-	if err != nil {
-		log.Error(err, "unable to fetch ModelAPI", "modelAPI", agent.Spec.ModelAPI)
+	var err error
+	if selectsModelAPI(agent) {
+		modelapi, err = r.selectModelAPI(ctx, agent)
+		if errors.Is(err, errNoModelAPIMatch) {
+			log.Info("no Ready ModelAPI matches the selector, waiting")
+			agent.Status.Phase = "Waiting"
+			agent.Status.Message = err.Error()
+			setReadyCondition(&agent.Status.Conditions, agent.Generation, kaosv1alpha1.ReasonDependencyNotReady, agent.Status.Message)
+			r.Status().Update(ctx, agent)
+			return ctrl.Result{}, nil
+		}
+		if err != nil {
+			log.Error(err, "unable to select ModelAPI")
+			agent.Status.Phase = "Failed"
+			agent.Status.Message = fmt.Sprintf("Failed to resolve ModelAPI: %v", err)
+			setReadyCondition(&agent.Status.Conditions, agent.Generation, kaosv1alpha1.ReasonReconcileFailed, agent.Status.Message)
+			r.Status().Update(ctx, agent)
+			return ctrl.Result{}, nil
+		}
+	} else if err = r.Get(ctx, types.NamespacedName{Name: embeddedModelAPIName(agent), Namespace: agent.Namespace}, modelapi); err != nil {
+		log.Error(err, "unable to fetch ModelAPI", "modelAPI", embeddedModelAPIName(agent))
 		agent.Status.Phase = "Failed"
 		agent.Status.Message = fmt.Sprintf("Failed to resolve ModelAPI: %v", err)
+		setReadyCondition(&agent.Status.Conditions, agent.Generation, kaosv1alpha1.ReasonReconcileFailed, agent.Status.Message)
 		r.Status().Update(ctx, agent)
 		return ctrl.Result{}, err
 	}
 
+	// The egress policy and the ModelAPI's references use the resolved ModelAPI before the status is written
+	if agent.Status.LinkedResources == nil {
+		agent.Status.LinkedResources = make(map[string]string)
+	}
+	agent.Status.LinkedResources["modelapi"] = modelapi.Name
+
 	// Check if we should wait for dependencies (default true)
 	waitForDeps := agent.Spec.WaitForDependencies == nil || *agent.Spec.WaitForDependencies
 
-	if !modelapi.Status.Ready && waitForDeps {
-		log.Info("ModelAPI not ready, waiting", "modelAPI", agent.Spec.ModelAPI)
+	// An embedded model runs in the agent pods, so the template's own pods need not be ready
+	if !modelapi.Status.Ready && waitForDeps && agent.Spec.EmbeddedModel == nil {
+		log.Info("ModelAPI not ready, waiting", "modelAPI", modelapi.Name)
 		agent.Status.Phase = "Waiting"
 		agent.Status.Message = "ModelAPI is not ready"
+		setReadyCondition(&agent.Status.Conditions, agent.Generation, kaosv1alpha1.ReasonDependencyNotReady, agent.Status.Message)
 		r.Status().Update(ctx, agent)
 		return ctrl.Result{}, nil
 	}
@@ -115,12 +220,37 @@ func (r *AgentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		log.Error(err, "model validation failed")
 		agent.Status.Phase = "Failed"
 		agent.Status.Message = err.Error()
+		setReadyCondition(&agent.Status.Conditions, agent.Generation, kaosv1alpha1.ReasonReconcileFailed, agent.Status.Message)
+		r.Status().Update(ctx, agent)
+		return ctrl.Result{}, nil
+	}
+
+	// Both sides of an mTLS connection must mount the same Secret
+	if err := validateMTLS(agent, modelapi); err != nil {
+		log.Error(err, "mTLS validation failed")
+		agent.Status.Phase = "Failed"
+		agent.Status.Message = err.Error()
+		setReadyCondition(&agent.Status.Conditions, agent.Generation, kaosv1alpha1.ReasonReconcileFailed, agent.Status.Message)
+		r.Status().Update(ctx, agent)
+		return ctrl.Result{}, nil
+	}
+
+	// spec.command and spec.args may only reference the supported placeholders
+	if err := validateAgentCommand(agent); err != nil {
+		log.Error(err, "command validation failed")
+		agent.Status.Phase = "Failed"
+		agent.Status.Message = err.Error()
+		setReadyCondition(&agent.Status.Conditions, agent.Generation, kaosv1alpha1.ReasonReconcileFailed, agent.Status.Message)
 		r.Status().Update(ctx, agent)
 		return ctrl.Result{}, nil
 	}
 
+	// Resolved dependencies are part of the reconcile fingerprint
+	dependencies := []metav1.Object{modelapi}
+
 	// Resolve MCPServer references
 	mcpServers := make(map[string]string)
+	mcpTools := make(map[string][]string)
 	for _, mcpName := range agent.Spec.MCPServers {
 		mcp := &kaosv1alpha1.MCPServer{}
 		err := r.Get(ctx, types.NamespacedName{Name: mcpName, Namespace: agent.Namespace}, mcp)
@@ -128,6 +258,7 @@ func (r *AgentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 			log.Error(err, "unable to fetch MCPServer", "mcpserver", mcpName)
 			agent.Status.Phase = "Failed"
 			agent.Status.Message = fmt.Sprintf("Failed to resolve MCPServer %s: %v", mcpName, err)
+			setReadyCondition(&agent.Status.Conditions, agent.Generation, kaosv1alpha1.ReasonReconcileFailed, agent.Status.Message)
 			r.Status().Update(ctx, agent)
 			return ctrl.Result{}, err
 		}
@@ -136,11 +267,14 @@ func (r *AgentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 			log.Info("MCPServer not ready, waiting", "mcpserver", mcpName)
 			agent.Status.Phase = "Waiting"
 			agent.Status.Message = fmt.Sprintf("MCPServer %s is not ready", mcpName)
+			setReadyCondition(&agent.Status.Conditions, agent.Generation, kaosv1alpha1.ReasonDependencyNotReady, agent.Status.Message)
 			r.Status().Update(ctx, agent)
 			return ctrl.Result{}, nil
 		}
 
 		mcpServers[mcpName] = mcp.Status.Endpoint
+		mcpTools[mcpName] = mcp.Status.AvailableTools
+		dependencies = append(dependencies, mcp)
 	}
 
 	// Resolve peer agent endpoints
@@ -153,6 +287,7 @@ func (r *AgentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 				log.Info("peer agent not found yet", "peer", peerName)
 				continue
 			}
+			dependencies = append(dependencies, peerAgent)
 
 			if peerAgent.Status.Endpoint != "" {
 				peerAgents[peerName] = peerAgent.Status.Endpoint
@@ -161,51 +296,147 @@ func (r *AgentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		}
 	}
 
-	// Create or update Deployment
-	deployment := &appsv1.Deployment{}
-	deploymentName := fmt.Sprintf("agent-%s", agent.Name)
-	err = r.Get(ctx, types.NamespacedName{Name: deploymentName, Namespace: agent.Namespace}, deployment)
-
-	if err != nil && apierrors.IsNotFound(err) {
-		// Create new Deployment
-		deployment = r.constructDeployment(agent, modelapi, mcpServers, peerAgents)
-		if err := controllerutil.SetControllerReference(agent, deployment, r.Scheme); err != nil {
-			log.Error(err, "failed to set controller reference")
+	// Inject or remove ephemeral debug containers on running pods
+	if err := r.reconcileDebugContainers(ctx, agent, log); err != nil {
+		log.Error(err, "failed to reconcile debug containers")
+	}
+
+	// Pods are restarted at their active deadline even when nothing else changed
+	deadlineRequeue, err := r.reconcileActiveDeadline(ctx, agent, log)
+	if err != nil {
+		log.Error(err, "failed to enforce spec.activeDeadlineSeconds")
+	}
+
+	// Skip rendering when neither the spec, any child nor any dependency changed since the last reconcile
+	dependencies = append(dependencies, replicaSetFailures(ctx, r.Client, agent.Namespace, "agent-"+agent.Name,
+		map[string]string{"app": "agent", "agent": agent.Name}))
+	if fingerprint, ok := observedFingerprint(ctx, r.Client, agent.Namespace, r.childObjects(agent), dependencies...); ok &&
+		r.ReconcileCache.Unchanged(agent, fingerprint) {
+		return ctrl.Result{RequeueAfter: deadlineRequeue}, nil
+	}
+
+	// Apply the baseline egress policy before the pods start
+	if err := r.reconcileEgressPolicy(ctx, agent, log); err != nil {
+		log.Error(err, "failed to reconcile egress NetworkPolicy")
+		agent.Status.Phase = "Failed"
+		agent.Status.Message = fmt.Sprintf("Failed to reconcile egress NetworkPolicy: %v", err)
+		setReadyCondition(&agent.Status.Conditions, agent.Generation, kaosv1alpha1.ReasonReconcileFailed, agent.Status.Message)
+		r.Status().Update(ctx, agent)
+		return ctrl.Result{}, err
+	}
+
+	// Build desired Deployment, pinning the image digest if requested
+	desiredDeployment := r.constructDeployment(agent, modelapi, mcpServers, mcpTools, peerAgents)
+	if agent.Spec.PinDigest {
+		resolvedImage, err := util.PinImageDigest(ctx, r.ImageResolver, &desiredDeployment.Spec.Template, "agent", agent.Status.ResolvedImage)
+		if err != nil {
+			log.Error(err, "failed to pin image digest")
+			agent.Status.Phase = "Failed"
+			agent.Status.Message = fmt.Sprintf("Failed to pin image digest: %v", err)
+			setReadyCondition(&agent.Status.Conditions, agent.Generation, kaosv1alpha1.ReasonReconcileFailed, agent.Status.Message)
+			r.Status().Update(ctx, agent)
 			return ctrl.Result{}, err
 		}
+		agent.Status.ResolvedImage = resolvedImage
+	} else {
+		agent.Status.ResolvedImage = ""
+	}
 
-		log.Info("Creating Deployment", "name", deployment.Name)
-		if err := r.Create(ctx, deployment); err != nil {
-			log.Error(err, "failed to create Deployment")
+	// Create or update the workload: a StatefulSet for stateful agents, a Deployment otherwise
+	deployment := &appsv1.Deployment{}
+	requestedReplicas := specReplicas(agent.Spec.Replicas)
+	var statefulSet *appsv1.StatefulSet
+	if statefulAgent(agent) {
+		statefulSet, err = r.reconcileStatefulSet(ctx, agent, constructStatefulSet(agent, desiredDeployment), log)
+		if err != nil {
+			log.Error(err, "failed to reconcile StatefulSet")
 			agent.Status.Phase = "Failed"
-			agent.Status.Message = fmt.Sprintf("Failed to create Deployment: %v", err)
+			agent.Status.Message = fmt.Sprintf("Failed to reconcile StatefulSet: %v", err)
+			setReadyCondition(&agent.Status.Conditions, agent.Generation, kaosv1alpha1.ReasonReconcileFailed, agent.Status.Message)
 			r.Status().Update(ctx, agent)
 			return ctrl.Result{}, err
 		}
-	} else if err != nil {
-		log.Error(err, "failed to get Deployment")
-		return ctrl.Result{}, err
 	} else {
-		// Deployment exists - check if spec has changed using hash annotation
-		desiredDeployment := r.constructDeployment(agent, modelapi, mcpServers, peerAgents)
-		currentHash := ""
-		if deployment.Spec.Template.Annotations != nil {
-			currentHash = deployment.Spec.Template.Annotations[util.PodSpecHashAnnotation]
-		}
-		desiredHash := ""
-		if desiredDeployment.Spec.Template.Annotations != nil {
-			desiredHash = desiredDeployment.Spec.Template.Annotations[util.PodSpecHashAnnotation]
-		}
-
-		if currentHash != desiredHash {
-			log.Info("Updating Deployment due to spec change", "name", deployment.Name,
-				"currentHash", currentHash, "desiredHash", desiredHash)
-			// Update the deployment spec to trigger rolling update
-			deployment.Spec.Template = desiredDeployment.Spec.Template
-			if err := r.Update(ctx, deployment); err != nil {
-				log.Error(err, "failed to update Deployment")
+		if err := r.pruneWorkload(ctx, agent, &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: desiredDeployment.Name}}, log); err != nil {
+			log.Error(err, "failed to remove StatefulSet")
+			return ctrl.Result{}, err
+		}
+
+		deploymentName := fmt.Sprintf("agent-%s", agent.Name)
+		err = r.Get(ctx, types.NamespacedName{Name: deploymentName, Namespace: agent.Namespace}, deployment)
+
+		if err != nil && apierrors.IsNotFound(err) {
+			// Create new Deployment
+			deployment = desiredDeployment
+			if err := controllerutil.SetControllerReference(agent, deployment, r.Scheme); err != nil {
+				log.Error(err, "failed to set controller reference")
 				return ctrl.Result{}, err
 			}
+
+			util.SetAppliedReplicas(deployment)
+			log.Info("Creating Deployment", "name", deployment.Name)
+			if err := r.Create(ctx, deployment); err != nil {
+				log.Error(err, "failed to create Deployment")
+				agent.Status.Phase = "Failed"
+				agent.Status.Message = fmt.Sprintf("Failed to create Deployment: %v", err)
+				setReadyCondition(&agent.Status.Conditions, agent.Generation, kaosv1alpha1.ReasonReconcileFailed, agent.Status.Message)
+				r.Status().Update(ctx, agent)
+				return ctrl.Result{}, err
+			}
+		} else if err != nil {
+			log.Error(err, "failed to get Deployment")
+			return ctrl.Result{}, err
+		} else {
+			// Deployment exists - check if spec has changed using hash annotation
+			currentHash := ""
+			if deployment.Spec.Template.Annotations != nil {
+				currentHash = deployment.Spec.Template.Annotations[util.PodSpecHashAnnotation]
+			}
+			desiredHash := ""
+			if desiredDeployment.Spec.Template.Annotations != nil {
+				desiredHash = desiredDeployment.Spec.Template.Annotations[util.PodSpecHashAnnotation]
+			}
+
+			// Propagated owner labels only change metadata, so they never roll the pods
+			labelsChanged := util.PropagateLabels(deployment, agent, util.PropagatedLabelKeys())
+			replicasChanged := replicasOutOfSync(ctx, r.Client, deployment, desiredDeployment)
+			drift := deploymentDrift(deployment, desiredDeployment, replicasChanged)
+			if currentHash != desiredHash || len(drift) > 0 {
+				log.Info("Updating Deployment due to spec change", "name", deployment.Name,
+					"currentHash", currentHash, "desiredHash", desiredHash)
+				// Update the deployment spec to trigger rolling update
+				deployment.Spec.Template = desiredDeployment.Spec.Template
+			}
+			if replicasChanged {
+				log.Info("Scaling Deployment", "name", deployment.Name, "replicas", *desiredDeployment.Spec.Replicas)
+				deployment.Spec.Replicas = desiredDeployment.Spec.Replicas
+				util.SetAppliedReplicas(deployment)
+			}
+			// Replicas scaled beyond the operator maximum, e.g. by a HorizontalPodAutoscaler, are scaled back down
+			liveReplicas, replicasCapped := capLiveReplicas(deployment, r.MaxReplicas)
+			if replicasCapped {
+				log.Info("Capping Deployment replicas", "name", deployment.Name, "replicas", liveReplicas, "max", r.MaxReplicas)
+				requestedReplicas = max(requestedReplicas, liveReplicas)
+			}
+			historyLimitChanged := revisionHistoryLimitChanged(deployment.Spec.RevisionHistoryLimit, desiredDeployment.Spec.RevisionHistoryLimit)
+			if historyLimitChanged {
+				deployment.Spec.RevisionHistoryLimit = desiredDeployment.Spec.RevisionHistoryLimit
+			}
+			deadlineChanged := progressDeadlineChanged(deployment.Spec.ProgressDeadlineSeconds, desiredDeployment.Spec.ProgressDeadlineSeconds)
+			if deadlineChanged {
+				deployment.Spec.ProgressDeadlineSeconds = desiredDeployment.Spec.ProgressDeadlineSeconds
+			}
+			strategyChanged := rolloutStrategyChanged(deployment.Spec.Strategy, desiredDeployment.Spec.Strategy)
+			if strategyChanged {
+				deployment.Spec.Strategy = desiredDeployment.Spec.Strategy
+			}
+			if currentHash != desiredHash || labelsChanged || replicasChanged || replicasCapped || historyLimitChanged || deadlineChanged || strategyChanged || len(drift) > 0 {
+				if err := r.Update(ctx, deployment); err != nil {
+					log.Error(err, "failed to update Deployment")
+					return ctrl.Result{}, err
+				}
+				recordDriftCorrected(r.Recorder, agent, deployment, drift)
+			}
 		}
 	}
 
@@ -228,12 +459,18 @@ func (r *AgentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 				log.Error(err, "failed to create Service")
 				agent.Status.Phase = "Failed"
 				agent.Status.Message = fmt.Sprintf("Failed to create Service: %v", err)
+				setReadyCondition(&agent.Status.Conditions, agent.Generation, kaosv1alpha1.ReasonReconcileFailed, agent.Status.Message)
 				r.Status().Update(ctx, agent)
 				return ctrl.Result{}, err
 			}
 		} else if err != nil {
 			log.Error(err, "failed to get Service")
 			return ctrl.Result{}, err
+		} else if util.PropagateLabels(service, agent, util.PropagatedLabelKeys()) {
+			if err := r.Update(ctx, service); err != nil {
+				log.Error(err, "failed to update Service")
+				return ctrl.Result{}, err
+			}
 		}
 
 		// Set endpoint for A2A (base URL only - clients append paths like /.well-known/agent)
@@ -255,45 +492,144 @@ func (r *AgentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		}, log); err != nil {
 			log.Error(err, "failed to reconcile HTTPRoute")
 		}
+	} else if err := r.pruneExposure(ctx, agent, log); err != nil {
+		log.Error(err, "failed to remove A2A Service")
+		return ctrl.Result{}, err
 	}
 
-	// Update status
-	agent.Status.LinkedResources = make(map[string]string)
-	agent.Status.LinkedResources["modelapi"] = agent.Spec.ModelAPI
+	// Create, update or remove the headless Service for per-pod addressing
+	if err := r.reconcileHeadlessService(ctx, agent, log); err != nil {
+		log.Error(err, "failed to reconcile headless Service")
+		agent.Status.Phase = "Failed"
+		agent.Status.Message = fmt.Sprintf("Failed to reconcile headless Service: %v", err)
+		setReadyCondition(&agent.Status.Conditions, agent.Generation, kaosv1alpha1.ReasonReconcileFailed, agent.Status.Message)
+		r.Status().Update(ctx, agent)
+		return ctrl.Result{}, err
+	}
 
-	// Copy deployment status for rolling update visibility
-	agent.Status.Deployment = util.CopyDeploymentStatus(deployment)
+	// Create or remove the Lease the replicas elect a leader with
+	if err := r.reconcileLeaderLease(ctx, agent, log); err != nil {
+		log.Error(err, "failed to reconcile leader Lease")
+		agent.Status.Phase = "Failed"
+		agent.Status.Message = fmt.Sprintf("Failed to reconcile leader Lease: %v", err)
+		setReadyCondition(&agent.Status.Conditions, agent.Generation, kaosv1alpha1.ReasonReconcileFailed, agent.Status.Message)
+		r.Status().Update(ctx, agent)
+		return ctrl.Result{}, err
+	}
 
-	// Check deployment readiness
-	if deployment.Status.ReadyReplicas > 0 {
+	// Update status
+	agent.Status.LinkedResources = make(map[string]string)
+	agent.Status.LinkedResources["modelapi"] = modelapi.Name
+
+	// Copy workload status for rolling update visibility
+	degraded := degradedCondition(agent.Status.Conditions)
+	var degradedRequeue time.Duration
+	workloadKind, desiredReplicas, selector := "Deployment", deployment.Spec.Replicas, deployment.Spec.Selector
+	if statefulSet != nil {
+		workloadKind, desiredReplicas, selector = "StatefulSet", statefulSet.Spec.Replicas, statefulSet.Spec.Selector
+		agent.Status.Deployment = util.CopyStatefulSetStatus(statefulSet)
+	} else {
+		agent.Status.Deployment = util.CopyDeploymentStatus(deployment)
+		degradedRequeue = setDegradedCondition(ctx, r.Client, deployment, &agent.Status.Conditions, agent.Generation, log)
+	}
+	agent.Status.Replicas = agent.Status.Deployment.Replicas
+	agent.Status.ReadyReplicas = agent.Status.Deployment.ReadyReplicas
+	agent.Status.Selector = metav1.FormatLabelSelector(selector)
+	setReplicasCappedCondition(&agent.Status.Conditions, agent.Generation, requestedReplicas, r.MaxReplicas)
+	recordDegradedTransition(r.Recorder, agent, degraded, agent.Status.Conditions)
+
+	// Check workload readiness
+	readyReason := kaosv1alpha1.ReasonDeploymentNotReady
+	if agent.Status.ReadyReplicas > 0 {
 		agent.Status.Ready = true
 		agent.Status.Phase = "Ready"
+		readyReason = kaosv1alpha1.ReasonDeploymentReady
 	} else {
 		agent.Status.Phase = "Pending"
 		agent.Status.Ready = false
 	}
 
-	agent.Status.Message = fmt.Sprintf("Deployment ready replicas: %d/%d", deployment.Status.ReadyReplicas, *deployment.Spec.Replicas)
+	agent.Status.Message = fmt.Sprintf("%s ready replicas: %d/%d", workloadKind, agent.Status.ReadyReplicas, *desiredReplicas)
+	setReadyCondition(&agent.Status.Conditions, agent.Generation, readyReason, agent.Status.Message)
+	agent.Status.LastReconcileTime = reconcileTime(r.Clock)
 
 	if err := r.Status().Update(ctx, agent); err != nil {
 		log.Error(err, "failed to update status")
 		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{}, nil
+	// Unavailable replicas without a Warning event yet are checked again on a full reconcile,
+	// as a later event does not change any watched object
+	if degradedRequeue > 0 {
+		return ctrl.Result{RequeueAfter: util.SoonestRequeue(deadlineRequeue, degradedRequeue)}, nil
+	}
+	if fingerprint, ok := observedFingerprint(ctx, r.Client, agent.Namespace, r.childObjects(agent), dependencies...); ok {
+		r.ReconcileCache.Record(agent, fingerprint)
+	}
+
+	return ctrl.Result{RequeueAfter: deadlineRequeue}, nil
+}
+
+// pruneExposure removes the A2A Service and HTTPRoute of an agent whose agentNetwork.expose
+// was turned off, and clears status.endpoint so it no longer advertises a stale address
+func (r *AgentReconciler) pruneExposure(ctx context.Context, agent *kaosv1alpha1.Agent, log logr.Logger) error {
+	agent.Status.Endpoint = ""
+
+	service := &corev1.Service{}
+	serviceName := fmt.Sprintf("agent-%s", agent.Name)
+	if err := r.Get(ctx, types.NamespacedName{Name: serviceName, Namespace: agent.Namespace}, service); err == nil {
+		if metav1.IsControlledBy(service, agent) {
+			log.Info("Deleting Service", "name", serviceName)
+			if err := r.Delete(ctx, service); client.IgnoreNotFound(err) != nil {
+				return err
+			}
+		}
+	} else if !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	if err := gateway.DeleteHTTPRoute(ctx, r.Client, agent, gateway.ResourceTypeAgent, log); err != nil {
+		log.Error(err, "failed to delete HTTPRoute")
+	}
+	return nil
+}
+
+// childObjects returns empty named instances of the objects owned by the Agent
+func (r *AgentReconciler) childObjects(agent *kaosv1alpha1.Agent) []client.Object {
+	name := fmt.Sprintf("agent-%s", agent.Name)
+	children := []client.Object{&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name}}}
+	if statefulAgent(agent) {
+		children = []client.Object{&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: name}}}
+	}
+	if agent.Spec.AgentNetwork == nil || agent.Spec.AgentNetwork.Expose == nil || *agent.Spec.AgentNetwork.Expose {
+		children = append(children, &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: name}})
+		children = append(children, httpRouteChild(gateway.ResourceTypeAgent, agent.Name)...)
+	}
+	if headlessServiceEnabled(agent) {
+		children = append(children, &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: headlessServiceName(agent)}})
+	}
+	if r.DefaultAgentEgress == AgentEgressDeny {
+		children = append(children, &networkingv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: egressPolicyName(agent)}})
+	}
+	// A deleted Lease is recreated on the next reconcile. Renewals change its resourceVersion,
+	// but the Lease is not watched, so they only bypass the cache on otherwise triggered reconciles.
+	if agent.Spec.LeaderLease {
+		children = append(children, &coordinationv1.Lease{ObjectMeta: metav1.ObjectMeta{Name: leaderLeaseName(agent)}})
+	}
+	return children
 }
 
 // constructDeployment creates a Deployment for the Agent
-func (r *AgentReconciler) constructDeployment(agent *kaosv1alpha1.Agent, modelapi *kaosv1alpha1.ModelAPI, mcpServers map[string]string, peerAgents map[string]string) *appsv1.Deployment {
+func (r *AgentReconciler) constructDeployment(agent *kaosv1alpha1.Agent, modelapi *kaosv1alpha1.ModelAPI, mcpServers map[string]string, mcpTools map[string][]string, peerAgents map[string]string) *appsv1.Deployment {
 	labels := map[string]string{
 		"app":   "agent",
 		"agent": agent.Name,
 	}
 
-	replicas := int32(1)
+	replicas := capReplicas(specReplicas(agent.Spec.Replicas), r.MaxReplicas)
 
 	// Build environment variables
-	env := r.constructEnvVars(agent, modelapi, mcpServers, peerAgents)
+	env := r.constructEnvVars(agent, modelapi, mcpServers, mcpTools, peerAgents)
 
 	// Get agent image from environment or use default
 	agentImage := os.Getenv("DEFAULT_AGENT_IMAGE")
@@ -301,10 +637,16 @@ func (r *AgentReconciler) constructDeployment(agent *kaosv1alpha1.Agent, modelap
 		agentImage = "axsauze/kaos-agent:latest"
 	}
 
+	// Expand spec.command and spec.args from the resolved references
+	commandData := util.NewCommandTemplateData(agentModelEndpoint(agent, modelapi), mcpServers)
+
 	container := corev1.Container{
 		Name:            "agent",
 		Image:           agentImage,
-		ImagePullPolicy: corev1.PullIfNotPresent,
+		ImagePullPolicy: util.PullPolicy(agent.Spec.ImagePullPolicy, agentImage),
+		WorkingDir:      agent.Spec.WorkingDir,
+		Command:         expandAgentCommand(agent.Spec.Command, commandData),
+		Args:            expandAgentCommand(agent.Spec.Args, commandData),
 		Ports: []corev1.ContainerPort{
 			{
 				Name:          "http",
@@ -338,7 +680,19 @@ func (r *AgentReconciler) constructDeployment(agent *kaosv1alpha1.Agent, modelap
 	}
 
 	basePodSpec := corev1.PodSpec{
-		Containers: []corev1.Container{container},
+		Containers:                   []corev1.Container{container},
+		HostAliases:                  agent.Spec.HostAliases,
+		RuntimeClassName:             agent.Spec.RuntimeClassName,
+		Overhead:                     agent.Spec.Overhead,
+		SchedulerName:                agent.Spec.SchedulerName,
+		ShareProcessNamespace:        agent.Spec.ShareProcessNamespace,
+		EnableServiceLinks:           util.EnableServiceLinks(agent.Spec.EnableServiceLinks),
+		AutomountServiceAccountToken: util.AutomountServiceAccountToken(agent.Spec.AutomountServiceAccountToken, agent.Spec.LeaderLease),
+	}
+	mountMTLSSecret(&basePodSpec, "agent", agent.Spec.MTLS)
+	mountServiceAccountToken(&basePodSpec, "agent", agent.Spec.ProjectedServiceAccountToken)
+	if agent.Spec.EmbeddedModel != nil {
+		embedModel(&basePodSpec, modelapi)
 	}
 
 	// Apply podSpec override using strategic merge patch if provided
@@ -350,9 +704,6 @@ func (r *AgentReconciler) constructDeployment(agent *kaosv1alpha1.Agent, modelap
 		}
 	}
 
-	// Compute hash of the pod spec for change detection
-	podSpecHash := util.ComputePodSpecHash(finalPodSpec)
-
 	deployment := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      fmt.Sprintf("agent-%s", agent.Name),
@@ -360,32 +711,46 @@ func (r *AgentReconciler) constructDeployment(agent *kaosv1alpha1.Agent, modelap
 			Labels:    labels,
 		},
 		Spec: appsv1.DeploymentSpec{
-			Replicas: &replicas,
+			Replicas:                &replicas,
+			RevisionHistoryLimit:    revisionHistoryLimit(agent.Spec.RevisionHistoryLimit),
+			ProgressDeadlineSeconds: progressDeadlineSeconds(agent.Spec.ProgressDeadlineSeconds),
+			Strategy:                rolloutStrategy(agent),
 			Selector: &metav1.LabelSelector{
 				MatchLabels: labels,
 			},
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels: labels,
-					Annotations: map[string]string{
-						util.PodSpecHashAnnotation: podSpecHash,
-					},
+					Labels:      util.PodLabels(labels, "agent", agent.Name),
+					Annotations: util.MeshInjectionAnnotations(agent.Spec.MeshInjection, os.Getenv("MESH_TYPE")),
 				},
 				Spec: finalPodSpec,
 			},
 		},
 	}
 
+	// A new kaos.agentic/redeploy value rolls the pods without a spec change
+	util.SetRedeployAnnotation(&deployment.Spec.Template, agent)
+
+	// The global pod template patch is applied last, so it takes precedence over podSpec
+	if err := r.PodTemplatePatch.Apply(&deployment.Spec.Template); err != nil {
+		r.Log.Error(err, "unable to apply the global pod template patch", "deployment", deployment.Name)
+	}
+
+	// Compute hash of the pod template for change detection
+	util.SetPodTemplateHash(&deployment.Spec.Template)
+
+	util.PropagateLabels(deployment, agent, util.PropagatedLabelKeys())
+
 	return deployment
 }
 
 // constructEnvVars builds environment variables for the agent
-func (r *AgentReconciler) constructEnvVars(agent *kaosv1alpha1.Agent, modelapi *kaosv1alpha1.ModelAPI, mcpServers map[string]string, peerAgents map[string]string) []corev1.EnvVar {
+func (r *AgentReconciler) constructEnvVars(agent *kaosv1alpha1.Agent, modelapi *kaosv1alpha1.ModelAPI, mcpServers map[string]string, mcpTools map[string][]string, peerAgents map[string]string) []corev1.EnvVar {
 	var env []corev1.EnvVar
 
 	// Agent identity and configuration
 	env = append(env, corev1.EnvVar{
-		Name:  "AGENT_NAME",
+		Name:  util.AgentNameEnv,
 		Value: agent.Name,
 	})
 
@@ -410,54 +775,77 @@ func (r *AgentReconciler) constructEnvVars(agent *kaosv1alpha1.Agent, modelapi *
 
 	// ModelAPI configuration
 	env = append(env, corev1.EnvVar{
-		Name:  "MODEL_API_URL",
-		Value: modelapi.Status.Endpoint,
+		Name:  util.ModelAPIURLEnv,
+		Value: agentModelEndpoint(agent, modelapi),
 	})
 
 	// MODEL_NAME from required spec.model field
 	env = append(env, corev1.EnvVar{
-		Name:  "MODEL_NAME",
+		Name:  util.ModelNameEnv,
 		Value: agent.Spec.Model,
 	})
 
 	// Reasoning loop configuration
 	if agent.Spec.Config != nil && agent.Spec.Config.ReasoningLoopMaxSteps != nil {
 		env = append(env, corev1.EnvVar{
-			Name:  "AGENTIC_LOOP_MAX_STEPS",
+			Name:  util.AgenticLoopMaxStepsEnv,
 			Value: fmt.Sprintf("%d", *agent.Spec.Config.ReasoningLoopMaxSteps),
 		})
 	}
 
+	// Runtime request handling configuration
+	if agent.Spec.Runtime != nil {
+		if agent.Spec.Runtime.MaxConcurrency != nil {
+			env = append(env, corev1.EnvVar{
+				Name:  util.AgentMaxConcurrencyEnv,
+				Value: fmt.Sprintf("%d", *agent.Spec.Runtime.MaxConcurrency),
+			})
+		}
+		if agent.Spec.Runtime.RequestTimeout != "" {
+			env = append(env, corev1.EnvVar{
+				Name:  util.AgentRequestTimeoutEnv,
+				Value: agent.Spec.Runtime.RequestTimeout,
+			})
+		}
+	}
+
+	// Log level override (takes precedence over a LOG_LEVEL in config.env)
+	env = util.WithLogLevel(env, agent.Spec.LogLevel)
+
+	// OpenTelemetry configuration
+	env = append(env, util.BuildTelemetryEnvVars(agent.Spec.Telemetry, agent.Name)...)
+	env = append(env, util.TraceSamplerEnvVars(agent.Spec.Telemetry, agent.Annotations[kaosv1alpha1.TraceSampleRatioAnnotation])...)
+
 	// Memory configuration
 	if agent.Spec.Config != nil && agent.Spec.Config.Memory != nil {
 		mem := agent.Spec.Config.Memory
 		if mem.Enabled != nil {
 			env = append(env, corev1.EnvVar{
-				Name:  "MEMORY_ENABLED",
+				Name:  util.MemoryEnabledEnv,
 				Value: fmt.Sprintf("%t", *mem.Enabled),
 			})
 		}
 		if mem.Type != "" {
 			env = append(env, corev1.EnvVar{
-				Name:  "MEMORY_TYPE",
+				Name:  util.MemoryTypeEnv,
 				Value: mem.Type,
 			})
 		}
 		if mem.ContextLimit != nil {
 			env = append(env, corev1.EnvVar{
-				Name:  "MEMORY_CONTEXT_LIMIT",
+				Name:  util.MemoryContextLimitEnv,
 				Value: fmt.Sprintf("%d", *mem.ContextLimit),
 			})
 		}
 		if mem.MaxSessions != nil {
 			env = append(env, corev1.EnvVar{
-				Name:  "MEMORY_MAX_SESSIONS",
+				Name:  util.MemoryMaxSessionsEnv,
 				Value: fmt.Sprintf("%d", *mem.MaxSessions),
 			})
 		}
 		if mem.MaxSessionEvents != nil {
 			env = append(env, corev1.EnvVar{
-				Name:  "MEMORY_MAX_SESSION_EVENTS",
+				Name:  util.MemoryMaxSessionEventsEnv,
 				Value: fmt.Sprintf("%d", *mem.MaxSessionEvents),
 			})
 		}
@@ -473,7 +861,7 @@ func (r *AgentReconciler) constructEnvVars(agent *kaosv1alpha1.Agent, modelapi *
 		sort.Strings(mcpNames)
 
 		env = append(env, corev1.EnvVar{
-			Name:  "MCP_SERVERS",
+			Name:  util.MCPServersEnv,
 			Value: strings.Join(mcpNames, ","), // Comma-separated list
 		})
 
@@ -487,6 +875,9 @@ func (r *AgentReconciler) constructEnvVars(agent *kaosv1alpha1.Agent, modelapi *
 		}
 	}
 
+	// Tools advertised by the referenced MCP servers
+	env = append(env, availableToolsEnvVars(mcpTools)...)
+
 	// Peer Agents configuration
 	if len(peerAgents) > 0 {
 		peerNames := make([]string, 0, len(peerAgents))
@@ -497,7 +888,7 @@ func (r *AgentReconciler) constructEnvVars(agent *kaosv1alpha1.Agent, modelapi *
 		sort.Strings(peerNames)
 
 		env = append(env, corev1.EnvVar{
-			Name:  "PEER_AGENTS",
+			Name:  util.PeerAgentsEnv,
 			Value: strings.Join(peerNames, ","),
 		})
 
@@ -513,7 +904,39 @@ func (r *AgentReconciler) constructEnvVars(agent *kaosv1alpha1.Agent, modelapi *
 		}
 	}
 
-	return env
+	if agent.Spec.LeaderLease {
+		env = append(env, corev1.EnvVar{
+			Name:  util.AgentLeaderLeaseEnv,
+			Value: leaderLeaseName(agent),
+		})
+	}
+
+	// Certificate files of the spec.mtls Secret
+	env = append(env, mtlsEnvVars(agent.Spec.MTLS)...)
+
+	// Path of the spec.projectedServiceAccountToken token
+	env = append(env, serviceAccountTokenEnvVars(agent.Spec.ProjectedServiceAccountToken)...)
+
+	// Secret keys mapped to the env var names the agent expects
+	for _, mapping := range agent.Spec.SecretKeyMappings {
+		env = append(env, corev1.EnvVar{
+			Name: mapping.EnvName,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: mapping.SecretName},
+					Key:                  mapping.Key,
+				},
+			},
+		})
+	}
+
+	// With the escape annotation, config.env overrides the operator's own env vars
+	if agent.Annotations[kaosv1alpha1.AllowReservedEnvAnnotation] == "true" && agent.Spec.Config != nil {
+		env = append(env, agent.Spec.Config.Env...)
+	}
+
+	// Render in a stable order so reordering config.env does not roll the pods
+	return util.StableEnv(env)
 }
 
 // constructService creates a Service for A2A communication
@@ -543,6 +966,8 @@ func (r *AgentReconciler) constructService(agent *kaosv1alpha1.Agent) *corev1.Se
 		},
 	}
 
+	util.PropagateLabels(service, agent, util.PropagatedLabelKeys())
+
 	return service
 }
 
@@ -559,7 +984,7 @@ func (r *AgentReconciler) SetupWithManager(mgr ctrl.Manager) error {
 
 		requests := []ctrl.Request{}
 		for _, agent := range agentList.Items {
-			if agent.Spec.ModelAPI == modelapi.Name {
+			if agentUsesModelAPI(&agent, modelapi) {
 				requests = append(requests, ctrl.Request{
 					NamespacedName: types.NamespacedName{Name: agent.Name, Namespace: agent.Namespace},
 				})
@@ -591,11 +1016,16 @@ func (r *AgentReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	})
 
 	builder := ctrl.NewControllerManagedBy(mgr).
+		WithOptions(controller.Options{RateLimiter: r.RateLimiter}).
 		For(&kaosv1alpha1.Agent{}).
 		Owns(&appsv1.Deployment{}).
+		Watches(&appsv1.ReplicaSet{}, replicaSetToOwner(mgr.GetClient(), "Agent"), ctrlbuilder.WithPredicates(replicaFailureChanged)).
+		Owns(&appsv1.StatefulSet{}).
 		Owns(&corev1.Service{}).
+		Owns(&networkingv1.NetworkPolicy{}).
 		Watches(&kaosv1alpha1.ModelAPI{}, mapModelAPIToAgents).
-		Watches(&kaosv1alpha1.MCPServer{}, mapMCPServerToAgents)
+		Watches(&kaosv1alpha1.MCPServer{}, mapMCPServerToAgents).
+		Watches(&corev1.Pod{}, handler.EnqueueRequestsFromMapFunc(agentForPod), ctrlbuilder.WithPredicates(agentPodStarted))
 
 	// Own HTTPRoutes if Gateway API is enabled
 	if gateway.GetConfig().Enabled {
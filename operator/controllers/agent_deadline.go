@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+	"github.com/axsaucedo/kaos/operator/pkg/util"
+)
+
+// ActiveDeadlineExceededReason is the reason of the event recorded when an agent pod that ran
+// past spec.activeDeadlineSeconds is evicted
+const ActiveDeadlineExceededReason = "ActiveDeadlineExceeded"
+
+// deadlineRolloverInterval is how often the pods past their active deadline are checked while
+// they are replaced one at a time, or while a PodDisruptionBudget refuses their eviction
+const deadlineRolloverInterval = 30 * time.Second
+
+// podStartTime returns when the kubelet started the pod, or its creation time before that
+func podStartTime(pod *corev1.Pod) time.Time {
+	if pod.Status.StartTime != nil {
+		return pod.Status.StartTime.Time
+	}
+	return pod.CreationTimestamp.Time
+}
+
+// reconcileActiveDeadline evicts the agent pods that have run longer than
+// spec.activeDeadlineSeconds, so the Deployment replaces them with fresh pods. Pods are
+// replaced one at a time, the oldest first, and through the Eviction API so that a
+// PodDisruptionBudget can hold a replacement back. Pod age does not change any watched object,
+// so it returns how long until the next pod is due, or 0 when there is no deadline.
+func (r *AgentReconciler) reconcileActiveDeadline(ctx context.Context, agent *kaosv1alpha1.Agent, log logr.Logger) (time.Duration, error) {
+	if agent.Spec.ActiveDeadlineSeconds == nil || statefulAgent(agent) {
+		return 0, nil
+	}
+	c := r.Clock
+	if c == nil {
+		c = clock.RealClock{}
+	}
+	now := c.Now()
+	deadline := time.Duration(*agent.Spec.ActiveDeadlineSeconds) * time.Second
+
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(agent.Namespace),
+		client.MatchingLabels{"app": "agent", "agent": agent.Name}); err != nil {
+		return 0, err
+	}
+
+	next := time.Duration(0)
+	terminating := false
+	var expired *corev1.Pod
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.DeletionTimestamp != nil {
+			terminating = true
+			continue
+		}
+		if remaining := deadline - now.Sub(podStartTime(pod)); remaining > 0 {
+			next = util.SoonestRequeue(next, remaining)
+			continue
+		}
+		if expired == nil || podStartTime(pod).Before(podStartTime(expired)) {
+			expired = pod
+		}
+	}
+	if expired == nil {
+		// Replacements for evicted pods start now, so they reach the deadline a full deadline later
+		if next == 0 {
+			next = deadline
+		}
+		return next, nil
+	}
+	// Wait for the previous replacement before evicting the next pod
+	if terminating {
+		return deadlineRolloverInterval, nil
+	}
+
+	age := now.Sub(podStartTime(expired)).Round(time.Second)
+	log.Info("Evicting pod past its active deadline", "pod", expired.Name, "age", age)
+	eviction := &policyv1.Eviction{ObjectMeta: metav1.ObjectMeta{Name: expired.Name, Namespace: expired.Namespace}}
+	if err := r.SubResource("eviction").Create(ctx, expired, eviction); apierrors.IsTooManyRequests(err) {
+		log.Info("Eviction refused by a PodDisruptionBudget, retrying", "pod", expired.Name)
+		return deadlineRolloverInterval, nil
+	} else if client.IgnoreNotFound(err) != nil {
+		return deadlineRolloverInterval, err
+	}
+	if r.Recorder != nil {
+		r.Recorder.Eventf(agent, corev1.EventTypeWarning, ActiveDeadlineExceededReason,
+			"Evicted pod %s after it ran for %s, past the active deadline of %ds", expired.Name, age, *agent.Spec.ActiveDeadlineSeconds)
+	}
+	return deadlineRolloverInterval, nil
+}
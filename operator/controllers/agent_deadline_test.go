@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	clocktesting "k8s.io/utils/clock/testing"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+var _ = Describe("spec.activeDeadlineSeconds", func() {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	agentPod := func(name string, started time.Time) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": "agent", "agent": "watchdog"}},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning, StartTime: &metav1.Time{Time: started}},
+		}
+	}
+
+	It("should evict a pod that exceeds the deadline and record an event", func() {
+		deadline := int64(3600)
+		agent := &kaosv1alpha1.Agent{
+			ObjectMeta: metav1.ObjectMeta{Name: "watchdog", Namespace: "default"},
+			Spec:       kaosv1alpha1.AgentSpec{ModelAPI: "api", Model: "mock-model", ActiveDeadlineSeconds: &deadline},
+		}
+		hung := agentPod("agent-watchdog-hung", now.Add(-2*time.Hour))
+		fresh := agentPod("agent-watchdog-fresh", now.Add(-40*time.Minute))
+		r, c := newCachedAgentReconciler(agent, hung, fresh)
+		recorder := record.NewFakeRecorder(10)
+		r.Recorder = recorder
+		r.Clock = clocktesting.NewFakePassiveClock(now)
+		ctx := context.Background()
+
+		requeue, err := r.reconcileActiveDeadline(ctx, agent, ctrl.Log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(apierrors.IsNotFound(c.Get(ctx, types.NamespacedName{Name: hung.Name, Namespace: "default"}, &corev1.Pod{}))).To(BeTrue())
+		Expect(c.Get(ctx, types.NamespacedName{Name: fresh.Name, Namespace: "default"}, &corev1.Pod{})).To(Succeed())
+		Expect(recorder.Events).To(Receive(Equal("Warning ActiveDeadlineExceeded Evicted pod agent-watchdog-hung after it ran for 2h0m0s, past the active deadline of 3600s")))
+		Expect(recorder.Events).NotTo(Receive())
+		Expect(requeue).To(Equal(deadlineRolloverInterval))
+
+		// The remaining pod is checked again when it reaches the deadline
+		requeue, err = r.reconcileActiveDeadline(ctx, agent, ctrl.Log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requeue).To(Equal(20 * time.Minute))
+	})
+
+	It("should replace one pod at a time, the oldest first", func() {
+		deadline := int64(3600)
+		agent := &kaosv1alpha1.Agent{
+			ObjectMeta: metav1.ObjectMeta{Name: "watchdog", Namespace: "default"},
+			Spec:       kaosv1alpha1.AgentSpec{ModelAPI: "api", Model: "mock-model", ActiveDeadlineSeconds: &deadline},
+		}
+		older := agentPod("agent-watchdog-older", now.Add(-3*time.Hour))
+		old := agentPod("agent-watchdog-old", now.Add(-2*time.Hour))
+		r, c := newCachedAgentReconciler(agent, older, old)
+		r.Clock = clocktesting.NewFakePassiveClock(now)
+		ctx := context.Background()
+
+		requeue, err := r.reconcileActiveDeadline(ctx, agent, ctrl.Log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requeue).To(Equal(deadlineRolloverInterval))
+		Expect(apierrors.IsNotFound(c.Get(ctx, types.NamespacedName{Name: older.Name, Namespace: "default"}, &corev1.Pod{}))).To(BeTrue())
+		Expect(c.Get(ctx, types.NamespacedName{Name: old.Name, Namespace: "default"}, &corev1.Pod{})).To(Succeed())
+
+		// The next pod waits while a replaced one is still terminating
+		terminating := agentPod("agent-watchdog-terminating", now.Add(-3*time.Hour))
+		terminating.Finalizers = []string{"kaos.tools/test"}
+		Expect(c.Create(ctx, terminating)).To(Succeed())
+		Expect(c.Delete(ctx, terminating)).To(Succeed())
+		requeue, err = r.reconcileActiveDeadline(ctx, agent, ctrl.Log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requeue).To(Equal(deadlineRolloverInterval))
+		Expect(c.Get(ctx, types.NamespacedName{Name: old.Name, Namespace: "default"}, &corev1.Pod{})).To(Succeed())
+	})
+
+	It("should retry an eviction refused by a PodDisruptionBudget", func() {
+		deadline := int64(3600)
+		agent := &kaosv1alpha1.Agent{
+			ObjectMeta: metav1.ObjectMeta{Name: "watchdog", Namespace: "default"},
+			Spec:       kaosv1alpha1.AgentSpec{ModelAPI: "api", Model: "mock-model", ActiveDeadlineSeconds: &deadline},
+		}
+		hung := agentPod("agent-watchdog-hung", now.Add(-2*time.Hour))
+		r, c := newCachedAgentReconciler(agent, hung)
+		r.Client = interceptor.NewClient(c.(client.WithWatch), interceptor.Funcs{
+			SubResourceCreate: func(context.Context, client.Client, string, client.Object, client.Object, ...client.SubResourceCreateOption) error {
+				return apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0)
+			},
+		})
+		recorder := record.NewFakeRecorder(10)
+		r.Recorder = recorder
+		r.Clock = clocktesting.NewFakePassiveClock(now)
+		ctx := context.Background()
+
+		requeue, err := r.reconcileActiveDeadline(ctx, agent, ctrl.Log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requeue).To(Equal(deadlineRolloverInterval))
+		Expect(c.Get(ctx, types.NamespacedName{Name: hung.Name, Namespace: "default"}, &corev1.Pod{})).To(Succeed())
+		Expect(recorder.Events).NotTo(Receive())
+	})
+
+	It("should leave pods alone without a deadline or for StatefulSet agents", func() {
+		deadline := int64(60)
+		agent := &kaosv1alpha1.Agent{
+			ObjectMeta: metav1.ObjectMeta{Name: "watchdog", Namespace: "default"},
+			Spec:       kaosv1alpha1.AgentSpec{ModelAPI: "api", Model: "mock-model"},
+		}
+		r := &AgentReconciler{Clock: clocktesting.NewFakePassiveClock(now)}
+		requeue, err := r.reconcileActiveDeadline(context.Background(), agent, ctrl.Log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requeue).To(BeZero())
+
+		agent.Spec.ActiveDeadlineSeconds = &deadline
+		agent.Spec.WorkloadType = kaosv1alpha1.AgentWorkloadTypeStatefulSet
+		requeue, err = r.reconcileActiveDeadline(context.Background(), agent, ctrl.Log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requeue).To(BeZero())
+	})
+})
@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+// debugContainerPrefix prefixes the names of ephemeral debug containers added by the operator
+const debugContainerPrefix = "kaos-debug"
+
+// DebugContainerFailedReason is the reason of the Warning event recorded on an Agent when a
+// debug container cannot be added to one of its pods
+const DebugContainerFailedReason = "DebugContainerFailed"
+
+// reconcileDebugContainers injects an ephemeral debug container into each running agent pod
+// while the debug-image annotation is set. Ephemeral containers cannot be removed from a pod,
+// so once the annotation is cleared, pods still carrying a debug container are deleted and
+// replaced by the Deployment. A pod that cannot take the debug container is reported with a
+// DebugContainerFailed event, and the other pods are still processed.
+func (r *AgentReconciler) reconcileDebugContainers(ctx context.Context, agent *kaosv1alpha1.Agent, log logr.Logger) error {
+	debugImage := agent.Annotations[kaosv1alpha1.DebugImageAnnotation]
+
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(agent.Namespace),
+		client.MatchingLabels{"app": "agent", "agent": agent.Name}); err != nil {
+		return err
+	}
+
+	var failures []error
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+
+		debugContainers := 0
+		hasImage := false
+		for _, ec := range pod.Spec.EphemeralContainers {
+			if strings.HasPrefix(ec.Name, debugContainerPrefix) {
+				debugContainers++
+				hasImage = hasImage || ec.Image == debugImage
+			}
+		}
+
+		if debugImage == "" {
+			if debugContainers > 0 {
+				log.Info("Deleting pod to remove debug container", "pod", pod.Name)
+				if err := r.Delete(ctx, pod); client.IgnoreNotFound(err) != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if hasImage || pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+
+		log.Info("Adding debug container to pod", "pod", pod.Name, "image", debugImage)
+		pod.Spec.EphemeralContainers = append(pod.Spec.EphemeralContainers, corev1.EphemeralContainer{
+			EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+				Name:                     fmt.Sprintf("%s-%d", debugContainerPrefix, debugContainers),
+				Image:                    debugImage,
+				Stdin:                    true,
+				TTY:                      true,
+				TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+			},
+			TargetContainerName: "agent",
+		})
+		if err := r.SubResource("ephemeralcontainers").Update(ctx, pod); err != nil {
+			if r.Recorder != nil {
+				r.Recorder.Eventf(agent, corev1.EventTypeWarning, DebugContainerFailedReason,
+					"Failed to add debug container %s to pod %s: %v", debugImage, pod.Name, err)
+			}
+			failures = append(failures, fmt.Errorf("pod %s: %w", pod.Name, err))
+		}
+	}
+
+	return errors.Join(failures...)
+}
+
+// agentForPod maps an agent pod to its Agent, so pods that start after the debug-image
+// annotation was set, such as replacements, get the debug container
+func agentForPod(ctx context.Context, obj client.Object) []ctrl.Request {
+	labels := obj.GetLabels()
+	if labels["app"] != "agent" || labels["agent"] == "" {
+		return nil
+	}
+	return []ctrl.Request{{NamespacedName: types.NamespacedName{Name: labels["agent"], Namespace: obj.GetNamespace()}}}
+}
+
+// agentPodStarted passes pod creations and phase changes, such as a pod turning Running,
+// but not the frequent status updates of running pods
+var agentPodStarted = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldPod, okOld := e.ObjectOld.(*corev1.Pod)
+		newPod, okNew := e.ObjectNew.(*corev1.Pod)
+		return okOld && okNew && oldPod.Status.Phase != newPod.Status.Phase
+	},
+	DeleteFunc: func(event.DeleteEvent) bool { return false },
+}
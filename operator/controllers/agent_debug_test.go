@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+var _ = Describe("Agent debug containers", func() {
+	agent := &kaosv1alpha1.Agent{ObjectMeta: metav1.ObjectMeta{
+		Name: "debugged", Namespace: "default",
+		Annotations: map[string]string{kaosv1alpha1.DebugImageAnnotation: "busybox:1.36"},
+	}}
+	pod := func(name string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": "agent", "agent": "debugged"}},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+
+	It("should map agent pods to their Agent", func() {
+		Expect(agentForPod(context.Background(), pod("agent-debugged-abc"))).To(Equal([]ctrl.Request{
+			{NamespacedName: types.NamespacedName{Name: "debugged", Namespace: "default"}},
+		}))
+		other := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "modelapi-x", Labels: map[string]string{"app": "modelapi"}}}
+		Expect(agentForPod(context.Background(), other)).To(BeEmpty())
+	})
+
+	It("should report a failed debug container patch as an event and still patch the other pods", func() {
+		scheme := newTestScheme()
+		var patched []string
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pod("agent-debugged-a"), pod("agent-debugged-b")).
+			WithInterceptorFuncs(interceptor.Funcs{
+				SubResourceUpdate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+					if obj.GetName() == "agent-debugged-a" {
+						return errors.New("ephemeral containers are disabled")
+					}
+					patched = append(patched, obj.GetName())
+					return nil
+				},
+			}).Build()
+		recorder := record.NewFakeRecorder(10)
+		r := &AgentReconciler{Client: c, Scheme: scheme, Recorder: recorder}
+
+		err := r.reconcileDebugContainers(context.Background(), agent, ctrl.Log)
+		Expect(err).To(MatchError(ContainSubstring("pod agent-debugged-a: ephemeral containers are disabled")))
+		Expect(patched).To(Equal([]string{"agent-debugged-b"}))
+		Expect(recorder.Events).To(Receive(Equal("Warning DebugContainerFailed Failed to add debug container busybox:1.36 to pod agent-debugged-a: ephemeral containers are disabled")))
+	})
+})
@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+	"github.com/axsaucedo/kaos/operator/pkg/util"
+)
+
+const (
+	// embeddedModelContainerName is the name of the model server sidecar in the agent pods
+	embeddedModelContainerName = "model-api"
+	// embeddedModelPortName names the sidecar's port, which must not clash with the agent's http port
+	embeddedModelPortName = "model-http"
+	// embeddedModelSocketVolume is the emptyDir shared by the agent and the sidecar
+	embeddedModelSocketVolume = "model-socket"
+	// embeddedModelSocketDir is where both containers mount the shared emptyDir
+	embeddedModelSocketDir = "/var/run/kaos-model"
+	// embeddedProxyPort moves LiteLLM off port 8000, where the agent listens
+	embeddedProxyPort int32 = 8001
+)
+
+// embeddedModelAPIName returns the ModelAPI an Agent calls: the spec.embeddedModel template,
+// if one is named, or spec.modelAPI
+func embeddedModelAPIName(agent *kaosv1alpha1.Agent) string {
+	if agent.Spec.EmbeddedModel != nil && agent.Spec.EmbeddedModel.ModelAPI != "" {
+		return agent.Spec.EmbeddedModel.ModelAPI
+	}
+	return agent.Spec.ModelAPI
+}
+
+// embeddedModelPort returns the port the sidecar of a ModelAPI template listens on
+func embeddedModelPort(modelapi *kaosv1alpha1.ModelAPI) int32 {
+	if modelapi.Spec.Mode == kaosv1alpha1.ModelAPIModeHosted {
+		return 11434
+	}
+	return embeddedProxyPort
+}
+
+// agentModelEndpoint returns the URL the agent reaches its model at: the sidecar on the
+// loopback for an embedded model, or the ModelAPI Service otherwise
+func agentModelEndpoint(agent *kaosv1alpha1.Agent, modelapi *kaosv1alpha1.ModelAPI) string {
+	if agent.Spec.EmbeddedModel != nil {
+		return fmt.Sprintf("%s://localhost:%d", mtlsScheme(modelapi.Spec.MTLS), embeddedModelPort(modelapi))
+	}
+	return modelapi.Status.Endpoint
+}
+
+// embeddedModelSocketEnv points both containers at the Unix domain socket in the shared emptyDir
+func embeddedModelSocketEnv() corev1.EnvVar {
+	return corev1.EnvVar{Name: util.ModelAPISocketEnv, Value: path.Join(embeddedModelSocketDir, "model.sock")}
+}
+
+// embedModel adds the model server of the ModelAPI template to the agent pod spec: its
+// model-api container and init containers, the volumes they mount and the shared socket
+// emptyDir. The sidecar is rendered from the template's own pod spec, so its podSpec
+// overrides, such as resources, carry over.
+func embedModel(podSpec *corev1.PodSpec, modelapi *kaosv1alpha1.ModelAPI) {
+	rendered := (&ModelAPIReconciler{}).constructDeployment(modelapi).Spec.Template.Spec
+
+	socketMount := corev1.VolumeMount{Name: embeddedModelSocketVolume, MountPath: embeddedModelSocketDir}
+	for i := range podSpec.Containers {
+		podSpec.Containers[i].VolumeMounts = append(podSpec.Containers[i].VolumeMounts, socketMount)
+		podSpec.Containers[i].Env = append(podSpec.Containers[i].Env, embeddedModelSocketEnv())
+	}
+
+	for _, container := range rendered.Containers {
+		if container.Name != embeddedModelContainerName {
+			continue
+		}
+		container.VolumeMounts = append(container.VolumeMounts, socketMount)
+		container.Env = append(container.Env, embeddedModelSocketEnv())
+		if modelapi.Spec.Mode == kaosv1alpha1.ModelAPIModeProxy {
+			moveProxyPort(&container, embeddedProxyPort)
+		}
+		for i := range container.Ports {
+			if container.Ports[i].Name == "http" {
+				container.Ports[i].Name = embeddedModelPortName
+			}
+		}
+		podSpec.Containers = append(podSpec.Containers, container)
+	}
+	podSpec.InitContainers = append(podSpec.InitContainers, rendered.InitContainers...)
+
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name:         embeddedModelSocketVolume,
+		VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+	})
+	existing := map[string]bool{}
+	for _, volume := range podSpec.Volumes {
+		existing[volume.Name] = true
+	}
+	for _, volume := range rendered.Volumes {
+		if !existing[volume.Name] {
+			podSpec.Volumes = append(podSpec.Volumes, volume)
+		}
+	}
+}
+
+// moveProxyPort makes the LiteLLM container listen on port instead of 8000, updating its
+// --port argument, container port and HTTP probes
+func moveProxyPort(container *corev1.Container, port int32) {
+	for i := 0; i+1 < len(container.Args); i++ {
+		if container.Args[i] == "--port" {
+			container.Args[i+1] = strconv.Itoa(int(port))
+		}
+	}
+	for i := range container.Ports {
+		if container.Ports[i].ContainerPort == 8000 {
+			container.Ports[i].ContainerPort = port
+		}
+	}
+	for _, probe := range []*corev1.Probe{container.LivenessProbe, container.ReadinessProbe, container.StartupProbe} {
+		if probe != nil && probe.HTTPGet != nil && probe.HTTPGet.Port.IntValue() == 8000 {
+			probe.HTTPGet.Port = intstr.FromInt(int(port))
+		}
+	}
+}
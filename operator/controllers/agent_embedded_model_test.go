@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+// envValue returns the value of the named env var of a container
+func envValue(container corev1.Container, name string) string {
+	for _, env := range container.Env {
+		if env.Name == name {
+			return env.Value
+		}
+	}
+	return ""
+}
+
+var _ = Describe("spec.embeddedModel", func() {
+	modelapi := &kaosv1alpha1.ModelAPI{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+		Spec: kaosv1alpha1.ModelAPISpec{
+			Mode:        kaosv1alpha1.ModelAPIModeProxy,
+			ProxyConfig: &kaosv1alpha1.ProxyConfig{Models: []string{"openai/gpt-4"}},
+		},
+		Status: kaosv1alpha1.ModelAPIStatus{Endpoint: "http://modelapi-api.default.svc.cluster.local:8000"},
+	}
+
+	It("should run the ModelAPI in the agent pod, sharing the socket volume", func() {
+		agent := &kaosv1alpha1.Agent{
+			ObjectMeta: metav1.ObjectMeta{Name: "colocated", Namespace: "default"},
+			Spec: kaosv1alpha1.AgentSpec{
+				ModelAPI:      "api",
+				Model:         "openai/gpt-4",
+				EmbeddedModel: &kaosv1alpha1.EmbeddedModelConfig{},
+			},
+		}
+		r := &AgentReconciler{}
+		podSpec := r.constructDeployment(agent, modelapi, nil, nil, nil).Spec.Template.Spec
+
+		Expect(podSpec.Containers).To(HaveLen(2))
+		agentContainer, model := podSpec.Containers[0], podSpec.Containers[1]
+		Expect(agentContainer.Name).To(Equal("agent"))
+		Expect(model.Name).To(Equal("model-api"))
+		socketMount := corev1.VolumeMount{Name: "model-socket", MountPath: "/var/run/kaos-model"}
+		Expect(agentContainer.VolumeMounts).To(ContainElement(socketMount))
+		Expect(model.VolumeMounts).To(ContainElement(socketMount))
+		Expect(podSpec.Volumes).To(ContainElement(corev1.Volume{
+			Name:         "model-socket",
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		}))
+		Expect(envValue(agentContainer, "MODEL_API_SOCKET")).To(Equal("/var/run/kaos-model/model.sock"))
+		Expect(envValue(model, "MODEL_API_SOCKET")).To(Equal("/var/run/kaos-model/model.sock"))
+
+		// The LiteLLM config mount carries over, and the proxy moves off the agent's port
+		Expect(podSpec.Volumes).To(ContainElement(HaveField("Name", "litellm-config")))
+		Expect(model.Args).To(Equal([]string{"--config", "/etc/litellm/config.yaml", "--port", "8001"}))
+		Expect(model.Ports).To(Equal([]corev1.ContainerPort{{Name: "model-http", ContainerPort: 8001, Protocol: corev1.ProtocolTCP}}))
+		Expect(model.ReadinessProbe.HTTPGet.Port.IntValue()).To(Equal(8001))
+		Expect(envValue(agentContainer, "MODEL_API_URL")).To(Equal("http://localhost:8001"))
+	})
+
+	It("should call the ModelAPI Service without an embedded model", func() {
+		agent := &kaosv1alpha1.Agent{
+			ObjectMeta: metav1.ObjectMeta{Name: "remote", Namespace: "default"},
+			Spec:       kaosv1alpha1.AgentSpec{ModelAPI: "api", Model: "openai/gpt-4"},
+		}
+		podSpec := (&AgentReconciler{}).constructDeployment(agent, modelapi, nil, nil, nil).Spec.Template.Spec
+		Expect(podSpec.Containers).To(HaveLen(1))
+		Expect(podSpec.Volumes).To(BeEmpty())
+		Expect(envValue(podSpec.Containers[0], "MODEL_API_URL")).To(Equal(modelapi.Status.Endpoint))
+	})
+
+	It("should resolve the template named in the embedded model", func() {
+		agent := &kaosv1alpha1.Agent{Spec: kaosv1alpha1.AgentSpec{ModelAPI: "api"}}
+		Expect(embeddedModelAPIName(agent)).To(Equal("api"))
+		agent.Spec.EmbeddedModel = &kaosv1alpha1.EmbeddedModelConfig{ModelAPI: "template"}
+		Expect(embeddedModelAPIName(agent)).To(Equal("template"))
+	})
+})
@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"encoding/json"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+	"github.com/axsaucedo/kaos/operator/pkg/util"
+)
+
+// renderAgentTemplate renders the agent pod template for the given config.env as JSON
+func renderAgentTemplate(env []corev1.EnvVar) []byte {
+	agent := &kaosv1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{Name: "stable", Namespace: "default"},
+		Spec: kaosv1alpha1.AgentSpec{
+			ModelAPI: "api",
+			Model:    "mock-model",
+			Config:   &kaosv1alpha1.AgentConfig{Env: env},
+			Telemetry: &kaosv1alpha1.TelemetryConfig{
+				Enabled:  true,
+				Endpoint: "http://otel-collector:4317",
+			},
+		},
+	}
+	modelapi := &kaosv1alpha1.ModelAPI{Status: kaosv1alpha1.ModelAPIStatus{Endpoint: "http://modelapi-api:8000"}}
+	mcpServers := map[string]string{"search": "http://mcpserver-search:8000", "echo": "http://mcpserver-echo:8000"}
+
+	deployment := (&AgentReconciler{}).constructDeployment(agent, modelapi, mcpServers, nil, nil)
+	data, err := json.Marshal(deployment.Spec.Template)
+	Expect(err).NotTo(HaveOccurred())
+	return data
+}
+
+var _ = Describe("Agent env rendering", func() {
+	It("should render a byte-stable pod template when config.env is reordered", func() {
+		userEnv := []corev1.EnvVar{
+			{Name: "ZETA", Value: "z"},
+			{Name: "ALPHA", Value: "a"},
+			{Name: "GREETING_URL", Value: "$(MODEL_API_URL)/greet"},
+			{Name: "SECRET", ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "creds"},
+					Key:                  "token",
+				},
+			}},
+		}
+		reordered := []corev1.EnvVar{userEnv[3], userEnv[1], userEnv[0], userEnv[2]}
+
+		Expect(renderAgentTemplate(reordered)).To(Equal(renderAgentTemplate(userEnv)))
+	})
+
+	It("should override user env with operator-injected values", func() {
+		var template corev1.PodTemplateSpec
+		Expect(json.Unmarshal(renderAgentTemplate([]corev1.EnvVar{{Name: "MODEL_NAME", Value: "user-model"}}), &template)).To(Succeed())
+
+		var modelNames []string
+		for _, e := range template.Spec.Containers[0].Env {
+			if e.Name == "MODEL_NAME" {
+				modelNames = append(modelNames, e.Value)
+			}
+		}
+		Expect(modelNames).To(Equal([]string{"mock-model"}))
+		Expect(template.Annotations).To(HaveKey(util.PodSpecHashAnnotation))
+	})
+
+	It("should let user env override operator values with the escape annotation", func() {
+		agent := &kaosv1alpha1.Agent{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "override",
+				Namespace:   "default",
+				Annotations: map[string]string{kaosv1alpha1.AllowReservedEnvAnnotation: "true"},
+			},
+			Spec: kaosv1alpha1.AgentSpec{
+				ModelAPI: "api",
+				Model:    "mock-model",
+				Config:   &kaosv1alpha1.AgentConfig{Env: []corev1.EnvVar{{Name: "MODEL_API_URL", Value: "http://egress-proxy:8080"}}},
+			},
+		}
+		modelapi := &kaosv1alpha1.ModelAPI{Status: kaosv1alpha1.ModelAPIStatus{Endpoint: "http://modelapi-api:8000"}}
+
+		env := (&AgentReconciler{}).constructDeployment(agent, modelapi, nil, nil, nil).Spec.Template.Spec.Containers[0].Env
+		Expect(env).To(ContainElement(corev1.EnvVar{Name: "MODEL_API_URL", Value: "http://egress-proxy:8080"}))
+		Expect(env).NotTo(ContainElement(corev1.EnvVar{Name: "MODEL_API_URL", Value: "http://modelapi-api:8000"}))
+		Expect(env).To(ContainElement(corev1.EnvVar{Name: "MODEL_NAME", Value: "mock-model"}))
+	})
+
+	It("should only set env vars the webhook reserves", func() {
+		enabled, limit, steps, concurrency := true, int32(10), int32(5), int32(4)
+		agent := &kaosv1alpha1.Agent{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "everything", Namespace: "default",
+				Annotations: map[string]string{kaosv1alpha1.TraceSampleRatioAnnotation: "0.5"},
+			},
+			Spec: kaosv1alpha1.AgentSpec{
+				ModelAPI: "api",
+				Model:    "mock-model",
+				Config: &kaosv1alpha1.AgentConfig{
+					ReasoningLoopMaxSteps: &steps,
+					Memory: &kaosv1alpha1.MemoryConfig{
+						Enabled: &enabled, Type: "local", ContextLimit: &limit, MaxSessions: &limit, MaxSessionEvents: &limit,
+					},
+				},
+				Runtime: &kaosv1alpha1.AgentRuntimeConfig{MaxConcurrency: &concurrency, RequestTimeout: time.Minute.String()},
+				Telemetry: &kaosv1alpha1.TelemetryConfig{
+					Enabled: true, Endpoint: "http://otel:4317", TracesEndpoint: "http://otel:4317",
+					MetricsEndpoint: "http://otel:4317", LogsEndpoint: "http://otel:4317", Headers: map[string]string{"a": "b"},
+				},
+				LeaderLease:                  true,
+				ProjectedServiceAccountToken: &kaosv1alpha1.ProjectedServiceAccountTokenConfig{Audience: "vault"},
+			},
+		}
+		env := (&AgentReconciler{}).constructEnvVars(agent, &kaosv1alpha1.ModelAPI{},
+			map[string]string{"search": "http://search:8000"}, map[string][]string{"search": {"lookup"}},
+			map[string]string{"worker": "http://worker:8000"})
+
+		Expect(len(env)).To(BeNumerically(">", 30))
+		for _, e := range env {
+			Expect(util.IsReservedAgentEnv(e.Name)).To(BeTrue(), e.Name)
+		}
+	})
+})
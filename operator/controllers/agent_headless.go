@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+	"github.com/axsaucedo/kaos/operator/pkg/util"
+)
+
+// headlessServiceName returns the name of the headless Service of an agent
+func headlessServiceName(agent *kaosv1alpha1.Agent) string {
+	return fmt.Sprintf("agent-%s-headless", agent.Name)
+}
+
+// reconcileHeadlessService applies the headless Service when spec.headlessService is set or
+// the agent runs from a StatefulSet, and removes it otherwise. It is created alongside the regular A2A Service, whose virtual IP
+// keeps backing status.endpoint.
+func (r *AgentReconciler) reconcileHeadlessService(ctx context.Context, agent *kaosv1alpha1.Agent, log logr.Logger) error {
+	existing := &corev1.Service{}
+	err := r.Get(ctx, types.NamespacedName{Name: headlessServiceName(agent), Namespace: agent.Namespace}, existing)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	found := err == nil
+
+	if !headlessServiceEnabled(agent) {
+		if found && metav1.IsControlledBy(existing, agent) {
+			log.Info("Deleting headless Service", "name", existing.Name)
+			return client.IgnoreNotFound(r.Delete(ctx, existing))
+		}
+		return nil
+	}
+
+	if !found {
+		desired := constructHeadlessService(agent)
+		if err := controllerutil.SetControllerReference(agent, desired, r.Scheme); err != nil {
+			return err
+		}
+		log.Info("Creating headless Service", "name", desired.Name)
+		return r.Create(ctx, desired)
+	}
+
+	if util.PropagateLabels(existing, agent, util.PropagatedLabelKeys()) {
+		return r.Update(ctx, existing)
+	}
+	return nil
+}
+
+// constructHeadlessService creates a clusterIP: None Service selecting the agent pods. Not-ready
+// pods are published too, so peers can resolve each other while they start up.
+func constructHeadlessService(agent *kaosv1alpha1.Agent) *corev1.Service {
+	labels := map[string]string{
+		"app":   "agent",
+		"agent": agent.Name,
+	}
+
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      headlessServiceName(agent),
+			Namespace: agent.Namespace,
+			Labels:    labels,
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP:                corev1.ClusterIPNone,
+			PublishNotReadyAddresses: true,
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "http",
+					Port:       8000,
+					TargetPort: intstr.FromInt(8000),
+					Protocol:   corev1.ProtocolTCP,
+				},
+			},
+			Selector: labels,
+		},
+	}
+
+	util.PropagateLabels(service, agent, util.PropagatedLabelKeys())
+
+	return service
+}
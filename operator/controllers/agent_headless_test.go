@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+var _ = Describe("Agent headless Service", func() {
+	var (
+		r     *AgentReconciler
+		agent *kaosv1alpha1.Agent
+		key   types.NamespacedName
+	)
+
+	BeforeEach(func() {
+		agent = &kaosv1alpha1.Agent{
+			ObjectMeta: metav1.ObjectMeta{Name: "stateful", Namespace: "default", UID: "agent-uid"},
+			Spec: kaosv1alpha1.AgentSpec{
+				ModelAPI:        "api",
+				Model:           "mock-model",
+				HeadlessService: true,
+			},
+		}
+		r, _ = newCachedAgentReconciler(agent)
+		key = types.NamespacedName{Name: "agent-stateful-headless", Namespace: "default"}
+	})
+
+	It("should create a clusterIP: None Service selecting the agent pods", func() {
+		ctx := context.Background()
+		Expect(r.reconcileHeadlessService(ctx, agent, log.FromContext(ctx))).To(Succeed())
+
+		service := &corev1.Service{}
+		Expect(r.Get(ctx, key, service)).To(Succeed())
+		Expect(metav1.IsControlledBy(service, agent)).To(BeTrue())
+		Expect(service.Spec.ClusterIP).To(Equal(corev1.ClusterIPNone))
+		Expect(service.Spec.PublishNotReadyAddresses).To(BeTrue())
+		Expect(service.Spec.Selector).To(Equal(map[string]string{"app": "agent", "agent": "stateful"}))
+		Expect(service.Spec.Ports).To(HaveLen(1))
+		Expect(service.Spec.Ports[0].Port).To(Equal(int32(8000)))
+	})
+
+	It("should remove the headless Service when it is disabled", func() {
+		ctx := context.Background()
+		Expect(r.reconcileHeadlessService(ctx, agent, log.FromContext(ctx))).To(Succeed())
+
+		agent.Spec.HeadlessService = false
+		Expect(r.reconcileHeadlessService(ctx, agent, log.FromContext(ctx))).To(Succeed())
+		err := r.Get(ctx, key, &corev1.Service{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+})
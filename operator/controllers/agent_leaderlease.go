@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+// leaderLeaseName returns the name of the coordination Lease the replicas of an agent elect a leader with
+func leaderLeaseName(agent *kaosv1alpha1.Agent) string {
+	return fmt.Sprintf("agent-%s-leader", agent.Name)
+}
+
+// reconcileLeaderLease creates the leader Lease when spec.leaderLease is set and removes it
+// otherwise. The operator only creates the Lease: the agent pods acquire and renew it, so it
+// is neither updated here nor watched, as renewals would otherwise trigger reconciles. Leases
+// are not cached by the manager either, so the Lease is read from the API server.
+func (r *AgentReconciler) reconcileLeaderLease(ctx context.Context, agent *kaosv1alpha1.Agent, log logr.Logger) error {
+	existing := &coordinationv1.Lease{}
+	err := r.Get(ctx, types.NamespacedName{Name: leaderLeaseName(agent), Namespace: agent.Namespace}, existing)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	found := err == nil
+
+	if !agent.Spec.LeaderLease {
+		if found && metav1.IsControlledBy(existing, agent) {
+			log.Info("Deleting leader Lease", "name", existing.Name)
+			return client.IgnoreNotFound(r.Delete(ctx, existing))
+		}
+		return nil
+	}
+	if found {
+		return nil
+	}
+
+	lease := &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      leaderLeaseName(agent),
+			Namespace: agent.Namespace,
+			Labels: map[string]string{
+				"app":   "agent",
+				"agent": agent.Name,
+			},
+		},
+	}
+	if err := controllerutil.SetControllerReference(agent, lease, r.Scheme); err != nil {
+		return err
+	}
+	log.Info("Creating leader Lease", "name", lease.Name)
+	return client.IgnoreAlreadyExists(r.Create(ctx, lease))
+}
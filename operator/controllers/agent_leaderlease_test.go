@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+var _ = Describe("Agent leader Lease", func() {
+	It("should inject AGENT_LEADER_LEASE and create the Lease only when enabled", func() {
+		replicas := int32(3)
+		agent := &kaosv1alpha1.Agent{
+			ObjectMeta: metav1.ObjectMeta{Name: "elected", Namespace: "default", UID: "agent-uid"},
+			Spec: kaosv1alpha1.AgentSpec{
+				ModelAPI:    "api",
+				Model:       "mock-model",
+				Replicas:    &replicas,
+				LeaderLease: true,
+			},
+		}
+		r, c := newCachedAgentReconciler(agent)
+		ctx := context.Background()
+		key := types.NamespacedName{Name: "agent-elected-leader", Namespace: "default"}
+
+		env := r.constructEnvVars(agent, &kaosv1alpha1.ModelAPI{}, nil, nil, nil)
+		Expect(env).To(ContainElement(corev1.EnvVar{Name: "AGENT_LEADER_LEASE", Value: "agent-elected-leader"}))
+
+		Expect(r.reconcileLeaderLease(ctx, agent, log.FromContext(ctx))).To(Succeed())
+		lease := &coordinationv1.Lease{}
+		Expect(c.Get(ctx, key, lease)).To(Succeed())
+		Expect(metav1.IsControlledBy(lease, agent)).To(BeTrue())
+
+		// A Lease held by a pod is left alone on later reconciles
+		holder := "agent-elected-0"
+		lease.Spec.HolderIdentity = &holder
+		Expect(c.Update(ctx, lease)).To(Succeed())
+		Expect(r.reconcileLeaderLease(ctx, agent, log.FromContext(ctx))).To(Succeed())
+		Expect(c.Get(ctx, key, lease)).To(Succeed())
+		Expect(*lease.Spec.HolderIdentity).To(Equal(holder))
+
+		agent.Spec.LeaderLease = false
+		env = r.constructEnvVars(agent, &kaosv1alpha1.ModelAPI{}, nil, nil, nil)
+		Expect(env).NotTo(ContainElement(HaveField("Name", "AGENT_LEADER_LEASE")))
+		Expect(r.reconcileLeaderLease(ctx, agent, log.FromContext(ctx))).To(Succeed())
+		Expect(apierrors.IsNotFound(c.Get(ctx, key, lease))).To(BeTrue())
+	})
+
+	It("should recreate a deleted Lease although nothing else changed", func() {
+		agent := &kaosv1alpha1.Agent{
+			ObjectMeta: metav1.ObjectMeta{Name: "elected", Namespace: "default"},
+			Spec:       kaosv1alpha1.AgentSpec{ModelAPI: "api", Model: "mock-model", LeaderLease: true},
+		}
+		modelapi := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode:        kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig: &kaosv1alpha1.ProxyConfig{Models: []string{"mock-model"}},
+			},
+			Status: kaosv1alpha1.ModelAPIStatus{Ready: true, Endpoint: "http://modelapi-api.default:8000"},
+		}
+		r, c := newCachedAgentReconciler(agent, modelapi)
+		ctx := context.Background()
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "elected", Namespace: "default"}}
+		key := types.NamespacedName{Name: "agent-elected-leader", Namespace: "default"}
+
+		for i := 0; i < 2; i++ {
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		lease := &coordinationv1.Lease{}
+		Expect(c.Get(ctx, key, lease)).To(Succeed())
+		Expect(c.Delete(ctx, lease)).To(Succeed())
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, key, &coordinationv1.Lease{})).To(Succeed())
+	})
+})
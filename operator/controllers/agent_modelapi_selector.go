@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+// errNoModelAPIMatch is returned while no Ready ModelAPI matches spec.modelAPISelector
+var errNoModelAPIMatch = errors.New("no Ready ModelAPI matches spec.modelAPISelector")
+
+// selectsModelAPI reports whether the Agent finds its ModelAPI by spec.modelAPISelector
+// rather than by name
+func selectsModelAPI(agent *kaosv1alpha1.Agent) bool {
+	return agent.Spec.ModelAPISelector != nil && embeddedModelAPIName(agent) == ""
+}
+
+// agentModelAPIName returns the name of the ModelAPI the Agent uses: the one it names, or the
+// one its spec.modelAPISelector last resolved to
+func agentModelAPIName(agent *kaosv1alpha1.Agent) string {
+	if selectsModelAPI(agent) {
+		return agent.Status.LinkedResources["modelapi"]
+	}
+	return embeddedModelAPIName(agent)
+}
+
+// agentUsesModelAPI reports whether a change to the ModelAPI can change the Agent: it is the
+// ModelAPI the Agent names, or its labels match the Agent's spec.modelAPISelector
+func agentUsesModelAPI(agent *kaosv1alpha1.Agent, modelapi *kaosv1alpha1.ModelAPI) bool {
+	if !selectsModelAPI(agent) {
+		return embeddedModelAPIName(agent) == modelapi.Name
+	}
+	selector, err := metav1.LabelSelectorAsSelector(agent.Spec.ModelAPISelector)
+	return err == nil && selector.Matches(labels.Set(modelapi.Labels))
+}
+
+// selectModelAPI resolves spec.modelAPISelector to the single Ready ModelAPI in the Agent's
+// namespace matching it. It returns errNoModelAPIMatch while none is Ready, and an error
+// naming the candidates when several are, since picking one would be arbitrary.
+func (r *AgentReconciler) selectModelAPI(ctx context.Context, agent *kaosv1alpha1.Agent) (*kaosv1alpha1.ModelAPI, error) {
+	selector, err := metav1.LabelSelectorAsSelector(agent.Spec.ModelAPISelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid spec.modelAPISelector: %w", err)
+	}
+	modelapis := &kaosv1alpha1.ModelAPIList{}
+	if err := r.List(ctx, modelapis, client.InNamespace(agent.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, err
+	}
+
+	var ready []*kaosv1alpha1.ModelAPI
+	for i := range modelapis.Items {
+		if modelapis.Items[i].Status.Ready && modelapis.Items[i].DeletionTimestamp == nil {
+			ready = append(ready, &modelapis.Items[i])
+		}
+	}
+	switch len(ready) {
+	case 0:
+		return nil, errNoModelAPIMatch
+	case 1:
+		return ready[0], nil
+	}
+	names := make([]string, 0, len(ready))
+	for _, modelapi := range ready {
+		names = append(names, modelapi.Name)
+	}
+	slices.Sort(names)
+	return nil, fmt.Errorf("spec.modelAPISelector matches %d Ready ModelAPIs (%s), it must match exactly one", len(names), strings.Join(names, ", "))
+}
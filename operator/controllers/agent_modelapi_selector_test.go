@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+var _ = Describe("spec.modelAPISelector", func() {
+	agent := &kaosv1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{Name: "selecting", Namespace: "default"},
+		Spec: kaosv1alpha1.AgentSpec{
+			Model:            "openai/gpt-4",
+			ModelAPISelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "search"}},
+		},
+	}
+	modelAPI := func(name, team string, ready bool) *kaosv1alpha1.ModelAPI {
+		return &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"team": team}},
+			Status:     kaosv1alpha1.ModelAPIStatus{Ready: ready},
+		}
+	}
+	It("should resolve the single Ready ModelAPI matching the selector", func() {
+		r, _ := newCachedAgentReconciler(
+			modelAPI("api-7f3k", "search", true),
+			// Neither a ModelAPI that is not Ready nor one of another team is a candidate
+			modelAPI("api-starting", "search", false),
+			modelAPI("api-x9q2", "billing", true),
+		)
+		modelapi, err := r.selectModelAPI(context.Background(), agent)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(modelapi.Name).To(Equal("api-7f3k"))
+	})
+
+	It("should wait while no Ready ModelAPI matches", func() {
+		r, _ := newCachedAgentReconciler(modelAPI("api-starting", "search", false), modelAPI("api-x9q2", "billing", true))
+		_, err := r.selectModelAPI(context.Background(), agent)
+		Expect(err).To(MatchError(errNoModelAPIMatch))
+	})
+
+	It("should reject a selector matching several Ready ModelAPIs", func() {
+		r, _ := newCachedAgentReconciler(modelAPI("api-b", "search", true), modelAPI("api-a", "search", true))
+		_, err := r.selectModelAPI(context.Background(), agent)
+		Expect(err).To(MatchError("spec.modelAPISelector matches 2 Ready ModelAPIs (api-a, api-b), it must match exactly one"))
+	})
+
+	It("should map matching ModelAPIs to the Agent", func() {
+		Expect(agentUsesModelAPI(agent, modelAPI("api-7f3k", "search", false))).To(BeTrue())
+		Expect(agentUsesModelAPI(agent, modelAPI("api-x9q2", "billing", true))).To(BeFalse())
+
+		named := &kaosv1alpha1.Agent{Spec: kaosv1alpha1.AgentSpec{ModelAPI: "api-x9q2"}}
+		Expect(agentUsesModelAPI(named, modelAPI("api-x9q2", "billing", true))).To(BeTrue())
+	})
+})
@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+	"github.com/axsaucedo/kaos/operator/pkg/util"
+)
+
+// Supported values of the --default-agent-egress operator flag
+const (
+	AgentEgressAllow = "allow"
+	AgentEgressDeny  = "deny"
+)
+
+// egressPolicyName returns the name of the baseline egress NetworkPolicy of an agent
+func egressPolicyName(agent *kaosv1alpha1.Agent) string {
+	return fmt.Sprintf("agent-%s-default-egress", agent.Name)
+}
+
+// reconcileEgressPolicy applies the baseline default-deny egress NetworkPolicy when the
+// operator runs with --default-agent-egress=deny, and removes it otherwise. NetworkPolicies
+// are additive, so explicit allow policies created alongside it keep working.
+func (r *AgentReconciler) reconcileEgressPolicy(ctx context.Context, agent *kaosv1alpha1.Agent, log logr.Logger) error {
+	existing := &networkingv1.NetworkPolicy{}
+	err := r.Get(ctx, types.NamespacedName{Name: egressPolicyName(agent), Namespace: agent.Namespace}, existing)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	found := err == nil
+
+	if r.DefaultAgentEgress != AgentEgressDeny {
+		if found && metav1.IsControlledBy(existing, agent) {
+			log.Info("Deleting egress NetworkPolicy", "name", existing.Name)
+			return client.IgnoreNotFound(r.Delete(ctx, existing))
+		}
+		return nil
+	}
+
+	desired := constructEgressPolicy(agent)
+	if !found {
+		if err := controllerutil.SetControllerReference(agent, desired, r.Scheme); err != nil {
+			return err
+		}
+		log.Info("Creating egress NetworkPolicy", "name", desired.Name)
+		return r.Create(ctx, desired)
+	}
+
+	labelsChanged := util.PropagateLabels(existing, agent, util.PropagatedLabelKeys())
+	if !reflect.DeepEqual(existing.Spec, desired.Spec) || labelsChanged {
+		log.Info("Updating egress NetworkPolicy", "name", existing.Name)
+		existing.Spec = desired.Spec
+		return r.Update(ctx, existing)
+	}
+	return nil
+}
+
+// constructEgressPolicy denies all egress from the agent pods except DNS and traffic to the
+// agent's own dependencies: its ModelAPI, MCP servers and the peer agents it may call
+func constructEgressPolicy(agent *kaosv1alpha1.Agent) *networkingv1.NetworkPolicy {
+	udp, tcp := corev1.ProtocolUDP, corev1.ProtocolTCP
+	dnsPort := intstr.FromInt(53)
+
+	egress := []networkingv1.NetworkPolicyEgressRule{
+		{
+			// DNS is always permitted, to any resolver
+			Ports: []networkingv1.NetworkPolicyPort{
+				{Protocol: &udp, Port: &dnsPort},
+				{Protocol: &tcp, Port: &dnsPort},
+			},
+		},
+		{To: []networkingv1.NetworkPolicyPeer{podPeer("modelapi", agentModelAPIName(agent))}},
+	}
+	for _, name := range agent.Spec.MCPServers {
+		egress = append(egress, networkingv1.NetworkPolicyEgressRule{
+			To: []networkingv1.NetworkPolicyPeer{podPeer("mcpserver", name)},
+		})
+	}
+	if agent.Spec.AgentNetwork != nil {
+		for _, name := range agent.Spec.AgentNetwork.Access {
+			egress = append(egress, networkingv1.NetworkPolicyEgressRule{
+				To: []networkingv1.NetworkPolicyPeer{podPeer("agent", name)},
+			})
+		}
+	}
+
+	policy := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      egressPolicyName(agent),
+			Namespace: agent.Namespace,
+			Labels:    map[string]string{"app": "agent", "agent": agent.Name},
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": "agent", "agent": agent.Name},
+			},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+			Egress:      egress,
+		},
+	}
+	util.PropagateLabels(policy, agent, util.PropagatedLabelKeys())
+
+	return policy
+}
+
+// podPeer selects the pods the operator generates for the named resource of the given app
+func podPeer(app, name string) networkingv1.NetworkPolicyPeer {
+	return networkingv1.NetworkPolicyPeer{
+		PodSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"app": app, app: name},
+		},
+	}
+}
@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+var _ = Describe("Agent egress NetworkPolicy", func() {
+	var (
+		r     *AgentReconciler
+		agent *kaosv1alpha1.Agent
+		key   types.NamespacedName
+	)
+
+	BeforeEach(func() {
+		agent = &kaosv1alpha1.Agent{
+			ObjectMeta: metav1.ObjectMeta{Name: "locked", Namespace: "default", UID: "agent-uid"},
+			Spec: kaosv1alpha1.AgentSpec{
+				ModelAPI:     "api",
+				Model:        "mock-model",
+				MCPServers:   []string{"search"},
+				AgentNetwork: &kaosv1alpha1.AgentNetworkConfig{Access: []string{"worker"}},
+			},
+		}
+		r, _ = newCachedAgentReconciler(agent)
+		r.DefaultAgentEgress = AgentEgressDeny
+		key = types.NamespacedName{Name: "agent-locked-default-egress", Namespace: "default"}
+	})
+
+	It("should apply a baseline default-deny egress policy that permits DNS and dependencies", func() {
+		ctx := context.Background()
+		Expect(r.reconcileEgressPolicy(ctx, agent, log.FromContext(ctx))).To(Succeed())
+
+		policy := &networkingv1.NetworkPolicy{}
+		Expect(r.Get(ctx, key, policy)).To(Succeed())
+		Expect(metav1.IsControlledBy(policy, agent)).To(BeTrue())
+		Expect(policy.Spec.PodSelector.MatchLabels).To(Equal(map[string]string{"app": "agent", "agent": "locked"}))
+		Expect(policy.Spec.PolicyTypes).To(Equal([]networkingv1.PolicyType{networkingv1.PolicyTypeEgress}))
+
+		egress := policy.Spec.Egress
+		Expect(egress).To(HaveLen(4))
+		Expect(egress[0].To).To(BeEmpty())
+		Expect(egress[0].Ports).To(HaveLen(2))
+		Expect(*egress[0].Ports[0].Protocol).To(Equal(corev1.ProtocolUDP))
+		Expect(egress[0].Ports[0].Port.IntValue()).To(Equal(53))
+		Expect(*egress[0].Ports[1].Protocol).To(Equal(corev1.ProtocolTCP))
+		Expect(egress[1].To[0].PodSelector.MatchLabels).To(HaveKeyWithValue("modelapi", "api"))
+		Expect(egress[2].To[0].PodSelector.MatchLabels).To(HaveKeyWithValue("mcpserver", "search"))
+		Expect(egress[3].To[0].PodSelector.MatchLabels).To(HaveKeyWithValue("agent", "worker"))
+	})
+
+	It("should remove the baseline policy when egress is allowed", func() {
+		ctx := context.Background()
+		Expect(r.reconcileEgressPolicy(ctx, agent, log.FromContext(ctx))).To(Succeed())
+
+		r.DefaultAgentEgress = AgentEgressAllow
+		Expect(r.reconcileEgressPolicy(ctx, agent, log.FromContext(ctx))).To(Succeed())
+		err := r.Get(ctx, key, &networkingv1.NetworkPolicy{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+})
@@ -0,0 +1,36 @@
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+var _ = Describe("spec.secretKeyMappings", func() {
+	It("should create the mapped env vars from the source secret keys", func() {
+		agent := &kaosv1alpha1.Agent{
+			ObjectMeta: metav1.ObjectMeta{Name: "mapped", Namespace: "default"},
+			Spec: kaosv1alpha1.AgentSpec{
+				ModelAPI: "api",
+				Model:    "mock-model",
+				SecretKeyMappings: []kaosv1alpha1.SecretKeyMapping{
+					{SecretName: "provider-credentials", Key: "api-key", EnvName: "OPENAI_API_KEY"},
+					{SecretName: "provider-credentials", Key: "org", EnvName: "OPENAI_ORG_ID"},
+				},
+			},
+		}
+		env := (&AgentReconciler{}).constructEnvVars(agent, &kaosv1alpha1.ModelAPI{}, nil, nil, nil)
+
+		secretRef := func(key string) *corev1.EnvVarSource {
+			return &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "provider-credentials"},
+				Key:                  key,
+			}}
+		}
+		Expect(env).To(ContainElement(corev1.EnvVar{Name: "OPENAI_API_KEY", ValueFrom: secretRef("api-key")}))
+		Expect(env).To(ContainElement(corev1.EnvVar{Name: "OPENAI_ORG_ID", ValueFrom: secretRef("org")}))
+	})
+})
@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+	"github.com/axsaucedo/kaos/operator/pkg/util"
+)
+
+// statefulAgent reports whether the agent runs from a StatefulSet instead of a Deployment
+func statefulAgent(agent *kaosv1alpha1.Agent) bool {
+	return agent.Spec.WorkloadType == kaosv1alpha1.AgentWorkloadTypeStatefulSet
+}
+
+// headlessServiceEnabled reports whether the agent needs its headless Service, either
+// because it was requested or because it governs the agent's StatefulSet
+func headlessServiceEnabled(agent *kaosv1alpha1.Agent) bool {
+	return agent.Spec.HeadlessService || statefulAgent(agent)
+}
+
+// constructStatefulSet creates a StatefulSet running the pod template, replicas and selector
+// of the agent's desired Deployment, with the headless Service as its governing Service
+func constructStatefulSet(agent *kaosv1alpha1.Agent, deployment *appsv1.Deployment) *appsv1.StatefulSet {
+	podManagementPolicy := agent.Spec.PodManagementPolicy
+	if podManagementPolicy == "" {
+		podManagementPolicy = appsv1.OrderedReadyPodManagement
+	}
+	return &appsv1.StatefulSet{
+		ObjectMeta: deployment.ObjectMeta,
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:             deployment.Spec.Replicas,
+			RevisionHistoryLimit: deployment.Spec.RevisionHistoryLimit,
+			Selector:             deployment.Spec.Selector,
+			Template:             deployment.Spec.Template,
+			ServiceName:          headlessServiceName(agent),
+			VolumeClaimTemplates: agent.Spec.VolumeClaimTemplates,
+			PodManagementPolicy:  podManagementPolicy,
+		},
+	}
+}
+
+// reconcileStatefulSet creates or updates the StatefulSet of a StatefulSet agent, removing
+// the Deployment left over from a workloadType switch first so both never run at once.
+// VolumeClaimTemplates and the pod management policy are immutable, so changes only apply to a
+// recreated StatefulSet.
+func (r *AgentReconciler) reconcileStatefulSet(ctx context.Context, agent *kaosv1alpha1.Agent, desired *appsv1.StatefulSet, log logr.Logger) (*appsv1.StatefulSet, error) {
+	if err := r.pruneWorkload(ctx, agent, &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: desired.Name}}, log); err != nil {
+		return nil, err
+	}
+
+	statefulSet := &appsv1.StatefulSet{}
+	err := r.Get(ctx, types.NamespacedName{Name: desired.Name, Namespace: agent.Namespace}, statefulSet)
+	if apierrors.IsNotFound(err) {
+		if err := controllerutil.SetControllerReference(agent, desired, r.Scheme); err != nil {
+			return nil, err
+		}
+		log.Info("Creating StatefulSet", "name", desired.Name)
+		return desired, r.Create(ctx, desired)
+	} else if err != nil {
+		return nil, err
+	}
+
+	currentHash := statefulSet.Spec.Template.Annotations[util.PodSpecHashAnnotation]
+	desiredHash := desired.Spec.Template.Annotations[util.PodSpecHashAnnotation]
+	labelsChanged := util.PropagateLabels(statefulSet, agent, util.PropagatedLabelKeys())
+	replicasChanged := statefulSet.Spec.Replicas == nil || *statefulSet.Spec.Replicas != *desired.Spec.Replicas
+	if currentHash != desiredHash {
+		log.Info("Updating StatefulSet due to spec change", "name", statefulSet.Name,
+			"currentHash", currentHash, "desiredHash", desiredHash)
+		statefulSet.Spec.Template = desired.Spec.Template
+	}
+	if replicasChanged {
+		log.Info("Scaling StatefulSet", "name", statefulSet.Name, "replicas", *desired.Spec.Replicas)
+		statefulSet.Spec.Replicas = desired.Spec.Replicas
+	}
+	historyLimitChanged := revisionHistoryLimitChanged(statefulSet.Spec.RevisionHistoryLimit, desired.Spec.RevisionHistoryLimit)
+	if historyLimitChanged {
+		statefulSet.Spec.RevisionHistoryLimit = desired.Spec.RevisionHistoryLimit
+	}
+	if currentHash != desiredHash || labelsChanged || replicasChanged || historyLimitChanged {
+		if err := r.Update(ctx, statefulSet); err != nil {
+			return nil, err
+		}
+	}
+	return statefulSet, nil
+}
+
+// pruneWorkload deletes the named workload of the agent if it exists and is controlled by the
+// agent, e.g. the StatefulSet left behind after switching back to a Deployment
+func (r *AgentReconciler) pruneWorkload(ctx context.Context, agent *kaosv1alpha1.Agent, obj client.Object, log logr.Logger) error {
+	if err := r.Get(ctx, types.NamespacedName{Name: obj.GetName(), Namespace: agent.Namespace}, obj); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !metav1.IsControlledBy(obj, agent) {
+		return nil
+	}
+	log.Info("Deleting workload replaced by workloadType change", "name", obj.GetName())
+	return client.IgnoreNotFound(r.Delete(ctx, obj))
+}
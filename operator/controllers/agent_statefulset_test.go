@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+var _ = Describe("Agent StatefulSet workload", func() {
+	It("should replace the Deployment with a StatefulSet and keep it scaled", func() {
+		agent := &kaosv1alpha1.Agent{
+			ObjectMeta: metav1.ObjectMeta{Name: "stateful", Namespace: "default", UID: "agent-uid"},
+			Spec: kaosv1alpha1.AgentSpec{
+				ModelAPI:     "api",
+				Model:        "mock-model",
+				WorkloadType: kaosv1alpha1.AgentWorkloadTypeStatefulSet,
+			},
+		}
+		r, c := newCachedAgentReconciler(agent)
+		ctx := context.Background()
+		key := types.NamespacedName{Name: "agent-stateful", Namespace: "default"}
+
+		// A Deployment left from before switching workloadType
+		desired := r.constructDeployment(agent, &kaosv1alpha1.ModelAPI{}, nil, nil, nil)
+		previous := desired.DeepCopy()
+		Expect(controllerutil.SetControllerReference(agent, previous, r.Scheme)).To(Succeed())
+		Expect(c.Create(ctx, previous)).To(Succeed())
+
+		_, err := r.reconcileStatefulSet(ctx, agent, constructStatefulSet(agent, desired), log.FromContext(ctx))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(apierrors.IsNotFound(c.Get(ctx, key, &appsv1.Deployment{}))).To(BeTrue())
+
+		statefulSet := &appsv1.StatefulSet{}
+		Expect(c.Get(ctx, key, statefulSet)).To(Succeed())
+		Expect(metav1.IsControlledBy(statefulSet, agent)).To(BeTrue())
+		Expect(statefulSet.Spec.ServiceName).To(Equal("agent-stateful-headless"))
+		Expect(*statefulSet.Spec.Replicas).To(Equal(int32(1)))
+
+		replicas := int32(3)
+		agent.Spec.Replicas = &replicas
+		desired = r.constructDeployment(agent, &kaosv1alpha1.ModelAPI{}, nil, nil, nil)
+		_, err = r.reconcileStatefulSet(ctx, agent, constructStatefulSet(agent, desired), log.FromContext(ctx))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, key, statefulSet)).To(Succeed())
+		Expect(*statefulSet.Spec.Replicas).To(Equal(int32(3)))
+		Expect(*statefulSet.Spec.RevisionHistoryLimit).To(Equal(int32(3)))
+
+		limit := int32(1)
+		agent.Spec.RevisionHistoryLimit = &limit
+		desired = r.constructDeployment(agent, &kaosv1alpha1.ModelAPI{}, nil, nil, nil)
+		Expect(*desired.Spec.RevisionHistoryLimit).To(Equal(int32(1)))
+		_, err = r.reconcileStatefulSet(ctx, agent, constructStatefulSet(agent, desired), log.FromContext(ctx))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, key, statefulSet)).To(Succeed())
+		Expect(*statefulSet.Spec.RevisionHistoryLimit).To(Equal(int32(1)))
+	})
+
+	It("should start pods in order unless podManagementPolicy is Parallel", func() {
+		agent := &kaosv1alpha1.Agent{
+			ObjectMeta: metav1.ObjectMeta{Name: "stateful", Namespace: "default"},
+			Spec: kaosv1alpha1.AgentSpec{
+				ModelAPI:     "api",
+				Model:        "mock-model",
+				WorkloadType: kaosv1alpha1.AgentWorkloadTypeStatefulSet,
+			},
+		}
+		r := &AgentReconciler{}
+		desired := r.constructDeployment(agent, &kaosv1alpha1.ModelAPI{}, nil, nil, nil)
+		Expect(constructStatefulSet(agent, desired).Spec.PodManagementPolicy).To(Equal(appsv1.OrderedReadyPodManagement))
+
+		agent.Spec.PodManagementPolicy = appsv1.ParallelPodManagement
+		Expect(constructStatefulSet(agent, desired).Spec.PodManagementPolicy).To(Equal(appsv1.ParallelPodManagement))
+	})
+})
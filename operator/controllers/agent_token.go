@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+	"github.com/axsaucedo/kaos/operator/pkg/util"
+)
+
+const (
+	// serviceAccountTokenVolumeName is the pod volume holding the projected token
+	serviceAccountTokenVolumeName = "kaos-token"
+
+	// serviceAccountTokenMountPath is where the projected token is mounted in the main
+	// container, apart from the default service account mount
+	serviceAccountTokenMountPath = "/var/run/secrets/kaos/serviceaccount"
+
+	// serviceAccountTokenPath is the file name of the token in the volume
+	serviceAccountTokenPath = "token"
+
+	// defaultServiceAccountTokenExpiration is the token lifetime when expirationSeconds is unset
+	defaultServiceAccountTokenExpiration = int64(3600)
+)
+
+// serviceAccountTokenEnvVars returns the path of the projected token, or nothing when
+// spec.projectedServiceAccountToken is unset
+func serviceAccountTokenEnvVars(config *kaosv1alpha1.ProjectedServiceAccountTokenConfig) []corev1.EnvVar {
+	if config == nil {
+		return nil
+	}
+	return []corev1.EnvVar{
+		{Name: util.ServiceAccountTokenFileEnv, Value: serviceAccountTokenMountPath + "/" + serviceAccountTokenPath},
+	}
+}
+
+// mountServiceAccountToken adds a projected volume with a bound token for the configured
+// audience, and mounts it read-only into the named container. The kubelet rotates the
+// token, unlike a legacy service account token Secret
+func mountServiceAccountToken(spec *corev1.PodSpec, containerName string, config *kaosv1alpha1.ProjectedServiceAccountTokenConfig) {
+	if config == nil {
+		return
+	}
+	expiration := defaultServiceAccountTokenExpiration
+	if config.ExpirationSeconds != nil {
+		expiration = *config.ExpirationSeconds
+	}
+	spec.Volumes = append(spec.Volumes, corev1.Volume{
+		Name: serviceAccountTokenVolumeName,
+		VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{
+			Sources: []corev1.VolumeProjection{{
+				ServiceAccountToken: &corev1.ServiceAccountTokenProjection{
+					Audience:          config.Audience,
+					ExpirationSeconds: &expiration,
+					Path:              serviceAccountTokenPath,
+				},
+			}},
+		}},
+	})
+	for i := range spec.Containers {
+		if spec.Containers[i].Name == containerName {
+			spec.Containers[i].VolumeMounts = append(spec.Containers[i].VolumeMounts, corev1.VolumeMount{
+				Name:      serviceAccountTokenVolumeName,
+				MountPath: serviceAccountTokenMountPath,
+				ReadOnly:  true,
+			})
+		}
+	}
+}
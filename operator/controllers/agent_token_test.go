@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+var _ = Describe("spec.projectedServiceAccountToken", func() {
+	modelapi := &kaosv1alpha1.ModelAPI{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"}}
+	newAgent := func(config *kaosv1alpha1.ProjectedServiceAccountTokenConfig) *kaosv1alpha1.Agent {
+		return &kaosv1alpha1.Agent{
+			ObjectMeta: metav1.ObjectMeta{Name: "operator-bot", Namespace: "default"},
+			Spec: kaosv1alpha1.AgentSpec{
+				ModelAPI:                     "api",
+				Model:                        "openai/gpt-4",
+				ProjectedServiceAccountToken: config,
+			},
+		}
+	}
+
+	It("should mount a projected token with the configured audience", func() {
+		expiration := int64(1800)
+		agent := newAgent(&kaosv1alpha1.ProjectedServiceAccountTokenConfig{Audience: "vault", ExpirationSeconds: &expiration})
+		spec := (&AgentReconciler{}).constructDeployment(agent, modelapi, nil, nil, nil).Spec.Template.Spec
+
+		Expect(spec.Volumes).To(ContainElement(corev1.Volume{
+			Name: serviceAccountTokenVolumeName,
+			VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{
+				Sources: []corev1.VolumeProjection{{ServiceAccountToken: &corev1.ServiceAccountTokenProjection{
+					Audience:          "vault",
+					ExpirationSeconds: &expiration,
+					Path:              "token",
+				}}},
+			}},
+		}))
+		container := spec.Containers[0]
+		Expect(container.VolumeMounts).To(ContainElement(corev1.VolumeMount{
+			Name: serviceAccountTokenVolumeName, MountPath: "/var/run/secrets/kaos/serviceaccount", ReadOnly: true,
+		}))
+		Expect(container.Env).To(ContainElement(corev1.EnvVar{
+			Name: "SERVICE_ACCOUNT_TOKEN_FILE", Value: "/var/run/secrets/kaos/serviceaccount/token",
+		}))
+	})
+
+	It("should default the expiration and mount nothing when unset", func() {
+		agent := newAgent(&kaosv1alpha1.ProjectedServiceAccountTokenConfig{Audience: "vault"})
+		volumes := (&AgentReconciler{}).constructDeployment(agent, modelapi, nil, nil, nil).Spec.Template.Spec.Volumes
+		Expect(volumes).To(HaveLen(1))
+		Expect(*volumes[0].Projected.Sources[0].ServiceAccountToken.ExpirationSeconds).To(Equal(int64(3600)))
+
+		Expect((&AgentReconciler{}).constructDeployment(newAgent(nil), modelapi, nil, nil, nil).Spec.Template.Spec.Volumes).To(BeEmpty())
+	})
+})
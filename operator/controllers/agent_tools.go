@@ -0,0 +1,25 @@
+package controllers
+
+import (
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/axsaucedo/kaos/operator/pkg/util"
+)
+
+// availableToolsEnvVars consolidates the tools advertised by the referenced MCPServers into
+// the AVAILABLE_TOOLS env var, sorted and de-duplicated, so the agent knows its full toolset
+// without querying every server. Nothing is returned while no server advertises tools.
+func availableToolsEnvVars(mcpTools map[string][]string) []corev1.EnvVar {
+	var tools []string
+	for _, serverTools := range mcpTools {
+		tools = append(tools, serverTools...)
+	}
+	if len(tools) == 0 {
+		return nil
+	}
+	slices.Sort(tools)
+	return []corev1.EnvVar{{Name: util.AvailableToolsEnv, Value: strings.Join(slices.Compact(tools), ",")}}
+}
@@ -0,0 +1,32 @@
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+var _ = Describe("AVAILABLE_TOOLS", func() {
+	agent := &kaosv1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{Name: "tooled", Namespace: "default"},
+		Spec:       kaosv1alpha1.AgentSpec{ModelAPI: "api", Model: "openai/gpt-4", MCPServers: []string{"search", "echo"}},
+	}
+	mcpServers := map[string]string{"search": "http://mcpserver-search:8000", "echo": "http://mcpserver-echo:8000"}
+
+	It("should aggregate the tools of the referenced MCPServers", func() {
+		mcpTools := map[string][]string{"search": {"web_search", "fetch"}, "echo": {"echo", "fetch"}}
+
+		env := (&AgentReconciler{}).constructEnvVars(agent, &kaosv1alpha1.ModelAPI{}, mcpServers, mcpTools, nil)
+		Expect(env).To(ContainElement(corev1.EnvVar{Name: "AVAILABLE_TOOLS", Value: "echo,fetch,web_search"}))
+	})
+
+	It("should omit the variable while no server advertises tools", func() {
+		env := (&AgentReconciler{}).constructEnvVars(agent, &kaosv1alpha1.ModelAPI{}, mcpServers, map[string][]string{"search": nil}, nil)
+		for _, e := range env {
+			Expect(e.Name).NotTo(Equal("AVAILABLE_TOOLS"))
+		}
+	})
+})
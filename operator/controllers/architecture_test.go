@@ -0,0 +1,41 @@
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+var _ = Describe("ModelAPI spec.architecture", func() {
+	newModelAPI := func() *kaosv1alpha1.ModelAPI {
+		return &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{Name: "arch", Namespace: "default"},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode:         kaosv1alpha1.ModelAPIModeHosted,
+				HostedConfig: &kaosv1alpha1.HostedConfig{Model: "smollm2:135m"},
+			},
+		}
+	}
+
+	It("should pin the pods to nodes of the architecture", func() {
+		modelapi := newModelAPI()
+		r := &ModelAPIReconciler{}
+		Expect(r.constructDeployment(modelapi).Spec.Template.Spec.NodeSelector).To(BeEmpty())
+
+		modelapi.Spec.Architecture = "arm64"
+		Expect(r.constructDeployment(modelapi).Spec.Template.Spec.NodeSelector).To(Equal(map[string]string{"kubernetes.io/arch": "arm64"}))
+	})
+
+	It("should keep the node selectors of the podSpec override", func() {
+		modelapi := newModelAPI()
+		modelapi.Spec.Architecture = "amd64"
+		modelapi.Spec.PodSpec = &corev1.PodSpec{NodeSelector: map[string]string{"pool": "models"}}
+		Expect((&ModelAPIReconciler{}).constructDeployment(modelapi).Spec.Template.Spec.NodeSelector).To(Equal(map[string]string{
+			"kubernetes.io/arch": "amd64",
+			"pool":               "models",
+		}))
+	})
+})
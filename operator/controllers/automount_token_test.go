@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+var _ = Describe("automountServiceAccountToken", func() {
+	enabled, disabled := true, false
+	meta := metav1.ObjectMeta{Name: "token", Namespace: "default"}
+
+	It("should not mount the service account token on Agent pods unless they call the API", func() {
+		agent := &kaosv1alpha1.Agent{
+			ObjectMeta: meta,
+			Spec:       kaosv1alpha1.AgentSpec{ModelAPI: "api", Model: "mock-model"},
+		}
+		r := &AgentReconciler{}
+		deployment := r.constructDeployment(agent, &kaosv1alpha1.ModelAPI{}, nil, nil, nil)
+		Expect(*deployment.Spec.Template.Spec.AutomountServiceAccountToken).To(BeFalse())
+
+		agent.Spec.AutomountServiceAccountToken = &enabled
+		deployment = r.constructDeployment(agent, &kaosv1alpha1.ModelAPI{}, nil, nil, nil)
+		Expect(*deployment.Spec.Template.Spec.AutomountServiceAccountToken).To(BeTrue())
+
+		// Leader election goes through the API, so the token is mounted unless disabled explicitly
+		agent.Spec.AutomountServiceAccountToken = nil
+		agent.Spec.LeaderLease = true
+		deployment = r.constructDeployment(agent, &kaosv1alpha1.ModelAPI{}, nil, nil, nil)
+		Expect(*deployment.Spec.Template.Spec.AutomountServiceAccountToken).To(BeTrue())
+
+		agent.Spec.AutomountServiceAccountToken = &disabled
+		deployment = r.constructDeployment(agent, &kaosv1alpha1.ModelAPI{}, nil, nil, nil)
+		Expect(*deployment.Spec.Template.Spec.AutomountServiceAccountToken).To(BeFalse())
+	})
+
+	It("should not mount the service account token on ModelAPI pods unless enabled", func() {
+		modelapi := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: meta,
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode:        kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig: &kaosv1alpha1.ProxyConfig{Models: []string{"openai/gpt-4"}},
+			},
+		}
+		r := &ModelAPIReconciler{}
+		Expect(*r.constructDeployment(modelapi).Spec.Template.Spec.AutomountServiceAccountToken).To(BeFalse())
+
+		modelapi.Spec.AutomountServiceAccountToken = &enabled
+		Expect(*r.constructDeployment(modelapi).Spec.Template.Spec.AutomountServiceAccountToken).To(BeTrue())
+	})
+
+	It("should not mount the service account token on MCPServer pods unless enabled", func() {
+		mcpserver := &kaosv1alpha1.MCPServer{
+			ObjectMeta: meta,
+			Spec: kaosv1alpha1.MCPServerSpec{
+				Type: kaosv1alpha1.MCPServerTypePython,
+				Config: kaosv1alpha1.MCPServerConfig{
+					Tools: &kaosv1alpha1.MCPToolsConfig{FromPackage: "mcp-server-kubernetes"},
+				},
+			},
+		}
+		r := &MCPServerReconciler{}
+		Expect(*r.constructDeployment(mcpserver).Spec.Template.Spec.AutomountServiceAccountToken).To(BeFalse())
+
+		mcpserver.Spec.AutomountServiceAccountToken = &enabled
+		Expect(*r.constructDeployment(mcpserver).Spec.Template.Spec.AutomountServiceAccountToken).To(BeTrue())
+	})
+})
@@ -0,0 +1,32 @@
+package controllers
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+	"github.com/axsaucedo/kaos/operator/pkg/util"
+)
+
+// circuitOpen reports whether the breaker holds off reconciling obj, setting the CircuitOpen
+// condition while it does and removing it once the generation or reset annotation changes
+func circuitOpen(breaker *util.CircuitBreaker, obj client.Object, conditions *[]metav1.Condition) bool {
+	key := types.NamespacedName{Name: obj.GetName(), Namespace: obj.GetNamespace()}
+	if breaker.Allow(key, obj.GetGeneration(), obj.GetAnnotations()[kaosv1alpha1.ResetCircuitAnnotation]) {
+		meta.RemoveStatusCondition(conditions, kaosv1alpha1.ConditionCircuitOpen)
+		return false
+	}
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               kaosv1alpha1.ConditionCircuitOpen,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: obj.GetGeneration(),
+		Reason:             kaosv1alpha1.ReasonRepeatedFailures,
+		Message: fmt.Sprintf("Reconcile failed %d times in a row; retrying every %s until it succeeds, the spec changes or the %s annotation is set",
+			breaker.Failures(key), breaker.Interval(), kaosv1alpha1.ResetCircuitAnnotation),
+	})
+	return true
+}
@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+	"github.com/axsaucedo/kaos/operator/pkg/util"
+)
+
+// unavailableRegistry fails every digest lookup
+type unavailableRegistry struct{}
+
+func (unavailableRegistry) ResolveDigest(context.Context, string) (string, error) {
+	return "", errors.New("registry unavailable")
+}
+
+var _ = Describe("Reconcile circuit breaker", func() {
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cached", Namespace: "default"}}
+
+	It("should open the circuit after repeated failures and close it on reset", func() {
+		r, c := newCachedMCPServerReconciler(nil)
+		r.ImageResolver = unavailableRegistry{}
+		r.CircuitBreaker = util.NewCircuitBreaker(3, time.Hour)
+		ctx := context.Background()
+
+		mcpserver := &kaosv1alpha1.MCPServer{}
+		Expect(c.Get(ctx, req.NamespacedName, mcpserver)).To(Succeed())
+		mcpserver.Spec.PinDigest = true
+		Expect(c.Update(ctx, mcpserver)).To(Succeed())
+
+		for i := 0; i < 3; i++ {
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).To(HaveOccurred())
+		}
+
+		// The fourth attempt is held off without an error, so controller-runtime does not retry it either
+		result, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeNumerically(">=", time.Hour))
+		Expect(c.Get(ctx, req.NamespacedName, mcpserver)).To(Succeed())
+		cond := meta.FindStatusCondition(mcpserver.Status.Conditions, kaosv1alpha1.ConditionCircuitOpen)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Reason).To(Equal(kaosv1alpha1.ReasonRepeatedFailures))
+		Expect(cond.Message).To(ContainSubstring("failed 3 times"))
+
+		// The held-off reconcile does not close the circuit, so the next one is held off too
+		result, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeNumerically(">=", time.Hour))
+		Expect(r.CircuitBreaker.Failures(req.NamespacedName)).To(Equal(3))
+
+		// Setting the reset annotation lets the next reconcile through
+		Expect(c.Get(ctx, req.NamespacedName, mcpserver)).To(Succeed())
+		mcpserver.Annotations = map[string]string{kaosv1alpha1.ResetCircuitAnnotation: "1"}
+		Expect(c.Update(ctx, mcpserver)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).To(MatchError(ContainSubstring("registry unavailable")))
+		Expect(c.Get(ctx, req.NamespacedName, mcpserver)).To(Succeed())
+		Expect(meta.FindStatusCondition(mcpserver.Status.Conditions, kaosv1alpha1.ConditionCircuitOpen)).To(BeNil())
+	})
+})
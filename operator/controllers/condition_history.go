@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+	"github.com/axsaucedo/kaos/operator/pkg/util"
+)
+
+// conditionHistoryOf returns the conditions and the condition history of an Agent, ModelAPI
+// or MCPServer
+func conditionHistoryOf(obj client.Object) ([]metav1.Condition, *[]kaosv1alpha1.ConditionTransition) {
+	switch o := obj.(type) {
+	case *kaosv1alpha1.Agent:
+		return o.Status.Conditions, &o.Status.ConditionHistory
+	case *kaosv1alpha1.ModelAPI:
+		return o.Status.Conditions, &o.Status.ConditionHistory
+	case *kaosv1alpha1.MCPServer:
+		return o.Status.Conditions, &o.Status.ConditionHistory
+	}
+	return nil, nil
+}
+
+// recordConditionHistory appends the condition transitions of the reconcile to the resource's
+// status.conditionHistory, keeping the last limit. It runs after the reconcile, whose status
+// updates set the conditions in many places, and only writes when a condition changed status.
+// A conflict with a newer status is left to the next reconcile, which that update triggers.
+func recordConditionHistory(ctx context.Context, c client.Client, obj client.Object, key types.NamespacedName, limit int) {
+	if err := c.Get(ctx, key, obj); err != nil {
+		return
+	}
+	original := obj.DeepCopyObject().(client.Object)
+	conditions, history := conditionHistoryOf(obj)
+	if history == nil {
+		return
+	}
+	updated, changed := util.RecordConditionTransitions(*history, conditions, limit)
+	if !changed {
+		return
+	}
+	*history = updated
+	if err := c.Status().Patch(ctx, obj, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{})); err != nil && !apierrors.IsConflict(err) {
+		log.FromContext(ctx).Error(err, "failed to record condition history")
+	}
+}
@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+var _ = Describe("status.conditionHistory", func() {
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cached", Namespace: "default"}}
+
+	It("should record the condition transitions of a reconcile once", func() {
+		r, c := newCachedMCPServerReconciler(nil)
+		r.ConditionHistoryLimit = 10
+		ctx := context.Background()
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		mcpserver := &kaosv1alpha1.MCPServer{}
+		Expect(c.Get(ctx, req.NamespacedName, mcpserver)).To(Succeed())
+		Expect(mcpserver.Status.ConditionHistory).NotTo(BeEmpty())
+		Expect(mcpserver.Status.ConditionHistory).To(ContainElement(And(
+			HaveField("Type", kaosv1alpha1.ConditionReady),
+			HaveField("Status", metav1.ConditionFalse),
+		)))
+		recorded := len(mcpserver.Status.ConditionHistory)
+
+		// A reconcile that changes no condition status adds nothing
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, req.NamespacedName, mcpserver)).To(Succeed())
+		Expect(mcpserver.Status.ConditionHistory).To(HaveLen(recorded))
+	})
+
+	It("should keep no history with a limit of 0", func() {
+		r, c := newCachedMCPServerReconciler(nil)
+		ctx := context.Background()
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		mcpserver := &kaosv1alpha1.MCPServer{}
+		Expect(c.Get(ctx, req.NamespacedName, mcpserver)).To(Succeed())
+		Expect(mcpserver.Status.Conditions).NotTo(BeEmpty())
+		Expect(mcpserver.Status.ConditionHistory).To(BeEmpty())
+	})
+})
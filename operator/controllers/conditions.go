@@ -0,0 +1,34 @@
+package controllers
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/clock"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+// setReadyCondition sets the Ready condition alongside status.phase. It is True only for
+// ReasonDeploymentReady; every other reason explains why the resource is not serving.
+func setReadyCondition(conditions *[]metav1.Condition, generation int64, reason, message string) {
+	status := metav1.ConditionFalse
+	if reason == kaosv1alpha1.ReasonDeploymentReady {
+		status = metav1.ConditionTrue
+	}
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               kaosv1alpha1.ConditionReady,
+		Status:             status,
+		ObservedGeneration: generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// reconcileTime returns the current time of the clock, or of the real clock when unset
+func reconcileTime(c clock.PassiveClock) *metav1.Time {
+	if c == nil {
+		c = clock.RealClock{}
+	}
+	now := metav1.NewTime(c.Now())
+	return &now
+}
@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clocktesting "k8s.io/utils/clock/testing"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+// unreachableToolDiscoverer fails every tool listing
+type unreachableToolDiscoverer struct{}
+
+func (unreachableToolDiscoverer) ListTools(context.Context, string) ([]kaosv1alpha1.DiscoveredTool, error) {
+	return nil, errors.New("connection refused")
+}
+
+// expectDefinedReasons asserts that every condition on the MCPServer uses a defined reason
+func expectDefinedReasons(c client.Client, key types.NamespacedName) *kaosv1alpha1.MCPServer {
+	mcpserver := &kaosv1alpha1.MCPServer{}
+	ExpectWithOffset(1, c.Get(context.Background(), key, mcpserver)).To(Succeed())
+	for _, cond := range mcpserver.Status.Conditions {
+		ExpectWithOffset(1, kaosv1alpha1.IsConditionReason(cond.Reason)).To(BeTrue(), "condition %s has undefined reason %q", cond.Type, cond.Reason)
+	}
+	return mcpserver
+}
+
+var _ = Describe("Condition reasons", func() {
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cached", Namespace: "default"}}
+
+	It("should only set conditions with the defined reason constants", func() {
+		r, c := newCachedMCPServerReconciler(nil)
+		r.ToolDiscoverer = unreachableToolDiscoverer{}
+		ctx := context.Background()
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		mcpserver := expectDefinedReasons(c, req.NamespacedName)
+		cond := meta.FindStatusCondition(mcpserver.Status.Conditions, kaosv1alpha1.ConditionReady)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+		Expect(cond.Reason).To(Equal(kaosv1alpha1.ReasonDeploymentNotReady))
+
+		deployment := &appsv1.Deployment{}
+		Expect(c.Get(ctx, types.NamespacedName{Name: "mcpserver-cached", Namespace: "default"}, deployment)).To(Succeed())
+		deployment.Status.Replicas = 1
+		deployment.Status.ReadyReplicas = 1
+		Expect(c.Status().Update(ctx, deployment)).To(Succeed())
+
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		mcpserver = expectDefinedReasons(c, req.NamespacedName)
+		Expect(meta.IsStatusConditionTrue(mcpserver.Status.Conditions, kaosv1alpha1.ConditionReady)).To(BeTrue())
+		Expect(meta.FindStatusCondition(mcpserver.Status.Conditions, kaosv1alpha1.ConditionReady).Reason).To(Equal(kaosv1alpha1.ReasonDeploymentReady))
+		cond = meta.FindStatusCondition(mcpserver.Status.Conditions, kaosv1alpha1.MCPServerConditionToolsDiscovered)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Reason).To(Equal(kaosv1alpha1.ReasonDiscoveryFailed))
+	})
+
+	It("should report a missing tools ConfigMap as DependencyNotReady", func() {
+		r, c := newCachedMCPServerReconciler(nil)
+		ctx := context.Background()
+
+		mcpserver := &kaosv1alpha1.MCPServer{}
+		Expect(c.Get(ctx, req.NamespacedName, mcpserver)).To(Succeed())
+		mcpserver.Spec.ToolsConfigMapRef = &corev1.LocalObjectReference{Name: "missing-tools"}
+		Expect(c.Update(ctx, mcpserver)).To(Succeed())
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		mcpserver = expectDefinedReasons(c, req.NamespacedName)
+		cond := meta.FindStatusCondition(mcpserver.Status.Conditions, kaosv1alpha1.ConditionReady)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+		Expect(cond.Reason).To(Equal(kaosv1alpha1.ReasonDependencyNotReady))
+	})
+
+	It("should advance status.lastReconcileTime on every full reconcile", func() {
+		r, c := newCachedMCPServerReconciler(nil)
+		start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+		fakeClock := clocktesting.NewFakePassiveClock(start)
+		r.Clock = fakeClock
+		ctx := context.Background()
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		mcpserver := &kaosv1alpha1.MCPServer{}
+		Expect(c.Get(ctx, req.NamespacedName, mcpserver)).To(Succeed())
+		Expect(mcpserver.Status.LastReconcileTime).NotTo(BeNil())
+		Expect(mcpserver.Status.LastReconcileTime.Time).To(BeTemporally("==", start))
+
+		fakeClock.SetTime(start.Add(time.Minute))
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, req.NamespacedName, mcpserver)).To(Succeed())
+		Expect(mcpserver.Status.LastReconcileTime.Time).To(BeTemporally("==", start.Add(time.Minute)))
+	})
+})
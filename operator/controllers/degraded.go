@@ -0,0 +1,231 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+	"github.com/axsaucedo/kaos/operator/pkg/util"
+)
+
+// RecoveredReason is the reason of the event recorded when a resource's Degraded condition clears
+const RecoveredReason = "Recovered"
+
+const (
+	// warningEventPollInterval is how often a Deployment with unavailable replicas and no
+	// Warning event explaining them is checked again, as events are not watched
+	warningEventPollInterval = 30 * time.Second
+
+	// eventInvolvedUIDField is the field selector that narrows events to one involved object
+	eventInvolvedUIDField = "involvedObject.uid"
+)
+
+// degradedCondition returns a copy of the Degraded condition, or nil when the resource is not
+// degraded, to compare against once the conditions were recomputed
+func degradedCondition(conditions []metav1.Condition) *metav1.Condition {
+	cond := meta.FindStatusCondition(conditions, kaosv1alpha1.ConditionDegraded)
+	if cond == nil || cond.Status != metav1.ConditionTrue {
+		return nil
+	}
+	copied := *cond
+	return &copied
+}
+
+// recordDegradedTransition bridges the Degraded condition to events for alerting systems that
+// watch them: a Warning event with the condition's reason and message when the resource becomes
+// degraded or the reason changes, and a Normal Recovered event once it clears. previous is the
+// degradedCondition from before the conditions were recomputed.
+func recordDegradedTransition(recorder record.EventRecorder, obj runtime.Object, previous *metav1.Condition, conditions []metav1.Condition) {
+	if recorder == nil {
+		return
+	}
+	current := degradedCondition(conditions)
+	switch {
+	case current != nil && (previous == nil || previous.Reason != current.Reason):
+		recorder.Event(obj, corev1.EventTypeWarning, current.Reason, current.Message)
+	case current == nil && previous != nil:
+		recorder.Eventf(obj, corev1.EventTypeNormal, RecoveredReason, "No longer degraded: %s resolved", previous.Reason)
+	}
+}
+
+// setDegradedCondition sets the Degraded condition with reason QuotaExceeded while a
+// ResourceQuota or LimitRange keeps the Deployment from creating its pods, with reason
+// RolloutStuck while the Deployment reports ProgressDeadlineExceeded, or with reason
+// WarningEvent while it has unavailable replicas and a Warning event explains why, and
+// removes it once none applies. ReplicaSets are only inspected while the Deployment is short
+// of replicas or has unavailable ones, since its own ReplicaFailure condition can lag behind.
+// It returns warningEventPollInterval while replicas are unavailable and no Warning event
+// explains why yet, since a later event does not trigger a reconcile, and 0 otherwise.
+func setDegradedCondition(ctx context.Context, c client.Client, deployment *appsv1.Deployment, conditions *[]metav1.Condition, generation int64, log logr.Logger) time.Duration {
+	var replicaSets []appsv1.ReplicaSet
+	shortOfReplicas := deployment.Spec.Replicas != nil && deployment.Status.Replicas < *deployment.Spec.Replicas
+	if (shortOfReplicas || deployment.Status.UnavailableReplicas > 0) && deployment.Spec.Selector != nil {
+		list := &appsv1.ReplicaSetList{}
+		if err := c.List(ctx, list, client.InNamespace(deployment.Namespace), client.MatchingLabels(deployment.Spec.Selector.MatchLabels)); err != nil {
+			log.Error(err, "failed to list ReplicaSets")
+		}
+		for _, rs := range list.Items {
+			if metav1.IsControlledBy(&rs, deployment) {
+				replicaSets = append(replicaSets, rs)
+			}
+		}
+	}
+
+	reason, message := kaosv1alpha1.ReasonQuotaExceeded, util.QuotaRejection(deployment, replicaSets)
+	if message == "" {
+		reason, message = kaosv1alpha1.ReasonRolloutStuck, rolloutStuck(deployment)
+	}
+	if message == "" && deployment.Status.UnavailableReplicas > 0 {
+		reason, message = kaosv1alpha1.ReasonWarningEvent, latestWarning(ctx, c, deployment, replicaSets, log)
+	}
+	if message == "" {
+		meta.RemoveStatusCondition(conditions, kaosv1alpha1.ConditionDegraded)
+		if deployment.Status.UnavailableReplicas > 0 {
+			return warningEventPollInterval
+		}
+		return 0
+	}
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               kaosv1alpha1.ConditionDegraded,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             reason,
+		Message:            message,
+	})
+	return 0
+}
+
+// latestWarning describes the latest Warning event of the Deployment, its ReplicaSets or
+// their pods, such as FailedScheduling on a pod, so the root cause of unavailable replicas
+// shows on the resource. Returns "" if there is none. Events are not cached by the manager,
+// so they are read from the API server, one involved object at a time, and only while
+// replicas are unavailable.
+func latestWarning(ctx context.Context, c client.Client, deployment *appsv1.Deployment, replicaSets []appsv1.ReplicaSet, log logr.Logger) string {
+	uids := map[types.UID]bool{deployment.UID: true}
+	for _, rs := range replicaSets {
+		uids[rs.UID] = true
+	}
+	if len(replicaSets) > 0 {
+		pods := &corev1.PodList{}
+		if err := c.List(ctx, pods, client.InNamespace(deployment.Namespace), client.MatchingLabels(deployment.Spec.Selector.MatchLabels)); err != nil {
+			log.Error(err, "failed to list pods")
+		}
+		for _, pod := range pods.Items {
+			for _, rs := range replicaSets {
+				if metav1.IsControlledBy(&pod, &rs) {
+					uids[pod.UID] = true
+				}
+			}
+		}
+	}
+
+	var events []corev1.Event
+	for uid := range uids {
+		list := &corev1.EventList{}
+		if err := c.List(ctx, list, client.InNamespace(deployment.Namespace),
+			client.MatchingFields{eventInvolvedUIDField: string(uid)}); err != nil {
+			log.Error(err, "failed to list events")
+			return ""
+		}
+		events = append(events, list.Items...)
+	}
+	event := util.LatestWarningEvent(events, uids)
+	if event == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s on %s %s: %s", event.Reason, event.InvolvedObject.Kind, event.InvolvedObject.Name, event.Message)
+}
+
+// rolloutStuck returns the message of the Deployment's Progressing condition if its progress
+// deadline was exceeded, or "" while the rollout is progressing or complete
+func rolloutStuck(deployment *appsv1.Deployment) string {
+	for _, cond := range deployment.Status.Conditions {
+		if cond.Type == appsv1.DeploymentProgressing && cond.Status == corev1.ConditionFalse && cond.Reason == "ProgressDeadlineExceeded" {
+			return cond.Message
+		}
+	}
+	return ""
+}
+
+// replicaSetFailures fingerprints the ReplicaFailure conditions of the ReplicaSets of the named
+// Deployment, which selects its pods by the given labels, so a quota rejection that has not yet
+// reached the Deployment's own conditions is not skipped by the reconcile cache
+func replicaSetFailures(ctx context.Context, c client.Reader, namespace, deploymentName string, selector map[string]string) metav1.Object {
+	list := &appsv1.ReplicaSetList{}
+	if err := c.List(ctx, list, client.InNamespace(namespace), client.MatchingLabels(selector)); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "failed to list ReplicaSets")
+	}
+	var failures []string
+	for _, rs := range list.Items {
+		owner := metav1.GetControllerOf(&rs)
+		if owner == nil || owner.Kind != "Deployment" || owner.Name != deploymentName {
+			continue
+		}
+		for _, cond := range rs.Status.Conditions {
+			if cond.Type == appsv1.ReplicaSetReplicaFailure && cond.Status == corev1.ConditionTrue {
+				failures = append(failures, rs.Name+"="+cond.Message)
+			}
+		}
+	}
+	return &metav1.ObjectMeta{UID: "replicaset-failures", ResourceVersion: strings.Join(failures, ",")}
+}
+
+// replicaSetToOwner maps a ReplicaSet to the resource of the given kind that owns its
+// Deployment. ReplicaSets are owned by the Deployment rather than the resource, so Owns cannot
+// watch them.
+func replicaSetToOwner(c client.Reader, kind string) handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []ctrl.Request {
+		ref := metav1.GetControllerOf(obj)
+		if ref == nil || ref.Kind != "Deployment" {
+			return nil
+		}
+		deployment := &appsv1.Deployment{}
+		if err := c.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: obj.GetNamespace()}, deployment); err != nil {
+			return nil
+		}
+		owner := metav1.GetControllerOf(deployment)
+		if owner == nil || owner.Kind != kind || owner.APIVersion != kaosv1alpha1.GroupVersion.String() {
+			return nil
+		}
+		return []ctrl.Request{{NamespacedName: types.NamespacedName{Name: owner.Name, Namespace: obj.GetNamespace()}}}
+	})
+}
+
+// replicaFailureChanged passes ReplicaSet updates that change its ReplicaFailure condition,
+// but not the frequent replica count updates that the Deployment already reflects
+var replicaFailureChanged = predicate.Funcs{
+	CreateFunc: func(event.CreateEvent) bool { return false },
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldRS, okOld := e.ObjectOld.(*appsv1.ReplicaSet)
+		newRS, okNew := e.ObjectNew.(*appsv1.ReplicaSet)
+		return okOld && okNew && replicaFailure(oldRS) != replicaFailure(newRS)
+	},
+	DeleteFunc:  func(event.DeleteEvent) bool { return false },
+	GenericFunc: func(event.GenericEvent) bool { return false },
+}
+
+// replicaFailure returns the message of the ReplicaSet's ReplicaFailure condition, or ""
+func replicaFailure(rs *appsv1.ReplicaSet) string {
+	for _, cond := range rs.Status.Conditions {
+		if cond.Type == appsv1.ReplicaSetReplicaFailure && cond.Status == corev1.ConditionTrue {
+			return cond.Message
+		}
+	}
+	return ""
+}
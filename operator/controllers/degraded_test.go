@@ -0,0 +1,272 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllertest"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+	"github.com/axsaucedo/kaos/operator/pkg/util"
+)
+
+var _ = Describe("Degraded condition", func() {
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cached", Namespace: "default"}}
+
+	It("should surface a ReplicaSet quota rejection as Degraded/QuotaExceeded and clear it on recovery", func() {
+		// The reconcile cache must not skip a rejection that only shows on the ReplicaSet
+		r, c := newCachedMCPServerReconciler(util.NewReconcileCache())
+		recorder := record.NewFakeRecorder(10)
+		r.Recorder = recorder
+		ctx := context.Background()
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		deployment := &appsv1.Deployment{}
+		Expect(c.Get(ctx, types.NamespacedName{Name: "mcpserver-cached", Namespace: "default"}, deployment)).To(Succeed())
+
+		// Simulate the ReplicaSet controller failing to create pods against a ResourceQuota
+		message := `pods "mcpserver-cached-abc" is forbidden: exceeded quota: compute, requested: limits.cpu=1, used: limits.cpu=4, limited: limits.cpu=4`
+		rs := &appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "mcpserver-cached-abc",
+				Namespace: "default",
+				Labels:    deployment.Spec.Selector.MatchLabels,
+			},
+			Spec: appsv1.ReplicaSetSpec{
+				Selector: deployment.Spec.Selector,
+				Template: deployment.Spec.Template,
+			},
+			Status: appsv1.ReplicaSetStatus{
+				Conditions: []appsv1.ReplicaSetCondition{{
+					Type:    appsv1.ReplicaSetReplicaFailure,
+					Status:  corev1.ConditionTrue,
+					Reason:  "FailedCreate",
+					Message: message,
+				}},
+			},
+		}
+		Expect(ctrl.SetControllerReference(deployment, rs, r.Scheme)).To(Succeed())
+		Expect(c.Create(ctx, rs)).To(Succeed())
+
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		mcpserver := &kaosv1alpha1.MCPServer{}
+		Expect(c.Get(ctx, req.NamespacedName, mcpserver)).To(Succeed())
+		cond := meta.FindStatusCondition(mcpserver.Status.Conditions, kaosv1alpha1.ConditionDegraded)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.Reason).To(Equal(kaosv1alpha1.ReasonQuotaExceeded))
+		Expect(cond.Message).To(Equal(message))
+		Expect(recorder.Events).To(Receive(Equal("Warning QuotaExceeded " + message)))
+
+		// Once pods can be created again the condition is removed
+		rs.Status.Conditions = nil
+		Expect(c.Status().Update(ctx, rs)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, req.NamespacedName, mcpserver)).To(Succeed())
+		Expect(meta.FindStatusCondition(mcpserver.Status.Conditions, kaosv1alpha1.ConditionDegraded)).To(BeNil())
+		Expect(recorder.Events).To(Receive(Equal("Normal Recovered No longer degraded: QuotaExceeded resolved")))
+	})
+
+	It("should surface the latest Warning event of the pods as Degraded/WarningEvent", func() {
+		r, c := newCachedMCPServerReconciler(nil)
+		recorder := record.NewFakeRecorder(10)
+		r.Recorder = recorder
+		ctx := context.Background()
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		deployment := &appsv1.Deployment{}
+		Expect(c.Get(ctx, types.NamespacedName{Name: "mcpserver-cached", Namespace: "default"}, deployment)).To(Succeed())
+
+		// Simulate a pod that cannot be scheduled, and an older warning about another pod
+		rs := &appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "mcpserver-cached-abc", Namespace: "default", UID: "rs-uid", Labels: deployment.Spec.Selector.MatchLabels},
+			Spec:       appsv1.ReplicaSetSpec{Selector: deployment.Spec.Selector, Template: deployment.Spec.Template},
+		}
+		Expect(ctrl.SetControllerReference(deployment, rs, r.Scheme)).To(Succeed())
+		Expect(c.Create(ctx, rs)).To(Succeed())
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "mcpserver-cached-abc-x7k2p", Namespace: "default", UID: "pod-uid", Labels: deployment.Spec.Selector.MatchLabels}}
+		Expect(ctrl.SetControllerReference(rs, pod, r.Scheme)).To(Succeed())
+		Expect(c.Create(ctx, pod)).To(Succeed())
+		deployment.Status.Replicas = 1
+		deployment.Status.UnavailableReplicas = 1
+		Expect(c.Status().Update(ctx, deployment)).To(Succeed())
+
+		message := "0/3 nodes are available: 3 Insufficient cpu. preemption: 0/3 nodes are available: 3 No preemption victims found for incoming pod."
+		now := time.Now()
+		for _, event := range []*corev1.Event{
+			{
+				ObjectMeta:     metav1.ObjectMeta{Name: "mcpserver-cached-abc-x7k2p.1", Namespace: "default"},
+				InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: pod.Name, UID: pod.UID},
+				Type:           corev1.EventTypeWarning,
+				Reason:         "FailedScheduling",
+				Message:        message,
+				LastTimestamp:  metav1.NewTime(now),
+			},
+			{
+				ObjectMeta:     metav1.ObjectMeta{Name: "other.1", Namespace: "default"},
+				InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "other", UID: "other-uid"},
+				Type:           corev1.EventTypeWarning,
+				Reason:         "BackOff",
+				Message:        "Back-off restarting failed container",
+				LastTimestamp:  metav1.NewTime(now.Add(time.Minute)),
+			},
+		} {
+			Expect(c.Create(ctx, event)).To(Succeed())
+		}
+
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		mcpserver := &kaosv1alpha1.MCPServer{}
+		Expect(c.Get(ctx, req.NamespacedName, mcpserver)).To(Succeed())
+		cond := meta.FindStatusCondition(mcpserver.Status.Conditions, kaosv1alpha1.ConditionDegraded)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.Reason).To(Equal(kaosv1alpha1.ReasonWarningEvent))
+		Expect(cond.Message).To(Equal("FailedScheduling on Pod mcpserver-cached-abc-x7k2p: " + message))
+		Expect(recorder.Events).To(Receive(ContainSubstring("Warning WarningEvent FailedScheduling")))
+
+		// Once the replicas are available the warning no longer applies
+		Expect(c.Get(ctx, types.NamespacedName{Name: "mcpserver-cached", Namespace: "default"}, deployment)).To(Succeed())
+		deployment.Status.UnavailableReplicas = 0
+		Expect(c.Status().Update(ctx, deployment)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, req.NamespacedName, mcpserver)).To(Succeed())
+		Expect(meta.FindStatusCondition(mcpserver.Status.Conditions, kaosv1alpha1.ConditionDegraded)).To(BeNil())
+	})
+
+	It("should poll for a Warning event while replicas are unavailable without one", func() {
+		r, c := newCachedMCPServerReconciler(util.NewReconcileCache())
+		ctx := context.Background()
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		deployment := &appsv1.Deployment{}
+		Expect(c.Get(ctx, types.NamespacedName{Name: "mcpserver-cached", Namespace: "default"}, deployment)).To(Succeed())
+		deployment.UID = "deployment-uid"
+		deployment.Status.Replicas = 1
+		deployment.Status.UnavailableReplicas = 1
+		Expect(c.Status().Update(ctx, deployment)).To(Succeed())
+
+		result, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(warningEventPollInterval))
+
+		// The event alone does not trigger a reconcile, so the poll must not hit the cache
+		Expect(c.Create(ctx, &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "mcpserver-cached.1", Namespace: "default"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Deployment", Name: deployment.Name, UID: deployment.UID},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "FailedCreate",
+			Message:        "admission webhook denied the request",
+		})).To(Succeed())
+		result, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeZero())
+		mcpserver := &kaosv1alpha1.MCPServer{}
+		Expect(c.Get(ctx, req.NamespacedName, mcpserver)).To(Succeed())
+		cond := meta.FindStatusCondition(mcpserver.Status.Conditions, kaosv1alpha1.ConditionDegraded)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Reason).To(Equal(kaosv1alpha1.ReasonWarningEvent))
+	})
+
+	It("should record an event only when the Degraded condition changes", func() {
+		recorder := record.NewFakeRecorder(10)
+		obj := &kaosv1alpha1.MCPServer{}
+		stuck := []metav1.Condition{{Type: kaosv1alpha1.ConditionDegraded, Status: metav1.ConditionTrue, Reason: kaosv1alpha1.ReasonRolloutStuck, Message: "timed out"}}
+
+		// Staying degraded for the same reason records nothing new
+		recordDegradedTransition(recorder, obj, degradedCondition(stuck), stuck)
+		Expect(recorder.Events).NotTo(Receive())
+
+		// A new reason is a new alert
+		quota := []metav1.Condition{{Type: kaosv1alpha1.ConditionDegraded, Status: metav1.ConditionTrue, Reason: kaosv1alpha1.ReasonQuotaExceeded, Message: "exceeded quota"}}
+		recordDegradedTransition(recorder, obj, degradedCondition(stuck), quota)
+		Expect(recorder.Events).To(Receive(Equal("Warning QuotaExceeded exceeded quota")))
+
+		recordDegradedTransition(recorder, obj, nil, nil)
+		Expect(recorder.Events).NotTo(Receive())
+	})
+
+	It("should surface an exceeded progress deadline as Degraded/RolloutStuck", func() {
+		deadline := int32(300)
+		modelapi := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{Name: "stuck", Namespace: "default", Generation: 2},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode:        kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig: &kaosv1alpha1.ProxyConfig{Models: []string{"openai/gpt-4"}},
+			},
+		}
+		r := &ModelAPIReconciler{}
+		Expect(*r.constructDeployment(modelapi).Spec.ProgressDeadlineSeconds).To(Equal(int32(600)))
+		modelapi.Spec.ProgressDeadlineSeconds = &deadline
+		deployment := r.constructDeployment(modelapi)
+		Expect(*deployment.Spec.ProgressDeadlineSeconds).To(Equal(deadline))
+
+		// The Deployment controller gives up on a rollout whose new pods never become available
+		message := `ReplicaSet "modelapi-stuck-7d4b9" has timed out progressing.`
+		deployment.Status.Replicas = *deployment.Spec.Replicas
+		deployment.Status.Conditions = []appsv1.DeploymentCondition{{
+			Type:    appsv1.DeploymentProgressing,
+			Status:  corev1.ConditionFalse,
+			Reason:  "ProgressDeadlineExceeded",
+			Message: message,
+		}}
+		ctx := context.Background()
+		setDegradedCondition(ctx, nil, deployment, &modelapi.Status.Conditions, modelapi.Generation, ctrl.Log)
+		cond := meta.FindStatusCondition(modelapi.Status.Conditions, kaosv1alpha1.ConditionDegraded)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.Reason).To(Equal(kaosv1alpha1.ReasonRolloutStuck))
+		Expect(cond.Message).To(Equal(message))
+		Expect(cond.ObservedGeneration).To(Equal(int64(2)))
+
+		// A later rollout that makes progress clears the condition
+		deployment.Status.Conditions[0].Status = corev1.ConditionTrue
+		deployment.Status.Conditions[0].Reason = "NewReplicaSetAvailable"
+		setDegradedCondition(ctx, nil, deployment, &modelapi.Status.Conditions, modelapi.Generation, ctrl.Log)
+		Expect(meta.FindStatusCondition(modelapi.Status.Conditions, kaosv1alpha1.ConditionDegraded)).To(BeNil())
+	})
+
+	It("should map a ReplicaSet to the resource owning its Deployment when ReplicaFailure changes", func() {
+		r, c := newCachedMCPServerReconciler(nil)
+		ctx := context.Background()
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		deployment := &appsv1.Deployment{}
+		Expect(c.Get(ctx, types.NamespacedName{Name: "mcpserver-cached", Namespace: "default"}, deployment)).To(Succeed())
+
+		rs := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "mcpserver-cached-abc", Namespace: "default"}}
+		Expect(ctrl.SetControllerReference(deployment, rs, r.Scheme)).To(Succeed())
+		queue := &controllertest.Queue{TypedInterface: workqueue.NewTyped[reconcile.Request]()}
+		replicaSetToOwner(c, "MCPServer").Create(ctx, event.CreateEvent{Object: rs}, queue)
+		Expect(queue.Len()).To(Equal(1))
+		item, _ := queue.Get()
+		Expect(item).To(Equal(req))
+		replicaSetToOwner(c, "Agent").Create(ctx, event.CreateEvent{Object: rs}, queue)
+		Expect(queue.Len()).To(Equal(0))
+
+		failed := rs.DeepCopy()
+		failed.Status.Conditions = []appsv1.ReplicaSetCondition{{Type: appsv1.ReplicaSetReplicaFailure, Status: corev1.ConditionTrue, Message: "exceeded quota"}}
+		Expect(replicaFailureChanged.Update(event.UpdateEvent{ObjectOld: rs, ObjectNew: failed})).To(BeTrue())
+		scaled := rs.DeepCopy()
+		scaled.Status.Replicas = 2
+		Expect(replicaFailureChanged.Update(event.UpdateEvent{ObjectOld: rs, ObjectNew: scaled})).To(BeFalse())
+	})
+})
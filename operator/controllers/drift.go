@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/axsaucedo/kaos/operator/pkg/util"
+)
+
+// DriftCorrectedReason is the reason of the event recorded when an owned Deployment that was
+// edited outside the operator is restored to its desired state
+const DriftCorrectedReason = "DriftCorrected"
+
+// defaultRevisionHistoryLimit is the number of old ReplicaSets kept per Deployment when
+// spec.revisionHistoryLimit is unset, lower than the Kubernetes default of 10
+const defaultRevisionHistoryLimit int32 = 3
+
+// revisionHistoryLimit returns the configured revision history limit or the default
+func revisionHistoryLimit(limit *int32) *int32 {
+	if limit == nil {
+		value := defaultRevisionHistoryLimit
+		return &value
+	}
+	value := *limit
+	return &value
+}
+
+// revisionHistoryLimitChanged reports whether a live workload's revision history limit
+// differs from the desired one
+func revisionHistoryLimitChanged(live, desired *int32) bool {
+	return live == nil || *live != *desired
+}
+
+// defaultProgressDeadlineSeconds is how long a Deployment rollout may make no progress when
+// spec.progressDeadlineSeconds is unset, the same as the Kubernetes default
+const defaultProgressDeadlineSeconds int32 = 600
+
+// progressDeadlineSeconds returns the configured progress deadline or the default
+func progressDeadlineSeconds(deadline *int32) *int32 {
+	value := defaultProgressDeadlineSeconds
+	if deadline != nil {
+		value = *deadline
+	}
+	return &value
+}
+
+// progressDeadlineChanged reports whether a live Deployment's progress deadline differs from
+// the desired one
+func progressDeadlineChanged(live, desired *int32) bool {
+	return live == nil || *live != *desired
+}
+
+// replicasOutOfSync reports whether the live Deployment's replicas differ from the desired
+// ones and should be reset. A HorizontalPodAutoscaler scaling the Deployment directly owns
+// its replicas, so they are then left alone.
+func replicasOutOfSync(ctx context.Context, c client.Reader, live, desired *appsv1.Deployment) bool {
+	if live.Spec.Replicas != nil && *live.Spec.Replicas == *desired.Spec.Replicas {
+		return false
+	}
+	return !scaledByHPA(ctx, c, live)
+}
+
+// scaledByHPA reports whether a HorizontalPodAutoscaler targets the Deployment directly
+func scaledByHPA(ctx context.Context, c client.Reader, deployment *appsv1.Deployment) bool {
+	hpas := &autoscalingv2.HorizontalPodAutoscalerList{}
+	if err := c.List(ctx, hpas, client.InNamespace(deployment.Namespace)); err != nil {
+		return false
+	}
+	for _, hpa := range hpas.Items {
+		ref := hpa.Spec.ScaleTargetRef
+		if ref.Kind == "Deployment" && ref.Name == deployment.Name && strings.HasPrefix(ref.APIVersion, "apps/") {
+			return true
+		}
+	}
+	return false
+}
+
+// deploymentDrift lists what was changed on the live Deployment outside the operator: pod
+// template edits while the pod-spec hash still matches the desired one, and replicas scaled
+// away from the count the operator last applied
+func deploymentDrift(live, desired *appsv1.Deployment, replicasChanged bool) []string {
+	var drift []string
+	if live.Spec.Template.Annotations[util.PodSpecHashAnnotation] == desired.Spec.Template.Annotations[util.PodSpecHashAnnotation] {
+		drift = util.TemplateDrift(&desired.Spec.Template, &live.Spec.Template)
+	}
+	if replicasChanged && util.ReplicasDrifted(live, desired) {
+		drift = append(drift, "replicas")
+	}
+	return drift
+}
+
+// recordDriftCorrected records a DriftCorrected event on the owner listing the restored fields
+func recordDriftCorrected(recorder record.EventRecorder, owner runtime.Object, deployment *appsv1.Deployment, drift []string) {
+	if recorder == nil || len(drift) == 0 {
+		return
+	}
+	recorder.Eventf(owner, corev1.EventTypeWarning, DriftCorrectedReason,
+		"Restored %s of Deployment %s edited outside the operator", strings.Join(drift, ", "), deployment.Name)
+}
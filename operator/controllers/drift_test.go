@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var _ = Describe("Drift correction", func() {
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cached", Namespace: "default"}}
+	deploymentKey := types.NamespacedName{Name: "mcpserver-cached", Namespace: "default"}
+
+	It("should revert a manually edited image and record DriftCorrected", func() {
+		r, c := newCachedMCPServerReconciler(nil)
+		recorder := record.NewFakeRecorder(10)
+		r.Recorder = recorder
+		ctx := context.Background()
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		original := deployedImage(c)
+
+		deployment := &appsv1.Deployment{}
+		Expect(c.Get(ctx, deploymentKey, deployment)).To(Succeed())
+		deployment.Spec.Template.Spec.Containers[0].Image = "example.com/hotfix:1"
+		Expect(c.Update(ctx, deployment)).To(Succeed())
+
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deployedImage(c)).To(Equal(original))
+		Expect(recorder.Events).To(Receive(And(
+			ContainSubstring(DriftCorrectedReason),
+			ContainSubstring("containers[mcp-server].image"),
+		)))
+	})
+
+	It("should reset manually scaled replicas unless an HPA scales the Deployment", func() {
+		r, c := newCachedMCPServerReconciler(nil)
+		recorder := record.NewFakeRecorder(10)
+		r.Recorder = recorder
+		ctx := context.Background()
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		scale := func(replicas int32) {
+			deployment := &appsv1.Deployment{}
+			Expect(c.Get(ctx, deploymentKey, deployment)).To(Succeed())
+			deployment.Spec.Replicas = &replicas
+			Expect(c.Update(ctx, deployment)).To(Succeed())
+		}
+		replicas := func() int32 {
+			deployment := &appsv1.Deployment{}
+			Expect(c.Get(ctx, deploymentKey, deployment)).To(Succeed())
+			return *deployment.Spec.Replicas
+		}
+
+		scale(3)
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(replicas()).To(Equal(int32(1)))
+		Expect(recorder.Events).To(Receive(ContainSubstring("Restored replicas")))
+
+		// Replicas owned by an HPA are left alone
+		hpa := &autoscalingv2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "mcp", Namespace: "default"},
+			Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "mcpserver-cached"},
+				MaxReplicas:    5,
+			},
+		}
+		Expect(c.Create(ctx, hpa)).To(Succeed())
+		scale(4)
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(replicas()).To(Equal(int32(4)))
+		Expect(recorder.Events).NotTo(Receive())
+	})
+})
@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+	"github.com/axsaucedo/kaos/operator/pkg/util"
+)
+
+var _ = Describe("Extra finalizers", func() {
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cached", Namespace: "default"}}
+
+	It("should register annotated finalizers and wait for them before completing deletion", func() {
+		r, c := newCachedMCPServerReconciler(nil)
+		ctx := context.Background()
+
+		mcpserver := &kaosv1alpha1.MCPServer{}
+		Expect(c.Get(ctx, req.NamespacedName, mcpserver)).To(Succeed())
+		mcpserver.Annotations = map[string]string{util.ExtraFinalizersAnnotation: "backup.example.com/snapshot"}
+		Expect(c.Update(ctx, mcpserver)).To(Succeed())
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, req.NamespacedName, mcpserver)).To(Succeed())
+		Expect(mcpserver.Finalizers).To(ConsistOf(mcpServerFinalizerName, "backup.example.com/snapshot"))
+
+		// The operator keeps its finalizer while the external one is present
+		Expect(c.Delete(ctx, mcpserver)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, req.NamespacedName, mcpserver)).To(Succeed())
+		Expect(mcpserver.DeletionTimestamp).NotTo(BeNil())
+		Expect(mcpserver.Finalizers).To(ContainElement(mcpServerFinalizerName))
+
+		// Once the external controller is done, deletion completes
+		controllerutil.RemoveFinalizer(mcpserver, "backup.example.com/snapshot")
+		Expect(c.Update(ctx, mcpserver)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(apierrors.IsNotFound(c.Get(ctx, req.NamespacedName, mcpserver))).To(BeTrue())
+	})
+})
@@ -9,11 +9,13 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
 )
@@ -542,4 +544,611 @@ var _ = Describe("Agent Controller", func() {
 		}
 		Expect(foundModelName).To(Equal("openai/gpt-4-turbo"))
 	})
+
+	It("should set runtime env vars from spec.runtime", func() {
+		modelAPIName := uniqueAgentName("runtime-modelapi")
+		agentName := uniqueAgentName("runtime-agent")
+
+		modelAPI := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      modelAPIName,
+				Namespace: namespace,
+			},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode: kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig: &kaosv1alpha1.ProxyConfig{
+					Models: []string{"mock-model"},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, modelAPI)).To(Succeed())
+		defer func() {
+			k8sClient.Delete(ctx, modelAPI)
+		}()
+
+		maxConcurrency := int32(4)
+		agent := &kaosv1alpha1.Agent{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      agentName,
+				Namespace: namespace,
+			},
+			Spec: kaosv1alpha1.AgentSpec{
+				ModelAPI:            modelAPIName,
+				Model:               "mock-model",
+				WaitForDependencies: boolPtr(false),
+				Runtime: &kaosv1alpha1.AgentRuntimeConfig{
+					MaxConcurrency: &maxConcurrency,
+					RequestTimeout: "90s",
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, agent)).To(Succeed())
+		defer func() {
+			k8sClient.Delete(ctx, agent)
+		}()
+
+		deployment := &appsv1.Deployment{}
+		Eventually(func() error {
+			return k8sClient.Get(ctx, types.NamespacedName{
+				Name:      fmt.Sprintf("agent-%s", agentName),
+				Namespace: namespace,
+			}, deployment)
+		}, timeout, interval).Should(Succeed())
+
+		envMap := make(map[string]string)
+		for _, env := range deployment.Spec.Template.Spec.Containers[0].Env {
+			envMap[env.Name] = env.Value
+		}
+		Expect(envMap["AGENT_MAX_CONCURRENCY"]).To(Equal("4"))
+		Expect(envMap["AGENT_REQUEST_TIMEOUT"]).To(Equal("90s"))
+	})
+
+	It("should pass spec.hostAliases through to the agent pod spec", func() {
+		modelAPIName := uniqueAgentName("hostalias-modelapi")
+		agentName := uniqueAgentName("hostalias-agent")
+
+		modelAPI := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      modelAPIName,
+				Namespace: namespace,
+			},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode: kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig: &kaosv1alpha1.ProxyConfig{
+					Models: []string{"mock-model"},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, modelAPI)).To(Succeed())
+		defer func() {
+			k8sClient.Delete(ctx, modelAPI)
+		}()
+
+		agent := &kaosv1alpha1.Agent{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      agentName,
+				Namespace: namespace,
+			},
+			Spec: kaosv1alpha1.AgentSpec{
+				ModelAPI:            modelAPIName,
+				Model:               "mock-model",
+				WaitForDependencies: boolPtr(false),
+				HostAliases: []corev1.HostAlias{
+					{IP: "10.20.30.40", Hostnames: []string{"crm.example.internal"}},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, agent)).To(Succeed())
+		defer func() {
+			k8sClient.Delete(ctx, agent)
+		}()
+
+		deployment := &appsv1.Deployment{}
+		Eventually(func() error {
+			return k8sClient.Get(ctx, types.NamespacedName{
+				Name:      fmt.Sprintf("agent-%s", agentName),
+				Namespace: namespace,
+			}, deployment)
+		}, timeout, interval).Should(Succeed())
+
+		Expect(deployment.Spec.Template.Spec.HostAliases).To(Equal([]corev1.HostAlias{
+			{IP: "10.20.30.40", Hostnames: []string{"crm.example.internal"}},
+		}))
+	})
+
+	It("should add an ephemeral debug container to running pods when annotated", func() {
+		modelAPIName := uniqueAgentName("debug-modelapi")
+		agentName := uniqueAgentName("debug-agent")
+
+		modelAPI := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      modelAPIName,
+				Namespace: namespace,
+			},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode: kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig: &kaosv1alpha1.ProxyConfig{
+					Models: []string{"mock-model"},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, modelAPI)).To(Succeed())
+		defer func() {
+			k8sClient.Delete(ctx, modelAPI)
+		}()
+
+		agent := &kaosv1alpha1.Agent{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      agentName,
+				Namespace: namespace,
+			},
+			Spec: kaosv1alpha1.AgentSpec{
+				ModelAPI:            modelAPIName,
+				Model:               "mock-model",
+				WaitForDependencies: boolPtr(false),
+			},
+		}
+		Expect(k8sClient.Create(ctx, agent)).To(Succeed())
+		defer func() {
+			k8sClient.Delete(ctx, agent)
+		}()
+
+		Eventually(func() error {
+			return k8sClient.Get(ctx, types.NamespacedName{
+				Name:      fmt.Sprintf("agent-%s", agentName),
+				Namespace: namespace,
+			}, &appsv1.Deployment{})
+		}, timeout, interval).Should(Succeed())
+
+		// envtest runs no Deployment controller, so create a running agent pod manually
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("agent-%s-pod", agentName),
+				Namespace: namespace,
+				Labels:    map[string]string{"app": "agent", "agent": agentName},
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "agent", Image: "axsauze/kaos-agent:latest"}},
+			},
+		}
+		Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+		defer func() {
+			k8sClient.Delete(ctx, pod)
+		}()
+		pod.Status.Phase = corev1.PodRunning
+		Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+
+		Eventually(func() error {
+			current := &kaosv1alpha1.Agent{}
+			if err := k8sClient.Get(ctx, types.NamespacedName{Name: agentName, Namespace: namespace}, current); err != nil {
+				return err
+			}
+			current.Annotations = map[string]string{kaosv1alpha1.DebugImageAnnotation: "busybox:1.36"}
+			return k8sClient.Update(ctx, current)
+		}, timeout, interval).Should(Succeed())
+
+		Eventually(func() []string {
+			current := &corev1.Pod{}
+			if err := k8sClient.Get(ctx, types.NamespacedName{Name: pod.Name, Namespace: namespace}, current); err != nil {
+				return nil
+			}
+			var images []string
+			for _, ec := range current.Spec.EphemeralContainers {
+				images = append(images, ec.Image)
+			}
+			return images
+		}, timeout, interval).Should(Equal([]string{"busybox:1.36"}))
+	})
+
+	It("should set LOG_LEVEL from spec.logLevel over config.env", func() {
+		modelAPIName := uniqueAgentName("loglevel-modelapi")
+		agentName := uniqueAgentName("loglevel-agent")
+
+		modelAPI := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      modelAPIName,
+				Namespace: namespace,
+			},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode: kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig: &kaosv1alpha1.ProxyConfig{
+					Models: []string{"mock-model"},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, modelAPI)).To(Succeed())
+		defer func() {
+			k8sClient.Delete(ctx, modelAPI)
+		}()
+
+		agent := &kaosv1alpha1.Agent{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      agentName,
+				Namespace: namespace,
+			},
+			Spec: kaosv1alpha1.AgentSpec{
+				ModelAPI:            modelAPIName,
+				Model:               "mock-model",
+				WaitForDependencies: boolPtr(false),
+				Config: &kaosv1alpha1.AgentConfig{
+					Env: []corev1.EnvVar{{Name: "LOG_LEVEL", Value: "INFO"}},
+				},
+				LogLevel: "debug",
+			},
+		}
+		Expect(k8sClient.Create(ctx, agent)).To(Succeed())
+		defer func() {
+			k8sClient.Delete(ctx, agent)
+		}()
+
+		deployment := &appsv1.Deployment{}
+		Eventually(func() error {
+			return k8sClient.Get(ctx, types.NamespacedName{
+				Name:      fmt.Sprintf("agent-%s", agentName),
+				Namespace: namespace,
+			}, deployment)
+		}, timeout, interval).Should(Succeed())
+
+		var levels []string
+		for _, env := range deployment.Spec.Template.Spec.Containers[0].Env {
+			if env.Name == "LOG_LEVEL" {
+				levels = append(levels, env.Value)
+			}
+		}
+		Expect(levels).To(Equal([]string{"DEBUG"}))
+	})
+
+	It("should clear status.endpoint and remove the Service when agentNetwork.expose is disabled", func() {
+		modelAPIName := uniqueAgentName("prune-modelapi")
+		agentName := uniqueAgentName("prune-agent")
+
+		modelAPI := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      modelAPIName,
+				Namespace: namespace,
+			},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode: kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig: &kaosv1alpha1.ProxyConfig{
+					Models: []string{"mock-model"},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, modelAPI)).To(Succeed())
+		defer func() {
+			k8sClient.Delete(ctx, modelAPI)
+		}()
+
+		agent := &kaosv1alpha1.Agent{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      agentName,
+				Namespace: namespace,
+			},
+			Spec: kaosv1alpha1.AgentSpec{
+				ModelAPI:            modelAPIName,
+				Model:               "mock-model",
+				WaitForDependencies: boolPtr(false),
+			},
+		}
+		Expect(k8sClient.Create(ctx, agent)).To(Succeed())
+		defer func() {
+			k8sClient.Delete(ctx, agent)
+		}()
+
+		// Exposed by default - endpoint is published
+		Eventually(func() string {
+			updated := &kaosv1alpha1.Agent{}
+			if err := k8sClient.Get(ctx, types.NamespacedName{Name: agentName, Namespace: namespace}, updated); err != nil {
+				return ""
+			}
+			return updated.Status.Endpoint
+		}, timeout, interval).ShouldNot(BeEmpty())
+
+		// Remove the exposure
+		Eventually(func() error {
+			current := &kaosv1alpha1.Agent{}
+			if err := k8sClient.Get(ctx, types.NamespacedName{Name: agentName, Namespace: namespace}, current); err != nil {
+				return err
+			}
+			current.Spec.AgentNetwork = &kaosv1alpha1.AgentNetworkConfig{Expose: boolPtr(false)}
+			return k8sClient.Update(ctx, current)
+		}, timeout, interval).Should(Succeed())
+
+		Eventually(func() string {
+			updated := &kaosv1alpha1.Agent{}
+			if err := k8sClient.Get(ctx, types.NamespacedName{Name: agentName, Namespace: namespace}, updated); err != nil {
+				return "error"
+			}
+			return updated.Status.Endpoint
+		}, timeout, interval).Should(BeEmpty())
+
+		Eventually(func() bool {
+			err := k8sClient.Get(ctx, types.NamespacedName{
+				Name:      fmt.Sprintf("agent-%s", agentName),
+				Namespace: namespace,
+			}, &corev1.Service{})
+			return apierrors.IsNotFound(err)
+		}, timeout, interval).Should(BeTrue())
+	})
+
+	It("should set spec.runtimeClassName on the agent pod spec", func() {
+		modelAPIName := uniqueAgentName("runtimeclass-modelapi")
+		agentName := uniqueAgentName("runtimeclass-agent")
+
+		modelAPI := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      modelAPIName,
+				Namespace: namespace,
+			},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode: kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig: &kaosv1alpha1.ProxyConfig{
+					Models: []string{"mock-model"},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, modelAPI)).To(Succeed())
+		defer func() {
+			k8sClient.Delete(ctx, modelAPI)
+		}()
+
+		runtimeClass := "kata"
+		agent := &kaosv1alpha1.Agent{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      agentName,
+				Namespace: namespace,
+			},
+			Spec: kaosv1alpha1.AgentSpec{
+				ModelAPI:            modelAPIName,
+				Model:               "mock-model",
+				WaitForDependencies: boolPtr(false),
+				RuntimeClassName:    &runtimeClass,
+			},
+		}
+		Expect(k8sClient.Create(ctx, agent)).To(Succeed())
+		defer func() {
+			k8sClient.Delete(ctx, agent)
+		}()
+
+		deployment := &appsv1.Deployment{}
+		Eventually(func() error {
+			return k8sClient.Get(ctx, types.NamespacedName{
+				Name:      fmt.Sprintf("agent-%s", agentName),
+				Namespace: namespace,
+			}, deployment)
+		}, timeout, interval).Should(Succeed())
+		Expect(deployment.Spec.Template.Spec.RuntimeClassName).NotTo(BeNil())
+		Expect(*deployment.Spec.Template.Spec.RuntimeClassName).To(Equal("kata"))
+	})
+
+	It("should scale the agent Deployment through the scale subresource", func() {
+		modelAPIName := uniqueAgentName("scale-modelapi")
+		agentName := uniqueAgentName("scale-agent")
+
+		modelAPI := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      modelAPIName,
+				Namespace: namespace,
+			},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode: kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig: &kaosv1alpha1.ProxyConfig{
+					Models: []string{"mock-model"},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, modelAPI)).To(Succeed())
+		defer func() {
+			k8sClient.Delete(ctx, modelAPI)
+		}()
+
+		agent := &kaosv1alpha1.Agent{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      agentName,
+				Namespace: namespace,
+			},
+			Spec: kaosv1alpha1.AgentSpec{
+				ModelAPI:            modelAPIName,
+				Model:               "mock-model",
+				WaitForDependencies: boolPtr(false),
+			},
+		}
+		Expect(k8sClient.Create(ctx, agent)).To(Succeed())
+		defer func() {
+			k8sClient.Delete(ctx, agent)
+		}()
+
+		deploymentKey := types.NamespacedName{Name: fmt.Sprintf("agent-%s", agentName), Namespace: namespace}
+		deployment := &appsv1.Deployment{}
+		Eventually(func() error {
+			return k8sClient.Get(ctx, deploymentKey, deployment)
+		}, timeout, interval).Should(Succeed())
+		Expect(*deployment.Spec.Replicas).To(Equal(int32(1)))
+
+		// Equivalent of kubectl scale agent/<name> --replicas=3
+		scale := &autoscalingv1.Scale{}
+		Eventually(func() string {
+			if err := k8sClient.SubResource("scale").Get(ctx, agent, scale); err != nil {
+				return ""
+			}
+			return scale.Status.Selector
+		}, timeout, interval).Should(ContainSubstring("agent=" + agentName))
+		scale.Spec.Replicas = 3
+		Expect(k8sClient.SubResource("scale").Update(ctx, agent, client.WithSubResourceBody(scale))).To(Succeed())
+
+		Eventually(func() int32 {
+			if err := k8sClient.Get(ctx, deploymentKey, deployment); err != nil {
+				return 0
+			}
+			return *deployment.Spec.Replicas
+		}, timeout, interval).Should(Equal(int32(3)))
+	})
+
+	It("should dispatch agent pods to the configured scheduler", func() {
+		modelAPIName := uniqueAgentName("sched-modelapi")
+		agentName := uniqueAgentName("sched-agent")
+
+		modelAPI := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      modelAPIName,
+				Namespace: namespace,
+			},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode: kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig: &kaosv1alpha1.ProxyConfig{
+					Models: []string{"mock-model"},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, modelAPI)).To(Succeed())
+		defer func() {
+			k8sClient.Delete(ctx, modelAPI)
+		}()
+
+		agent := &kaosv1alpha1.Agent{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      agentName,
+				Namespace: namespace,
+			},
+			Spec: kaosv1alpha1.AgentSpec{
+				ModelAPI:            modelAPIName,
+				Model:               "mock-model",
+				WaitForDependencies: boolPtr(false),
+				SchedulerName:       "volcano",
+			},
+		}
+		Expect(k8sClient.Create(ctx, agent)).To(Succeed())
+		defer func() {
+			k8sClient.Delete(ctx, agent)
+		}()
+
+		deployment := &appsv1.Deployment{}
+		Eventually(func() error {
+			return k8sClient.Get(ctx, types.NamespacedName{
+				Name:      fmt.Sprintf("agent-%s", agentName),
+				Namespace: namespace,
+			}, deployment)
+		}, timeout, interval).Should(Succeed())
+		Expect(deployment.Spec.Template.Spec.SchedulerName).To(Equal("volcano"))
+	})
+
+	It("should run a StatefulSet agent with its volumeClaimTemplates", func() {
+		modelAPIName := uniqueAgentName("sts-modelapi")
+		agentName := uniqueAgentName("sts-agent")
+
+		modelAPI := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      modelAPIName,
+				Namespace: namespace,
+			},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode: kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig: &kaosv1alpha1.ProxyConfig{
+					Models: []string{"mock-model"},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, modelAPI)).To(Succeed())
+		defer func() {
+			k8sClient.Delete(ctx, modelAPI)
+		}()
+
+		agent := &kaosv1alpha1.Agent{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      agentName,
+				Namespace: namespace,
+			},
+			Spec: kaosv1alpha1.AgentSpec{
+				ModelAPI:            modelAPIName,
+				Model:               "mock-model",
+				WaitForDependencies: boolPtr(false),
+				WorkloadType:        kaosv1alpha1.AgentWorkloadTypeStatefulSet,
+				VolumeClaimTemplates: []corev1.PersistentVolumeClaim{{
+					ObjectMeta: metav1.ObjectMeta{Name: "state"},
+					Spec: corev1.PersistentVolumeClaimSpec{
+						AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+						Resources: corev1.VolumeResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+						},
+					},
+				}},
+			},
+		}
+		Expect(k8sClient.Create(ctx, agent)).To(Succeed())
+		defer func() {
+			k8sClient.Delete(ctx, agent)
+		}()
+
+		statefulSet := &appsv1.StatefulSet{}
+		Eventually(func() error {
+			return k8sClient.Get(ctx, types.NamespacedName{
+				Name:      fmt.Sprintf("agent-%s", agentName),
+				Namespace: namespace,
+			}, statefulSet)
+		}, timeout, interval).Should(Succeed())
+		Expect(statefulSet.Spec.ServiceName).To(Equal(fmt.Sprintf("agent-%s-headless", agentName)))
+		Expect(statefulSet.Spec.VolumeClaimTemplates).To(HaveLen(1))
+		Expect(statefulSet.Spec.VolumeClaimTemplates[0].Name).To(Equal("state"))
+		Expect(statefulSet.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests.Storage().String()).To(Equal("1Gi"))
+
+		// The governing headless Service is created and no Deployment is
+		headless := &corev1.Service{}
+		Eventually(func() error {
+			return k8sClient.Get(ctx, types.NamespacedName{
+				Name:      fmt.Sprintf("agent-%s-headless", agentName),
+				Namespace: namespace,
+			}, headless)
+		}, timeout, interval).Should(Succeed())
+		Expect(headless.Spec.ClusterIP).To(Equal(corev1.ClusterIPNone))
+		err := k8sClient.Get(ctx, types.NamespacedName{Name: fmt.Sprintf("agent-%s", agentName), Namespace: namespace}, &appsv1.Deployment{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should share the process namespace of agent pods when enabled", func() {
+		modelAPIName := uniqueAgentName("pidns-modelapi")
+		agentName := uniqueAgentName("pidns-agent")
+
+		modelAPI := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      modelAPIName,
+				Namespace: namespace,
+			},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode: kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig: &kaosv1alpha1.ProxyConfig{
+					Models: []string{"mock-model"},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, modelAPI)).To(Succeed())
+		defer func() {
+			k8sClient.Delete(ctx, modelAPI)
+		}()
+
+		agent := &kaosv1alpha1.Agent{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      agentName,
+				Namespace: namespace,
+			},
+			Spec: kaosv1alpha1.AgentSpec{
+				ModelAPI:              modelAPIName,
+				Model:                 "mock-model",
+				WaitForDependencies:   boolPtr(false),
+				ShareProcessNamespace: boolPtr(true),
+			},
+		}
+		Expect(k8sClient.Create(ctx, agent)).To(Succeed())
+		defer func() {
+			k8sClient.Delete(ctx, agent)
+		}()
+
+		deployment := &appsv1.Deployment{}
+		Eventually(func() error {
+			return k8sClient.Get(ctx, types.NamespacedName{
+				Name:      fmt.Sprintf("agent-%s", agentName),
+				Namespace: namespace,
+			}, deployment)
+		}, timeout, interval).Should(Succeed())
+		Expect(deployment.Spec.Template.Spec.ShareProcessNamespace).To(Equal(boolPtr(true)))
+	})
 })
@@ -10,6 +10,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 
@@ -237,4 +238,168 @@ def echo(message: str) -> str:
 		// Note: envtest doesn't run garbage collection, so we only verify the CRD deletion
 		// In a real cluster, the deployment would be garbage collected via OwnerReferences
 	})
+
+	It("should record discovered tools in status once the server is Ready", func() {
+		name := uniqueMCPServerName("mcp-discover")
+		mcp := &kaosv1alpha1.MCPServer{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Spec: kaosv1alpha1.MCPServerSpec{
+				Type: kaosv1alpha1.MCPServerTypePython,
+				Config: kaosv1alpha1.MCPServerConfig{
+					Tools: &kaosv1alpha1.MCPToolsConfig{
+						FromString: "def echo(message: str) -> str:\n    return message\n",
+					},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, mcp)).To(Succeed())
+		defer func() {
+			k8sClient.Delete(ctx, mcp)
+		}()
+
+		deployment := &appsv1.Deployment{}
+		Eventually(func() error {
+			return k8sClient.Get(ctx, types.NamespacedName{
+				Name:      fmt.Sprintf("mcpserver-%s", name),
+				Namespace: namespace,
+			}, deployment)
+		}, timeout, interval).Should(Succeed())
+
+		// envtest runs no Deployment controller, so mark the Deployment ready manually
+		setReady := func(available int32) {
+			Eventually(func() error {
+				if err := k8sClient.Get(ctx, types.NamespacedName{Name: deployment.Name, Namespace: namespace}, deployment); err != nil {
+					return err
+				}
+				deployment.Status.Replicas = 1
+				deployment.Status.ReadyReplicas = 1
+				deployment.Status.AvailableReplicas = available
+				return k8sClient.Status().Update(ctx, deployment)
+			}, timeout, interval).Should(Succeed())
+		}
+
+		// Discovery fails while the endpoint is unreachable
+		setReady(0)
+		Eventually(func() string {
+			current := &kaosv1alpha1.MCPServer{}
+			if err := k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, current); err != nil {
+				return ""
+			}
+			cond := meta.FindStatusCondition(current.Status.Conditions, kaosv1alpha1.MCPServerConditionToolsDiscovered)
+			if cond == nil || cond.Status != metav1.ConditionFalse {
+				return ""
+			}
+			return cond.Reason
+		}, timeout, interval).Should(Equal(kaosv1alpha1.ReasonDiscoveryFailed))
+
+		// Mock endpoint now advertises two tools
+		endpoint := fmt.Sprintf("http://mcpserver-%s.%s.svc.cluster.local:8000", name, namespace)
+		toolDiscoverer.SetTools(endpoint, []kaosv1alpha1.DiscoveredTool{
+			{Name: "echo", Description: "Echo the message back"},
+			{Name: "add", Description: "Add two numbers"},
+		})
+		setReady(1)
+
+		current := &kaosv1alpha1.MCPServer{}
+		Eventually(func() []string {
+			if err := k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, current); err != nil {
+				return nil
+			}
+			var names []string
+			for _, tool := range current.Status.DiscoveredTools {
+				names = append(names, tool.Name)
+			}
+			return names
+		}, timeout, interval).Should(Equal([]string{"echo", "add"}))
+
+		Expect(current.Status.AvailableTools).To(Equal([]string{"echo", "add"}))
+		Expect(meta.IsStatusConditionTrue(current.Status.Conditions, kaosv1alpha1.MCPServerConditionToolsDiscovered)).To(BeTrue())
+	})
+
+	It("should set the mesh injection annotation on the pod template", func() {
+		name := uniqueMCPServerName("mcp-mesh")
+		mcp := &kaosv1alpha1.MCPServer{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Spec: kaosv1alpha1.MCPServerSpec{
+				Type: kaosv1alpha1.MCPServerTypePython,
+				Config: kaosv1alpha1.MCPServerConfig{
+					Tools: &kaosv1alpha1.MCPToolsConfig{
+						FromPackage: "test-mcp-echo-server",
+					},
+				},
+				MeshInjection: kaosv1alpha1.MeshInjectionDisabled,
+			},
+		}
+		Expect(k8sClient.Create(ctx, mcp)).To(Succeed())
+		defer func() {
+			k8sClient.Delete(ctx, mcp)
+		}()
+
+		// MESH_TYPE is unset in the test environment, so the Istio annotation is used
+		deployment := &appsv1.Deployment{}
+		Eventually(func() error {
+			return k8sClient.Get(ctx, types.NamespacedName{
+				Name:      fmt.Sprintf("mcpserver-%s", name),
+				Namespace: namespace,
+			}, deployment)
+		}, timeout, interval).Should(Succeed())
+		Expect(deployment.Spec.Template.Annotations).To(HaveKeyWithValue("sidecar.istio.io/inject", "false"))
+		Expect(deployment.Spec.Template.Annotations).To(HaveKey("kaos.tools/pod-spec-hash"))
+	})
+
+	It("should add a stdio-to-SSE bridge sidecar and target its port when stdioBridge is enabled", func() {
+		name := uniqueMCPServerName("mcp-bridge")
+		mcp := &kaosv1alpha1.MCPServer{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Spec: kaosv1alpha1.MCPServerSpec{
+				Type: kaosv1alpha1.MCPServerTypePython,
+				Config: kaosv1alpha1.MCPServerConfig{
+					Tools: &kaosv1alpha1.MCPToolsConfig{
+						FromPackage: "mcp-server-time",
+					},
+					StdioBridge: &kaosv1alpha1.StdioBridgeConfig{Enabled: true},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, mcp)).To(Succeed())
+		defer func() {
+			k8sClient.Delete(ctx, mcp)
+		}()
+
+		deployment := &appsv1.Deployment{}
+		Eventually(func() error {
+			return k8sClient.Get(ctx, types.NamespacedName{
+				Name:      fmt.Sprintf("mcpserver-%s", name),
+				Namespace: namespace,
+			}, deployment)
+		}, timeout, interval).Should(Succeed())
+
+		containers := deployment.Spec.Template.Spec.Containers
+		Expect(containers).To(HaveLen(2))
+		// The server's stdio is wired to the FIFOs shared with the bridge
+		Expect(containers[0].Name).To(Equal("mcp-server"))
+		Expect(containers[0].Command[2]).To(ContainSubstring("< /bridge/in > /bridge/out"))
+		Expect(containers[0].ReadinessProbe).To(BeNil())
+		Expect(containers[1].Name).To(Equal("stdio-bridge"))
+		Expect(containers[1].Args).To(ContainElements("--outputTransport", "sse"))
+		Expect(containers[1].Ports[0].ContainerPort).To(Equal(int32(8080)))
+
+		service := &corev1.Service{}
+		Eventually(func() error {
+			return k8sClient.Get(ctx, types.NamespacedName{
+				Name:      fmt.Sprintf("mcpserver-%s", name),
+				Namespace: namespace,
+			}, service)
+		}, timeout, interval).Should(Succeed())
+		Expect(service.Spec.Ports[0].TargetPort.IntValue()).To(Equal(8080))
+	})
 })
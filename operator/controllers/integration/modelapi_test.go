@@ -3,6 +3,7 @@ package integration
 import (
 	"context"
 	"fmt"
+	"os"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -15,6 +16,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 
 	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+	"github.com/axsaucedo/kaos/operator/controllers"
 )
 
 // uniqueModelAPIName generates unique names to avoid conflicts between tests
@@ -611,6 +613,575 @@ model_list:
 		Expect(configMap.Data["config.yaml"]).To(ContainSubstring("openai/gpt-4"))
 		Expect(configMap.Data["config.yaml"]).To(ContainSubstring("openai/gpt-3.5-turbo"))
 	})
+
+	It("should pin the image digest and record it in status when pinDigest is set", func() {
+		name := uniqueModelAPIName("pin-digest")
+		modelAPI := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode: kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig: &kaosv1alpha1.ProxyConfig{
+					Models: []string{"mock-model"},
+				},
+				PinDigest: true,
+			},
+		}
+		Expect(k8sClient.Create(ctx, modelAPI)).To(Succeed())
+		defer func() {
+			k8sClient.Delete(ctx, modelAPI)
+		}()
+
+		pinnedImage := "ghcr.io/berriai/litellm:main-latest@" + fakeDigest
+
+		// Verify Deployment uses the digest-pinned image
+		deployment := &appsv1.Deployment{}
+		Eventually(func() string {
+			if err := k8sClient.Get(ctx, types.NamespacedName{
+				Name:      fmt.Sprintf("modelapi-%s", name),
+				Namespace: namespace,
+			}, deployment); err != nil {
+				return ""
+			}
+			return deployment.Spec.Template.Spec.Containers[0].Image
+		}, timeout, interval).Should(Equal(pinnedImage))
+
+		// Verify the resolved digest is recorded in status
+		Eventually(func() string {
+			updated := &kaosv1alpha1.ModelAPI{}
+			if err := k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, updated); err != nil {
+				return ""
+			}
+			return updated.Status.ResolvedImage
+		}, timeout, interval).Should(Equal(pinnedImage))
+		Expect(imageResolver.Calls("ghcr.io/berriai/litellm:main-latest")).To(BeNumerically(">=", 1))
+
+		// Change the image tag via podSpec - a new digest should be resolved for the new tag
+		Eventually(func() error {
+			current := &kaosv1alpha1.ModelAPI{}
+			if err := k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, current); err != nil {
+				return err
+			}
+			current.Spec.PodSpec = &corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "model-api", Image: "ghcr.io/berriai/litellm:v1.56.5"},
+				},
+			}
+			return k8sClient.Update(ctx, current)
+		}, timeout, interval).Should(Succeed())
+
+		Eventually(func() string {
+			updated := &kaosv1alpha1.ModelAPI{}
+			if err := k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, updated); err != nil {
+				return ""
+			}
+			return updated.Status.ResolvedImage
+		}, timeout, interval).Should(Equal("ghcr.io/berriai/litellm:v1.56.5@" + fakeDigest))
+		Expect(imageResolver.Calls("ghcr.io/berriai/litellm:v1.56.5")).To(BeNumerically(">=", 1))
+	})
+
+	It("should set externalTrafficPolicy on a LoadBalancer service", func() {
+		name := uniqueModelAPIName("traffic-policy")
+		modelAPI := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode: kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig: &kaosv1alpha1.ProxyConfig{
+					Models: []string{"mock-model"},
+				},
+				ServiceType:           corev1.ServiceTypeLoadBalancer,
+				ExternalTrafficPolicy: corev1.ServiceExternalTrafficPolicyLocal,
+			},
+		}
+		Expect(k8sClient.Create(ctx, modelAPI)).To(Succeed())
+		defer func() {
+			k8sClient.Delete(ctx, modelAPI)
+		}()
+
+		service := &corev1.Service{}
+		Eventually(func() error {
+			return k8sClient.Get(ctx, types.NamespacedName{
+				Name:      fmt.Sprintf("modelapi-%s", name),
+				Namespace: namespace,
+			}, service)
+		}, timeout, interval).Should(Succeed())
+		Expect(service.Spec.Type).To(Equal(corev1.ServiceTypeLoadBalancer))
+		Expect(service.Spec.ExternalTrafficPolicy).To(Equal(corev1.ServiceExternalTrafficPolicyLocal))
+	})
+
+	It("should route through a rate-limiting sidecar when rateLimit is set", func() {
+		name := uniqueModelAPIName("rate-limit")
+		burst := int32(20)
+		modelAPI := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode: kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig: &kaosv1alpha1.ProxyConfig{
+					Models: []string{"mock-model"},
+				},
+				RateLimit: &kaosv1alpha1.RateLimitConfig{
+					RequestsPerSecond: 5,
+					Burst:             &burst,
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, modelAPI)).To(Succeed())
+		defer func() {
+			k8sClient.Delete(ctx, modelAPI)
+		}()
+
+		// Verify the sidecar carries the configured limits
+		deployment := &appsv1.Deployment{}
+		Eventually(func() error {
+			return k8sClient.Get(ctx, types.NamespacedName{
+				Name:      fmt.Sprintf("modelapi-%s", name),
+				Namespace: namespace,
+			}, deployment)
+		}, timeout, interval).Should(Succeed())
+		containers := deployment.Spec.Template.Spec.Containers
+		Expect(containers).To(HaveLen(2))
+		Expect(containers[1].Name).To(Equal("rate-limiter"))
+		envMap := make(map[string]string)
+		for _, env := range containers[1].Env {
+			envMap[env.Name] = env.Value
+		}
+		Expect(envMap["RATE_LIMIT_RPS"]).To(Equal("5"))
+		Expect(envMap["RATE_LIMIT_BURST"]).To(Equal("20"))
+
+		// Verify the nginx config template is rendered into the ConfigMap
+		configMap := &corev1.ConfigMap{}
+		Eventually(func() error {
+			return k8sClient.Get(ctx, types.NamespacedName{
+				Name:      fmt.Sprintf("litellm-config-%s", name),
+				Namespace: namespace,
+			}, configMap)
+		}, timeout, interval).Should(Succeed())
+		Expect(configMap.Data["ratelimit.conf.template"]).To(ContainSubstring("limit_req zone=modelapi"))
+
+		// Verify the Service targets the sidecar rather than LiteLLM
+		service := &corev1.Service{}
+		Eventually(func() error {
+			return k8sClient.Get(ctx, types.NamespacedName{
+				Name:      fmt.Sprintf("modelapi-%s", name),
+				Namespace: namespace,
+			}, service)
+		}, timeout, interval).Should(Succeed())
+		Expect(service.Spec.Ports[0].Port).To(Equal(int32(8000)))
+		Expect(service.Spec.Ports[0].TargetPort.IntValue()).To(Equal(8080))
+	})
+
+	It("should add a preStop drain sized by drainSeconds", func() {
+		name := uniqueModelAPIName("drain")
+		drain := int32(25)
+		modelAPI := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode: kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig: &kaosv1alpha1.ProxyConfig{
+					Models: []string{"mock-model"},
+				},
+				DrainSeconds: &drain,
+			},
+		}
+		Expect(k8sClient.Create(ctx, modelAPI)).To(Succeed())
+		defer func() {
+			k8sClient.Delete(ctx, modelAPI)
+		}()
+
+		deployment := &appsv1.Deployment{}
+		Eventually(func() error {
+			return k8sClient.Get(ctx, types.NamespacedName{
+				Name:      fmt.Sprintf("modelapi-%s", name),
+				Namespace: namespace,
+			}, deployment)
+		}, timeout, interval).Should(Succeed())
+
+		podSpec := deployment.Spec.Template.Spec
+		preStop := podSpec.Containers[0].Lifecycle.PreStop
+		Expect(preStop).NotTo(BeNil())
+		Expect(preStop.Sleep).NotTo(BeNil())
+		Expect(preStop.Sleep.Seconds).To(Equal(int64(25)))
+		// Grace period must outlast the drain
+		Expect(*podSpec.TerminationGracePeriodSeconds).To(Equal(int64(55)))
+		Expect(podSpec.ReadinessGates).To(ContainElement(corev1.PodReadinessGate{ConditionType: controllers.ServingReadinessGate}))
+	})
+
+	It("should merge serviceAnnotations onto the Service without overriding managed ones", func() {
+		name := uniqueModelAPIName("svc-annotations")
+		modelAPI := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode: kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig: &kaosv1alpha1.ProxyConfig{
+					Models: []string{"mock-model"},
+				},
+				ServiceType: corev1.ServiceTypeLoadBalancer,
+				ServiceAnnotations: map[string]string{
+					"service.beta.kubernetes.io/aws-load-balancer-type": "nlb",
+					"kaos.tools/applied-annotations":                    "user-value",
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, modelAPI)).To(Succeed())
+		defer func() {
+			k8sClient.Delete(ctx, modelAPI)
+		}()
+
+		service := &corev1.Service{}
+		Eventually(func() error {
+			return k8sClient.Get(ctx, types.NamespacedName{
+				Name:      fmt.Sprintf("modelapi-%s", name),
+				Namespace: namespace,
+			}, service)
+		}, timeout, interval).Should(Succeed())
+		Expect(service.Annotations).To(HaveKeyWithValue("service.beta.kubernetes.io/aws-load-balancer-type", "nlb"))
+		// The reserved kaos.tools/ key keeps the operator-managed value
+		Expect(service.Annotations).To(HaveKeyWithValue("kaos.tools/applied-annotations", "service.beta.kubernetes.io/aws-load-balancer-type"))
+	})
+
+	It("should set runtimeClassName and default it for GPU pods", func() {
+		os.Setenv("DEFAULT_GPU_RUNTIME_CLASS", "nvidia")
+		defer os.Unsetenv("DEFAULT_GPU_RUNTIME_CLASS")
+
+		sandboxed := "gvisor"
+		explicit := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      uniqueModelAPIName("runtime-class"),
+				Namespace: namespace,
+			},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode: kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig: &kaosv1alpha1.ProxyConfig{
+					Models: []string{"mock-model"},
+				},
+				RuntimeClassName: &sandboxed,
+			},
+		}
+		gpu := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      uniqueModelAPIName("runtime-class-gpu"),
+				Namespace: namespace,
+			},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode: kaosv1alpha1.ModelAPIModeHosted,
+				HostedConfig: &kaosv1alpha1.HostedConfig{
+					Model: "smollm2:135m",
+				},
+				PodSpec: &corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name: "model-api",
+						Resources: corev1.ResourceRequirements{
+							Limits: corev1.ResourceList{"nvidia.com/gpu": resource.MustParse("1")},
+						},
+					}},
+				},
+			},
+		}
+		for _, modelAPI := range []*kaosv1alpha1.ModelAPI{explicit, gpu} {
+			Expect(k8sClient.Create(ctx, modelAPI)).To(Succeed())
+			defer k8sClient.Delete(ctx, modelAPI)
+		}
+
+		runtimeClassOf := func(name string) func() string {
+			return func() string {
+				deployment := &appsv1.Deployment{}
+				if err := k8sClient.Get(ctx, types.NamespacedName{
+					Name:      fmt.Sprintf("modelapi-%s", name),
+					Namespace: namespace,
+				}, deployment); err != nil || deployment.Spec.Template.Spec.RuntimeClassName == nil {
+					return ""
+				}
+				return *deployment.Spec.Template.Spec.RuntimeClassName
+			}
+		}
+		// An explicit runtime class wins; GPU pods fall back to the operator default
+		Eventually(runtimeClassOf(explicit.Name), timeout, interval).Should(Equal("gvisor"))
+		Eventually(runtimeClassOf(gpu.Name), timeout, interval).Should(Equal("nvidia"))
+	})
+
+	It("should propagate configured owner labels to the Deployment and Service", func() {
+		os.Setenv("PROPAGATED_LABELS", "team,cost-center")
+		defer os.Unsetenv("PROPAGATED_LABELS")
+
+		modelAPIName := uniqueModelAPIName("propagated-labels")
+		modelAPI := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      modelAPIName,
+				Namespace: namespace,
+				Labels: map[string]string{
+					"team":        "search",
+					"cost-center": "cc-42",
+					"unlisted":    "ignored",
+				},
+			},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode: kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig: &kaosv1alpha1.ProxyConfig{
+					Models: []string{"mock-model"},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, modelAPI)).To(Succeed())
+		defer k8sClient.Delete(ctx, modelAPI)
+
+		key := types.NamespacedName{Name: fmt.Sprintf("modelapi-%s", modelAPIName), Namespace: namespace}
+		deployment := &appsv1.Deployment{}
+		Eventually(func() error {
+			return k8sClient.Get(ctx, key, deployment)
+		}, timeout, interval).Should(Succeed())
+		Expect(deployment.Labels).To(HaveKeyWithValue("team", "search"))
+		Expect(deployment.Labels).To(HaveKeyWithValue("cost-center", "cc-42"))
+		Expect(deployment.Labels).NotTo(HaveKey("unlisted"))
+		// Selectors keep only the operator's own labels
+		Expect(deployment.Spec.Selector.MatchLabels).NotTo(HaveKey("team"))
+
+		service := &corev1.Service{}
+		Eventually(func() error {
+			return k8sClient.Get(ctx, key, service)
+		}, timeout, interval).Should(Succeed())
+		Expect(service.Labels).To(HaveKeyWithValue("team", "search"))
+		Expect(service.Labels).To(HaveKeyWithValue("cost-center", "cc-42"))
+		Expect(service.Spec.Selector).NotTo(HaveKey("cost-center"))
+
+		// Relabelling the owner is propagated to existing objects
+		Eventually(func() error {
+			if err := k8sClient.Get(ctx, types.NamespacedName{Name: modelAPIName, Namespace: namespace}, modelAPI); err != nil {
+				return err
+			}
+			modelAPI.Labels["team"] = "ads"
+			return k8sClient.Update(ctx, modelAPI)
+		}, timeout, interval).Should(Succeed())
+		Eventually(func() string {
+			if err := k8sClient.Get(ctx, key, service); err != nil {
+				return ""
+			}
+			return service.Labels["team"]
+		}, timeout, interval).Should(Equal("ads"))
+	})
+
+	It("should inject a preferred pod anti-affinity for multi-replica ModelAPIs", func() {
+		replicas := int32(3)
+		spread := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      uniqueModelAPIName("spread"),
+				Namespace: namespace,
+			},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode: kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig: &kaosv1alpha1.ProxyConfig{
+					Models: []string{"mock-model"},
+				},
+				Replicas: &replicas,
+			},
+		}
+		single := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      uniqueModelAPIName("spread-single"),
+				Namespace: namespace,
+			},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode: kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig: &kaosv1alpha1.ProxyConfig{
+					Models: []string{"mock-model"},
+				},
+			},
+		}
+		for _, modelAPI := range []*kaosv1alpha1.ModelAPI{spread, single} {
+			Expect(k8sClient.Create(ctx, modelAPI)).To(Succeed())
+			defer k8sClient.Delete(ctx, modelAPI)
+		}
+
+		deployment := &appsv1.Deployment{}
+		Eventually(func() error {
+			return k8sClient.Get(ctx, types.NamespacedName{
+				Name:      fmt.Sprintf("modelapi-%s", spread.Name),
+				Namespace: namespace,
+			}, deployment)
+		}, timeout, interval).Should(Succeed())
+		Expect(*deployment.Spec.Replicas).To(Equal(int32(3)))
+		affinity := deployment.Spec.Template.Spec.Affinity
+		Expect(affinity).NotTo(BeNil())
+		Expect(affinity.PodAntiAffinity).NotTo(BeNil())
+		terms := affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+		Expect(terms).To(HaveLen(1))
+		Expect(terms[0].PodAffinityTerm.TopologyKey).To(Equal("kubernetes.io/hostname"))
+		Expect(terms[0].PodAffinityTerm.LabelSelector.MatchLabels).To(HaveKeyWithValue("modelapi", spread.Name))
+
+		// A single replica has nothing to spread
+		Eventually(func() error {
+			return k8sClient.Get(ctx, types.NamespacedName{
+				Name:      fmt.Sprintf("modelapi-%s", single.Name),
+				Namespace: namespace,
+			}, deployment)
+		}, timeout, interval).Should(Succeed())
+		Expect(deployment.Spec.Template.Spec.Affinity).To(BeNil())
+	})
+
+	It("should inject failover upstreams in priority order", func() {
+		name := uniqueModelAPIName("proxy-upstreams")
+		modelAPI := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode: kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig: &kaosv1alpha1.ProxyConfig{
+					Models:    []string{"gpt-4o"},
+					Provider:  "openai",
+					Upstreams: []string{"http://primary:8000", "http://secondary:8000", "http://tertiary:8000"},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, modelAPI)).To(Succeed())
+		defer func() {
+			k8sClient.Delete(ctx, modelAPI)
+		}()
+
+		deployment := &appsv1.Deployment{}
+		Eventually(func() error {
+			return k8sClient.Get(ctx, types.NamespacedName{
+				Name:      fmt.Sprintf("modelapi-%s", name),
+				Namespace: namespace,
+			}, deployment)
+		}, timeout, interval).Should(Succeed())
+		env := map[string]string{}
+		for _, e := range deployment.Spec.Template.Spec.Containers[0].Env {
+			env[e.Name] = e.Value
+		}
+		Expect(env).To(HaveKeyWithValue("PROXY_API_BASES", "http://primary:8000,http://secondary:8000,http://tertiary:8000"))
+		Expect(env).To(HaveKeyWithValue("PROXY_API_BASE_1", "http://primary:8000"))
+		Expect(env).To(HaveKeyWithValue("PROXY_API_BASE_3", "http://tertiary:8000"))
+		Expect(env).NotTo(HaveKey("PROXY_API_BASE"))
+
+		configMap := &corev1.ConfigMap{}
+		Eventually(func() error {
+			return k8sClient.Get(ctx, types.NamespacedName{
+				Name:      fmt.Sprintf("litellm-config-%s", name),
+				Namespace: namespace,
+			}, configMap)
+		}, timeout, interval).Should(Succeed())
+		config := configMap.Data["config.yaml"]
+		Expect(config).To(ContainSubstring("api_base: \"os.environ/PROXY_API_BASE_1\"\n      order: 1"))
+		Expect(config).To(ContainSubstring("api_base: \"os.environ/PROXY_API_BASE_3\"\n      order: 3"))
+		Expect(config).To(ContainSubstring("enable_pre_call_checks: true"))
+	})
+
+	It("should set schedulerName and otherwise leave the default scheduler", func() {
+		custom := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      uniqueModelAPIName("sched-custom"),
+				Namespace: namespace,
+			},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode:          kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig:   &kaosv1alpha1.ProxyConfig{Models: []string{"openai/gpt-4"}},
+				SchedulerName: "volcano",
+			},
+		}
+		plain := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      uniqueModelAPIName("sched-default"),
+				Namespace: namespace,
+			},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode:        kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig: &kaosv1alpha1.ProxyConfig{Models: []string{"openai/gpt-4"}},
+			},
+		}
+		for _, m := range []*kaosv1alpha1.ModelAPI{custom, plain} {
+			Expect(k8sClient.Create(ctx, m)).To(Succeed())
+			defer k8sClient.Delete(ctx, m)
+		}
+
+		deployment := &appsv1.Deployment{}
+		Eventually(func() error {
+			return k8sClient.Get(ctx, types.NamespacedName{
+				Name:      fmt.Sprintf("modelapi-%s", custom.Name),
+				Namespace: namespace,
+			}, deployment)
+		}, timeout, interval).Should(Succeed())
+		Expect(deployment.Spec.Template.Spec.SchedulerName).To(Equal("volcano"))
+
+		// The API server fills in the cluster default when the field is unset
+		Eventually(func() error {
+			return k8sClient.Get(ctx, types.NamespacedName{
+				Name:      fmt.Sprintf("modelapi-%s", plain.Name),
+				Namespace: namespace,
+			}, deployment)
+		}, timeout, interval).Should(Succeed())
+		Expect(deployment.Spec.Template.Spec.SchedulerName).To(Equal(corev1.DefaultSchedulerName))
+	})
+
+	It("should cap the Deployment revision history", func() {
+		limit := int32(5)
+		custom := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      uniqueModelAPIName("history-custom"),
+				Namespace: namespace,
+			},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode:                 kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig:          &kaosv1alpha1.ProxyConfig{Models: []string{"openai/gpt-4"}},
+				RevisionHistoryLimit: &limit,
+			},
+		}
+		plain := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      uniqueModelAPIName("history-default"),
+				Namespace: namespace,
+			},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode:        kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig: &kaosv1alpha1.ProxyConfig{Models: []string{"openai/gpt-4"}},
+			},
+		}
+		for _, m := range []*kaosv1alpha1.ModelAPI{custom, plain} {
+			Expect(k8sClient.Create(ctx, m)).To(Succeed())
+			defer k8sClient.Delete(ctx, m)
+		}
+
+		historyLimit := func(m *kaosv1alpha1.ModelAPI) func() int32 {
+			return func() int32 {
+				deployment := &appsv1.Deployment{}
+				if err := k8sClient.Get(ctx, types.NamespacedName{
+					Name:      fmt.Sprintf("modelapi-%s", m.Name),
+					Namespace: namespace,
+				}, deployment); err != nil || deployment.Spec.RevisionHistoryLimit == nil {
+					return -1
+				}
+				return *deployment.Spec.RevisionHistoryLimit
+			}
+		}
+		Eventually(historyLimit(custom), timeout, interval).Should(Equal(int32(5)))
+		Eventually(historyLimit(plain), timeout, interval).Should(Equal(int32(3)))
+
+		// Changing the limit updates the existing Deployment
+		Eventually(func() error {
+			if err := k8sClient.Get(ctx, types.NamespacedName{Name: custom.Name, Namespace: namespace}, custom); err != nil {
+				return err
+			}
+			limit = 1
+			custom.Spec.RevisionHistoryLimit = &limit
+			return k8sClient.Update(ctx, custom)
+		}, timeout, interval).Should(Succeed())
+		Eventually(historyLimit(custom), timeout, interval).Should(Equal(int32(1)))
+	})
+
 })
 
 // containsSubstring checks if s contains substr (helper for test assertions)
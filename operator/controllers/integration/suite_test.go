@@ -6,8 +6,10 @@ package integration
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
@@ -23,14 +25,17 @@ import (
 
 	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
 	"github.com/axsaucedo/kaos/operator/controllers"
+	"github.com/axsaucedo/kaos/operator/pkg/util"
 )
 
 var (
-	cfg       *rest.Config
-	k8sClient client.Client
-	testEnv   *envtest.Environment
-	ctx       context.Context
-	cancel    context.CancelFunc
+	cfg            *rest.Config
+	k8sClient      client.Client
+	testEnv        *envtest.Environment
+	ctx            context.Context
+	cancel         context.CancelFunc
+	imageResolver  *fakeImageResolver
+	toolDiscoverer *fakeToolDiscoverer
 )
 
 func TestControllers(t *testing.T) {
@@ -72,21 +77,34 @@ var _ = BeforeSuite(func() {
 	})
 	Expect(err).ToNot(HaveOccurred())
 
+	imageResolver = &fakeImageResolver{calls: map[string]int{}}
+	toolDiscoverer = &fakeToolDiscoverer{tools: map[string][]kaosv1alpha1.DiscoveredTool{}}
+
 	err = (&controllers.AgentReconciler{
-		Client: k8sManager.GetClient(),
-		Scheme: k8sManager.GetScheme(),
+		Client:         k8sManager.GetClient(),
+		Scheme:         k8sManager.GetScheme(),
+		ImageResolver:  imageResolver,
+		ReconcileCache: util.NewReconcileCache(),
+		Recorder:       k8sManager.GetEventRecorderFor("agent-controller"),
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
 
 	err = (&controllers.MCPServerReconciler{
-		Client: k8sManager.GetClient(),
-		Scheme: k8sManager.GetScheme(),
+		Client:         k8sManager.GetClient(),
+		Scheme:         k8sManager.GetScheme(),
+		ImageResolver:  imageResolver,
+		ToolDiscoverer: toolDiscoverer,
+		ReconcileCache: util.NewReconcileCache(),
+		Recorder:       k8sManager.GetEventRecorderFor("mcpserver-controller"),
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
 
 	err = (&controllers.ModelAPIReconciler{
-		Client: k8sManager.GetClient(),
-		Scheme: k8sManager.GetScheme(),
+		Client:         k8sManager.GetClient(),
+		Scheme:         k8sManager.GetScheme(),
+		ImageResolver:  imageResolver,
+		ReconcileCache: util.NewReconcileCache(),
+		Recorder:       k8sManager.GetEventRecorderFor("modelapi-controller"),
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
 
@@ -124,3 +142,49 @@ func getFirstFoundEnvTestBinaryDir() string {
 	}
 	return ""
 }
+
+// fakeDigest is the digest returned by fakeImageResolver for every image
+const fakeDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+// fakeImageResolver resolves every image to fakeDigest and counts lookups per image
+type fakeImageResolver struct {
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (f *fakeImageResolver) ResolveDigest(_ context.Context, image string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls[image]++
+	return fakeDigest, nil
+}
+
+// Calls returns how many times the image has been resolved
+func (f *fakeImageResolver) Calls(image string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[image]
+}
+
+// fakeToolDiscoverer returns the tools registered for an endpoint and fails for unknown endpoints
+type fakeToolDiscoverer struct {
+	mu    sync.Mutex
+	tools map[string][]kaosv1alpha1.DiscoveredTool
+}
+
+func (f *fakeToolDiscoverer) ListTools(_ context.Context, endpoint string) ([]kaosv1alpha1.DiscoveredTool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tools, ok := f.tools[endpoint]
+	if !ok {
+		return nil, fmt.Errorf("connection refused: %s", endpoint)
+	}
+	return tools, nil
+}
+
+// SetTools registers the tools advertised by the MCP server at the endpoint
+func (f *fakeToolDiscoverer) SetTools(endpoint string, tools []kaosv1alpha1.DiscoveredTool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tools[endpoint] = tools
+}
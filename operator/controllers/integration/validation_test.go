@@ -0,0 +1,219 @@
+package integration
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+// The CRD schemas carry validation rules that the API server enforces without the
+// admission webhooks
+var _ = Describe("CRD validation", func() {
+	ctx := context.Background()
+	const namespace = "default"
+
+	// expectRejected checks that the API server refuses obj with a message containing msg
+	expectRejected := func(obj client.Object, msg string) {
+		err := k8sClient.Create(ctx, obj)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue(), "expected Invalid, got %v", err)
+		Expect(err.Error()).To(ContainSubstring(msg))
+	}
+
+	It("should reject mtls on a Hosted ModelAPI", func() {
+		expectRejected(&kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{Name: uniqueModelAPIName("hosted-mtls"), Namespace: namespace},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode:         kaosv1alpha1.ModelAPIModeHosted,
+				HostedConfig: &kaosv1alpha1.HostedConfig{Model: "smollm2:135m"},
+				MTLS:         &kaosv1alpha1.MTLSConfig{SecretName: "agent-model-tls"},
+			},
+		}, "mtls requires mode Proxy")
+	})
+
+	It("should require exactly one of modelAPI and modelAPISelector on an Agent", func() {
+		agent := func(modelAPI string, selector *metav1.LabelSelector) *kaosv1alpha1.Agent {
+			return &kaosv1alpha1.Agent{
+				ObjectMeta: metav1.ObjectMeta{Name: uniqueAgentName("model-ref"), Namespace: namespace},
+				Spec: kaosv1alpha1.AgentSpec{
+					ModelAPI:            modelAPI,
+					ModelAPISelector:    selector,
+					Model:               "mock-model",
+					WaitForDependencies: boolPtr(false),
+				},
+			}
+		}
+		selector := &metav1.LabelSelector{MatchLabels: map[string]string{"team": "search"}}
+		expectRejected(agent("", nil), "exactly one of modelAPI and modelAPISelector must be set")
+		expectRejected(agent("llm", selector), "exactly one of modelAPI and modelAPISelector must be set")
+
+		valid := agent("", selector)
+		Expect(k8sClient.Create(ctx, valid)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, valid)).To(Succeed())
+	})
+
+	It("should reject non-positive Agent runtime values", func() {
+		agent := func(runtime *kaosv1alpha1.AgentRuntimeConfig) *kaosv1alpha1.Agent {
+			return &kaosv1alpha1.Agent{
+				ObjectMeta: metav1.ObjectMeta{Name: uniqueAgentName("runtime"), Namespace: namespace},
+				Spec: kaosv1alpha1.AgentSpec{
+					ModelAPI:            "llm",
+					Model:               "mock-model",
+					Runtime:             runtime,
+					WaitForDependencies: boolPtr(false),
+				},
+			}
+		}
+		zero := int32(0)
+		expectRejected(agent(&kaosv1alpha1.AgentRuntimeConfig{MaxConcurrency: &zero}), "spec.runtime.maxConcurrency")
+		expectRejected(agent(&kaosv1alpha1.AgentRuntimeConfig{RequestTimeout: "-5s"}), "must be a positive duration")
+		expectRejected(agent(&kaosv1alpha1.AgentRuntimeConfig{RequestTimeout: "ten seconds"}), "spec.runtime.requestTimeout")
+
+		valid := agent(&kaosv1alpha1.AgentRuntimeConfig{RequestTimeout: "90s"})
+		Expect(k8sClient.Create(ctx, valid)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, valid)).To(Succeed())
+	})
+
+	It("should only allow externalTrafficPolicy on NodePort and LoadBalancer services", func() {
+		modelapi := func(serviceType corev1.ServiceType) *kaosv1alpha1.ModelAPI {
+			return &kaosv1alpha1.ModelAPI{
+				ObjectMeta: metav1.ObjectMeta{Name: uniqueModelAPIName("traffic-policy"), Namespace: namespace},
+				Spec: kaosv1alpha1.ModelAPISpec{
+					Mode:                  kaosv1alpha1.ModelAPIModeProxy,
+					ProxyConfig:           &kaosv1alpha1.ProxyConfig{Models: []string{"openai/gpt-4"}},
+					ServiceType:           serviceType,
+					ExternalTrafficPolicy: corev1.ServiceExternalTrafficPolicyLocal,
+				},
+			}
+		}
+		expectRejected(modelapi(""), "externalTrafficPolicy only applies to NodePort and LoadBalancer service types")
+		expectRejected(modelapi(corev1.ServiceTypeClusterIP), "externalTrafficPolicy only applies to NodePort and LoadBalancer service types")
+
+		valid := modelapi(corev1.ServiceTypeLoadBalancer)
+		Expect(k8sClient.Create(ctx, valid)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, valid)).To(Succeed())
+	})
+
+	It("should accept only supported log levels, in any case", func() {
+		const msg = "must be one of DEBUG, INFO, WARNING, ERROR or CRITICAL"
+		expectRejected(&kaosv1alpha1.Agent{
+			ObjectMeta: metav1.ObjectMeta{Name: uniqueAgentName("log-level"), Namespace: namespace},
+			Spec: kaosv1alpha1.AgentSpec{
+				ModelAPI:            "llm",
+				Model:               "mock-model",
+				LogLevel:            "TRACE",
+				WaitForDependencies: boolPtr(false),
+			},
+		}, msg)
+		expectRejected(&kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{Name: uniqueModelAPIName("log-level"), Namespace: namespace},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode:        kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig: &kaosv1alpha1.ProxyConfig{Models: []string{"openai/gpt-4"}},
+				LogLevel:    "loud",
+			},
+		}, msg)
+
+		mcpserver := func(level string) *kaosv1alpha1.MCPServer {
+			return &kaosv1alpha1.MCPServer{
+				ObjectMeta: metav1.ObjectMeta{Name: uniqueMCPServerName("log-level"), Namespace: namespace},
+				Spec: kaosv1alpha1.MCPServerSpec{
+					Type:     kaosv1alpha1.MCPServerTypePython,
+					Config:   kaosv1alpha1.MCPServerConfig{Tools: &kaosv1alpha1.MCPToolsConfig{FromString: "def echo(x: str) -> str:\n    return x\n"}},
+					LogLevel: level,
+				},
+			}
+		}
+		expectRejected(mcpserver("VERBOSE"), msg)
+
+		valid := mcpserver("debug")
+		Expect(k8sClient.Create(ctx, valid)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, valid)).To(Succeed())
+	})
+
+	It("should reject an unsupported image pull policy", func() {
+		mcpserver := func(policy corev1.PullPolicy) *kaosv1alpha1.MCPServer {
+			return &kaosv1alpha1.MCPServer{
+				ObjectMeta: metav1.ObjectMeta{Name: uniqueMCPServerName("pull-policy"), Namespace: namespace},
+				Spec: kaosv1alpha1.MCPServerSpec{
+					Type:            kaosv1alpha1.MCPServerTypePython,
+					Config:          kaosv1alpha1.MCPServerConfig{Tools: &kaosv1alpha1.MCPToolsConfig{FromString: "def echo(x: str) -> str:\n    return x\n"}},
+					ImagePullPolicy: policy,
+				},
+			}
+		}
+		expectRejected(mcpserver("Sometimes"), "spec.imagePullPolicy")
+
+		valid := mcpserver(corev1.PullNever)
+		Expect(k8sClient.Create(ctx, valid)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, valid)).To(Succeed())
+	})
+
+	It("should reject non-positive health check timings", func() {
+		negative, zero := int32(-1), int32(0)
+		expectRejected(&kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{Name: uniqueModelAPIName("health-timings"), Namespace: namespace},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode:        kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig: &kaosv1alpha1.ProxyConfig{Models: []string{"openai/gpt-4"}},
+				HealthCheck: &kaosv1alpha1.HealthCheckConfig{InitialDelaySeconds: &negative, PeriodSeconds: &zero},
+			},
+		}, "spec.healthCheck.initialDelaySeconds")
+	})
+
+	It("should reject mutually exclusive ModelAPI spec blocks", func() {
+		modelapi := func(mutate func(*kaosv1alpha1.ModelAPISpec)) *kaosv1alpha1.ModelAPI {
+			m := &kaosv1alpha1.ModelAPI{
+				ObjectMeta: metav1.ObjectMeta{Name: uniqueModelAPIName("exclusive"), Namespace: namespace},
+				Spec: kaosv1alpha1.ModelAPISpec{
+					Mode:        kaosv1alpha1.ModelAPIModeProxy,
+					ProxyConfig: &kaosv1alpha1.ProxyConfig{Models: []string{"openai/gpt-4"}},
+				},
+			}
+			mutate(&m.Spec)
+			return m
+		}
+		secretRef := &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "creds"}, Key: "key"}
+
+		expectRejected(modelapi(func(spec *kaosv1alpha1.ModelAPISpec) {
+			spec.HostedConfig = &kaosv1alpha1.HostedConfig{Model: "smollm2:135m"}
+		}), "only one of proxyConfig and hostedConfig may be set")
+		expectRejected(modelapi(func(spec *kaosv1alpha1.ModelAPISpec) {
+			spec.ProxyConfig.APIKey = &kaosv1alpha1.ApiKeySource{Value: "sk-test", ValueFrom: &kaosv1alpha1.ApiKeyValueFrom{SecretKeyRef: secretRef}}
+		}), "only one of value and valueFrom may be set")
+		expectRejected(modelapi(func(spec *kaosv1alpha1.ModelAPISpec) {
+			spec.ProxyConfig.APIKey = &kaosv1alpha1.ApiKeySource{ValueFrom: &kaosv1alpha1.ApiKeyValueFrom{
+				SecretKeyRef:    secretRef,
+				ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "creds"}, Key: "key"},
+			}}
+		}), "only one of secretKeyRef and configMapKeyRef may be set")
+		expectRejected(modelapi(func(spec *kaosv1alpha1.ModelAPISpec) {
+			spec.ProxyConfig.ConfigYaml = &kaosv1alpha1.ConfigYamlSource{FromString: "model_list: []", FromSecretKeyRef: secretRef}
+		}), "only one of fromString and fromSecretKeyRef may be set")
+
+		valid := modelapi(func(spec *kaosv1alpha1.ModelAPISpec) {
+			spec.ProxyConfig.APIKey = &kaosv1alpha1.ApiKeySource{ValueFrom: &kaosv1alpha1.ApiKeyValueFrom{SecretKeyRef: secretRef}}
+		})
+		Expect(k8sClient.Create(ctx, valid)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, valid)).To(Succeed())
+	})
+
+	It("should reject MCPServer tools from more than one source", func() {
+		expectRejected(&kaosv1alpha1.MCPServer{
+			ObjectMeta: metav1.ObjectMeta{Name: uniqueMCPServerName("tools-sources"), Namespace: namespace},
+			Spec: kaosv1alpha1.MCPServerSpec{
+				Type: kaosv1alpha1.MCPServerTypePython,
+				Config: kaosv1alpha1.MCPServerConfig{Tools: &kaosv1alpha1.MCPToolsConfig{
+					FromPackage: "mcp-server-calculator",
+					FromString:  "def echo(x: str) -> str:\n    return x\n",
+				}},
+			},
+		}, "only one of fromPackage, fromString and fromSecretKeyRef may be set")
+	})
+})
@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+// capReplicas limits replicas to maxReplicas, the --max-replicas-per-cr cap, where 0 means
+// no cap
+func capReplicas(replicas, maxReplicas int32) int32 {
+	if maxReplicas > 0 && replicas > maxReplicas {
+		return maxReplicas
+	}
+	return replicas
+}
+
+// capLiveReplicas scales a Deployment that was scaled beyond maxReplicas outside the
+// operator, typically by a HorizontalPodAutoscaler, back down to the cap. It returns the
+// replicas the Deployment had and whether they were lowered
+func capLiveReplicas(deployment *appsv1.Deployment, maxReplicas int32) (int32, bool) {
+	if deployment.Spec.Replicas == nil {
+		return 1, false
+	}
+	live := *deployment.Spec.Replicas
+	if capped := capReplicas(live, maxReplicas); capped != live {
+		deployment.Spec.Replicas = &capped
+		return live, true
+	}
+	return live, false
+}
+
+// setReplicasCappedCondition sets the ReplicasCapped condition when the requested replicas
+// exceed the --max-replicas-per-cr cap, and removes it otherwise
+func setReplicasCappedCondition(conditions *[]metav1.Condition, generation int64, requested, maxReplicas int32) {
+	if capReplicas(requested, maxReplicas) == requested {
+		meta.RemoveStatusCondition(conditions, kaosv1alpha1.ConditionReplicasCapped)
+		return
+	}
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               kaosv1alpha1.ConditionReplicasCapped,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             kaosv1alpha1.ReasonMaxReplicasExceeded,
+		Message:            fmt.Sprintf("%d replicas requested, capped at the operator maximum of %d", requested, maxReplicas),
+	})
+}
+
+// specReplicas returns spec.replicas, defaulting to one replica
+func specReplicas(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
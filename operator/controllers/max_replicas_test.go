@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+var _ = Describe("--max-replicas-per-cr", func() {
+	It("should clamp the Deployment replicas to the configured max", func() {
+		replicas := int32(10)
+		modelapi := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{Name: "capped", Namespace: "default", UID: "modelapi-uid", Generation: 1},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode:        kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig: &kaosv1alpha1.ProxyConfig{Models: []string{"openai/gpt-4"}},
+				Replicas:    &replicas,
+			},
+		}
+		r, c := newCachedModelAPIReconciler(modelapi)
+		r.MaxReplicas = 3
+		ctx := context.Background()
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "capped", Namespace: "default"}}
+		deploymentKey := types.NamespacedName{Name: "modelapi-capped", Namespace: "default"}
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		deployment := &appsv1.Deployment{}
+		Expect(c.Get(ctx, deploymentKey, deployment)).To(Succeed())
+		Expect(*deployment.Spec.Replicas).To(Equal(int32(3)))
+		Expect(c.Get(ctx, req.NamespacedName, modelapi)).To(Succeed())
+		cond := meta.FindStatusCondition(modelapi.Status.Conditions, kaosv1alpha1.ConditionReplicasCapped)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.Reason).To(Equal(kaosv1alpha1.ReasonMaxReplicasExceeded))
+		Expect(cond.Message).To(Equal("10 replicas requested, capped at the operator maximum of 3"))
+
+		// Within the cap the spec is applied as-is and the condition goes away
+		replicas = 2
+		modelapi.Spec.Replicas = &replicas
+		modelapi.Generation = 2
+		Expect(c.Update(ctx, modelapi)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, deploymentKey, deployment)).To(Succeed())
+		Expect(*deployment.Spec.Replicas).To(Equal(int32(2)))
+		Expect(c.Get(ctx, req.NamespacedName, modelapi)).To(Succeed())
+		Expect(meta.FindStatusCondition(modelapi.Status.Conditions, kaosv1alpha1.ConditionReplicasCapped)).To(BeNil())
+
+		// An autoscaler that pushes the Deployment past the cap is scaled back down
+		hpa := &autoscalingv2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "capped", Namespace: "default"},
+			Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "modelapi-capped"},
+				MaxReplicas:    8,
+			},
+		}
+		Expect(c.Create(ctx, hpa)).To(Succeed())
+		scaled := int32(8)
+		deployment.Spec.Replicas = &scaled
+		Expect(c.Update(ctx, deployment)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, deploymentKey, deployment)).To(Succeed())
+		Expect(*deployment.Spec.Replicas).To(Equal(int32(3)))
+		Expect(c.Get(ctx, req.NamespacedName, modelapi)).To(Succeed())
+		cond = meta.FindStatusCondition(modelapi.Status.Conditions, kaosv1alpha1.ConditionReplicasCapped)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Message).To(Equal("8 replicas requested, capped at the operator maximum of 3"))
+	})
+
+	It("should leave replicas alone without a cap", func() {
+		Expect(capReplicas(50, 0)).To(Equal(int32(50)))
+		Expect(capReplicas(2, 3)).To(Equal(int32(2)))
+		mcpserver := &kaosv1alpha1.MCPServer{
+			ObjectMeta: metav1.ObjectMeta{Name: "tools", Namespace: "default"},
+			Spec: kaosv1alpha1.MCPServerSpec{
+				Type:   kaosv1alpha1.MCPServerTypePython,
+				Config: kaosv1alpha1.MCPServerConfig{Tools: &kaosv1alpha1.MCPToolsConfig{FromPackage: "mcp-server-calculator"}},
+			},
+		}
+		Expect(*(&MCPServerReconciler{}).constructDeployment(mcpserver).Spec.Replicas).To(Equal(int32(1)))
+	})
+})
@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+// defaultSessionAffinityTimeout keeps a client on the same pod for 3 hours, as Kubernetes does
+const defaultSessionAffinityTimeout int32 = 10800
+
+// sessionAffinity returns the Service session affinity of an MCPServer. A bridged server keeps
+// each SSE session in the pod the client connected to, so its clients stick to one pod for
+// spec.sessionAffinityTimeout seconds; other servers balance every request.
+func sessionAffinity(mcpserver *kaosv1alpha1.MCPServer) (corev1.ServiceAffinity, *corev1.SessionAffinityConfig) {
+	if !stdioBridged(mcpserver) {
+		return corev1.ServiceAffinityNone, nil
+	}
+	timeout := defaultSessionAffinityTimeout
+	if mcpserver.Spec.SessionAffinityTimeout != nil {
+		timeout = *mcpserver.Spec.SessionAffinityTimeout
+	}
+	return corev1.ServiceAffinityClientIP, &corev1.SessionAffinityConfig{
+		ClientIP: &corev1.ClientIPConfig{TimeoutSeconds: &timeout},
+	}
+}
+
+// sessionAffinityChanged reports whether the live Service's session affinity or its timeout
+// differs from the desired one
+func sessionAffinityChanged(live, desired *corev1.Service) bool {
+	liveAffinity := live.Spec.SessionAffinity
+	if liveAffinity == "" {
+		liveAffinity = corev1.ServiceAffinityNone
+	}
+	if liveAffinity != desired.Spec.SessionAffinity {
+		return true
+	}
+	if desired.Spec.SessionAffinity != corev1.ServiceAffinityClientIP {
+		return false
+	}
+	config := live.Spec.SessionAffinityConfig
+	if config == nil || config.ClientIP == nil || config.ClientIP.TimeoutSeconds == nil {
+		return true
+	}
+	return *config.ClientIP.TimeoutSeconds != *desired.Spec.SessionAffinityConfig.ClientIP.TimeoutSeconds
+}
@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+var _ = Describe("spec.sessionAffinityTimeout", func() {
+	newMCPServer := func(bridged bool) *kaosv1alpha1.MCPServer {
+		return &kaosv1alpha1.MCPServer{
+			ObjectMeta: metav1.ObjectMeta{Name: "sse", Namespace: "default"},
+			Spec: kaosv1alpha1.MCPServerSpec{
+				Type: kaosv1alpha1.MCPServerTypePython,
+				Config: kaosv1alpha1.MCPServerConfig{
+					Tools:       &kaosv1alpha1.MCPToolsConfig{FromPackage: "mcp-server-calculator"},
+					StdioBridge: &kaosv1alpha1.StdioBridgeConfig{Enabled: bridged},
+				},
+			},
+		}
+	}
+	clientIPTimeout := func(service *corev1.Service) int32 {
+		Expect(service.Spec.SessionAffinity).To(Equal(corev1.ServiceAffinityClientIP))
+		return *service.Spec.SessionAffinityConfig.ClientIP.TimeoutSeconds
+	}
+
+	It("should set the timeout on the Service of an SSE server, defaulting to 3 hours", func() {
+		r := &MCPServerReconciler{}
+		mcpserver := newMCPServer(true)
+		Expect(clientIPTimeout(r.constructService(mcpserver))).To(Equal(int32(10800)))
+
+		timeout := int32(600)
+		mcpserver.Spec.SessionAffinityTimeout = &timeout
+		Expect(clientIPTimeout(r.constructService(mcpserver))).To(Equal(int32(600)))
+	})
+
+	It("should not pin clients of servers without the stdio bridge", func() {
+		service := (&MCPServerReconciler{}).constructService(newMCPServer(false))
+		Expect(service.Spec.SessionAffinity).To(Equal(corev1.ServiceAffinityNone))
+		Expect(service.Spec.SessionAffinityConfig).To(BeNil())
+	})
+
+	It("should detect a changed affinity or timeout on the live Service", func() {
+		r := &MCPServerReconciler{}
+		desired := r.constructService(newMCPServer(true))
+		Expect(sessionAffinityChanged(desired.DeepCopy(), desired)).To(BeFalse())
+
+		// A Service created without affinity is defaulted to None by the API server
+		live := r.constructService(newMCPServer(false))
+		live.Spec.SessionAffinity = ""
+		Expect(sessionAffinityChanged(live, desired)).To(BeTrue())
+		Expect(sessionAffinityChanged(live, r.constructService(newMCPServer(false)))).To(BeFalse())
+
+		timeout := int32(60)
+		shorter := newMCPServer(true)
+		shorter.Spec.SessionAffinityTimeout = &timeout
+		Expect(sessionAffinityChanged(desired, r.constructService(shorter))).To(BeTrue())
+	})
+})
@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"fmt"
+	"os"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+const (
+	// stdioBridgeContainerName is the name of the stdio-to-SSE bridge sidecar
+	stdioBridgeContainerName = "stdio-bridge"
+
+	// stdioBridgePort is the SSE port of the bridge; the Service targets it when bridged
+	stdioBridgePort int32 = 8080
+
+	// stdioBridgeDir is the shared emptyDir holding the FIFOs that carry the server's stdio
+	stdioBridgeDir = "/bridge"
+
+	// defaultStdioCPULimit and defaultStdioMemoryLimit bound a bridged server container when
+	// neither it nor DEFAULT_STDIO_CPU_LIMIT / DEFAULT_STDIO_MEMORY_LIMIT sets a limit
+	defaultStdioCPULimit    = "500m"
+	defaultStdioMemoryLimit = "512Mi"
+)
+
+// stdioBridgeFIFOs creates the FIFOs if they do not exist yet. Both containers run it, so
+// neither depends on the other starting first; a lost mkfifo race is harmless.
+const stdioBridgeFIFOs = "for f in " + stdioBridgeDir + "/in " + stdioBridgeDir + "/out; do [ -p $f ] || mkfifo $f 2>/dev/null; done"
+
+// stdioBridged reports whether the MCPServer runs behind the stdio-to-SSE bridge
+func stdioBridged(mcpserver *kaosv1alpha1.MCPServer) bool {
+	return mcpserver.Spec.Config.StdioBridge != nil && mcpserver.Spec.Config.StdioBridge.Enabled
+}
+
+// bridgeStdioCommand wraps the server command so that its stdin and stdout are connected
+// to the bridge FIFOs instead of the container's
+func bridgeStdioCommand(command []string) []string {
+	script := stdioBridgeFIFOs + `; exec "$@" < ` + stdioBridgeDir + "/in > " + stdioBridgeDir + "/out"
+	return append([]string{"sh", "-c", script, "mcp-server"}, command...)
+}
+
+// applyStdioResourceDefaults limits the server container of a bridged MCPServer when the
+// user sets no resources for it. Stdio servers spawn subprocesses that can leak, so an
+// unbounded container could grow until it starves the node.
+func applyStdioResourceDefaults(spec *corev1.PodSpec) {
+	for i := range spec.Containers {
+		container := &spec.Containers[i]
+		if container.Name != "mcp-server" || len(container.Resources.Limits) > 0 || len(container.Resources.Requests) > 0 {
+			continue
+		}
+		container.Resources.Limits = corev1.ResourceList{
+			corev1.ResourceCPU:    stdioLimit("DEFAULT_STDIO_CPU_LIMIT", defaultStdioCPULimit),
+			corev1.ResourceMemory: stdioLimit("DEFAULT_STDIO_MEMORY_LIMIT", defaultStdioMemoryLimit),
+		}
+	}
+}
+
+// stdioLimit parses the quantity in the given operator environment variable, falling back
+// to the built-in default when it is unset or invalid
+func stdioLimit(envName, fallback string) resource.Quantity {
+	if quantity, err := resource.ParseQuantity(os.Getenv(envName)); err == nil {
+		return quantity
+	}
+	return resource.MustParse(fallback)
+}
+
+// constructStdioBridgeContainer creates the bridge sidecar. Its stdio child relays the FIFOs,
+// so every SSE session is forwarded to the single server process in the mcp-server container.
+func (r *MCPServerReconciler) constructStdioBridgeContainer(mcpserver *kaosv1alpha1.MCPServer) corev1.Container {
+	image := mcpserver.Spec.Config.StdioBridge.Image
+	if image == "" {
+		image = os.Getenv("DEFAULT_MCP_BRIDGE_IMAGE")
+	}
+	if image == "" {
+		image = "supercorp/supergateway:3.4.0"
+	}
+
+	relay := fmt.Sprintf("sh -c '%s; cat %s/out & exec cat > %s/in'", stdioBridgeFIFOs, stdioBridgeDir, stdioBridgeDir)
+
+	return corev1.Container{
+		Name:            stdioBridgeContainerName,
+		Image:           image,
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		Args: []string{
+			"--stdio", relay,
+			"--outputTransport", "sse",
+			"--port", fmt.Sprintf("%d", stdioBridgePort),
+			"--healthEndpoint", "/healthz",
+		},
+		Ports: []corev1.ContainerPort{
+			{
+				Name:          "sse",
+				ContainerPort: stdioBridgePort,
+				Protocol:      corev1.ProtocolTCP,
+			},
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: "stdio-bridge", MountPath: stdioBridgeDir},
+		},
+		ReadinessProbe: &corev1.Probe{
+			ProbeHandler: corev1.ProbeHandler{
+				HTTPGet: &corev1.HTTPGetAction{
+					Path: "/healthz",
+					Port: intstr.FromInt(int(stdioBridgePort)),
+				},
+			},
+			InitialDelaySeconds: 5,
+			PeriodSeconds:       5,
+			TimeoutSeconds:      3,
+			FailureThreshold:    2,
+		},
+	}
+}
@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+var _ = Describe("stdio resource defaults", func() {
+	newMCPServer := func(bridged bool) *kaosv1alpha1.MCPServer {
+		return &kaosv1alpha1.MCPServer{
+			ObjectMeta: metav1.ObjectMeta{Name: "stdio", Namespace: "default"},
+			Spec: kaosv1alpha1.MCPServerSpec{
+				Type: kaosv1alpha1.MCPServerTypePython,
+				Config: kaosv1alpha1.MCPServerConfig{
+					Tools:       &kaosv1alpha1.MCPToolsConfig{FromPackage: "mcp-server-calculator"},
+					StdioBridge: &kaosv1alpha1.StdioBridgeConfig{Enabled: bridged},
+				},
+			},
+		}
+	}
+	serverResources := func(mcpserver *kaosv1alpha1.MCPServer) corev1.ResourceRequirements {
+		deployment := (&MCPServerReconciler{}).constructDeployment(mcpserver)
+		for _, container := range deployment.Spec.Template.Spec.Containers {
+			if container.Name == "mcp-server" {
+				return container.Resources
+			}
+		}
+		Fail("mcp-server container not found")
+		return corev1.ResourceRequirements{}
+	}
+
+	It("should limit a bridged server that sets no resources", func() {
+		GinkgoT().Setenv("DEFAULT_STDIO_CPU_LIMIT", "")
+		GinkgoT().Setenv("DEFAULT_STDIO_MEMORY_LIMIT", "1Gi")
+
+		resources := serverResources(newMCPServer(true))
+		Expect(resources.Limits.Cpu().Equal(resource.MustParse(defaultStdioCPULimit))).To(BeTrue())
+		Expect(resources.Limits.Memory().Equal(resource.MustParse("1Gi"))).To(BeTrue())
+		Expect(resources.Requests).To(BeEmpty())
+	})
+
+	It("should not limit servers that are not bridged", func() {
+		Expect(serverResources(newMCPServer(false)).Limits).To(BeEmpty())
+	})
+
+	It("should keep the resources the user sets", func() {
+		mcpserver := newMCPServer(true)
+		mcpserver.Spec.PodSpec = &corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name: "mcp-server",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")},
+				},
+			}},
+		}
+
+		resources := serverResources(mcpserver)
+		Expect(resources.Limits).To(BeEmpty())
+		Expect(resources.Requests.Memory().Equal(resource.MustParse("256Mi"))).To(BeTrue())
+	})
+})
@@ -5,19 +5,28 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlbuilder "sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
@@ -32,17 +41,58 @@ type MCPServerReconciler struct {
 	client.Client
 	Log    logr.Logger
 	Scheme *runtime.Scheme
+	// ImageResolver resolves image digests when spec.pinDigest is enabled
+	ImageResolver util.ImageResolver
+	// ToolDiscoverer queries running servers for the tools they advertise
+	ToolDiscoverer util.ToolDiscoverer
+	// ReconcileCache short-circuits reconciles that observe no spec or child changes
+	ReconcileCache *util.ReconcileCache
+	// Recorder records events such as DriftCorrected on the reconciled resources
+	Recorder record.EventRecorder
+	// CircuitBreaker backs off from resources that fail repeatedly at the same generation
+	CircuitBreaker *util.CircuitBreaker
+	// Metrics counts reconciles and stamps the last reconcile timestamp of each resource
+	Metrics *util.ReconcileMetrics
+	// PodTemplatePatch is the platform-wide patch applied last to every generated pod template
+	PodTemplatePatch *util.PodTemplatePatch
+	// MaxReplicas caps the replicas of every generated workload, whatever spec.replicas or a
+	// HorizontalPodAutoscaler ask for; 0 means no cap
+	MaxReplicas int32
+	// Clock stamps status.lastReconcileTime; defaults to the real clock
+	Clock clock.PassiveClock
+	// ConditionHistoryLimit is the number of condition transitions kept in
+	// status.conditionHistory; 0 disables the history
+	ConditionHistoryLimit int
+	// RateLimiter paces the retries of failed reconciles; defaults to controller-runtime's
+	RateLimiter workqueue.TypedRateLimiter[reconcile.Request]
 }
 
+// toolDiscoveryRetryInterval is how long to wait before retrying failed tool discovery, before jitter
+const toolDiscoveryRetryInterval = 30 * time.Second
+
 //+kubebuilder:rbac:groups=kaos.tools,resources=mcpservers,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=kaos.tools,resources=mcpservers/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=kaos.tools,resources=mcpservers/finalizers,verbs=update
 //+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=apps,resources=replicasets,verbs=get;list;watch
+//+kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch;list
+//+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
 //+kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
 func (r *MCPServerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	result, err := r.reconcile(ctx, req)
+	r.CircuitBreaker.Record(req.NamespacedName, err)
+	recordConditionHistory(ctx, r.Client, &kaosv1alpha1.MCPServer{}, req.NamespacedName, r.ConditionHistoryLimit)
+	observeReconcile(ctx, r.Client, r.Metrics, "MCPServer", &kaosv1alpha1.MCPServer{}, req.NamespacedName, err)
+	return result, err
+}
+
+// reconcile performs a single reconcile of the MCPServer; Reconcile feeds its outcome to the circuit breaker
+func (r *MCPServerReconciler) reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := log.FromContext(ctx)
 
 	mcpserver := &kaosv1alpha1.MCPServer{}
@@ -54,7 +104,14 @@ func (r *MCPServerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	// Handle deletion with finalizer
 	if mcpserver.ObjectMeta.DeletionTimestamp != nil {
 		if controllerutil.ContainsFinalizer(mcpserver, mcpServerFinalizerName) {
+			// Integrations registered through the extra-finalizers annotation clean up first
+			if pending := util.PendingExtraFinalizers(mcpserver); len(pending) > 0 {
+				log.Info("Waiting for extra finalizers", "finalizers", pending)
+				return ctrl.Result{}, nil
+			}
 			log.Info("Deleting MCPServer", "name", mcpserver.Name)
+			r.ReconcileCache.Forget(mcpserver)
+			r.CircuitBreaker.Forget(req.NamespacedName)
 			controllerutil.RemoveFinalizer(mcpserver, mcpServerFinalizerName)
 			if err := r.Update(ctx, mcpserver); err != nil {
 				log.Error(err, "failed to remove finalizer")
@@ -64,8 +121,8 @@ func (r *MCPServerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		return ctrl.Result{}, nil
 	}
 
-	// Add finalizer if not present
-	if !controllerutil.ContainsFinalizer(mcpserver, mcpServerFinalizerName) {
+	// Add finalizer, and any extra finalizers registered by integrations, if not present
+	if util.AddExtraFinalizers(mcpserver) || !controllerutil.ContainsFinalizer(mcpserver, mcpServerFinalizerName) {
 		controllerutil.AddFinalizer(mcpserver, mcpServerFinalizerName)
 		if err := r.Update(ctx, mcpserver); err != nil {
 			log.Error(err, "failed to add finalizer")
@@ -83,24 +140,91 @@ func (r *MCPServerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		}
 	}
 
+	// Reconciling again ends the pause recorded when the operator last shut down
+	if meta.RemoveStatusCondition(&mcpserver.Status.Conditions, kaosv1alpha1.ConditionReconcilePaused) {
+		if err := r.Status().Update(ctx, mcpserver); err != nil {
+			log.Error(err, "failed to update status")
+			return ctrl.Result{}, err
+		}
+	}
+
+	// Back off from resources that keep failing at the same generation
+	if circuitOpen(r.CircuitBreaker, mcpserver, &mcpserver.Status.Conditions) {
+		log.Info("circuit open, delaying reconcile", "interval", r.CircuitBreaker.Interval())
+		if err := r.Status().Update(ctx, mcpserver); err != nil {
+			log.Error(err, "failed to update status")
+		}
+		return ctrl.Result{RequeueAfter: util.JitteredRequeue(r.CircuitBreaker.Interval())}, nil
+	}
+
+	// Resolve the tools ConfigMap, whose content is rolled out with the pods
+	toolsConfig, err := r.getToolsConfigMap(ctx, mcpserver)
+	if err != nil && apierrors.IsNotFound(err) {
+		log.Info("tools ConfigMap not found yet", "configmap", mcpserver.Spec.ToolsConfigMapRef.Name)
+		mcpserver.Status.Phase = "Pending"
+		mcpserver.Status.Ready = false
+		mcpserver.Status.Message = fmt.Sprintf("Waiting for tools ConfigMap %s", mcpserver.Spec.ToolsConfigMapRef.Name)
+		setReadyCondition(&mcpserver.Status.Conditions, mcpserver.Generation, kaosv1alpha1.ReasonDependencyNotReady, mcpserver.Status.Message)
+		r.Status().Update(ctx, mcpserver)
+		return ctrl.Result{}, nil
+	} else if err != nil {
+		log.Error(err, "failed to get tools ConfigMap")
+		return ctrl.Result{}, err
+	}
+	var dependencies []metav1.Object
+	if toolsConfig != nil {
+		dependencies = append(dependencies, toolsConfig)
+	}
+	dependencies = append(dependencies, replicaSetFailures(ctx, r.Client, mcpserver.Namespace, "mcpserver-"+mcpserver.Name,
+		map[string]string{"app": "mcpserver", "mcpserver": mcpserver.Name}))
+
+	// Skip rendering when neither the spec, any child nor the tools ConfigMap changed since the last reconcile
+	if fingerprint, ok := observedFingerprint(ctx, r.Client, mcpserver.Namespace, r.childObjects(mcpserver), dependencies...); ok &&
+		r.ReconcileCache.Unchanged(mcpserver, fingerprint) {
+		return ctrl.Result{}, nil
+	}
+
+	// Build desired Deployment, pinning the image digest if requested
+	desiredDeployment := r.constructDeployment(mcpserver)
+	if toolsConfig != nil {
+		setToolsConfigHash(&desiredDeployment.Spec.Template, toolsConfig)
+	}
+	if mcpserver.Spec.PinDigest {
+		resolvedImage, err := util.PinImageDigest(ctx, r.ImageResolver, &desiredDeployment.Spec.Template, "mcp-server", mcpserver.Status.ResolvedImage)
+		if err != nil {
+			log.Error(err, "failed to pin image digest")
+			mcpserver.Status.Phase = "Failed"
+			mcpserver.Status.Message = fmt.Sprintf("Failed to pin image digest: %v", err)
+			setReadyCondition(&mcpserver.Status.Conditions, mcpserver.Generation, kaosv1alpha1.ReasonReconcileFailed, mcpserver.Status.Message)
+			r.Status().Update(ctx, mcpserver)
+			return ctrl.Result{}, err
+		}
+		mcpserver.Status.ResolvedImage = resolvedImage
+	} else {
+		mcpserver.Status.ResolvedImage = ""
+	}
+
 	// Create or update Deployment
 	deployment := &appsv1.Deployment{}
 	deploymentName := fmt.Sprintf("mcpserver-%s", mcpserver.Name)
-	err := r.Get(ctx, types.NamespacedName{Name: deploymentName, Namespace: mcpserver.Namespace}, deployment)
+	requestedReplicas := int32(1)
+	err = r.Get(ctx, types.NamespacedName{Name: deploymentName, Namespace: mcpserver.Namespace}, deployment)
 
 	if err != nil && apierrors.IsNotFound(err) {
 		// Create new Deployment
-		deployment = r.constructDeployment(mcpserver)
+		deployment = desiredDeployment
 		if err := controllerutil.SetControllerReference(mcpserver, deployment, r.Scheme); err != nil {
 			log.Error(err, "failed to set controller reference")
 			return ctrl.Result{}, err
 		}
 
+		util.SetAppliedReplicas(deployment)
 		log.Info("Creating Deployment", "name", deployment.Name)
 		if err := r.Create(ctx, deployment); err != nil {
 			log.Error(err, "failed to create Deployment")
 			mcpserver.Status.Phase = "Failed"
 			mcpserver.Status.Message = fmt.Sprintf("Failed to create Deployment: %v", err)
+			setReadyCondition(&mcpserver.Status.Conditions, mcpserver.Generation, kaosv1alpha1.ReasonReconcileFailed, mcpserver.Status.Message)
 			r.Status().Update(ctx, mcpserver)
 			return ctrl.Result{}, err
 		}
@@ -109,7 +233,6 @@ func (r *MCPServerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		return ctrl.Result{}, err
 	} else {
 		// Deployment exists - check if spec has changed using hash annotation
-		desiredDeployment := r.constructDeployment(mcpserver)
 		currentHash := ""
 		if deployment.Spec.Template.Annotations != nil {
 			currentHash = deployment.Spec.Template.Annotations[util.PodSpecHashAnnotation]
@@ -119,15 +242,37 @@ func (r *MCPServerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 			desiredHash = desiredDeployment.Spec.Template.Annotations[util.PodSpecHashAnnotation]
 		}
 
-		if currentHash != desiredHash {
+		// Propagated owner labels only change metadata, so they never roll the pods
+		labelsChanged := util.PropagateLabels(deployment, mcpserver, util.PropagatedLabelKeys())
+		replicasChanged := replicasOutOfSync(ctx, r.Client, deployment, desiredDeployment)
+		drift := deploymentDrift(deployment, desiredDeployment, replicasChanged)
+		if currentHash != desiredHash || len(drift) > 0 {
 			log.Info("Updating Deployment due to spec change", "name", deployment.Name,
 				"currentHash", currentHash, "desiredHash", desiredHash)
 			// Update the deployment spec to trigger rolling update
 			deployment.Spec.Template = desiredDeployment.Spec.Template
+		}
+		if replicasChanged {
+			log.Info("Scaling Deployment", "name", deployment.Name, "replicas", *desiredDeployment.Spec.Replicas)
+			deployment.Spec.Replicas = desiredDeployment.Spec.Replicas
+			util.SetAppliedReplicas(deployment)
+		}
+		// Replicas scaled beyond the operator maximum, e.g. by a HorizontalPodAutoscaler, are scaled back down
+		liveReplicas, replicasCapped := capLiveReplicas(deployment, r.MaxReplicas)
+		if replicasCapped {
+			log.Info("Capping Deployment replicas", "name", deployment.Name, "replicas", liveReplicas, "max", r.MaxReplicas)
+			requestedReplicas = max(requestedReplicas, liveReplicas)
+		}
+		strategyChanged := rolloutStrategyChanged(deployment.Spec.Strategy, desiredDeployment.Spec.Strategy)
+		if strategyChanged {
+			deployment.Spec.Strategy = desiredDeployment.Spec.Strategy
+		}
+		if currentHash != desiredHash || labelsChanged || replicasChanged || replicasCapped || strategyChanged || len(drift) > 0 {
 			if err := r.Update(ctx, deployment); err != nil {
 				log.Error(err, "failed to update Deployment")
 				return ctrl.Result{}, err
 			}
+			recordDriftCorrected(r.Recorder, mcpserver, deployment, drift)
 		}
 	}
 
@@ -149,16 +294,42 @@ func (r *MCPServerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 			log.Error(err, "failed to create Service")
 			mcpserver.Status.Phase = "Failed"
 			mcpserver.Status.Message = fmt.Sprintf("Failed to create Service: %v", err)
+			setReadyCondition(&mcpserver.Status.Conditions, mcpserver.Generation, kaosv1alpha1.ReasonReconcileFailed, mcpserver.Status.Message)
 			r.Status().Update(ctx, mcpserver)
 			return ctrl.Result{}, err
 		}
 	} else if err != nil {
 		log.Error(err, "failed to get Service")
 		return ctrl.Result{}, err
+	} else {
+		// Service exists - retarget it if the stdio bridge was toggled, or the port was renamed,
+		// and keep its session affinity in line with the bridge
+		desiredService := r.constructService(mcpserver)
+		portChanged := service.Spec.Ports[0].TargetPort != desiredService.Spec.Ports[0].TargetPort ||
+			servicePortRenamed(service.Spec.Ports[0], desiredService.Spec.Ports[0]) ||
+			metricsServicePortChanged(service.Spec.Ports, desiredService.Spec.Ports)
+		if portChanged {
+			log.Info("Updating Service due to port change", "name", service.Name)
+			service.Spec.Ports = desiredService.Spec.Ports
+		}
+		affinityChanged := sessionAffinityChanged(service, desiredService)
+		if affinityChanged {
+			log.Info("Updating Service due to session affinity change", "name", service.Name)
+			service.Spec.SessionAffinity = desiredService.Spec.SessionAffinity
+			service.Spec.SessionAffinityConfig = desiredService.Spec.SessionAffinityConfig
+		}
+		labelsChanged := util.PropagateLabels(service, mcpserver, util.PropagatedLabelKeys())
+		if portChanged || affinityChanged || labelsChanged {
+			if err := r.Update(ctx, service); err != nil {
+				log.Error(err, "failed to update Service")
+				return ctrl.Result{}, err
+			}
+		}
 	}
 
 	// Update status
 	mcpserver.Status.Endpoint = fmt.Sprintf("http://%s.%s.svc.cluster.local:8000", serviceName, mcpserver.Namespace)
+	mcpserver.Status.MetricsEndpoint = mcpServerMetricsEndpoint(mcpserver, serviceName)
 
 	// Create HTTPRoute if Gateway API is enabled
 	timeout := ""
@@ -179,24 +350,99 @@ func (r *MCPServerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 
 	// Copy deployment status for rolling update visibility
 	mcpserver.Status.Deployment = util.CopyDeploymentStatus(deployment)
+	mcpserver.Status.Selector = metav1.FormatLabelSelector(deployment.Spec.Selector)
+	degraded := degradedCondition(mcpserver.Status.Conditions)
+	degradedRequeue := setDegradedCondition(ctx, r.Client, deployment, &mcpserver.Status.Conditions, mcpserver.Generation, log)
+	setReplicasCappedCondition(&mcpserver.Status.Conditions, mcpserver.Generation, requestedReplicas, r.MaxReplicas)
+	recordDegradedTransition(r.Recorder, mcpserver, degraded, mcpserver.Status.Conditions)
 
 	// Check deployment readiness
+	readyReason := kaosv1alpha1.ReasonDeploymentNotReady
 	if deployment.Status.ReadyReplicas > 0 {
 		mcpserver.Status.Ready = true
 		mcpserver.Status.Phase = "Ready"
+		readyReason = kaosv1alpha1.ReasonDeploymentReady
 	} else {
 		mcpserver.Status.Phase = "Pending"
 		mcpserver.Status.Ready = false
 	}
 
 	mcpserver.Status.Message = fmt.Sprintf("Deployment ready replicas: %d/%d", deployment.Status.ReadyReplicas, *deployment.Spec.Replicas)
+	setReadyCondition(&mcpserver.Status.Conditions, mcpserver.Generation, readyReason, mcpserver.Status.Message)
+	mcpserver.Status.LastReconcileTime = reconcileTime(r.Clock)
+
+	// Discover advertised tools once Ready; failures are reported via condition and retried.
+	// Bridged servers are served over SSE, which the Streamable HTTP discovery client does not speak.
+	result := ctrl.Result{}
+	if mcpserver.Status.Ready && !stdioBridged(mcpserver) && !r.discoverTools(ctx, mcpserver, log) {
+		result.RequeueAfter = util.JitteredRequeue(toolDiscoveryRetryInterval)
+	}
+	// Unavailable replicas without a Warning event yet are checked again, bypassing the cache
+	result.RequeueAfter = util.SoonestRequeue(result.RequeueAfter, degradedRequeue)
 
 	if err := r.Status().Update(ctx, mcpserver); err != nil {
 		log.Error(err, "failed to update status")
 		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{}, nil
+	if result.IsZero() {
+		if fingerprint, ok := observedFingerprint(ctx, r.Client, mcpserver.Namespace, r.childObjects(mcpserver), dependencies...); ok {
+			r.ReconcileCache.Record(mcpserver, fingerprint)
+		}
+	}
+
+	return result, nil
+}
+
+// childObjects returns empty named instances of the objects owned by the MCPServer
+func (r *MCPServerReconciler) childObjects(mcpserver *kaosv1alpha1.MCPServer) []client.Object {
+	name := fmt.Sprintf("mcpserver-%s", mcpserver.Name)
+	children := []client.Object{
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: name}},
+	}
+	return append(children, httpRouteChild(gateway.ResourceTypeMCP, mcpserver.Name)...)
+}
+
+// discoverTools queries the server's MCP endpoint and records the advertised tools in status.
+// Results are cached per generation, so the server is only queried again after a spec change.
+// Returns false if discovery failed and should be retried.
+func (r *MCPServerReconciler) discoverTools(ctx context.Context, mcpserver *kaosv1alpha1.MCPServer, log logr.Logger) bool {
+	if r.ToolDiscoverer == nil {
+		return true
+	}
+
+	cond := meta.FindStatusCondition(mcpserver.Status.Conditions, kaosv1alpha1.MCPServerConditionToolsDiscovered)
+	if cond != nil && cond.Status == metav1.ConditionTrue && cond.ObservedGeneration == mcpserver.Generation {
+		return true
+	}
+
+	tools, err := r.ToolDiscoverer.ListTools(ctx, mcpserver.Status.Endpoint)
+	if err != nil {
+		log.Error(err, "failed to discover MCP tools")
+		meta.SetStatusCondition(&mcpserver.Status.Conditions, metav1.Condition{
+			Type:               kaosv1alpha1.MCPServerConditionToolsDiscovered,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: mcpserver.Generation,
+			Reason:             kaosv1alpha1.ReasonDiscoveryFailed,
+			Message:            fmt.Sprintf("Failed to discover tools: %v", err),
+		})
+		return false
+	}
+
+	mcpserver.Status.DiscoveredTools = tools
+	mcpserver.Status.AvailableTools = make([]string, 0, len(tools))
+	for _, tool := range tools {
+		mcpserver.Status.AvailableTools = append(mcpserver.Status.AvailableTools, tool.Name)
+	}
+	meta.SetStatusCondition(&mcpserver.Status.Conditions, metav1.Condition{
+		Type:               kaosv1alpha1.MCPServerConditionToolsDiscovered,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: mcpserver.Generation,
+		Reason:             kaosv1alpha1.ReasonDiscovered,
+		Message:            fmt.Sprintf("Discovered %d tools", len(tools)),
+	})
+	return true
 }
 
 // constructDeployment creates a Deployment for the MCPServer
@@ -206,7 +452,7 @@ func (r *MCPServerReconciler) constructDeployment(mcpserver *kaosv1alpha1.MCPSer
 		"mcpserver": mcpserver.Name,
 	}
 
-	replicas := int32(1)
+	replicas := capReplicas(1, r.MaxReplicas)
 
 	// Construct container based on server type
 	var container corev1.Container
@@ -218,7 +464,20 @@ func (r *MCPServerReconciler) constructDeployment(mcpserver *kaosv1alpha1.MCPSer
 	}
 
 	basePodSpec := corev1.PodSpec{
-		Containers: []corev1.Container{container},
+		Containers:                   []corev1.Container{container},
+		RuntimeClassName:             mcpserver.Spec.RuntimeClassName,
+		Overhead:                     mcpserver.Spec.Overhead,
+		EnableServiceLinks:           util.EnableServiceLinks(mcpserver.Spec.EnableServiceLinks),
+		AutomountServiceAccountToken: util.AutomountServiceAccountToken(mcpserver.Spec.AutomountServiceAccountToken, false),
+	}
+	if stdioBridged(mcpserver) {
+		basePodSpec.Containers = append(basePodSpec.Containers, r.constructStdioBridgeContainer(mcpserver))
+		basePodSpec.Volumes = []corev1.Volume{
+			{Name: "stdio-bridge", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+		}
+	}
+	if ref := mcpserver.Spec.ToolsConfigMapRef; ref != nil {
+		basePodSpec.Volumes = append(basePodSpec.Volumes, toolsConfigVolumeSource(ref))
 	}
 
 	// Apply podSpec override using strategic merge patch if provided
@@ -229,9 +488,9 @@ func (r *MCPServerReconciler) constructDeployment(mcpserver *kaosv1alpha1.MCPSer
 			finalPodSpec = merged
 		}
 	}
-
-	// Compute hash of the pod spec for change detection
-	podSpecHash := util.ComputePodSpecHash(finalPodSpec)
+	if stdioBridged(mcpserver) {
+		applyStdioResourceDefaults(&finalPodSpec)
+	}
 
 	deployment := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
@@ -244,18 +503,30 @@ func (r *MCPServerReconciler) constructDeployment(mcpserver *kaosv1alpha1.MCPSer
 			Selector: &metav1.LabelSelector{
 				MatchLabels: labels,
 			},
+			Strategy: rolloutStrategy(mcpserver),
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels: labels,
-					Annotations: map[string]string{
-						util.PodSpecHashAnnotation: podSpecHash,
-					},
+					Labels:      util.PodLabels(labels, "mcpserver", mcpserver.Name),
+					Annotations: util.MeshInjectionAnnotations(mcpserver.Spec.MeshInjection, os.Getenv("MESH_TYPE")),
 				},
 				Spec: finalPodSpec,
 			},
 		},
 	}
 
+	// A new kaos.agentic/redeploy value rolls the pods without a spec change
+	util.SetRedeployAnnotation(&deployment.Spec.Template, mcpserver)
+
+	// The global pod template patch is applied last, so it takes precedence over podSpec
+	if err := r.PodTemplatePatch.Apply(&deployment.Spec.Template); err != nil {
+		r.Log.Error(err, "unable to apply the global pod template patch", "deployment", deployment.Name)
+	}
+
+	// Compute hash of the pod template for change detection
+	util.SetPodTemplateHash(&deployment.Spec.Template)
+
+	util.PropagateLabels(deployment, mcpserver, util.PropagatedLabelKeys())
+
 	return deployment
 }
 
@@ -297,18 +568,35 @@ func (r *MCPServerReconciler) constructPythonContainer(mcpserver *kaosv1alpha1.M
 			packageName := mcpserver.Spec.Config.Tools.FromPackage
 			moduleName := strings.ReplaceAll(packageName, "-", "_")
 			image = "python:3.12-slim"
+			install := fmt.Sprintf("pip install %s", packageName)
+			if stdioBridged(mcpserver) {
+				// stdout carries the MCP protocol when bridged, so keep installer output off it
+				install += " 1>&2"
+			}
 			command = []string{
 				"sh",
 				"-c",
-				fmt.Sprintf("pip install %s && ( %s || python -m %s )", packageName, packageName, moduleName),
+				fmt.Sprintf("%s && ( %s || python -m %s )", install, packageName, moduleName),
 			}
 		}
 	}
 
+	// Tool definition files mounted from spec.toolsConfigMapRef
+	if mcpserver.Spec.ToolsConfigMapRef != nil {
+		env = append(env, corev1.EnvVar{Name: toolsConfigPathEnvVar, Value: toolsConfigMountPath})
+	}
+
+	// Log level override (takes precedence over a LOG_LEVEL in config.env)
+	env = util.WithLogLevel(env, mcpserver.Spec.LogLevel)
+
+	// Render in a stable order so reordering config.env does not roll the pods
+	env = util.StableEnv(env)
+
 	container := corev1.Container{
 		Name:            "mcp-server",
 		Image:           image,
-		ImagePullPolicy: corev1.PullIfNotPresent,
+		ImagePullPolicy: util.PullPolicy(mcpserver.Spec.ImagePullPolicy, image),
+		WorkingDir:      mcpserver.Spec.WorkingDir,
 		Command:         command,
 		Ports: []corev1.ContainerPort{
 			{
@@ -342,6 +630,27 @@ func (r *MCPServerReconciler) constructPythonContainer(mcpserver *kaosv1alpha1.M
 		},
 	}
 
+	// A stdio server does not listen; the bridge sidecar serves and probes it instead
+	if stdioBridged(mcpserver) {
+		container.Command = bridgeStdioCommand(command)
+		container.Ports = nil
+		container.LivenessProbe = nil
+		container.ReadinessProbe = nil
+		container.VolumeMounts = []corev1.VolumeMount{
+			{Name: "stdio-bridge", MountPath: stdioBridgeDir},
+		}
+	}
+	if port := metricsServicePort(mcpserver); port != nil {
+		container.Ports = append(container.Ports, corev1.ContainerPort{
+			Name: metricsPortName, ContainerPort: port.Port, Protocol: corev1.ProtocolTCP,
+		})
+	}
+	if mcpserver.Spec.ToolsConfigMapRef != nil {
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name: toolsConfigVolume, MountPath: toolsConfigMountPath, ReadOnly: true,
+		})
+	}
+
 	return container
 }
 
@@ -352,6 +661,12 @@ func (r *MCPServerReconciler) constructService(mcpserver *kaosv1alpha1.MCPServer
 		"mcpserver": mcpserver.Name,
 	}
 
+	// Route to the bridge's SSE port when the server only speaks stdio
+	targetPort := int32(8000)
+	if stdioBridged(mcpserver) {
+		targetPort = stdioBridgePort
+	}
+
 	service := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      fmt.Sprintf("mcpserver-%s", mcpserver.Name),
@@ -362,25 +677,44 @@ func (r *MCPServerReconciler) constructService(mcpserver *kaosv1alpha1.MCPServer
 			Type: corev1.ServiceTypeClusterIP,
 			Ports: []corev1.ServicePort{
 				{
-					Name:       "http",
-					Port:       8000,
-					TargetPort: intstr.FromInt(8000),
-					Protocol:   corev1.ProtocolTCP,
+					Name:        servicePortName(mcpserver.Spec.ServicePort),
+					Port:        8000,
+					TargetPort:  intstr.FromInt(int(targetPort)),
+					Protocol:    corev1.ProtocolTCP,
+					AppProtocol: servicePortAppProtocol(mcpserver.Spec.ServicePort, appProtocolHTTP),
 				},
 			},
 			Selector: labels,
 		},
 	}
 
+	if port := metricsServicePort(mcpserver); port != nil {
+		service.Spec.Ports = append(service.Spec.Ports, *port)
+	}
+	service.Spec.SessionAffinity, service.Spec.SessionAffinityConfig = sessionAffinity(mcpserver)
+
+	util.PropagateLabels(service, mcpserver, util.PropagatedLabelKeys())
+
 	return service
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *MCPServerReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// Index MCPServers by their tools ConfigMap, so ConfigMap events are matched to the
+	// MCPServers referencing them without listing the namespace
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &kaosv1alpha1.MCPServer{},
+		toolsConfigMapField, toolsConfigMapName); err != nil {
+		return err
+	}
+
 	builder := ctrl.NewControllerManagedBy(mgr).
+		WithOptions(controller.Options{RateLimiter: r.RateLimiter}).
 		For(&kaosv1alpha1.MCPServer{}).
 		Owns(&appsv1.Deployment{}).
-		Owns(&corev1.Service{})
+		Watches(&appsv1.ReplicaSet{}, replicaSetToOwner(mgr.GetClient(), "MCPServer"), ctrlbuilder.WithPredicates(replicaFailureChanged)).
+		Owns(&corev1.Service{}).
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.toolsConfigMapToMCPServers),
+			ctrlbuilder.WithPredicates(r.referencedToolsConfigMap()))
 
 	if gateway.GetConfig().Enabled {
 		builder = builder.Owns(&gatewayv1.HTTPRoute{})
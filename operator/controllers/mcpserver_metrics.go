@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+// metricsPortName is the name of the container and Service port of spec.metricsPort
+const metricsPortName = "metrics"
+
+// metricsServicePort returns the Service port exposing spec.metricsPort, or nil when unset
+func metricsServicePort(mcpserver *kaosv1alpha1.MCPServer) *corev1.ServicePort {
+	if mcpserver.Spec.MetricsPort == nil {
+		return nil
+	}
+	port := *mcpserver.Spec.MetricsPort
+	appProtocol := appProtocolHTTP
+	return &corev1.ServicePort{
+		Name:        metricsPortName,
+		Port:        port,
+		TargetPort:  intstr.FromInt(int(port)),
+		Protocol:    corev1.ProtocolTCP,
+		AppProtocol: &appProtocol,
+	}
+}
+
+// mcpServerMetricsEndpoint returns the URL of the server's metrics behind its Service, or ""
+// when spec.metricsPort is unset
+func mcpServerMetricsEndpoint(mcpserver *kaosv1alpha1.MCPServer, serviceName string) string {
+	if mcpserver.Spec.MetricsPort == nil {
+		return ""
+	}
+	return fmt.Sprintf("http://%s.%s.svc.cluster.local:%d/metrics", serviceName, mcpserver.Namespace, *mcpserver.Spec.MetricsPort)
+}
+
+// metricsServicePortChanged reports whether the live Service lacks the desired metrics port,
+// still has one that was removed, or exposes it on a different port
+func metricsServicePortChanged(live, desired []corev1.ServicePort) bool {
+	if len(live) != len(desired) {
+		return true
+	}
+	for i := 1; i < len(desired); i++ {
+		if live[i].Name != desired[i].Name || live[i].Port != desired[i].Port || live[i].TargetPort != desired[i].TargetPort {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+var _ = Describe("spec.metricsPort", func() {
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cached", Namespace: "default"}}
+
+	It("should expose the metrics port and report status.metricsEndpoint", func() {
+		r, c := newCachedMCPServerReconciler(nil)
+		ctx := context.Background()
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		mcpserver := &kaosv1alpha1.MCPServer{}
+		Expect(c.Get(ctx, req.NamespacedName, mcpserver)).To(Succeed())
+		Expect(mcpserver.Status.MetricsEndpoint).To(BeEmpty())
+
+		port := int32(9090)
+		mcpserver.Spec.MetricsPort = &port
+		mcpserver.Generation++
+		Expect(c.Update(ctx, mcpserver)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(c.Get(ctx, req.NamespacedName, mcpserver)).To(Succeed())
+		Expect(mcpserver.Status.MetricsEndpoint).To(Equal("http://mcpserver-cached.default.svc.cluster.local:9090/metrics"))
+
+		service := &corev1.Service{}
+		Expect(c.Get(ctx, types.NamespacedName{Name: "mcpserver-cached", Namespace: "default"}, service)).To(Succeed())
+		Expect(service.Spec.Ports).To(HaveLen(2))
+		Expect(service.Spec.Ports[1].Name).To(Equal("metrics"))
+		Expect(service.Spec.Ports[1].Port).To(Equal(int32(9090)))
+		Expect(service.Spec.Ports[1].TargetPort).To(Equal(intstr.FromInt(9090)))
+
+		container := r.constructDeployment(mcpserver).Spec.Template.Spec.Containers[0]
+		Expect(container.Ports).To(ContainElement(corev1.ContainerPort{Name: "metrics", ContainerPort: 9090, Protocol: corev1.ProtocolTCP}))
+
+		// Removing the port drops it from the Service and the status
+		mcpserver.Spec.MetricsPort = nil
+		mcpserver.Generation++
+		Expect(c.Update(ctx, mcpserver)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, req.NamespacedName, mcpserver)).To(Succeed())
+		Expect(mcpserver.Status.MetricsEndpoint).To(BeEmpty())
+		Expect(c.Get(ctx, types.NamespacedName{Name: "mcpserver-cached", Namespace: "default"}, service)).To(Succeed())
+		Expect(service.Spec.Ports).To(HaveLen(1))
+	})
+})
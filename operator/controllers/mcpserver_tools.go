@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+	"github.com/axsaucedo/kaos/operator/pkg/util"
+)
+
+const (
+	// toolsConfigVolume is the volume holding the spec.toolsConfigMapRef ConfigMap
+	toolsConfigVolume = "tools-config"
+	// toolsConfigMountPath is where the tool definition files are mounted in the server
+	toolsConfigMountPath = "/etc/mcp/tools"
+	// toolsConfigPathEnvVar tells the server where to load tool definition files from
+	toolsConfigPathEnvVar = "MCP_TOOLS_CONFIG_PATH"
+	// toolsConfigHashAnnotation records the content hash of the tools ConfigMap on the pod
+	// template, so that editing the ConfigMap rolls the pods
+	toolsConfigHashAnnotation = "kaos.tools/tools-config-hash"
+	// toolsConfigMapField indexes MCPServers by the ConfigMap named in spec.toolsConfigMapRef
+	toolsConfigMapField = "spec.toolsConfigMapRef.name"
+)
+
+// toolsConfigMapName is the toolsConfigMapField index function
+func toolsConfigMapName(obj client.Object) []string {
+	mcpserver, ok := obj.(*kaosv1alpha1.MCPServer)
+	if !ok || mcpserver.Spec.ToolsConfigMapRef == nil || mcpserver.Spec.ToolsConfigMapRef.Name == "" {
+		return nil
+	}
+	return []string{mcpserver.Spec.ToolsConfigMapRef.Name}
+}
+
+// toolsConfigMapUsers returns the MCPServers in the ConfigMap's namespace that reference it
+// in spec.toolsConfigMapRef
+func (r *MCPServerReconciler) toolsConfigMapUsers(ctx context.Context, configmap client.Object) []kaosv1alpha1.MCPServer {
+	mcpservers := &kaosv1alpha1.MCPServerList{}
+	if err := r.List(ctx, mcpservers, client.InNamespace(configmap.GetNamespace()),
+		client.MatchingFields{toolsConfigMapField: configmap.GetName()}); err != nil {
+		return nil
+	}
+	return mcpservers.Items
+}
+
+// toolsConfigMapToMCPServers maps a tools ConfigMap to the MCPServers referencing it
+func (r *MCPServerReconciler) toolsConfigMapToMCPServers(ctx context.Context, obj client.Object) []ctrl.Request {
+	var requests []ctrl.Request
+	for _, mcpserver := range r.toolsConfigMapUsers(ctx, obj) {
+		requests = append(requests, ctrl.Request{
+			NamespacedName: types.NamespacedName{Name: mcpserver.Name, Namespace: mcpserver.Namespace},
+		})
+	}
+	return requests
+}
+
+// referencedToolsConfigMap passes only the events of ConfigMaps an MCPServer references, so
+// changes to the other ConfigMaps of the namespace, such as those of ModelAPIs, are dropped
+// before they reach the map function
+func (r *MCPServerReconciler) referencedToolsConfigMap() predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return len(r.toolsConfigMapUsers(context.Background(), obj)) > 0
+	})
+}
+
+// getToolsConfigMap fetches the ConfigMap referenced by spec.toolsConfigMapRef, or returns
+// nil when none is referenced
+func (r *MCPServerReconciler) getToolsConfigMap(ctx context.Context, mcpserver *kaosv1alpha1.MCPServer) (*corev1.ConfigMap, error) {
+	ref := mcpserver.Spec.ToolsConfigMapRef
+	if ref == nil {
+		return nil, nil
+	}
+	configmap := &corev1.ConfigMap{}
+	if err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: mcpserver.Namespace}, configmap); err != nil {
+		return nil, err
+	}
+	return configmap, nil
+}
+
+// toolsConfigVolumeSource mounts the referenced ConfigMap
+func toolsConfigVolumeSource(ref *corev1.LocalObjectReference) corev1.Volume {
+	return corev1.Volume{
+		Name: toolsConfigVolume,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: *ref},
+		},
+	}
+}
+
+// setToolsConfigHash annotates the pod template with the content hash of the tools ConfigMap
+// and refreshes the pod template hash
+func setToolsConfigHash(template *corev1.PodTemplateSpec, configmap *corev1.ConfigMap) {
+	data, _ := json.Marshal([]interface{}{configmap.Data, configmap.BinaryData})
+	sum := sha256.Sum256(data)
+	if template.Annotations == nil {
+		template.Annotations = map[string]string{}
+	}
+	template.Annotations[toolsConfigHashAnnotation] = hex.EncodeToString(sum[:])[:16]
+	util.SetPodTemplateHash(template)
+}
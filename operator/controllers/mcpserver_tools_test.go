@@ -0,0 +1,132 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+	"github.com/axsaucedo/kaos/operator/pkg/util"
+)
+
+var _ = Describe("MCPServer tools ConfigMap", func() {
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "tools", Namespace: "default"}}
+	deploymentKey := types.NamespacedName{Name: "mcpserver-tools", Namespace: "default"}
+
+	It("should mount the ConfigMap, set the path env var and roll out content changes", func() {
+		scheme := newTestScheme()
+
+		configmap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "tool-defs", Namespace: "default"},
+			Data:       map[string]string{"tools.yaml": "- name: echo\n"},
+		}
+		mcpserver := &kaosv1alpha1.MCPServer{
+			ObjectMeta: metav1.ObjectMeta{Name: "tools", Namespace: "default", UID: "tools-uid", Generation: 1},
+			Spec: kaosv1alpha1.MCPServerSpec{
+				Type:              kaosv1alpha1.MCPServerTypePython,
+				Config:            kaosv1alpha1.MCPServerConfig{Tools: &kaosv1alpha1.MCPToolsConfig{FromPackage: "file-tools-server"}},
+				ToolsConfigMapRef: &corev1.LocalObjectReference{Name: "tool-defs"},
+			},
+		}
+		c := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(mcpserver, configmap).
+			WithStatusSubresource(&kaosv1alpha1.MCPServer{}).
+			Build()
+		r := &MCPServerReconciler{Client: c, Scheme: scheme, ReconcileCache: util.NewReconcileCache()}
+		ctx := context.Background()
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		deployment := &appsv1.Deployment{}
+		Expect(c.Get(ctx, deploymentKey, deployment)).To(Succeed())
+		template := deployment.Spec.Template
+		Expect(template.Spec.Volumes).To(ContainElement(HaveField("VolumeSource.ConfigMap.Name", "tool-defs")))
+		container := template.Spec.Containers[0]
+		Expect(container.VolumeMounts).To(ContainElement(corev1.VolumeMount{
+			Name: toolsConfigVolume, MountPath: "/etc/mcp/tools", ReadOnly: true,
+		}))
+		Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: "MCP_TOOLS_CONFIG_PATH", Value: "/etc/mcp/tools"}))
+		contentHash := template.Annotations[toolsConfigHashAnnotation]
+		podSpecHash := template.Annotations[util.PodSpecHashAnnotation]
+		Expect(contentHash).NotTo(BeEmpty())
+
+		// Editing the ConfigMap changes both hashes, which rolls the pods
+		Expect(c.Get(ctx, types.NamespacedName{Name: "tool-defs", Namespace: "default"}, configmap)).To(Succeed())
+		configmap.Data["tools.yaml"] = "- name: echo\n- name: reverse\n"
+		Expect(c.Update(ctx, configmap)).To(Succeed())
+
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, deploymentKey, deployment)).To(Succeed())
+		Expect(deployment.Spec.Template.Annotations[toolsConfigHashAnnotation]).NotTo(Equal(contentHash))
+		Expect(deployment.Spec.Template.Annotations[util.PodSpecHashAnnotation]).NotTo(Equal(podSpecHash))
+	})
+
+	It("should wait for a missing ConfigMap", func() {
+		scheme := newTestScheme()
+
+		mcpserver := &kaosv1alpha1.MCPServer{
+			ObjectMeta: metav1.ObjectMeta{Name: "tools", Namespace: "default", UID: "tools-uid", Generation: 1},
+			Spec: kaosv1alpha1.MCPServerSpec{
+				Type:              kaosv1alpha1.MCPServerTypePython,
+				Config:            kaosv1alpha1.MCPServerConfig{Tools: &kaosv1alpha1.MCPToolsConfig{FromPackage: "file-tools-server"}},
+				ToolsConfigMapRef: &corev1.LocalObjectReference{Name: "missing"},
+			},
+		}
+		c := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(mcpserver).
+			WithStatusSubresource(&kaosv1alpha1.MCPServer{}).
+			Build()
+		r := &MCPServerReconciler{Client: c, Scheme: scheme}
+		ctx := context.Background()
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, req.NamespacedName, mcpserver)).To(Succeed())
+		Expect(mcpserver.Status.Phase).To(Equal("Pending"))
+		Expect(mcpserver.Status.Message).To(ContainSubstring("missing"))
+		Expect(c.Get(ctx, deploymentKey, &appsv1.Deployment{})).NotTo(Succeed())
+	})
+
+	It("should only pass events of ConfigMaps an MCPServer references", func() {
+		mcpserver := &kaosv1alpha1.MCPServer{
+			ObjectMeta: metav1.ObjectMeta{Name: "tools", Namespace: "default"},
+			Spec: kaosv1alpha1.MCPServerSpec{
+				Type:              kaosv1alpha1.MCPServerTypePython,
+				Config:            kaosv1alpha1.MCPServerConfig{Tools: &kaosv1alpha1.MCPToolsConfig{FromPackage: "file-tools-server"}},
+				ToolsConfigMapRef: &corev1.LocalObjectReference{Name: "tool-defs"},
+			},
+		}
+		c := fake.NewClientBuilder().
+			WithScheme(newTestScheme()).
+			WithObjects(mcpserver).
+			WithIndex(&kaosv1alpha1.MCPServer{}, toolsConfigMapField, toolsConfigMapName).
+			Build()
+		r := &MCPServerReconciler{Client: c}
+		referenced := r.referencedToolsConfigMap()
+
+		toolDefs := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "tool-defs", Namespace: "default"}}
+		Expect(referenced.Generic(event.GenericEvent{Object: toolDefs})).To(BeTrue())
+		Expect(r.toolsConfigMapToMCPServers(context.Background(), toolDefs)).To(ConsistOf(req))
+
+		// Same name in another namespace, and an unrelated ConfigMap such as a ModelAPI's
+		for _, other := range []*corev1.ConfigMap{
+			{ObjectMeta: metav1.ObjectMeta{Name: "tool-defs", Namespace: "team-b"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "litellm-config-llm", Namespace: "default"}},
+		} {
+			Expect(referenced.Generic(event.GenericEvent{Object: other})).To(BeFalse())
+			Expect(r.toolsConfigMapToMCPServers(context.Background(), other)).To(BeEmpty())
+		}
+	})
+})
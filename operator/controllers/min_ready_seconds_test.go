@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+	"github.com/axsaucedo/kaos/operator/pkg/util"
+)
+
+var _ = Describe("spec.minReadySeconds", func() {
+	It("should hold old pods until new ones have been available for the window", func() {
+		minReady := int32(30)
+		modelapi := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "stable", Namespace: "default", UID: "modelapi-uid", Generation: 1,
+				Annotations: map[string]string{util.FeatureGatesAnnotation: "BlueGreenRollout=true"},
+			},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode:            kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig:     &kaosv1alpha1.ProxyConfig{Models: []string{"openai/gpt-4"}},
+				MinReadySeconds: &minReady,
+			},
+		}
+		r, c := newCachedModelAPIReconciler(modelapi)
+		ctx := context.Background()
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "stable", Namespace: "default"}}
+		deploymentKey := types.NamespacedName{Name: "modelapi-stable", Namespace: "default"}
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		deployment := &appsv1.Deployment{}
+		Expect(c.Get(ctx, deploymentKey, deployment)).To(Succeed())
+		Expect(deployment.Spec.MinReadySeconds).To(Equal(int32(30)))
+		// No old pod is removed until a new one is available, i.e. ready for minReadySeconds
+		Expect(deployment.Spec.Strategy.RollingUpdate.MaxUnavailable).To(Equal(&intstr.IntOrString{Type: intstr.Int, IntVal: 0}))
+
+		// A changed window is applied to the existing Deployment
+		Expect(c.Get(ctx, req.NamespacedName, modelapi)).To(Succeed())
+		minReady = 120
+		modelapi.Spec.MinReadySeconds = &minReady
+		modelapi.Generation = 2
+		Expect(c.Update(ctx, modelapi)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, deploymentKey, deployment)).To(Succeed())
+		Expect(deployment.Spec.MinReadySeconds).To(Equal(int32(120)))
+
+		Expect(c.Get(ctx, req.NamespacedName, modelapi)).To(Succeed())
+		modelapi.Spec.MinReadySeconds = nil
+		modelapi.Generation = 3
+		Expect(c.Update(ctx, modelapi)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, deploymentKey, deployment)).To(Succeed())
+		Expect(deployment.Spec.MinReadySeconds).To(BeZero())
+	})
+})
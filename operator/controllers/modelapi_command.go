@@ -0,0 +1,50 @@
+package controllers
+
+import (
+	"fmt"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+	"github.com/axsaucedo/kaos/operator/pkg/util"
+)
+
+// validateHostedCommand reports the first spec.hostedConfig.command or args entry using an
+// unsupported placeholder, for clusters running without the admission webhook
+func validateHostedCommand(modelapi *kaosv1alpha1.ModelAPI) error {
+	hosted := modelapi.Spec.HostedConfig
+	if modelapi.Spec.Mode != kaosv1alpha1.ModelAPIModeHosted || hosted == nil {
+		return nil
+	}
+	for i, value := range hosted.Command {
+		if err := util.ValidateHostedCommandTemplate(value); err != nil {
+			return fmt.Errorf("spec.hostedConfig.command[%d]: %w", i, err)
+		}
+	}
+	for i, value := range hosted.Args {
+		if err := util.ValidateHostedCommandTemplate(value); err != nil {
+			return fmt.Errorf("spec.hostedConfig.args[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// hostedCommand returns the expanded command and args of the model-api container. Without
+// a custom command the image entrypoint runs; entries that fail to expand are kept as
+// written, since validateHostedCommand rejects them before the Deployment is built.
+func hostedCommand(hosted *kaosv1alpha1.HostedConfig) ([]string, []string) {
+	data := util.HostedCommandTemplateData{Model: hosted.Model, ModelsDir: sharedCacheMountPath}
+	expand := func(values []string) []string {
+		if len(values) == 0 {
+			return nil
+		}
+		expanded := make([]string, len(values))
+		for i, value := range values {
+			out, err := util.ExpandHostedCommandTemplate(value, data)
+			if err != nil {
+				out = value
+			}
+			expanded[i] = out
+		}
+		return expanded
+	}
+	return expand(hosted.Command), expand(hosted.Args)
+}
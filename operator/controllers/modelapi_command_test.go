@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+var _ = Describe("Hosted ModelAPI command", func() {
+	var modelapi *kaosv1alpha1.ModelAPI
+
+	BeforeEach(func() {
+		modelapi = &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{Name: "custom", Namespace: "default"},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode: kaosv1alpha1.ModelAPIModeHosted,
+				HostedConfig: &kaosv1alpha1.HostedConfig{
+					Model:   "smollm2:135m",
+					Command: []string{"/bin/sh", "-c"},
+					Args:    []string{"OLLAMA_MODELS={{ .ModelsDir }} ollama serve & ollama run {{ .Model }}; wait"},
+				},
+			},
+		}
+	})
+
+	It("should replace the image entrypoint with the expanded custom command", func() {
+		Expect(validateHostedCommand(modelapi)).To(Succeed())
+		container := (&ModelAPIReconciler{}).constructDeployment(modelapi).Spec.Template.Spec.Containers[0]
+		Expect(container.Name).To(Equal("model-api"))
+		Expect(container.Command).To(Equal([]string{"/bin/sh", "-c"}))
+		Expect(container.Args).To(Equal([]string{"OLLAMA_MODELS=/root/.ollama/models ollama serve & ollama run smollm2:135m; wait"}))
+	})
+
+	It("should keep the image entrypoint when command and args are unset", func() {
+		modelapi.Spec.HostedConfig.Command, modelapi.Spec.HostedConfig.Args = nil, nil
+		container := (&ModelAPIReconciler{}).constructDeployment(modelapi).Spec.Template.Spec.Containers[0]
+		Expect(container.Command).To(BeNil())
+		Expect(container.Args).To(BeEmpty())
+	})
+
+	It("should reject an undefined model path placeholder", func() {
+		modelapi.Spec.HostedConfig.Args = []string{"--model={{ .Model }}", "--path={{ .ModelPath }}"}
+		Expect(validateHostedCommand(modelapi)).To(MatchError(ContainSubstring("spec.hostedConfig.args[1]")))
+	})
+})
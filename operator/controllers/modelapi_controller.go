@@ -2,24 +2,36 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
 	"os"
+	"reflect"
 	"strings"
 
 	"github.com/go-logr/logr"
+	"gopkg.in/yaml.v3"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlbuilder "sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
-	"gopkg.in/yaml.v3"
 
 	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
 	"github.com/axsaucedo/kaos/operator/pkg/gateway"
@@ -33,18 +45,68 @@ type ModelAPIReconciler struct {
 	client.Client
 	Log    logr.Logger
 	Scheme *runtime.Scheme
+	// ImageResolver resolves image digests when spec.pinDigest is enabled
+	ImageResolver util.ImageResolver
+	// ReconcileCache short-circuits reconciles that observe no spec or child changes
+	ReconcileCache *util.ReconcileCache
+	// Recorder records events such as DriftCorrected on the reconciled resources
+	Recorder record.EventRecorder
+	// CircuitBreaker backs off from resources that fail repeatedly at the same generation
+	CircuitBreaker *util.CircuitBreaker
+	// Metrics counts reconciles and stamps the last reconcile timestamp of each resource
+	Metrics *util.ReconcileMetrics
+	// PodTemplatePatch is the platform-wide patch applied last to every generated pod template
+	PodTemplatePatch *util.PodTemplatePatch
+	// MaxReplicas caps the replicas of every generated workload, whatever spec.replicas or a
+	// HorizontalPodAutoscaler ask for; 0 means no cap
+	MaxReplicas int32
+	// Clock stamps status.lastReconcileTime; defaults to the real clock
+	Clock clock.PassiveClock
+	// ConditionHistoryLimit is the number of condition transitions kept in
+	// status.conditionHistory; 0 disables the history
+	ConditionHistoryLimit int
+	// RateLimiter paces the retries of failed reconciles; defaults to controller-runtime's
+	RateLimiter workqueue.TypedRateLimiter[reconcile.Request]
+	// GatewayAPIAvailable is set when the cluster serves the Gateway API HTTPRoute kind,
+	// which spec.httpRoute needs
+	GatewayAPIAvailable bool
+	// KServeAvailable is set when the cluster serves the KServe InferenceService kind, which
+	// spec.backend KServe needs
+	KServeAvailable bool
+	// LegacyGRPCProbes is set when the cluster predates native gRPC probes (Kubernetes 1.24),
+	// so grpc health checks fall back to exec probes
+	LegacyGRPCProbes bool
+	// LegacySleepAction is set when the cluster predates preStop sleep actions (Kubernetes
+	// 1.30), so spec.drainSeconds falls back to an exec sleep
+	LegacySleepAction bool
 }
 
 //+kubebuilder:rbac:groups=kaos.tools,resources=modelapis,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=kaos.tools,resources=modelapis/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=kaos.tools,resources=modelapis/finalizers,verbs=update
+//+kubebuilder:rbac:groups=kaos.tools,resources=agents,verbs=get;list;watch
 //+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=apps,resources=replicasets,verbs=get;list;watch
+//+kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch;list
 //+kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;delete
+//+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=pods/status,verbs=patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
 func (r *ModelAPIReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	result, err := r.reconcile(ctx, req)
+	r.CircuitBreaker.Record(req.NamespacedName, err)
+	recordConditionHistory(ctx, r.Client, &kaosv1alpha1.ModelAPI{}, req.NamespacedName, r.ConditionHistoryLimit)
+	observeReconcile(ctx, r.Client, r.Metrics, "ModelAPI", &kaosv1alpha1.ModelAPI{}, req.NamespacedName, err)
+	return result, err
+}
+
+// reconcile performs a single reconcile of the ModelAPI; Reconcile feeds its outcome to the circuit breaker
+func (r *ModelAPIReconciler) reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := log.FromContext(ctx)
 
 	modelapi := &kaosv1alpha1.ModelAPI{}
@@ -56,8 +118,23 @@ func (r *ModelAPIReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 	// Handle deletion with finalizer
 	if modelapi.ObjectMeta.DeletionTimestamp != nil {
 		if controllerutil.ContainsFinalizer(modelapi, modelAPIFinalizerName) {
+			// Integrations registered through the extra-finalizers annotation clean up first
+			if pending := util.PendingExtraFinalizers(modelapi); len(pending) > 0 {
+				log.Info("Waiting for extra finalizers", "finalizers", pending)
+				return ctrl.Result{}, nil
+			}
 			// Perform cleanup
 			log.Info("Deleting ModelAPI", "name", modelapi.Name)
+			if err := r.deleteSharedCacheClaim(ctx, modelapi, log); err != nil {
+				log.Error(err, "failed to clean up the shared cache claim")
+				return ctrl.Result{}, err
+			}
+			if err := r.restoreMinReplicas(ctx, modelapi); err != nil {
+				log.Error(err, "failed to restore HorizontalPodAutoscaler minReplicas")
+				return ctrl.Result{}, err
+			}
+			r.ReconcileCache.Forget(modelapi)
+			r.CircuitBreaker.Forget(req.NamespacedName)
 			controllerutil.RemoveFinalizer(modelapi, modelAPIFinalizerName)
 			if err := r.Update(ctx, modelapi); err != nil {
 				log.Error(err, "failed to remove finalizer")
@@ -67,8 +144,8 @@ func (r *ModelAPIReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		return ctrl.Result{}, nil
 	}
 
-	// Add finalizer if not present
-	if !controllerutil.ContainsFinalizer(modelapi, modelAPIFinalizerName) {
+	// Add finalizer, and any extra finalizers registered by integrations, if not present
+	if util.AddExtraFinalizers(modelapi) || !controllerutil.ContainsFinalizer(modelapi, modelAPIFinalizerName) {
 		controllerutil.AddFinalizer(modelapi, modelAPIFinalizerName)
 		if err := r.Update(ctx, modelapi); err != nil {
 			log.Error(err, "failed to add finalizer")
@@ -86,10 +163,88 @@ func (r *ModelAPIReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		}
 	}
 
+	// Reconciling again ends the pause recorded when the operator last shut down
+	if meta.RemoveStatusCondition(&modelapi.Status.Conditions, kaosv1alpha1.ConditionReconcilePaused) {
+		if err := r.Status().Update(ctx, modelapi); err != nil {
+			log.Error(err, "failed to update status")
+			return ctrl.Result{}, err
+		}
+	}
+
+	// Back off from resources that keep failing at the same generation
+	if circuitOpen(r.CircuitBreaker, modelapi, &modelapi.Status.Conditions) {
+		log.Info("circuit open, delaying reconcile", "interval", r.CircuitBreaker.Interval())
+		if err := r.Status().Update(ctx, modelapi); err != nil {
+			log.Error(err, "failed to update status")
+		}
+		return ctrl.Result{RequeueAfter: util.JitteredRequeue(r.CircuitBreaker.Interval())}, nil
+	}
+
+	// The surge window is time-based, so it is applied even when nothing else changed
+	surgeRequeue, err := r.reconcileSurge(ctx, modelapi)
+	if err != nil {
+		log.Error(err, "failed to apply spec.surgeReplicas")
+		return ctrl.Result{}, err
+	}
+	// Shared cache claims are neither cached nor watched, so changes to them are polled for
+	scheduledRequeue := util.SoonestRequeue(surgeRequeue, sharedCacheRequeue(modelapi))
+
+	// A ModelAPI that Agents use is never scaled to zero, so a failed lookup must not lift the hold
+	agents, err := referencingAgents(ctx, r.Client, modelapi)
+	if err != nil {
+		log.Error(err, "failed to list referencing Agents")
+		return ctrl.Result{}, err
+	}
+	if err := r.holdReferencedMinReplicas(ctx, modelapi, len(agents) > 0); err != nil {
+		log.Error(err, "failed to hold the minReplicas of referenced ModelAPI")
+		return ctrl.Result{}, err
+	}
+
+	// Pod readiness is not part of the fingerprint, so the serving gate is set on every reconcile
+	if err := r.reconcileServingGate(ctx, modelapi); err != nil {
+		log.Error(err, "failed to set the serving readiness gate of pods")
+		return ctrl.Result{}, err
+	}
+
+	// Skip rendering when neither the spec, any child, the Service endpoints nor the referencing
+	// Agents changed since the last reconcile
+	endpoints := r.modelAPIEndpoints(ctx, modelapi)
+	deps := append(endpointSliceObjects(endpoints), referencesObject(agents),
+		replicaSetFailures(ctx, r.Client, modelapi.Namespace, "modelapi-"+modelapi.Name,
+			map[string]string{"app": "modelapi", "modelapi": modelapi.Name}))
+	deps = append(deps, r.sharedCacheObjects(ctx, modelapi)...)
+	if fingerprint, ok := observedFingerprint(ctx, r.Client, modelapi.Namespace, r.childObjects(modelapi), deps...); ok &&
+		r.ReconcileCache.Unchanged(modelapi, fingerprint) {
+		return ctrl.Result{RequeueAfter: scheduledRequeue}, nil
+	}
+
+	// A spec.mode change recreates the children, and is refused without the migration annotation
+	if err := r.migrateMode(ctx, modelapi, log); err != nil {
+		log.Error(err, "failed to migrate mode")
+		modelapi.Status.Phase = "Failed"
+		modelapi.Status.Message = fmt.Sprintf("Failed to migrate mode: %v", err)
+		setReadyCondition(&modelapi.Status.Conditions, modelapi.Generation, kaosv1alpha1.ReasonReconcileFailed, modelapi.Status.Message)
+		r.Status().Update(ctx, modelapi)
+		if errors.Is(err, errModeChangeNotAllowed) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
 	// Create ConfigMap for Proxy mode - always needed since we use config file mode
 	needsConfigMap := modelapi.Spec.Mode == kaosv1alpha1.ModelAPIModeProxy &&
 		modelapi.Spec.ProxyConfig != nil
 
+	// A custom Hosted command may only reference the supported placeholders
+	if err := validateHostedCommand(modelapi); err != nil {
+		log.Error(err, "command validation failed")
+		modelapi.Status.Phase = "Failed"
+		modelapi.Status.Message = err.Error()
+		setReadyCondition(&modelapi.Status.Conditions, modelapi.Generation, kaosv1alpha1.ReasonReconcileFailed, modelapi.Status.Message)
+		r.Status().Update(ctx, modelapi)
+		return ctrl.Result{}, nil
+	}
+
 	// Validate configYaml against models list if both are provided
 	if needsConfigMap && modelapi.Spec.ProxyConfig.ConfigYaml != nil &&
 		modelapi.Spec.ProxyConfig.ConfigYaml.FromString != "" {
@@ -97,6 +252,7 @@ func (r *ModelAPIReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 			log.Error(err, "configYaml validation failed")
 			modelapi.Status.Phase = "Failed"
 			modelapi.Status.Message = err.Error()
+			setReadyCondition(&modelapi.Status.Conditions, modelapi.Generation, kaosv1alpha1.ReasonReconcileFailed, modelapi.Status.Message)
 			r.Status().Update(ctx, modelapi)
 			return ctrl.Result{}, nil
 		}
@@ -120,6 +276,7 @@ func (r *ModelAPIReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 				log.Error(err, "failed to create ConfigMap")
 				modelapi.Status.Phase = "Failed"
 				modelapi.Status.Message = fmt.Sprintf("Failed to create ConfigMap: %v", err)
+				setReadyCondition(&modelapi.Status.Conditions, modelapi.Generation, kaosv1alpha1.ReasonReconcileFailed, modelapi.Status.Message)
 				r.Status().Update(ctx, modelapi)
 				return ctrl.Result{}, err
 			}
@@ -127,12 +284,14 @@ func (r *ModelAPIReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 			log.Error(err, "failed to get ConfigMap")
 			modelapi.Status.Phase = "Failed"
 			modelapi.Status.Message = fmt.Sprintf("Failed to get ConfigMap: %v", err)
+			setReadyCondition(&modelapi.Status.Conditions, modelapi.Generation, kaosv1alpha1.ReasonReconcileFailed, modelapi.Status.Message)
 			r.Status().Update(ctx, modelapi)
 			return ctrl.Result{}, err
 		} else {
 			// ConfigMap exists - check if it needs updating
 			desiredConfigMap := r.constructConfigMap(modelapi)
-			if configmap.Data["config.yaml"] != desiredConfigMap.Data["config.yaml"] {
+			labelsChanged := util.PropagateLabels(configmap, modelapi, util.PropagatedLabelKeys())
+			if !reflect.DeepEqual(configmap.Data, desiredConfigMap.Data) || labelsChanged {
 				log.Info("Updating ConfigMap", "name", configmap.Name)
 				configmap.Data = desiredConfigMap.Data
 				if err := r.Update(ctx, configmap); err != nil {
@@ -143,24 +302,66 @@ func (r *ModelAPIReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		}
 	}
 
+	// Build desired Deployment, pinning the image digest if requested
+	desiredDeployment := r.constructDeployment(modelapi)
+	if len(agents) > 0 && *desiredDeployment.Spec.Replicas < referencedMinReplicas {
+		replicas := referencedMinReplicas
+		desiredDeployment.Spec.Replicas = &replicas
+	}
+	if modelapi.Spec.PinDigest {
+		resolvedImage, err := util.PinImageDigest(ctx, r.ImageResolver, &desiredDeployment.Spec.Template, "model-api", modelapi.Status.ResolvedImage)
+		if err != nil {
+			log.Error(err, "failed to pin image digest")
+			modelapi.Status.Phase = "Failed"
+			modelapi.Status.Message = fmt.Sprintf("Failed to pin image digest: %v", err)
+			setReadyCondition(&modelapi.Status.Conditions, modelapi.Generation, kaosv1alpha1.ReasonReconcileFailed, modelapi.Status.Message)
+			r.Status().Update(ctx, modelapi)
+			return ctrl.Result{}, err
+		}
+		modelapi.Status.ResolvedImage = resolvedImage
+	} else {
+		modelapi.Status.ResolvedImage = ""
+	}
+
+	// spec.backend KServe runs the pods from an InferenceService where the KServe CRDs are
+	// installed, and falls back to the Deployment otherwise
+	if kserveBackend(modelapi) {
+		if r.KServeAvailable {
+			return r.reconcileInferenceService(ctx, modelapi, desiredDeployment, deps, log)
+		}
+		r.setInferenceServiceCondition(modelapi, metav1.ConditionFalse, kaosv1alpha1.ReasonKServeUnavailable,
+			"the KServe CRDs are not installed, so the pods run from a Deployment")
+	} else {
+		meta.RemoveStatusCondition(&modelapi.Status.Conditions, kaosv1alpha1.ConditionInferenceServiceApplied)
+		if r.KServeAvailable {
+			if err := r.pruneChild(ctx, modelapi, newInferenceService(fmt.Sprintf("modelapi-%s", modelapi.Name), modelapi.Namespace), log); err != nil {
+				log.Error(err, "failed to delete InferenceService of the KServe backend")
+				return ctrl.Result{}, err
+			}
+		}
+	}
+
 	// Create or update Deployment
 	deployment := &appsv1.Deployment{}
 	deploymentName := fmt.Sprintf("modelapi-%s", modelapi.Name)
-	err := r.Get(ctx, types.NamespacedName{Name: deploymentName, Namespace: modelapi.Namespace}, deployment)
+	requestedReplicas := specReplicas(modelapi.Spec.Replicas)
+	err = r.Get(ctx, types.NamespacedName{Name: deploymentName, Namespace: modelapi.Namespace}, deployment)
 
 	if err != nil && apierrors.IsNotFound(err) {
 		// Create new Deployment
-		deployment = r.constructDeployment(modelapi)
+		deployment = desiredDeployment
 		if err := controllerutil.SetControllerReference(modelapi, deployment, r.Scheme); err != nil {
 			log.Error(err, "failed to set controller reference")
 			return ctrl.Result{}, err
 		}
 
+		util.SetAppliedReplicas(deployment)
 		log.Info("Creating Deployment", "name", deployment.Name)
 		if err := r.Create(ctx, deployment); err != nil {
 			log.Error(err, "failed to create Deployment")
 			modelapi.Status.Phase = "Failed"
 			modelapi.Status.Message = fmt.Sprintf("Failed to create Deployment: %v", err)
+			setReadyCondition(&modelapi.Status.Conditions, modelapi.Generation, kaosv1alpha1.ReasonReconcileFailed, modelapi.Status.Message)
 			r.Status().Update(ctx, modelapi)
 			return ctrl.Result{}, err
 		}
@@ -169,7 +370,6 @@ func (r *ModelAPIReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		return ctrl.Result{}, err
 	} else {
 		// Deployment exists - check if spec has changed using hash annotation
-		desiredDeployment := r.constructDeployment(modelapi)
 		currentHash := ""
 		if deployment.Spec.Template.Annotations != nil {
 			currentHash = deployment.Spec.Template.Annotations[util.PodSpecHashAnnotation]
@@ -179,15 +379,62 @@ func (r *ModelAPIReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 			desiredHash = desiredDeployment.Spec.Template.Annotations[util.PodSpecHashAnnotation]
 		}
 
-		if currentHash != desiredHash {
+		// Propagated owner labels only change metadata, so they never roll the pods
+		labelsChanged := util.PropagateLabels(deployment, modelapi, util.PropagatedLabelKeys())
+		replicasChanged := replicasOutOfSync(ctx, r.Client, deployment, desiredDeployment)
+		drift := deploymentDrift(deployment, desiredDeployment, replicasChanged)
+		if currentHash != desiredHash || len(drift) > 0 {
 			log.Info("Updating Deployment due to spec change", "name", deployment.Name,
 				"currentHash", currentHash, "desiredHash", desiredHash)
 			// Update the deployment spec to trigger rolling update
 			deployment.Spec.Template = desiredDeployment.Spec.Template
+		}
+		if replicasChanged {
+			log.Info("Scaling Deployment", "name", deployment.Name, "replicas", *desiredDeployment.Spec.Replicas)
+			deployment.Spec.Replicas = desiredDeployment.Spec.Replicas
+			util.SetAppliedReplicas(deployment)
+		}
+		// Replicas scaled beyond the operator maximum, e.g. by a HorizontalPodAutoscaler, are scaled back down
+		liveReplicas, replicasCapped := capLiveReplicas(deployment, r.MaxReplicas)
+		if replicasCapped {
+			log.Info("Capping Deployment replicas", "name", deployment.Name, "replicas", liveReplicas, "max", r.MaxReplicas)
+			requestedReplicas = max(requestedReplicas, liveReplicas)
+		}
+		// Replicas scaled to zero, e.g. by an event-driven autoscaler, are scaled back up while referenced
+		replicasFloored := len(agents) > 0 && floorLiveReplicas(deployment)
+		if replicasFloored {
+			log.Info("Scaling referenced Deployment back up", "name", deployment.Name, "agents", agents)
+		}
+		historyLimitChanged := revisionHistoryLimitChanged(deployment.Spec.RevisionHistoryLimit, desiredDeployment.Spec.RevisionHistoryLimit)
+		if historyLimitChanged {
+			deployment.Spec.RevisionHistoryLimit = desiredDeployment.Spec.RevisionHistoryLimit
+		}
+		deadlineChanged := progressDeadlineChanged(deployment.Spec.ProgressDeadlineSeconds, desiredDeployment.Spec.ProgressDeadlineSeconds)
+		if deadlineChanged {
+			deployment.Spec.ProgressDeadlineSeconds = desiredDeployment.Spec.ProgressDeadlineSeconds
+		}
+		strategyChanged := rolloutStrategyChanged(deployment.Spec.Strategy, desiredDeployment.Spec.Strategy)
+		if strategyChanged {
+			deployment.Spec.Strategy = desiredDeployment.Spec.Strategy
+		}
+		minReadyChanged := deployment.Spec.MinReadySeconds != desiredDeployment.Spec.MinReadySeconds
+		if minReadyChanged {
+			deployment.Spec.MinReadySeconds = desiredDeployment.Spec.MinReadySeconds
+		}
+		// Deployments created before the mode was recorded get it on their next update
+		modeUnrecorded := deployment.Annotations[modelAPIModeAnnotation] == ""
+		if modeUnrecorded {
+			if deployment.Annotations == nil {
+				deployment.Annotations = map[string]string{}
+			}
+			deployment.Annotations[modelAPIModeAnnotation] = string(modelapi.Spec.Mode)
+		}
+		if currentHash != desiredHash || labelsChanged || replicasChanged || replicasCapped || replicasFloored || historyLimitChanged || deadlineChanged || strategyChanged || minReadyChanged || modeUnrecorded || len(drift) > 0 {
 			if err := r.Update(ctx, deployment); err != nil {
 				log.Error(err, "failed to update Deployment")
 				return ctrl.Result{}, err
 			}
+			recordDriftCorrected(r.Recorder, modelapi, deployment, drift)
 		}
 	}
 
@@ -209,6 +456,7 @@ func (r *ModelAPIReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 			log.Error(err, "failed to create Service")
 			modelapi.Status.Phase = "Failed"
 			modelapi.Status.Message = fmt.Sprintf("Failed to create Service: %v", err)
+			setReadyCondition(&modelapi.Status.Conditions, modelapi.Generation, kaosv1alpha1.ReasonReconcileFailed, modelapi.Status.Message)
 			r.Status().Update(ctx, modelapi)
 			return ctrl.Result{}, err
 		}
@@ -216,15 +464,53 @@ func (r *ModelAPIReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		log.Error(err, "failed to get Service")
 		return ctrl.Result{}, err
 	} else {
-		// Service exists - check if port needs to be updated (mode changed)
+		// Service exists - check if port (mode changed), target port (rate limiting toggled),
+		// port name or appProtocol, type, traffic policy or user annotations need updating
 		desiredService := r.constructService(modelapi)
 		currentPort := service.Spec.Ports[0].Port
 		desiredPort := desiredService.Spec.Ports[0].Port
-
-		if currentPort != desiredPort {
-			log.Info("Updating Service due to port change", "name", service.Name,
-				"currentPort", currentPort, "desiredPort", desiredPort)
+		targetPortChanged := service.Spec.Ports[0].TargetPort != desiredService.Spec.Ports[0].TargetPort
+		portRenamed := servicePortRenamed(service.Spec.Ports[0], desiredService.Spec.Ports[0])
+		specChanged := currentPort != desiredPort || targetPortChanged || portRenamed || service.Spec.Type != desiredService.Spec.Type ||
+			service.Spec.ExternalTrafficPolicy != desiredService.Spec.ExternalTrafficPolicy
+
+		if specChanged {
+			log.Info("Updating Service due to spec change", "name", service.Name,
+				"currentPort", currentPort, "desiredPort", desiredPort,
+				"currentType", service.Spec.Type, "desiredType", desiredService.Spec.Type)
+			// Keep allocated node ports so clients of NodePort/LoadBalancer services are not disrupted
+			if desiredService.Spec.Type != corev1.ServiceTypeClusterIP {
+				for i := range desiredService.Spec.Ports {
+					for _, p := range service.Spec.Ports {
+						if p.Name == desiredService.Spec.Ports[i].Name {
+							desiredService.Spec.Ports[i].NodePort = p.NodePort
+						}
+					}
+				}
+			}
 			service.Spec.Ports = desiredService.Spec.Ports
+			service.Spec.Type = desiredService.Spec.Type
+			service.Spec.ExternalTrafficPolicy = desiredService.Spec.ExternalTrafficPolicy
+			if service.Spec.ExternalTrafficPolicy != corev1.ServiceExternalTrafficPolicyLocal {
+				service.Spec.HealthCheckNodePort = 0
+			}
+		}
+
+		// Merge onto the live annotations so those set by cloud controllers are kept
+		annotationsChanged := util.ApplyUserAnnotations(service, modelapi.Spec.ServiceAnnotations)
+		labelsChanged := util.PropagateLabels(service, modelapi, util.PropagatedLabelKeys())
+		// Services created before the component label was set get it, so their slices are cached
+		if service.Labels[util.ComponentLabel] != "modelapi" {
+			labels := maps.Clone(service.Labels)
+			if labels == nil {
+				labels = map[string]string{}
+			}
+			labels[util.ComponentLabel] = "modelapi"
+			service.Labels = labels
+			labelsChanged = true
+		}
+
+		if specChanged || annotationsChanged || labelsChanged {
 			if err := r.Update(ctx, service); err != nil {
 				log.Error(err, "failed to update Service")
 				return ctrl.Result{}, err
@@ -237,7 +523,7 @@ func (r *ModelAPIReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 	if modelapi.Spec.Mode == kaosv1alpha1.ModelAPIModeHosted {
 		port = 11434
 	}
-	modelapi.Status.Endpoint = fmt.Sprintf("http://%s.%s.svc.cluster.local:%d", serviceName, modelapi.Namespace, port)
+	modelapi.Status.Endpoint = fmt.Sprintf("%s://%s.%s.svc.cluster.local:%d", mtlsScheme(modelapi.Spec.MTLS), serviceName, modelapi.Namespace, port)
 
 	// Create HTTPRoute if Gateway API is enabled
 	timeout := ""
@@ -256,26 +542,80 @@ func (r *ModelAPIReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		log.Error(err, "failed to reconcile HTTPRoute")
 	}
 
+	// Create, update or remove the HTTPRoute of spec.httpRoute
+	if err := r.reconcileModelAPIRoute(ctx, modelapi, serviceName, int32(port), log); err != nil {
+		log.Error(err, "failed to reconcile spec.httpRoute HTTPRoute")
+	}
+
 	// Copy deployment status for rolling update visibility
 	modelapi.Status.Deployment = util.CopyDeploymentStatus(deployment)
-
-	// Check deployment readiness
-	if deployment.Status.ReadyReplicas > 0 {
+	degraded := degradedCondition(modelapi.Status.Conditions)
+	degradedRequeue := setDegradedCondition(ctx, r.Client, deployment, &modelapi.Status.Conditions, modelapi.Generation, log)
+	// Failed model downloads take precedence, as they are the cause of a stuck rollout
+	downloadRequeue := r.setModelDownloadCondition(ctx, modelapi, deployment, log)
+	r.setSharedCacheCondition(ctx, modelapi, log)
+	recordDegradedTransition(r.Recorder, modelapi, degraded, modelapi.Status.Conditions)
+	modelapi.Status.Replicas = deployment.Status.Replicas
+	modelapi.Status.ReadyReplicas = deployment.Status.ReadyReplicas
+	modelapi.Status.Selector = metav1.FormatLabelSelector(deployment.Spec.Selector)
+	setReplicasCappedCondition(&modelapi.Status.Conditions, modelapi.Generation, requestedReplicas, r.MaxReplicas)
+	setMinReplicasHeldCondition(&modelapi.Status.Conditions, modelapi.Generation, agents)
+
+	// Check deployment readiness, and that the Service routes to at least one ready pod,
+	// since a running pod is not necessarily in the Service endpoints yet
+	readyReason := kaosv1alpha1.ReasonDeploymentNotReady
+	modelapi.Status.Message = fmt.Sprintf("Deployment ready replicas: %d/%d", deployment.Status.ReadyReplicas, *deployment.Spec.Replicas)
+	if deployment.Status.ReadyReplicas > 0 && readyEndpoints(endpoints) > 0 {
 		modelapi.Status.Ready = true
 		modelapi.Status.Phase = "Ready"
+		readyReason = kaosv1alpha1.ReasonDeploymentReady
 	} else {
 		modelapi.Status.Phase = "Pending"
 		modelapi.Status.Ready = false
+		if deployment.Status.ReadyReplicas > 0 {
+			readyReason = kaosv1alpha1.ReasonEndpointsNotReady
+			modelapi.Status.Message += fmt.Sprintf(", but Service %s has no ready endpoints", serviceName)
+		}
 	}
 
-	modelapi.Status.Message = fmt.Sprintf("Deployment ready replicas: %d/%d", deployment.Status.ReadyReplicas, *deployment.Spec.Replicas)
+	setReadyCondition(&modelapi.Status.Conditions, modelapi.Generation, readyReason, modelapi.Status.Message)
+	modelapi.Status.LastReconcileTime = reconcileTime(r.Clock)
 
 	if err := r.Status().Update(ctx, modelapi); err != nil {
 		log.Error(err, "failed to update status")
 		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{}, nil
+	// Init container restarts and events do not change any watched object, so pods still
+	// pulling their model, or unavailable without an explanation yet, are checked again on a
+	// full reconcile rather than through the cache
+	requeue := scheduledRequeue
+	if pollRequeue := util.SoonestRequeue(downloadRequeue, degradedRequeue); pollRequeue > 0 {
+		requeue = util.SoonestRequeue(requeue, pollRequeue)
+	} else if fingerprint, ok := observedFingerprint(ctx, r.Client, modelapi.Namespace, r.childObjects(modelapi), deps...); ok {
+		r.ReconcileCache.Record(modelapi, fingerprint)
+	}
+
+	return ctrl.Result{RequeueAfter: requeue}, nil
+}
+
+// childObjects returns empty named instances of the objects owned by the ModelAPI
+func (r *ModelAPIReconciler) childObjects(modelapi *kaosv1alpha1.ModelAPI) []client.Object {
+	name := fmt.Sprintf("modelapi-%s", modelapi.Name)
+	children := []client.Object{
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: name}},
+	}
+	if kserveBackend(modelapi) && r.KServeAvailable {
+		children = []client.Object{newInferenceService(name, "")}
+	}
+	if modelapi.Spec.Mode == kaosv1alpha1.ModelAPIModeProxy && modelapi.Spec.ProxyConfig != nil {
+		children = append(children, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("litellm-config-%s", modelapi.Name)}})
+	}
+	if modelapi.Spec.HTTPRoute != nil && r.GatewayAPIAvailable {
+		children = append(children, &gatewayv1.HTTPRoute{ObjectMeta: metav1.ObjectMeta{Name: modelAPIRouteName(modelapi)}})
+	}
+	return append(children, httpRouteChild(gateway.ResourceTypeModelAPI, modelapi.Name)...)
 }
 
 // constructDeployment creates a Deployment for the ModelAPI
@@ -285,7 +625,7 @@ func (r *ModelAPIReconciler) constructDeployment(modelapi *kaosv1alpha1.ModelAPI
 		"modelapi": modelapi.Name,
 	}
 
-	replicas := int32(1)
+	replicas := capReplicas(specReplicas(modelapi.Spec.Replicas), r.MaxReplicas)
 
 	// Build volumes list - add litellm-config for Proxy mode (always uses config file)
 	volumes := []corev1.Volume{}
@@ -308,7 +648,20 @@ func (r *ModelAPIReconciler) constructDeployment(modelapi *kaosv1alpha1.ModelAPI
 	if ollamaImage == "" {
 		ollamaImage = "alpine/ollama:latest"
 	}
-	if modelapi.Spec.Mode == kaosv1alpha1.ModelAPIModeHosted && modelapi.Spec.HostedConfig != nil && modelapi.Spec.HostedConfig.Model != "" {
+	if claim := sharedCacheClaim(modelapi); claim != "" {
+		// Models come pre-populated from the shared cache, so there is nothing to pull
+		volumes = append(volumes, corev1.Volume{
+			Name: "ollama-data",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		}, corev1.Volume{
+			Name: sharedCacheVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claim, ReadOnly: true},
+			},
+		})
+	} else if modelapi.Spec.Mode == kaosv1alpha1.ModelAPIModeHosted && modelapi.Spec.HostedConfig != nil && modelapi.Spec.HostedConfig.Model != "" {
 		// Init container starts Ollama server, pulls model, then exits
 		// The model is stored in the emptyDir volume shared with main container
 		volumes = append(volumes, corev1.Volume{
@@ -320,7 +673,7 @@ func (r *ModelAPIReconciler) constructDeployment(modelapi *kaosv1alpha1.ModelAPI
 		initContainers = append(initContainers, corev1.Container{
 			Name:            "pull-model",
 			Image:           ollamaImage,
-			ImagePullPolicy: corev1.PullIfNotPresent,
+			ImagePullPolicy: util.PullPolicy(modelapi.Spec.ImagePullPolicy, ollamaImage),
 			Command:         []string{"/bin/sh", "-c"},
 			Args: []string{
 				fmt.Sprintf("ollama serve & OLLAMA_PID=$! && sleep 5 && ollama pull %s && kill $OLLAMA_PID", modelapi.Spec.HostedConfig.Model),
@@ -331,13 +684,29 @@ func (r *ModelAPIReconciler) constructDeployment(modelapi *kaosv1alpha1.ModelAPI
 		})
 	}
 
+	containers := []corev1.Container{
+		r.constructContainer(modelapi),
+	}
+	if rateLimited(modelapi) {
+		containers = append(containers, r.constructRateLimiterContainer(modelapi))
+	}
+
 	basePodSpec := corev1.PodSpec{
-		InitContainers: initContainers,
-		Containers: []corev1.Container{
-			r.constructContainer(modelapi),
-		},
-		Volumes: volumes,
+		InitContainers:               initContainers,
+		Containers:                   containers,
+		Volumes:                      volumes,
+		HostAliases:                  modelapi.Spec.HostAliases,
+		RuntimeClassName:             modelapi.Spec.RuntimeClassName,
+		Overhead:                     modelapi.Spec.Overhead,
+		SchedulerName:                modelapi.Spec.SchedulerName,
+		NodeSelector:                 util.ArchitectureNodeSelector(modelapi.Spec.Architecture),
+		EnableServiceLinks:           util.EnableServiceLinks(modelapi.Spec.EnableServiceLinks),
+		AutomountServiceAccountToken: util.AutomountServiceAccountToken(modelapi.Spec.AutomountServiceAccountToken, false),
 	}
+	mountMTLSSecret(&basePodSpec, "model-api", modelapi.Spec.MTLS)
+
+	// Keep pods serving while they are removed from the Service endpoints
+	r.applyDrain(&basePodSpec, modelapi)
 
 	// Apply podSpec override using strategic merge patch if provided
 	finalPodSpec := basePodSpec
@@ -348,39 +717,80 @@ func (r *ModelAPIReconciler) constructDeployment(modelapi *kaosv1alpha1.ModelAPI
 		}
 	}
 
-	// Compute hash of the pod spec for change detection
-	podSpecHash := util.ComputePodSpecHash(finalPodSpec)
+	// Spread replicas across nodes unless the user provided their own affinity
+	if replicas > 1 && finalPodSpec.Affinity == nil && (modelapi.Spec.SpreadReplicas == nil || *modelapi.Spec.SpreadReplicas) {
+		finalPodSpec.Affinity = spreadAffinity(labels)
+	}
+
+	// GPU pods without an explicit runtime class use the operator default, if configured
+	if finalPodSpec.RuntimeClassName == nil && util.RequestsGPU(finalPodSpec) {
+		if gpuRuntimeClass := os.Getenv("DEFAULT_GPU_RUNTIME_CLASS"); gpuRuntimeClass != "" {
+			finalPodSpec.RuntimeClassName = &gpuRuntimeClass
+		}
+	}
 
 	deployment := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      fmt.Sprintf("modelapi-%s", modelapi.Name),
-			Namespace: modelapi.Namespace,
-			Labels:    labels,
+			Name:        fmt.Sprintf("modelapi-%s", modelapi.Name),
+			Namespace:   modelapi.Namespace,
+			Labels:      labels,
+			Annotations: map[string]string{modelAPIModeAnnotation: string(modelapi.Spec.Mode)},
 		},
 		Spec: appsv1.DeploymentSpec{
-			Replicas: &replicas,
+			Replicas:                &replicas,
+			RevisionHistoryLimit:    revisionHistoryLimit(modelapi.Spec.RevisionHistoryLimit),
+			ProgressDeadlineSeconds: progressDeadlineSeconds(modelapi.Spec.ProgressDeadlineSeconds),
+			MinReadySeconds:         minReadySeconds(modelapi.Spec.MinReadySeconds),
+			Strategy:                rolloutStrategy(modelapi),
 			Selector: &metav1.LabelSelector{
 				MatchLabels: labels,
 			},
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels: labels,
-					Annotations: map[string]string{
-						util.PodSpecHashAnnotation: podSpecHash,
-					},
+					Labels:      util.PodLabels(labels, "modelapi", modelapi.Name),
+					Annotations: util.MeshInjectionAnnotations(modelapi.Spec.MeshInjection, os.Getenv("MESH_TYPE")),
 				},
 				Spec: finalPodSpec,
 			},
 		},
 	}
 
+	// A new kaos.agentic/redeploy value rolls the pods without a spec change
+	util.SetRedeployAnnotation(&deployment.Spec.Template, modelapi)
+
+	// The global pod template patch is applied last, so it takes precedence over podSpec
+	if err := r.PodTemplatePatch.Apply(&deployment.Spec.Template); err != nil {
+		r.Log.Error(err, "unable to apply the global pod template patch", "deployment", deployment.Name)
+	}
+
+	// Compute hash of the pod template for change detection
+	util.SetPodTemplateHash(&deployment.Spec.Template)
+
+	util.PropagateLabels(deployment, modelapi, util.PropagatedLabelKeys())
+
 	return deployment
 }
 
+// spreadAffinity prefers scheduling pods matching the labels on different nodes, so that a
+// single node failure does not take down every replica
+func spreadAffinity(labels map[string]string) *corev1.Affinity {
+	return &corev1.Affinity{
+		PodAntiAffinity: &corev1.PodAntiAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{{
+				Weight: 100,
+				PodAffinityTerm: corev1.PodAffinityTerm{
+					LabelSelector: &metav1.LabelSelector{MatchLabels: labels},
+					TopologyKey:   corev1.LabelHostname,
+				},
+			}},
+		},
+	}
+}
+
 // constructContainer creates the container spec based on ModelAPI mode
 func (r *ModelAPIReconciler) constructContainer(modelapi *kaosv1alpha1.ModelAPI) corev1.Container {
 	var image string
-	var args []string
+	var command, args []string
 	var env []corev1.EnvVar
 	var port int32 = 8000
 	var healthPath string = "/health"
@@ -400,6 +810,7 @@ func (r *ModelAPIReconciler) constructContainer(modelapi *kaosv1alpha1.ModelAPI)
 		// - User provides configYaml → use their config directly
 		// - User provides apiBase → generate wildcard config to forward all requests
 		args = []string{"--config", "/etc/litellm/config.yaml", "--port", "8000"}
+		args = append(args, litellmTLSArgs(modelapi.Spec.MTLS)...)
 
 		// Add PROXY_API_BASE env var if apiBase is configured
 		if modelapi.Spec.ProxyConfig != nil && modelapi.Spec.ProxyConfig.APIBase != "" {
@@ -409,6 +820,21 @@ func (r *ModelAPIReconciler) constructContainer(modelapi *kaosv1alpha1.ModelAPI)
 			})
 		}
 
+		// Add the failover upstreams in priority order, plus one env var per upstream for the config
+		if modelapi.Spec.ProxyConfig != nil && len(modelapi.Spec.ProxyConfig.Upstreams) > 0 {
+			upstreams := modelapi.Spec.ProxyConfig.Upstreams
+			env = append(env, corev1.EnvVar{
+				Name:  "PROXY_API_BASES",
+				Value: strings.Join(upstreams, ","),
+			})
+			for i, upstream := range upstreams {
+				env = append(env, corev1.EnvVar{
+					Name:  upstreamEnvVar(i),
+					Value: upstream,
+				})
+			}
+		}
+
 		// Add PROXY_API_KEY env var if apiKey is configured
 		if modelapi.Spec.ProxyConfig != nil && modelapi.Spec.ProxyConfig.APIKey != nil {
 			apiKey := modelapi.Spec.ProxyConfig.APIKey
@@ -469,9 +895,22 @@ func (r *ModelAPIReconciler) constructContainer(modelapi *kaosv1alpha1.ModelAPI)
 		// Add user-provided env vars for hosted
 		if modelapi.Spec.HostedConfig != nil {
 			env = append(env, modelapi.Spec.HostedConfig.Env...)
+			command, args = hostedCommand(modelapi.Spec.HostedConfig)
 		}
 	}
 
+	// Certificate files of the spec.mtls Secret
+	env = append(env, mtlsEnvVars(modelapi.Spec.MTLS)...)
+
+	// Provider-specific spec.extra options
+	env = append(env, extraEnvVars(modelapi.Spec.Extra)...)
+
+	// Log level override (takes precedence over a user-provided LOG_LEVEL)
+	env = util.WithLogLevel(env, modelapi.Spec.LogLevel)
+
+	// Render in a stable order so reordering user env does not roll the pods
+	env = util.StableEnv(env)
+
 	// Build volume mounts - add litellm-config for Proxy mode (always uses config file)
 	volumeMounts := []corev1.VolumeMount{}
 	if modelapi.Spec.Mode == kaosv1alpha1.ModelAPIModeProxy && modelapi.Spec.ProxyConfig != nil {
@@ -487,11 +926,20 @@ func (r *ModelAPIReconciler) constructContainer(modelapi *kaosv1alpha1.ModelAPI)
 			MountPath: "/root/.ollama",
 		})
 	}
+	if sharedCacheClaim(modelapi) != "" {
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      sharedCacheVolumeName,
+			MountPath: sharedCacheMountPath,
+			ReadOnly:  true,
+		})
+	}
 
 	container := corev1.Container{
 		Name:            "model-api",
 		Image:           image,
-		ImagePullPolicy: corev1.PullIfNotPresent,
+		ImagePullPolicy: util.PullPolicy(modelapi.Spec.ImagePullPolicy, image),
+		WorkingDir:      modelapi.Spec.WorkingDir,
+		Command:         command,
 		Args:            args,
 		Ports: []corev1.ContainerPort{
 			{
@@ -503,41 +951,34 @@ func (r *ModelAPIReconciler) constructContainer(modelapi *kaosv1alpha1.ModelAPI)
 		Env:          env,
 		VolumeMounts: volumeMounts,
 		LivenessProbe: &corev1.Probe{
-			ProbeHandler: corev1.ProbeHandler{
-				HTTPGet: &corev1.HTTPGetAction{
-					Path:   healthPath,
-					Port:   intstr.FromInt(int(port)),
-					Scheme: corev1.URISchemeHTTP,
-				},
-			},
-			InitialDelaySeconds: 30,
-			PeriodSeconds:       10,
-			TimeoutSeconds:      5,
+			ProbeHandler:        r.probeHandler(modelapi, healthPath, port),
+			InitialDelaySeconds: util.ModelAPILivenessTimings.InitialDelaySeconds,
+			PeriodSeconds:       util.ModelAPILivenessTimings.PeriodSeconds,
+			TimeoutSeconds:      util.ModelAPILivenessTimings.TimeoutSeconds,
 			FailureThreshold:    3,
 		},
 		ReadinessProbe: &corev1.Probe{
-			ProbeHandler: corev1.ProbeHandler{
-				HTTPGet: &corev1.HTTPGetAction{
-					Path:   healthPath,
-					Port:   intstr.FromInt(int(port)),
-					Scheme: corev1.URISchemeHTTP,
-				},
-			},
-			InitialDelaySeconds: 15,
-			PeriodSeconds:       5,
-			TimeoutSeconds:      5,
+			ProbeHandler:        r.probeHandler(modelapi, healthPath, port),
+			InitialDelaySeconds: util.ModelAPIReadinessTimings.InitialDelaySeconds,
+			PeriodSeconds:       util.ModelAPIReadinessTimings.PeriodSeconds,
+			TimeoutSeconds:      util.ModelAPIReadinessTimings.TimeoutSeconds,
 			FailureThreshold:    3,
 		},
 	}
+	applyProbeTimings(container.LivenessProbe, modelapi.Spec.HealthCheck)
+	applyProbeTimings(container.ReadinessProbe, modelapi.Spec.HealthCheck)
 
 	return container
 }
 
 // constructService creates a Service for the ModelAPI
 func (r *ModelAPIReconciler) constructService(modelapi *kaosv1alpha1.ModelAPI) *corev1.Service {
+	// The EndpointSlices of the Service inherit its labels, and the manager only caches the
+	// slices carrying the component label
 	labels := map[string]string{
-		"app":      "modelapi",
-		"modelapi": modelapi.Name,
+		"app":               "modelapi",
+		"modelapi":          modelapi.Name,
+		util.ComponentLabel: "modelapi",
 	}
 
 	// Use different ports based on mode
@@ -547,6 +988,24 @@ func (r *ModelAPIReconciler) constructService(modelapi *kaosv1alpha1.ModelAPI) *
 		port = 11434
 		targetPort = 11434
 	}
+	// Route through the rate-limiting sidecar when one is configured
+	if rateLimited(modelapi) {
+		targetPort = rateLimiterPort
+	}
+
+	serviceType := corev1.ServiceTypeClusterIP
+	if modelapi.Spec.ServiceType != "" {
+		serviceType = modelapi.Spec.ServiceType
+	}
+
+	// External traffic policy only applies to NodePort and LoadBalancer services
+	var trafficPolicy corev1.ServiceExternalTrafficPolicy
+	if serviceType != corev1.ServiceTypeClusterIP {
+		trafficPolicy = corev1.ServiceExternalTrafficPolicyCluster
+		if modelapi.Spec.ExternalTrafficPolicy != "" {
+			trafficPolicy = modelapi.Spec.ExternalTrafficPolicy
+		}
+	}
 
 	service := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
@@ -555,18 +1014,23 @@ func (r *ModelAPIReconciler) constructService(modelapi *kaosv1alpha1.ModelAPI) *
 			Labels:    labels,
 		},
 		Spec: corev1.ServiceSpec{
-			Type: corev1.ServiceTypeClusterIP,
+			Type:                  serviceType,
+			ExternalTrafficPolicy: trafficPolicy,
 			Ports: []corev1.ServicePort{
 				{
-					Name:       "http",
-					Port:       port,
-					TargetPort: intstr.FromInt(int(targetPort)),
-					Protocol:   corev1.ProtocolTCP,
+					Name:        servicePortName(modelapi.Spec.ServicePort),
+					Port:        port,
+					TargetPort:  intstr.FromInt(int(targetPort)),
+					Protocol:    corev1.ProtocolTCP,
+					AppProtocol: servicePortAppProtocol(modelapi.Spec.ServicePort, modelAPIAppProtocol(modelapi)),
 				},
 			},
 			Selector: labels,
 		},
 	}
+	util.ApplyUserAnnotations(service, modelapi.Spec.ServiceAnnotations)
+
+	util.PropagateLabels(service, modelapi, util.PropagatedLabelKeys())
 
 	return service
 }
@@ -600,10 +1064,20 @@ func (r *ModelAPIReconciler) constructConfigMap(modelapi *kaosv1alpha1.ModelAPI)
 			"config.yaml": configYaml,
 		},
 	}
+	if rateLimited(modelapi) {
+		configmap.Data[rateLimiterTemplateKey] = rateLimiterTemplate
+	}
+
+	util.PropagateLabels(configmap, modelapi, util.PropagatedLabelKeys())
 
 	return configmap
 }
 
+// upstreamEnvVar names the env var holding the i-th (0-based) failover upstream
+func upstreamEnvVar(i int) string {
+	return fmt.Sprintf("PROXY_API_BASE_%d", i+1)
+}
+
 // generateLiteLLMConfig creates LiteLLM config YAML from ProxyConfig
 // The `provider` field determines how models are routed:
 // - With provider: model_name: "<model>" → model: "<provider>/<model>"
@@ -636,6 +1110,23 @@ func (r *ModelAPIReconciler) generateLiteLLMConfig(proxyConfig *kaosv1alpha1.Pro
 		}
 		sb.WriteString(fmt.Sprintf("      model: \"%s\"\n", litellmModel))
 
+		// Failover: one deployment per upstream, tried in ascending order
+		if len(proxyConfig.Upstreams) > 0 {
+			for i := range proxyConfig.Upstreams {
+				if i > 0 {
+					sb.WriteString(fmt.Sprintf("  - model_name: \"%s\"\n", model))
+					sb.WriteString("    litellm_params:\n")
+					sb.WriteString(fmt.Sprintf("      model: \"%s\"\n", litellmModel))
+				}
+				sb.WriteString(fmt.Sprintf("      api_base: \"os.environ/%s\"\n", upstreamEnvVar(i)))
+				if proxyConfig.APIKey != nil {
+					sb.WriteString("      api_key: \"os.environ/PROXY_API_KEY\"\n")
+				}
+				sb.WriteString(fmt.Sprintf("      order: %d\n", i+1))
+			}
+			continue
+		}
+
 		// Add api_base if configured
 		if proxyConfig.APIBase != "" {
 			sb.WriteString("      api_base: \"os.environ/PROXY_API_BASE\"\n")
@@ -650,20 +1141,35 @@ func (r *ModelAPIReconciler) generateLiteLLMConfig(proxyConfig *kaosv1alpha1.Pro
 	sb.WriteString("\nlitellm_settings:\n")
 	sb.WriteString("  drop_params: true\n")
 
+	// Deployment order is only honoured with pre-call checks enabled
+	if len(proxyConfig.Upstreams) > 0 {
+		sb.WriteString("\nrouter_settings:\n")
+		sb.WriteString("  enable_pre_call_checks: true\n")
+	}
+
 	return sb.String()
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ModelAPIReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	builder := ctrl.NewControllerManagedBy(mgr).
+		WithOptions(controller.Options{RateLimiter: r.RateLimiter}).
 		For(&kaosv1alpha1.ModelAPI{}).
 		Owns(&appsv1.Deployment{}).
+		Watches(&appsv1.ReplicaSet{}, replicaSetToOwner(mgr.GetClient(), "ModelAPI"), ctrlbuilder.WithPredicates(replicaFailureChanged)).
 		Owns(&corev1.Service{}).
-		Owns(&corev1.ConfigMap{})
+		Owns(&corev1.ConfigMap{}).
+		Watches(&discoveryv1.EndpointSlice{}, handler.EnqueueRequestsFromMapFunc(endpointSliceToModelAPI)).
+		Watches(&kaosv1alpha1.Agent{}, handler.EnqueueRequestsFromMapFunc(agentToModelAPI)).
+		Watches(&corev1.Pod{}, handler.EnqueueRequestsFromMapFunc(modelAPIForPod), ctrlbuilder.WithPredicates(servingGatePodChanged))
 
-	if gateway.GetConfig().Enabled {
+	// spec.httpRoute routes need the watch too, but only where the CRDs are installed
+	if gateway.GetConfig().Enabled || r.GatewayAPIAvailable {
 		builder = builder.Owns(&gatewayv1.HTTPRoute{})
 	}
+	if r.KServeAvailable {
+		builder = builder.Owns(newInferenceService("", ""))
+	}
 
 	return builder.Complete(r)
 }
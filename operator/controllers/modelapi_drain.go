@@ -0,0 +1,180 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+// ServingReadinessGate is the pod readiness gate of draining ModelAPI pods. The operator sets
+// it True on live pods and False as soon as a pod starts terminating, so the pod leaves the
+// Service endpoints, including the serving endpoints of terminating pods, before the drain.
+const ServingReadinessGate corev1.PodConditionType = "kaos.tools/serving"
+
+// drainTerminationBuffer is added to the drain so the container still has time to shut down
+// gracefully after the preStop hook, matching the Kubernetes default grace period
+const drainTerminationBuffer int64 = 30
+
+// applyDrain adds the preStop drain and the serving readiness gate to the ModelAPI pods when
+// spec.drainSeconds is set. Containers whose podSpec override sets a preStop hook keep it.
+func (r *ModelAPIReconciler) applyDrain(podSpec *corev1.PodSpec, modelapi *kaosv1alpha1.ModelAPI) {
+	drain := drainSeconds(modelapi)
+	if drain == 0 {
+		return
+	}
+	gracePeriod := int64(drain) + drainTerminationBuffer
+	podSpec.TerminationGracePeriodSeconds = &gracePeriod
+	podSpec.ReadinessGates = append(podSpec.ReadinessGates, corev1.PodReadinessGate{ConditionType: ServingReadinessGate})
+	for i := range podSpec.Containers {
+		if !hasUserPreStop(modelapi, podSpec.Containers[i].Name) {
+			podSpec.Containers[i].Lifecycle = drainLifecycle(drain, r.LegacySleepAction)
+		}
+	}
+}
+
+// drainSeconds returns the configured preStop drain, or 0 when draining is disabled
+func drainSeconds(modelapi *kaosv1alpha1.ModelAPI) int32 {
+	if modelapi.Spec.DrainSeconds != nil {
+		return *modelapi.Spec.DrainSeconds
+	}
+	return 0
+}
+
+// hasUserPreStop reports whether the podSpec override sets a preStop hook on the named container
+func hasUserPreStop(modelapi *kaosv1alpha1.ModelAPI, name string) bool {
+	if modelapi.Spec.PodSpec == nil {
+		return false
+	}
+	for _, c := range modelapi.Spec.PodSpec.Containers {
+		if c.Name == name && c.Lifecycle != nil && c.Lifecycle.PreStop != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// drainLifecycle delays container termination so that requests already routed to the pod
+// complete. The preStop sleep covers the time until the endpoint change propagates to proxies
+// and kube-proxy. Clusters before Kubernetes 1.30 have no sleep action, so legacy runs the
+// sleep binary of the container image instead.
+func drainLifecycle(seconds int32, legacy bool) *corev1.Lifecycle {
+	if legacy {
+		return &corev1.Lifecycle{
+			PreStop: &corev1.LifecycleHandler{
+				Exec: &corev1.ExecAction{Command: []string{"sleep", strconv.Itoa(int(seconds))}},
+			},
+		}
+	}
+	return &corev1.Lifecycle{
+		PreStop: &corev1.LifecycleHandler{
+			Sleep: &corev1.SleepAction{Seconds: int64(seconds)},
+		},
+	}
+}
+
+// reconcileServingGate sets the serving readiness gate of the ModelAPI pods that carry it:
+// True while a pod is live, False once it is terminating. The pods come from the manager's
+// Pod cache, which only holds pods labelled with an operator app such as app=modelapi.
+func (r *ModelAPIReconciler) reconcileServingGate(ctx context.Context, modelapi *kaosv1alpha1.ModelAPI) error {
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(modelapi.Namespace),
+		client.MatchingLabels{"app": "modelapi", "modelapi": modelapi.Name}); err != nil {
+		return err
+	}
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if !hasServingGate(pod) {
+			continue
+		}
+		status := corev1.ConditionTrue
+		reason := "PodLive"
+		if pod.DeletionTimestamp != nil {
+			status = corev1.ConditionFalse
+			reason = "PodTerminating"
+		}
+		if servingCondition(pod) == status {
+			continue
+		}
+
+		patch := client.StrategicMergeFrom(pod.DeepCopy())
+		setServingCondition(pod, status, reason)
+		if err := r.Status().Patch(ctx, pod, patch); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("pod %s: %w", pod.Name, err)
+		}
+	}
+	return nil
+}
+
+// hasServingGate reports whether the pod spec lists the serving readiness gate
+func hasServingGate(pod *corev1.Pod) bool {
+	for _, gate := range pod.Spec.ReadinessGates {
+		if gate.ConditionType == ServingReadinessGate {
+			return true
+		}
+	}
+	return false
+}
+
+// servingCondition returns the status of the serving condition, empty when the pod has none
+func servingCondition(pod *corev1.Pod) corev1.ConditionStatus {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == ServingReadinessGate {
+			return c.Status
+		}
+	}
+	return ""
+}
+
+// setServingCondition adds or updates the serving condition of the pod
+func setServingCondition(pod *corev1.Pod, status corev1.ConditionStatus, reason string) {
+	condition := corev1.PodCondition{
+		Type:               ServingReadinessGate,
+		Status:             status,
+		Reason:             reason,
+		LastTransitionTime: metav1.Now(),
+	}
+	for i := range pod.Status.Conditions {
+		if pod.Status.Conditions[i].Type == ServingReadinessGate {
+			pod.Status.Conditions[i] = condition
+			return
+		}
+	}
+	pod.Status.Conditions = append(pod.Status.Conditions, condition)
+}
+
+// modelAPIForPod maps a ModelAPI pod to its ModelAPI
+func modelAPIForPod(ctx context.Context, obj client.Object) []ctrl.Request {
+	labels := obj.GetLabels()
+	if labels["app"] != "modelapi" || labels["modelapi"] == "" {
+		return nil
+	}
+	return []ctrl.Request{{NamespacedName: types.NamespacedName{Name: labels["modelapi"], Namespace: obj.GetNamespace()}}}
+}
+
+// servingGatePodChanged passes the creation of pods with the serving readiness gate and the
+// start of their termination, but not the frequent status updates of running pods
+var servingGatePodChanged = predicate.Funcs{
+	CreateFunc: func(e event.CreateEvent) bool {
+		pod, ok := e.Object.(*corev1.Pod)
+		return ok && hasServingGate(pod)
+	},
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldPod, okOld := e.ObjectOld.(*corev1.Pod)
+		newPod, okNew := e.ObjectNew.(*corev1.Pod)
+		return okOld && okNew && hasServingGate(newPod) &&
+			(oldPod.DeletionTimestamp == nil) != (newPod.DeletionTimestamp == nil)
+	},
+	DeleteFunc:  func(event.DeleteEvent) bool { return false },
+	GenericFunc: func(event.GenericEvent) bool { return false },
+}
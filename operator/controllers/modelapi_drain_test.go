@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+var _ = Describe("ModelAPI spec.drainSeconds", func() {
+	newDrainModelAPI := func(drain *int32) *kaosv1alpha1.ModelAPI {
+		return &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{Name: "llm", Namespace: "default"},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode:         kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig:  &kaosv1alpha1.ProxyConfig{Models: []string{"openai/gpt-4"}},
+				DrainSeconds: drain,
+			},
+		}
+	}
+
+	It("should add a preStop sleep and the serving readiness gate", func() {
+		drain := int32(25)
+		podSpec := (&ModelAPIReconciler{}).constructDeployment(newDrainModelAPI(&drain)).Spec.Template.Spec
+
+		preStop := podSpec.Containers[0].Lifecycle.PreStop
+		Expect(preStop.Sleep).To(Equal(&corev1.SleepAction{Seconds: 25}))
+		Expect(*podSpec.TerminationGracePeriodSeconds).To(Equal(int64(55)))
+		Expect(podSpec.ReadinessGates).To(ConsistOf(corev1.PodReadinessGate{ConditionType: ServingReadinessGate}))
+	})
+
+	It("should fall back to an exec sleep before Kubernetes 1.30", func() {
+		drain := int32(25)
+		r := &ModelAPIReconciler{LegacySleepAction: true}
+		preStop := r.constructDeployment(newDrainModelAPI(&drain)).Spec.Template.Spec.Containers[0].Lifecycle.PreStop
+
+		Expect(preStop.Sleep).To(BeNil())
+		Expect(preStop.Exec.Command).To(Equal([]string{"sleep", "25"}))
+	})
+
+	It("should keep a preStop hook set in podSpec", func() {
+		drain := int32(25)
+		modelapi := newDrainModelAPI(&drain)
+		userHook := &corev1.LifecycleHandler{Exec: &corev1.ExecAction{Command: []string{"/bin/drain.sh"}}}
+		modelapi.Spec.PodSpec = &corev1.PodSpec{Containers: []corev1.Container{{
+			Name:      "model-api",
+			Lifecycle: &corev1.Lifecycle{PreStop: userHook},
+		}}}
+		podSpec := (&ModelAPIReconciler{}).constructDeployment(modelapi).Spec.Template.Spec
+
+		Expect(podSpec.Containers[0].Lifecycle.PreStop).To(Equal(userHook))
+	})
+
+	It("should not drain when drainSeconds is unset", func() {
+		podSpec := (&ModelAPIReconciler{}).constructDeployment(newDrainModelAPI(nil)).Spec.Template.Spec
+
+		Expect(podSpec.Containers[0].Lifecycle).To(BeNil())
+		Expect(podSpec.ReadinessGates).To(BeEmpty())
+		Expect(podSpec.TerminationGracePeriodSeconds).To(BeNil())
+	})
+
+	It("should set the serving gate True on live pods and False on terminating ones", func() {
+		scheme := newTestScheme()
+		newPod := func(name string) *corev1.Pod {
+			return &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: name, Namespace: "default",
+					Labels: map[string]string{"app": "modelapi", "modelapi": "llm"},
+				},
+				Spec: corev1.PodSpec{
+					Containers:     []corev1.Container{{Name: "model-api", Image: "litellm"}},
+					ReadinessGates: []corev1.PodReadinessGate{{ConditionType: ServingReadinessGate}},
+				},
+			}
+		}
+		live := newPod("live")
+		terminating := newPod("terminating")
+		terminating.Finalizers = []string{"kaos.tools/test"}
+		terminating.DeletionTimestamp = &metav1.Time{Time: metav1.Now().Time}
+		setServingCondition(terminating, corev1.ConditionTrue, "PodLive")
+		ungated := newPod("ungated")
+		ungated.Spec.ReadinessGates = nil
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(live, terminating, ungated).
+			WithStatusSubresource(&corev1.Pod{}).Build()
+		r := &ModelAPIReconciler{Client: c, Scheme: scheme}
+
+		drain := int32(10)
+		Expect(r.reconcileServingGate(context.Background(), newDrainModelAPI(&drain))).To(Succeed())
+
+		for obj, status := range map[client.Object]corev1.ConditionStatus{
+			live: corev1.ConditionTrue, terminating: corev1.ConditionFalse, ungated: "",
+		} {
+			pod := &corev1.Pod{}
+			Expect(c.Get(context.Background(), client.ObjectKeyFromObject(obj), pod)).To(Succeed())
+			Expect(servingCondition(pod)).To(Equal(status), pod.Name)
+		}
+	})
+
+	It("should enqueue the ModelAPI of gated pods when they are created or start terminating", func() {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "p", Namespace: "default", Labels: map[string]string{"app": "modelapi", "modelapi": "llm"}},
+			Spec:       corev1.PodSpec{ReadinessGates: []corev1.PodReadinessGate{{ConditionType: ServingReadinessGate}}},
+		}
+		requests := modelAPIForPod(context.Background(), pod)
+		Expect(requests).To(HaveLen(1))
+		Expect(requests[0].Name).To(Equal("llm"))
+
+		terminating := pod.DeepCopy()
+		terminating.DeletionTimestamp = &metav1.Time{Time: metav1.Now().Time}
+		Expect(servingGatePodChanged.Update(event.UpdateEvent{ObjectOld: pod, ObjectNew: terminating})).To(BeTrue())
+		Expect(servingGatePodChanged.Update(event.UpdateEvent{ObjectOld: pod, ObjectNew: pod.DeepCopy()})).To(BeFalse())
+	})
+})
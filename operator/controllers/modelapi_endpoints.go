@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+//+kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=get;list;watch
+
+// serviceEndpointSlices lists the EndpointSlices the endpoints controller maintains for the
+// named Service
+func serviceEndpointSlices(ctx context.Context, c client.Reader, namespace, serviceName string) ([]discoveryv1.EndpointSlice, error) {
+	list := &discoveryv1.EndpointSliceList{}
+	if err := c.List(ctx, list, client.InNamespace(namespace), client.MatchingLabels{discoveryv1.LabelServiceName: serviceName}); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// readyEndpoints counts the ready backends across the EndpointSlices. A nil ready condition
+// means ready, as defined by the EndpointSlice API.
+func readyEndpoints(slices []discoveryv1.EndpointSlice) int {
+	ready := 0
+	for _, slice := range slices {
+		for _, endpoint := range slice.Endpoints {
+			if endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready {
+				ready++
+			}
+		}
+	}
+	return ready
+}
+
+// endpointSliceObjects returns the EndpointSlices as objects for the reconcile fingerprint
+func endpointSliceObjects(slices []discoveryv1.EndpointSlice) []metav1.Object {
+	objs := make([]metav1.Object, 0, len(slices))
+	for i := range slices {
+		objs = append(objs, &slices[i])
+	}
+	return objs
+}
+
+// endpointSliceToModelAPI maps an EndpointSlice to the ModelAPI whose Service it belongs to.
+// The slices are owned by the Service rather than the ModelAPI, so Owns cannot watch them.
+func endpointSliceToModelAPI(_ context.Context, obj client.Object) []ctrl.Request {
+	name, ok := strings.CutPrefix(obj.GetLabels()[discoveryv1.LabelServiceName], "modelapi-")
+	if !ok || name == "" {
+		return nil
+	}
+	return []ctrl.Request{{NamespacedName: types.NamespacedName{Name: name, Namespace: obj.GetNamespace()}}}
+}
+
+// modelAPIEndpoints returns the EndpointSlices of the ModelAPI Service, or none if they
+// cannot be listed
+func (r *ModelAPIReconciler) modelAPIEndpoints(ctx context.Context, modelapi *kaosv1alpha1.ModelAPI) []discoveryv1.EndpointSlice {
+	slices, err := serviceEndpointSlices(ctx, r.Client, modelapi.Namespace, "modelapi-"+modelapi.Name)
+	if err != nil {
+		log := ctrl.LoggerFrom(ctx)
+		log.Error(err, "failed to list EndpointSlices")
+	}
+	return slices
+}
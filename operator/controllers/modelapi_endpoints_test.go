@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+	"github.com/axsaucedo/kaos/operator/pkg/util"
+)
+
+var _ = Describe("ModelAPI Service endpoints", func() {
+	It("should stay not Ready while the Deployment is available but the Service has no ready endpoints", func() {
+		modelapi := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{Name: "endpoints", Namespace: "default", UID: "modelapi-uid"},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode:        kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig: &kaosv1alpha1.ProxyConfig{Models: []string{"openai/gpt-4"}},
+			},
+		}
+		r, c := newCachedModelAPIReconciler(modelapi)
+		ctx := context.Background()
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "endpoints", Namespace: "default"}}
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		deployment := &appsv1.Deployment{}
+		Expect(c.Get(ctx, types.NamespacedName{Name: "modelapi-endpoints", Namespace: "default"}, deployment)).To(Succeed())
+		deployment.Status.Replicas = 1
+		deployment.Status.ReadyReplicas = 1
+		deployment.Status.AvailableReplicas = 1
+		Expect(c.Status().Update(ctx, deployment)).To(Succeed())
+
+		// The pod is running, but the endpoints controller has not added it to the Service yet
+		notReady := false
+		slice := &discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "modelapi-endpoints-x7k2p",
+				Namespace: "default",
+				Labels:    map[string]string{discoveryv1.LabelServiceName: "modelapi-endpoints"},
+			},
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Endpoints: []discoveryv1.Endpoint{{
+				Addresses:  []string{"10.0.0.12"},
+				Conditions: discoveryv1.EndpointConditions{Ready: &notReady},
+			}},
+		}
+		Expect(c.Create(ctx, slice)).To(Succeed())
+
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, req.NamespacedName, modelapi)).To(Succeed())
+		Expect(modelapi.Status.Ready).To(BeFalse())
+		Expect(modelapi.Status.Phase).To(Equal("Pending"))
+		cond := meta.FindStatusCondition(modelapi.Status.Conditions, kaosv1alpha1.ConditionReady)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+		Expect(cond.Reason).To(Equal(kaosv1alpha1.ReasonEndpointsNotReady))
+		Expect(cond.Message).To(ContainSubstring("Service modelapi-endpoints has no ready endpoints"))
+
+		// Once the endpoint turns ready the ModelAPI becomes Ready, despite the reconcile cache
+		ready := true
+		slice.Endpoints[0].Conditions.Ready = &ready
+		Expect(c.Update(ctx, slice)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, req.NamespacedName, modelapi)).To(Succeed())
+		Expect(modelapi.Status.Ready).To(BeTrue())
+		Expect(meta.FindStatusCondition(modelapi.Status.Conditions, kaosv1alpha1.ConditionReady).Reason).To(Equal(kaosv1alpha1.ReasonDeploymentReady))
+	})
+
+	It("should map an EndpointSlice to the ModelAPI of its Service", func() {
+		slice := &discoveryv1.EndpointSlice{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "team-a",
+			Labels:    map[string]string{discoveryv1.LabelServiceName: "modelapi-llm"},
+		}}
+		Expect(endpointSliceToModelAPI(context.Background(), slice)).To(ConsistOf(
+			ctrl.Request{NamespacedName: types.NamespacedName{Name: "llm", Namespace: "team-a"}}))
+
+		slice.Labels[discoveryv1.LabelServiceName] = "agent-llm"
+		Expect(endpointSliceToModelAPI(context.Background(), slice)).To(BeEmpty())
+	})
+
+	It("should label the Service so the cache holds its EndpointSlices", func() {
+		modelapi := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{Name: "labelled", Namespace: "default"},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode:        kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig: &kaosv1alpha1.ProxyConfig{Models: []string{"openai/gpt-4"}},
+			},
+		}
+		// A Service created before the component label was added
+		existing := (&ModelAPIReconciler{}).constructService(modelapi)
+		delete(existing.Labels, util.ComponentLabel)
+		r, c := newCachedModelAPIReconciler(modelapi, existing)
+		ctx := context.Background()
+
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "labelled", Namespace: "default"}})
+		Expect(err).NotTo(HaveOccurred())
+		service := &corev1.Service{}
+		Expect(c.Get(ctx, types.NamespacedName{Name: "modelapi-labelled", Namespace: "default"}, service)).To(Succeed())
+		Expect(service.Labels).To(HaveKeyWithValue(util.ComponentLabel, "modelapi"))
+		Expect(service.Labels).To(HaveKeyWithValue("modelapi", "labelled"))
+	})
+})
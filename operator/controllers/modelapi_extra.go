@@ -0,0 +1,22 @@
+package controllers
+
+import (
+	"encoding/json"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// extraEnvVar carries the spec.extra options of a ModelAPI as a JSON object
+const extraEnvVar = "KAOS_EXTRA"
+
+// extraEnvVars serializes spec.extra into the KAOS_EXTRA env var, or returns nothing when
+// no options are set. Keys are sorted by encoding/json, so the pod template hash stays
+// stable across reconciles.
+func extraEnvVars(extra map[string]string) []corev1.EnvVar {
+	if len(extra) == 0 {
+		return nil
+	}
+	// A map of strings always marshals
+	value, _ := json.Marshal(extra)
+	return []corev1.EnvVar{{Name: extraEnvVar, Value: string(value)}}
+}
@@ -0,0 +1,50 @@
+package controllers
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+var _ = Describe("spec.extra", func() {
+	extraEnv := func(modelapi *kaosv1alpha1.ModelAPI) *corev1.EnvVar {
+		deployment := (&ModelAPIReconciler{}).constructDeployment(modelapi)
+		for _, env := range deployment.Spec.Template.Spec.Containers[0].Env {
+			if env.Name == "KAOS_EXTRA" {
+				return &env
+			}
+		}
+		return nil
+	}
+	newModelAPI := func(extra map[string]string) *kaosv1alpha1.ModelAPI {
+		return &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{Name: "extra", Namespace: "default"},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode:        kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig: &kaosv1alpha1.ProxyConfig{Models: []string{"openai/gpt-4"}},
+				Extra:       extra,
+			},
+		}
+	}
+
+	It("should serialize the map into KAOS_EXTRA", func() {
+		extra := map[string]string{"region": "us-east-1", "deployment_id": "gpt-4-prod", "api_version": "2024-06-01"}
+		env := extraEnv(newModelAPI(extra))
+		Expect(env).NotTo(BeNil())
+		Expect(env.Value).To(Equal(`{"api_version":"2024-06-01","deployment_id":"gpt-4-prod","region":"us-east-1"}`))
+
+		var decoded map[string]string
+		Expect(json.Unmarshal([]byte(env.Value), &decoded)).To(Succeed())
+		Expect(decoded).To(Equal(extra))
+	})
+
+	It("should not set KAOS_EXTRA without options", func() {
+		Expect(extraEnv(newModelAPI(nil))).To(BeNil())
+		Expect(extraEnv(newModelAPI(map[string]string{}))).To(BeNil())
+	})
+})
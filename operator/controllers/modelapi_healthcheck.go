@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+// grpcHealthProbeBinary is run by exec probes on clusters without native gRPC probes. It must
+// be present in the model server image.
+const grpcHealthProbeBinary = "grpc_health_probe"
+
+// grpcHealthCheck reports whether the ModelAPI pods are probed over gRPC
+func grpcHealthCheck(modelapi *kaosv1alpha1.ModelAPI) bool {
+	return modelapi.Spec.Mode == kaosv1alpha1.ModelAPIModeHosted && modelapi.Spec.HealthCheck != nil &&
+		modelapi.Spec.HealthCheck.Type == kaosv1alpha1.HealthCheckTypeGRPC
+}
+
+// probeHandler returns the liveness and readiness probe handler of the model server: an HTTP
+// GET of healthPath by default, or a gRPC health check when spec.healthCheck.type is grpc
+func (r *ModelAPIReconciler) probeHandler(modelapi *kaosv1alpha1.ModelAPI, healthPath string, port int32) corev1.ProbeHandler {
+	if !grpcHealthCheck(modelapi) {
+		return corev1.ProbeHandler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path:   healthPath,
+				Port:   intstr.FromInt(int(port)),
+				Scheme: mtlsProbeScheme(modelapi.Spec.MTLS),
+			},
+		}
+	}
+
+	healthCheck := modelapi.Spec.HealthCheck
+	if healthCheck.Port != nil {
+		port = *healthCheck.Port
+	}
+	if r.LegacyGRPCProbes {
+		command := []string{grpcHealthProbeBinary, fmt.Sprintf("-addr=localhost:%d", port)}
+		if healthCheck.Service != "" {
+			command = append(command, "-service="+healthCheck.Service)
+		}
+		return corev1.ProbeHandler{Exec: &corev1.ExecAction{Command: command}}
+	}
+
+	grpc := &corev1.GRPCAction{Port: port}
+	if healthCheck.Service != "" {
+		grpc.Service = &healthCheck.Service
+	}
+	return corev1.ProbeHandler{GRPC: grpc}
+}
+
+// applyProbeTimings overrides the probe's timings with those set in spec.healthCheck
+func applyProbeTimings(probe *corev1.Probe, healthCheck *kaosv1alpha1.HealthCheckConfig) {
+	if healthCheck == nil {
+		return
+	}
+	if healthCheck.InitialDelaySeconds != nil {
+		probe.InitialDelaySeconds = *healthCheck.InitialDelaySeconds
+	}
+	if healthCheck.PeriodSeconds != nil {
+		probe.PeriodSeconds = *healthCheck.PeriodSeconds
+	}
+	if healthCheck.TimeoutSeconds != nil {
+		probe.TimeoutSeconds = *healthCheck.TimeoutSeconds
+	}
+}
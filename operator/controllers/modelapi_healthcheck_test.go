@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+var _ = Describe("ModelAPI health checks", func() {
+	hostedModelAPI := func(healthCheck *kaosv1alpha1.HealthCheckConfig) *kaosv1alpha1.ModelAPI {
+		return &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{Name: "triton", Namespace: "default"},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode:         kaosv1alpha1.ModelAPIModeHosted,
+				HostedConfig: &kaosv1alpha1.HostedConfig{Model: "smollm2:135m"},
+				HealthCheck:  healthCheck,
+			},
+		}
+	}
+
+	It("should probe Hosted ModelAPIs over HTTP by default", func() {
+		container := (&ModelAPIReconciler{}).constructContainer(hostedModelAPI(nil))
+		Expect(container.ReadinessProbe.HTTPGet).NotTo(BeNil())
+		Expect(container.ReadinessProbe.GRPC).To(BeNil())
+	})
+
+	It("should generate native gRPC probes for a grpc health check", func() {
+		port := int32(8001)
+		modelapi := hostedModelAPI(&kaosv1alpha1.HealthCheckConfig{
+			Type:    kaosv1alpha1.HealthCheckTypeGRPC,
+			Port:    &port,
+			Service: "inference",
+		})
+		container := (&ModelAPIReconciler{}).constructContainer(modelapi)
+
+		for _, probe := range []*corev1.Probe{container.ReadinessProbe, container.LivenessProbe} {
+			Expect(probe.HTTPGet).To(BeNil())
+			Expect(probe.GRPC).NotTo(BeNil())
+			Expect(probe.GRPC.Port).To(Equal(int32(8001)))
+			Expect(*probe.GRPC.Service).To(Equal("inference"))
+		}
+
+		// The gRPC port defaults to the model server port
+		modelapi.Spec.HealthCheck = &kaosv1alpha1.HealthCheckConfig{Type: kaosv1alpha1.HealthCheckTypeGRPC}
+		container = (&ModelAPIReconciler{}).constructContainer(modelapi)
+		Expect(container.ReadinessProbe.GRPC.Port).To(Equal(int32(11434)))
+		Expect(container.ReadinessProbe.GRPC.Service).To(BeNil())
+	})
+
+	It("should fall back to grpc_health_probe on clusters without native gRPC probes", func() {
+		modelapi := hostedModelAPI(&kaosv1alpha1.HealthCheckConfig{Type: kaosv1alpha1.HealthCheckTypeGRPC, Service: "inference"})
+		container := (&ModelAPIReconciler{LegacyGRPCProbes: true}).constructContainer(modelapi)
+		Expect(container.ReadinessProbe.GRPC).To(BeNil())
+		Expect(container.ReadinessProbe.Exec.Command).To(Equal([]string{
+			"grpc_health_probe", "-addr=localhost:11434", "-service=inference",
+		}))
+	})
+
+	It("should apply the configured probe timings to both probes", func() {
+		delay, period, timeout := int32(120), int32(20), int32(10)
+		modelapi := hostedModelAPI(&kaosv1alpha1.HealthCheckConfig{
+			InitialDelaySeconds: &delay, PeriodSeconds: &period, TimeoutSeconds: &timeout,
+		})
+		container := (&ModelAPIReconciler{}).constructContainer(modelapi)
+		for _, probe := range []*corev1.Probe{container.ReadinessProbe, container.LivenessProbe} {
+			Expect(probe.InitialDelaySeconds).To(Equal(delay))
+			Expect(probe.PeriodSeconds).To(Equal(period))
+			Expect(probe.TimeoutSeconds).To(Equal(timeout))
+		}
+
+		// Unset timings keep the per-probe defaults
+		container = (&ModelAPIReconciler{}).constructContainer(hostedModelAPI(&kaosv1alpha1.HealthCheckConfig{TimeoutSeconds: &timeout}))
+		Expect(container.LivenessProbe.PeriodSeconds).To(Equal(int32(10)))
+		Expect(container.ReadinessProbe.PeriodSeconds).To(Equal(int32(5)))
+
+		// The defaults satisfy the webhook's rule that a probe times out before the next is due
+		container = (&ModelAPIReconciler{}).constructContainer(hostedModelAPI(nil))
+		for _, probe := range []*corev1.Probe{container.ReadinessProbe, container.LivenessProbe} {
+			Expect(probe.TimeoutSeconds).To(BeNumerically("<", probe.PeriodSeconds))
+		}
+	})
+})
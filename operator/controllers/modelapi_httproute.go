@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+	"github.com/axsaucedo/kaos/operator/pkg/util"
+)
+
+// modelAPIRouteName returns the name of the HTTPRoute created for spec.httpRoute. It differs
+// from the operator-wide Gateway route, modelapi-<name>, so both can exist side by side.
+func modelAPIRouteName(modelapi *kaosv1alpha1.ModelAPI) string {
+	return fmt.Sprintf("modelapi-%s-route", modelapi.Name)
+}
+
+// reconcileModelAPIRoute creates or updates the HTTPRoute of spec.httpRoute, or removes it
+// once the field is cleared. Without the Gateway API CRDs the route is skipped and the
+// HTTPRouteApplied condition reports why, instead of failing the reconcile.
+func (r *ModelAPIReconciler) reconcileModelAPIRoute(ctx context.Context, modelapi *kaosv1alpha1.ModelAPI, serviceName string, port int32, log logr.Logger) error {
+	if modelapi.Spec.HTTPRoute == nil {
+		meta.RemoveStatusCondition(&modelapi.Status.Conditions, kaosv1alpha1.ConditionHTTPRouteApplied)
+		if !r.GatewayAPIAvailable {
+			return nil
+		}
+		existing := &gatewayv1.HTTPRoute{}
+		if err := r.Get(ctx, types.NamespacedName{Name: modelAPIRouteName(modelapi), Namespace: modelapi.Namespace}, existing); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		if !metav1.IsControlledBy(existing, modelapi) {
+			return nil
+		}
+		log.Info("Deleting HTTPRoute", "name", existing.Name)
+		return client.IgnoreNotFound(r.Delete(ctx, existing))
+	}
+
+	if !r.GatewayAPIAvailable {
+		r.setHTTPRouteCondition(modelapi, metav1.ConditionFalse, kaosv1alpha1.ReasonGatewayAPIUnavailable,
+			"the Gateway API CRDs are not installed, so spec.httpRoute is ignored")
+		return nil
+	}
+
+	desired := constructModelAPIRoute(modelapi, serviceName, port)
+	util.PropagateLabels(desired, modelapi, util.PropagatedLabelKeys())
+
+	existing := &gatewayv1.HTTPRoute{}
+	err := r.Get(ctx, types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, existing)
+	switch {
+	case apierrors.IsNotFound(err):
+		if err := controllerutil.SetControllerReference(modelapi, desired, r.Scheme); err != nil {
+			return err
+		}
+		log.Info("Creating HTTPRoute", "name", desired.Name)
+		err = r.Create(ctx, desired)
+	case err == nil:
+		existing.Spec = desired.Spec
+		util.PropagateLabels(existing, modelapi, util.PropagatedLabelKeys())
+		err = r.Update(ctx, existing)
+	}
+	if meta.IsNoMatchError(err) {
+		r.setHTTPRouteCondition(modelapi, metav1.ConditionFalse, kaosv1alpha1.ReasonGatewayAPIUnavailable,
+			"the Gateway API CRDs are not installed, so spec.httpRoute is ignored")
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	r.setHTTPRouteCondition(modelapi, metav1.ConditionTrue, kaosv1alpha1.ReasonRouteApplied,
+		fmt.Sprintf("HTTPRoute %s attached to Gateway %s", desired.Name, modelapi.Spec.HTTPRoute.GatewayRef.Name))
+	return nil
+}
+
+// setHTTPRouteCondition sets the HTTPRouteApplied condition
+func (r *ModelAPIReconciler) setHTTPRouteCondition(modelapi *kaosv1alpha1.ModelAPI, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&modelapi.Status.Conditions, metav1.Condition{
+		Type:               kaosv1alpha1.ConditionHTTPRouteApplied,
+		Status:             status,
+		ObservedGeneration: modelapi.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// constructModelAPIRoute builds the HTTPRoute of spec.httpRoute, routing the path prefix
+// of the configured hostnames to the ModelAPI Service
+func constructModelAPIRoute(modelapi *kaosv1alpha1.ModelAPI, serviceName string, port int32) *gatewayv1.HTTPRoute {
+	config := modelapi.Spec.HTTPRoute
+
+	parentRef := gatewayv1.ParentReference{Name: gatewayv1.ObjectName(config.GatewayRef.Name)}
+	if config.GatewayRef.Namespace != "" {
+		namespace := gatewayv1.Namespace(config.GatewayRef.Namespace)
+		parentRef.Namespace = &namespace
+	}
+	if config.GatewayRef.SectionName != "" {
+		sectionName := gatewayv1.SectionName(config.GatewayRef.SectionName)
+		parentRef.SectionName = &sectionName
+	}
+
+	hostnames := make([]gatewayv1.Hostname, 0, len(config.Hostnames))
+	for _, hostname := range config.Hostnames {
+		hostnames = append(hostnames, gatewayv1.Hostname(hostname))
+	}
+
+	pathType := gatewayv1.PathMatchPathPrefix
+	pathValue := config.PathPrefix
+	if pathValue == "" {
+		pathValue = "/"
+	}
+	backendPort := gatewayv1.PortNumber(port)
+
+	return &gatewayv1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      modelAPIRouteName(modelapi),
+			Namespace: modelapi.Namespace,
+			Labels:    map[string]string{"app": "modelapi", "modelapi": modelapi.Name},
+		},
+		Spec: gatewayv1.HTTPRouteSpec{
+			CommonRouteSpec: gatewayv1.CommonRouteSpec{ParentRefs: []gatewayv1.ParentReference{parentRef}},
+			Hostnames:       hostnames,
+			Rules: []gatewayv1.HTTPRouteRule{{
+				Matches: []gatewayv1.HTTPRouteMatch{{
+					Path: &gatewayv1.HTTPPathMatch{Type: &pathType, Value: &pathValue},
+				}},
+				BackendRefs: []gatewayv1.HTTPBackendRef{{
+					BackendRef: gatewayv1.BackendRef{
+						BackendObjectReference: gatewayv1.BackendObjectReference{
+							Name: gatewayv1.ObjectName(serviceName),
+							Port: &backendPort,
+						},
+					},
+				}},
+			}},
+		},
+	}
+}
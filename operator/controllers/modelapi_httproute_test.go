@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+var _ = Describe("ModelAPI spec.httpRoute", func() {
+	var modelapi *kaosv1alpha1.ModelAPI
+
+	BeforeEach(func() {
+		modelapi = &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{Name: "routed", Namespace: "default", UID: "modelapi-uid"},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode:        kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig: &kaosv1alpha1.ProxyConfig{Models: []string{"openai/gpt-4"}},
+				HTTPRoute: &kaosv1alpha1.HTTPRouteConfig{
+					GatewayRef: kaosv1alpha1.GatewayReference{Name: "public", Namespace: "gateways", SectionName: "https"},
+					Hostnames:  []string{"llm.example.com"},
+				},
+			},
+		}
+	})
+
+	It("should create an HTTPRoute attached to the Gateway and backed by the ModelAPI Service", func() {
+		r, c := newCachedModelAPIReconciler(modelapi)
+		r.GatewayAPIAvailable = true
+		ctx := context.Background()
+		key := types.NamespacedName{Name: "modelapi-routed-route", Namespace: "default"}
+
+		Expect(r.reconcileModelAPIRoute(ctx, modelapi, "modelapi-routed", 8000, log.FromContext(ctx))).To(Succeed())
+		route := &gatewayv1.HTTPRoute{}
+		Expect(c.Get(ctx, key, route)).To(Succeed())
+		Expect(metav1.IsControlledBy(route, modelapi)).To(BeTrue())
+
+		Expect(route.Spec.ParentRefs).To(HaveLen(1))
+		parent := route.Spec.ParentRefs[0]
+		Expect(string(parent.Name)).To(Equal("public"))
+		Expect(string(*parent.Namespace)).To(Equal("gateways"))
+		Expect(string(*parent.SectionName)).To(Equal("https"))
+		Expect(route.Spec.Hostnames).To(ConsistOf(gatewayv1.Hostname("llm.example.com")))
+
+		Expect(route.Spec.Rules).To(HaveLen(1))
+		Expect(*route.Spec.Rules[0].Matches[0].Path.Value).To(Equal("/"))
+		backend := route.Spec.Rules[0].BackendRefs[0].BackendObjectReference
+		Expect(string(backend.Name)).To(Equal("modelapi-routed"))
+		Expect(int32(*backend.Port)).To(Equal(int32(8000)))
+		Expect(meta.IsStatusConditionTrue(modelapi.Status.Conditions, kaosv1alpha1.ConditionHTTPRouteApplied)).To(BeTrue())
+
+		// Clearing spec.httpRoute removes the route and the condition
+		modelapi.Spec.HTTPRoute = nil
+		Expect(r.reconcileModelAPIRoute(ctx, modelapi, "modelapi-routed", 8000, log.FromContext(ctx))).To(Succeed())
+		Expect(apierrors.IsNotFound(c.Get(ctx, key, route))).To(BeTrue())
+		Expect(meta.FindStatusCondition(modelapi.Status.Conditions, kaosv1alpha1.ConditionHTTPRouteApplied)).To(BeNil())
+	})
+
+	It("should report the missing Gateway API instead of failing", func() {
+		r, c := newCachedModelAPIReconciler(modelapi)
+		ctx := context.Background()
+
+		Expect(r.reconcileModelAPIRoute(ctx, modelapi, "modelapi-routed", 8000, log.FromContext(ctx))).To(Succeed())
+		cond := meta.FindStatusCondition(modelapi.Status.Conditions, kaosv1alpha1.ConditionHTTPRouteApplied)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+		Expect(cond.Reason).To(Equal(kaosv1alpha1.ReasonGatewayAPIUnavailable))
+		Expect(apierrors.IsNotFound(c.Get(ctx, types.NamespacedName{Name: "modelapi-routed-route", Namespace: "default"}, &gatewayv1.HTTPRoute{}))).To(BeTrue())
+	})
+})
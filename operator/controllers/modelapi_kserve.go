@@ -0,0 +1,252 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+	"github.com/axsaucedo/kaos/operator/pkg/util"
+)
+
+// inferenceServiceGVK is the KServe kind created for spec.backend KServe. It is handled as
+// unstructured content, so the operator does not depend on the KServe API module.
+var inferenceServiceGVK = schema.GroupVersionKind{Group: "serving.kserve.io", Version: "v1beta1", Kind: "InferenceService"}
+
+const (
+	// kserveDeploymentModeAnnotation selects how KServe runs the predictor; RawDeployment
+	// matches the plain Deployment and Service of the default backend and needs no Knative
+	kserveDeploymentModeAnnotation = "serving.kserve.io/deploymentMode"
+
+	// kserveContainerName is the name KServe expects of the predictor's serving container
+	kserveContainerName = "kserve-container"
+)
+
+// KServeAvailable reports whether the cluster serves the KServe v1beta1 InferenceService
+// kind, i.e. whether the KServe CRDs are installed
+func KServeAvailable(client discovery.DiscoveryInterface) (bool, error) {
+	resources, err := client.ServerResourcesForGroupVersion(inferenceServiceGVK.GroupVersion().String())
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Kind == inferenceServiceGVK.Kind {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// kserveBackend reports whether the ModelAPI asks for its pods to run from an InferenceService
+func kserveBackend(modelapi *kaosv1alpha1.ModelAPI) bool {
+	return modelapi.Spec.Backend == kaosv1alpha1.ModelAPIBackendKServe
+}
+
+// newInferenceService returns an empty InferenceService with the given name
+func newInferenceService(name, namespace string) *unstructured.Unstructured {
+	isvc := &unstructured.Unstructured{}
+	isvc.SetGroupVersionKind(inferenceServiceGVK)
+	isvc.SetName(name)
+	isvc.SetNamespace(namespace)
+	return isvc
+}
+
+// constructInferenceService maps the pod template the Deployment backend would run onto the
+// predictor of a raw-deployment InferenceService: the model server container, with its model
+// source, resources and probes, becomes the custom predictor container, and the replicas its
+// minReplicas. The ModelAPI labels and pod template annotations are set on the
+// InferenceService, which KServe copies onto the predictor pods.
+func constructInferenceService(modelapi *kaosv1alpha1.ModelAPI, template *corev1.PodTemplateSpec, replicas, maxReplicas int32) (*unstructured.Unstructured, error) {
+	podSpec := template.Spec.DeepCopy()
+	for i := range podSpec.Containers {
+		if podSpec.Containers[i].Name == "model-api" {
+			podSpec.Containers[i].Name = kserveContainerName
+		}
+	}
+	predictor, err := runtime.DefaultUnstructuredConverter.ToUnstructured(podSpec)
+	if err != nil {
+		return nil, fmt.Errorf("converting the pod spec: %w", err)
+	}
+	predictor["minReplicas"] = int64(replicas)
+	if maxReplicas > 0 {
+		predictor["maxReplicas"] = int64(maxReplicas)
+	}
+
+	isvc := newInferenceService(fmt.Sprintf("modelapi-%s", modelapi.Name), modelapi.Namespace)
+	isvc.SetLabels(template.Labels)
+	annotations := map[string]string{kserveDeploymentModeAnnotation: "RawDeployment"}
+	for k, v := range template.Annotations {
+		annotations[k] = v
+	}
+	isvc.SetAnnotations(annotations)
+	util.PropagateLabels(isvc, modelapi, util.PropagatedLabelKeys())
+	if err := unstructured.SetNestedMap(isvc.Object, predictor, "spec", "predictor"); err != nil {
+		return nil, err
+	}
+	return isvc, nil
+}
+
+// inferenceServiceReady reports whether the InferenceService's Ready condition is True
+func inferenceServiceReady(isvc *unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(isvc.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if ok && condition["type"] == "Ready" {
+			return condition["status"] == string(metav1.ConditionTrue)
+		}
+	}
+	return false
+}
+
+// inferenceServiceURL returns the in-cluster URL KServe reports for the InferenceService
+func inferenceServiceURL(isvc *unstructured.Unstructured) string {
+	if url, _, _ := unstructured.NestedString(isvc.Object, "status", "address", "url"); url != "" {
+		return url
+	}
+	url, _, _ := unstructured.NestedString(isvc.Object, "status", "url")
+	return url
+}
+
+// setInferenceServiceCondition sets the InferenceServiceApplied condition
+func (r *ModelAPIReconciler) setInferenceServiceCondition(modelapi *kaosv1alpha1.ModelAPI, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&modelapi.Status.Conditions, metav1.Condition{
+		Type:               kaosv1alpha1.ConditionInferenceServiceApplied,
+		Status:             status,
+		ObservedGeneration: modelapi.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// pruneChild deletes the named child of the ModelAPI if it exists and is controlled by it,
+// e.g. the Deployment left behind after switching spec.backend to KServe
+func (r *ModelAPIReconciler) pruneChild(ctx context.Context, modelapi *kaosv1alpha1.ModelAPI, obj client.Object, log logr.Logger) error {
+	if err := r.Get(ctx, types.NamespacedName{Name: obj.GetName(), Namespace: modelapi.Namespace}, obj); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !metav1.IsControlledBy(obj, modelapi) {
+		return nil
+	}
+	log.Info("Deleting child replaced by backend change", "name", obj.GetName())
+	return client.IgnoreNotFound(r.Delete(ctx, obj, client.PropagationPolicy(metav1.DeletePropagationBackground)))
+}
+
+// reconcileInferenceService runs the ModelAPI pods from a KServe InferenceService instead of
+// the Deployment and Service of the default backend, which are removed. The ModelAPI reports
+// the URL KServe assigns as its endpoint, and is Ready once the InferenceService is.
+func (r *ModelAPIReconciler) reconcileInferenceService(ctx context.Context, modelapi *kaosv1alpha1.ModelAPI, desiredDeployment *appsv1.Deployment, deps []metav1.Object, log logr.Logger) (ctrl.Result, error) {
+	desired, err := constructInferenceService(modelapi, &desiredDeployment.Spec.Template, *desiredDeployment.Spec.Replicas, r.MaxReplicas)
+	if err != nil {
+		log.Error(err, "failed to construct InferenceService")
+		return ctrl.Result{}, err
+	}
+
+	isvc := newInferenceService(desired.GetName(), desired.GetNamespace())
+	err = r.Get(ctx, types.NamespacedName{Name: desired.GetName(), Namespace: desired.GetNamespace()}, isvc)
+	if err != nil && apierrors.IsNotFound(err) {
+		isvc = desired
+		if err := controllerutil.SetControllerReference(modelapi, isvc, r.Scheme); err != nil {
+			log.Error(err, "failed to set controller reference")
+			return ctrl.Result{}, err
+		}
+		log.Info("Creating InferenceService", "name", isvc.GetName())
+		if err := r.Create(ctx, isvc); err != nil {
+			log.Error(err, "failed to create InferenceService")
+			modelapi.Status.Phase = "Failed"
+			modelapi.Status.Message = fmt.Sprintf("Failed to create InferenceService: %v", err)
+			setReadyCondition(&modelapi.Status.Conditions, modelapi.Generation, kaosv1alpha1.ReasonReconcileFailed, modelapi.Status.Message)
+			r.Status().Update(ctx, modelapi)
+			return ctrl.Result{}, err
+		}
+	} else if err != nil {
+		log.Error(err, "failed to get InferenceService")
+		return ctrl.Result{}, err
+	} else {
+		// The pod template hash annotation covers the predictor, except for its replicas
+		currentPredictor, _, _ := unstructured.NestedMap(isvc.Object, "spec", "predictor")
+		desiredPredictor, _, _ := unstructured.NestedMap(desired.Object, "spec", "predictor")
+		specChanged := isvc.GetAnnotations()[util.PodSpecHashAnnotation] != desired.GetAnnotations()[util.PodSpecHashAnnotation] ||
+			currentPredictor["minReplicas"] != desiredPredictor["minReplicas"] ||
+			currentPredictor["maxReplicas"] != desiredPredictor["maxReplicas"]
+		if specChanged {
+			log.Info("Updating InferenceService due to spec change", "name", isvc.GetName())
+			if err := unstructured.SetNestedMap(isvc.Object, desiredPredictor, "spec", "predictor"); err != nil {
+				return ctrl.Result{}, err
+			}
+			annotations := isvc.GetAnnotations()
+			if annotations == nil {
+				annotations = map[string]string{}
+			}
+			for k, v := range desired.GetAnnotations() {
+				annotations[k] = v
+			}
+			isvc.SetAnnotations(annotations)
+		}
+		labelsChanged := util.PropagateLabels(isvc, modelapi, util.PropagatedLabelKeys())
+		if specChanged || labelsChanged {
+			if err := r.Update(ctx, isvc); err != nil {
+				log.Error(err, "failed to update InferenceService")
+				return ctrl.Result{}, err
+			}
+		}
+	}
+
+	// The Deployment and Service of the default backend are replaced by KServe's own
+	name := fmt.Sprintf("modelapi-%s", modelapi.Name)
+	for _, child := range []client.Object{
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: name}},
+	} {
+		if err := r.pruneChild(ctx, modelapi, child, log); err != nil {
+			log.Error(err, "failed to delete child of the Deployment backend", "name", name)
+			return ctrl.Result{}, err
+		}
+	}
+
+	r.setInferenceServiceCondition(modelapi, metav1.ConditionTrue, kaosv1alpha1.ReasonInferenceServiceApplied,
+		fmt.Sprintf("InferenceService %s runs the model server", isvc.GetName()))
+	modelapi.Status.Endpoint = inferenceServiceURL(isvc)
+	modelapi.Status.Deployment = nil
+	modelapi.Status.Replicas = 0
+	modelapi.Status.ReadyReplicas = 0
+	modelapi.Status.Selector = ""
+
+	readyReason := kaosv1alpha1.ReasonDeploymentNotReady
+	if inferenceServiceReady(isvc) {
+		modelapi.Status.Ready = true
+		modelapi.Status.Phase = "Ready"
+		modelapi.Status.Message = fmt.Sprintf("InferenceService %s is ready", isvc.GetName())
+		readyReason = kaosv1alpha1.ReasonDeploymentReady
+	} else {
+		modelapi.Status.Ready = false
+		modelapi.Status.Phase = "Pending"
+		modelapi.Status.Message = fmt.Sprintf("Waiting for InferenceService %s to be ready", isvc.GetName())
+	}
+	setReadyCondition(&modelapi.Status.Conditions, modelapi.Generation, readyReason, modelapi.Status.Message)
+	modelapi.Status.LastReconcileTime = reconcileTime(r.Clock)
+
+	if err := r.Status().Update(ctx, modelapi); err != nil {
+		log.Error(err, "failed to update status")
+		return ctrl.Result{}, err
+	}
+	if fingerprint, ok := observedFingerprint(ctx, r.Client, modelapi.Namespace, r.childObjects(modelapi), deps...); ok {
+		r.ReconcileCache.Record(modelapi, fingerprint)
+	}
+	return ctrl.Result{}, nil
+}
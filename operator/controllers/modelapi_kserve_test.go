@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+	"github.com/axsaucedo/kaos/operator/pkg/util"
+)
+
+var _ = Describe("ModelAPI spec.backend", func() {
+	var (
+		scheme   *runtime.Scheme
+		modelapi *kaosv1alpha1.ModelAPI
+	)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "served", Namespace: "default"}}
+	key := types.NamespacedName{Name: "modelapi-served", Namespace: "default"}
+
+	BeforeEach(func() {
+		scheme = newTestScheme()
+		scheme.AddKnownTypeWithName(inferenceServiceGVK, &unstructured.Unstructured{})
+		scheme.AddKnownTypeWithName(inferenceServiceGVK.GroupVersion().WithKind("InferenceServiceList"), &unstructured.UnstructuredList{})
+
+		modelapi = &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{Name: "served", Namespace: "default", UID: "modelapi-uid", Generation: 1},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode:         kaosv1alpha1.ModelAPIModeHosted,
+				Backend:      kaosv1alpha1.ModelAPIBackendKServe,
+				HostedConfig: &kaosv1alpha1.HostedConfig{Model: "smollm2:135m"},
+				PodSpec: &corev1.PodSpec{Containers: []corev1.Container{{
+					Name: "model-api",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+					},
+				}}},
+			},
+		}
+	})
+
+	newReconciler := func(kserveAvailable bool) (*ModelAPIReconciler, client.Client) {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(modelapi).
+			WithStatusSubresource(&kaosv1alpha1.ModelAPI{}).Build()
+		return &ModelAPIReconciler{Client: c, Scheme: scheme, Recorder: record.NewFakeRecorder(10),
+			ReconcileCache: util.NewReconcileCache(), KServeAvailable: kserveAvailable}, c
+	}
+
+	It("should run the model server from an InferenceService instead of a Deployment", func() {
+		r, c := newReconciler(true)
+		ctx := context.Background()
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(apierrors.IsNotFound(c.Get(ctx, key, &appsv1.Deployment{}))).To(BeTrue())
+		Expect(apierrors.IsNotFound(c.Get(ctx, key, &corev1.Service{}))).To(BeTrue())
+
+		isvc := newInferenceService("", "")
+		Expect(c.Get(ctx, key, isvc)).To(Succeed())
+		Expect(metav1.IsControlledBy(isvc, modelapi)).To(BeTrue())
+		Expect(isvc.GetAnnotations()).To(HaveKeyWithValue("serving.kserve.io/deploymentMode", "RawDeployment"))
+		Expect(isvc.GetLabels()).To(HaveKeyWithValue("modelapi", "served"))
+		minReplicas, _, _ := unstructured.NestedInt64(isvc.Object, "spec", "predictor", "minReplicas")
+		Expect(minReplicas).To(Equal(int64(1)))
+
+		containers, _, _ := unstructured.NestedSlice(isvc.Object, "spec", "predictor", "containers")
+		Expect(containers).NotTo(BeEmpty())
+		container := containers[0].(map[string]interface{})
+		Expect(container["name"]).To(Equal("kserve-container"))
+		Expect(container["image"]).To(ContainSubstring("ollama"))
+		cpu, _, _ := unstructured.NestedString(container, "resources", "requests", "cpu")
+		Expect(cpu).To(Equal("2"))
+		Expect(c.Get(ctx, req.NamespacedName, modelapi)).To(Succeed())
+		Expect(modelapi.Status.Phase).To(Equal("Pending"))
+		Expect(meta.IsStatusConditionTrue(modelapi.Status.Conditions, kaosv1alpha1.ConditionInferenceServiceApplied)).To(BeTrue())
+
+		// The ModelAPI follows the readiness and URL KServe reports
+		Expect(unstructured.SetNestedSlice(isvc.Object, []interface{}{
+			map[string]interface{}{"type": "Ready", "status": "True"},
+		}, "status", "conditions")).To(Succeed())
+		Expect(unstructured.SetNestedField(isvc.Object, "http://modelapi-served-predictor.default.svc.cluster.local", "status", "address", "url")).To(Succeed())
+		Expect(c.Update(ctx, isvc)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, req.NamespacedName, modelapi)).To(Succeed())
+		Expect(modelapi.Status.Phase).To(Equal("Ready"))
+		Expect(modelapi.Status.Endpoint).To(Equal("http://modelapi-served-predictor.default.svc.cluster.local"))
+
+		// Switching back to the Deployment backend removes the InferenceService
+		modelapi.Spec.Backend = kaosv1alpha1.ModelAPIBackendDeployment
+		modelapi.Generation++
+		Expect(c.Update(ctx, modelapi)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, key, &appsv1.Deployment{})).To(Succeed())
+		Expect(apierrors.IsNotFound(c.Get(ctx, key, newInferenceService("", "")))).To(BeTrue())
+		Expect(c.Get(ctx, req.NamespacedName, modelapi)).To(Succeed())
+		Expect(meta.FindStatusCondition(modelapi.Status.Conditions, kaosv1alpha1.ConditionInferenceServiceApplied)).To(BeNil())
+	})
+
+	It("should fall back to a Deployment without the KServe CRDs", func() {
+		r, c := newReconciler(false)
+		ctx := context.Background()
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, key, &appsv1.Deployment{})).To(Succeed())
+		Expect(c.Get(ctx, req.NamespacedName, modelapi)).To(Succeed())
+		condition := meta.FindStatusCondition(modelapi.Status.Conditions, kaosv1alpha1.ConditionInferenceServiceApplied)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(kaosv1alpha1.ReasonKServeUnavailable))
+	})
+})
@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+	"github.com/axsaucedo/kaos/operator/pkg/util"
+)
+
+// modelAPIModeAnnotation records on the Deployment the spec.mode it was created for
+const modelAPIModeAnnotation = util.ReservedAnnotationPrefix + "mode"
+
+// ModeMigratedReason is the reason of the event recorded when the children of a ModelAPI are
+// recreated after a spec.mode change
+const ModeMigratedReason = "ModeMigrated"
+
+// errModeChangeNotAllowed is returned when spec.mode changed without the migration annotation
+var errModeChangeNotAllowed = fmt.Errorf("spec.mode is immutable unless the %s annotation is \"true\"", kaosv1alpha1.AllowModeMigrationAnnotation)
+
+// migrateMode deletes the Deployment, Service and LiteLLM ConfigMap of a ModelAPI whose
+// spec.mode differs from the mode its Deployment was created for, so the rest of the reconcile
+// recreates them for the new mode. Without the allow-mode-migration annotation the change is
+// refused with errModeChangeNotAllowed and the children are left untouched.
+func (r *ModelAPIReconciler) migrateMode(ctx context.Context, modelapi *kaosv1alpha1.ModelAPI, log logr.Logger) error {
+	deployment := &appsv1.Deployment{}
+	if err := r.Get(ctx, client.ObjectKey{Name: fmt.Sprintf("modelapi-%s", modelapi.Name), Namespace: modelapi.Namespace}, deployment); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	previous := kaosv1alpha1.ModelAPIMode(deployment.Annotations[modelAPIModeAnnotation])
+	if previous == "" || previous == modelapi.Spec.Mode {
+		return nil
+	}
+	if modelapi.Annotations[kaosv1alpha1.AllowModeMigrationAnnotation] != "true" {
+		return errModeChangeNotAllowed
+	}
+
+	log.Info("Recreating children after mode change", "from", previous, "to", modelapi.Spec.Mode)
+	children := []client.Object{
+		deployment,
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("modelapi-%s", modelapi.Name), Namespace: modelapi.Namespace}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("litellm-config-%s", modelapi.Name), Namespace: modelapi.Namespace}},
+	}
+	for _, child := range children {
+		if err := r.Delete(ctx, child, client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("deleting %s: %w", child.GetName(), err)
+		}
+	}
+	if r.Recorder != nil {
+		r.Recorder.Eventf(modelapi, corev1.EventTypeNormal, ModeMigratedReason,
+			"Recreated the Deployment and Service for the change from %s to %s mode", previous, modelapi.Spec.Mode)
+	}
+	return nil
+}
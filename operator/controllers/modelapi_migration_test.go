@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+var _ = Describe("ModelAPI mode migration", func() {
+	It("should only recreate the children of a ModelAPI whose mode changed with the migration annotation", func() {
+		modelapi := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{Name: "migrate", Namespace: "default"},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode:        kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig: &kaosv1alpha1.ProxyConfig{Models: []string{"openai/gpt-4"}},
+			},
+		}
+		r, c := newCachedModelAPIReconciler(modelapi)
+		recorder := record.NewFakeRecorder(10)
+		r.Recorder = recorder
+		ctx := context.Background()
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "migrate", Namespace: "default"}}
+		name := types.NamespacedName{Name: "modelapi-migrate", Namespace: "default"}
+		configMapName := types.NamespacedName{Name: "litellm-config-migrate", Namespace: "default"}
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		proxyDeployment := &appsv1.Deployment{}
+		Expect(c.Get(ctx, name, proxyDeployment)).To(Succeed())
+		Expect(proxyDeployment.Annotations).To(HaveKeyWithValue(modelAPIModeAnnotation, "Proxy"))
+		Expect(c.Get(ctx, configMapName, &corev1.ConfigMap{})).To(Succeed())
+		// Mark the Proxy Deployment so a recreated one can be told apart
+		proxyDeployment.Annotations["test/generation"] = "proxy"
+		Expect(c.Update(ctx, proxyDeployment)).To(Succeed())
+
+		switchToHosted := func(annotations map[string]string) {
+			Expect(c.Get(ctx, req.NamespacedName, modelapi)).To(Succeed())
+			modelapi.Annotations = annotations
+			modelapi.Spec.Mode = kaosv1alpha1.ModelAPIModeHosted
+			modelapi.Spec.ProxyConfig = nil
+			modelapi.Spec.HostedConfig = &kaosv1alpha1.HostedConfig{Model: "smollm2:135m"}
+			Expect(c.Update(ctx, modelapi)).To(Succeed())
+		}
+
+		// Without the annotation the mode change is refused and the children are kept
+		switchToHosted(nil)
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, req.NamespacedName, modelapi)).To(Succeed())
+		Expect(modelapi.Status.Phase).To(Equal("Failed"))
+		Expect(modelapi.Status.Message).To(ContainSubstring(kaosv1alpha1.AllowModeMigrationAnnotation))
+		deployment := &appsv1.Deployment{}
+		Expect(c.Get(ctx, name, deployment)).To(Succeed())
+		Expect(deployment.Annotations).To(HaveKey("test/generation"))
+
+		// With it, the Proxy children are deleted and Hosted ones created
+		switchToHosted(map[string]string{kaosv1alpha1.AllowModeMigrationAnnotation: "true"})
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, name, deployment)).To(Succeed())
+		Expect(deployment.Annotations).NotTo(HaveKey("test/generation"))
+		Expect(deployment.Annotations).To(HaveKeyWithValue(modelAPIModeAnnotation, "Hosted"))
+		Expect(deployment.Spec.Template.Spec.Containers[0].Ports[0].ContainerPort).To(Equal(int32(11434)))
+		service := &corev1.Service{}
+		Expect(c.Get(ctx, name, service)).To(Succeed())
+		Expect(service.Spec.Ports[0].Port).To(Equal(int32(11434)))
+		Expect(apierrors.IsNotFound(c.Get(ctx, configMapName, &corev1.ConfigMap{}))).To(BeTrue())
+		Expect(recorder.Events).To(Receive(ContainSubstring(ModeMigratedReason)))
+	})
+})
@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+const (
+	// modelPullContainerName is the init container that downloads a Hosted ModelAPI's model
+	modelPullContainerName = "pull-model"
+
+	// modelPullFailureThreshold is the number of failed downloads from which a pod is
+	// considered crash looping on its model pull rather than hitting a transient error
+	modelPullFailureThreshold = 2
+
+	// modelPullPollInterval is how often pods that are still pulling their model are checked
+	// for failed downloads, as init container restarts do not change the Deployment
+	modelPullPollInterval = 30 * time.Second
+
+	// modelPullMaxBackoff caps the requeue interval while a download keeps failing
+	modelPullMaxBackoff = 5 * time.Minute
+
+	// ollamaRegistry serves the models of the Ollama library
+	ollamaRegistry = "https://registry.ollama.ai"
+)
+
+// pullsModel reports whether the ModelAPI pods download their model in an init container
+func pullsModel(modelapi *kaosv1alpha1.ModelAPI) bool {
+	return modelapi.Spec.Mode == kaosv1alpha1.ModelAPIModeHosted && modelapi.Spec.HostedConfig != nil &&
+		modelapi.Spec.HostedConfig.Model != "" && sharedCacheClaim(modelapi) == ""
+}
+
+// ollamaModelURL returns where Ollama downloads a model from: the library for bare names
+// such as smollm2:135m, a user namespace for names such as user/model, and the named host
+// for references such as hf.co/org/model
+func ollamaModelURL(model string) string {
+	parts := strings.Split(model, "/")
+	switch {
+	case len(parts) == 1:
+		return ollamaRegistry + "/library/" + model
+	case len(parts) == 2 && !strings.Contains(parts[0], "."):
+		return ollamaRegistry + "/" + model
+	default:
+		return "https://" + model
+	}
+}
+
+// modelPullFailure returns the pull-model init container status of the pod that failed its
+// download most often, if any pod failed at least modelPullFailureThreshold times
+func modelPullFailure(pods []corev1.Pod) *corev1.ContainerStatus {
+	var worst *corev1.ContainerStatus
+	for i := range pods {
+		for j := range pods[i].Status.InitContainerStatuses {
+			status := &pods[i].Status.InitContainerStatuses[j]
+			if status.Name != modelPullContainerName || status.RestartCount < modelPullFailureThreshold {
+				continue
+			}
+			if terminated := status.LastTerminationState.Terminated; terminated == nil || terminated.ExitCode == 0 {
+				continue
+			}
+			if worst == nil || status.RestartCount > worst.RestartCount {
+				worst = status
+			}
+		}
+	}
+	return worst
+}
+
+// modelPullBackoff doubles the requeue interval with every failed download beyond the
+// threshold, up to modelPullMaxBackoff
+func modelPullBackoff(restarts int32) time.Duration {
+	backoff := modelPullPollInterval
+	for i := int32(modelPullFailureThreshold); i < restarts && backoff < modelPullMaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, modelPullMaxBackoff)
+}
+
+// setModelDownloadCondition sets the Degraded condition with reason ModelDownloadFailed
+// while a pod keeps failing to download the model of a Hosted ModelAPI, naming the download
+// URL and the init container's last exit reason. It returns when to check the pods again:
+// with backoff while a download keeps failing, every modelPullPollInterval while pods are
+// still pulling, and 0 once every replica is ready.
+func (r *ModelAPIReconciler) setModelDownloadCondition(ctx context.Context, modelapi *kaosv1alpha1.ModelAPI, deployment *appsv1.Deployment, log logr.Logger) time.Duration {
+	if !pullsModel(modelapi) || deployment.Spec.Selector == nil {
+		return 0
+	}
+	if deployment.Spec.Replicas != nil && deployment.Status.ReadyReplicas >= *deployment.Spec.Replicas {
+		return 0
+	}
+
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(deployment.Namespace), client.MatchingLabels(deployment.Spec.Selector.MatchLabels)); err != nil {
+		log.Error(err, "failed to list ModelAPI pods")
+		return modelPullPollInterval
+	}
+	status := modelPullFailure(pods.Items)
+	if status == nil {
+		return modelPullPollInterval
+	}
+
+	terminated := status.LastTerminationState.Terminated
+	model := modelapi.Spec.HostedConfig.Model
+	message := fmt.Sprintf("Downloading model %s from %s failed %d times: init container %s last exited with reason %s (exit code %d)",
+		model, ollamaModelURL(model), status.RestartCount, modelPullContainerName, terminated.Reason, terminated.ExitCode)
+	if terminated.Message != "" {
+		message += ": " + terminated.Message
+	}
+	meta.SetStatusCondition(&modelapi.Status.Conditions, metav1.Condition{
+		Type:               kaosv1alpha1.ConditionDegraded,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: modelapi.Generation,
+		Reason:             kaosv1alpha1.ReasonModelDownloadFailed,
+		Message:            message,
+	})
+	return modelPullBackoff(status.RestartCount)
+}
@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+var _ = Describe("Hosted ModelAPI model download failures", func() {
+	It("should set Degraded with the download URL and the init container's exit reason", func() {
+		modelapi := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{Name: "ollama", Namespace: "default", UID: "modelapi-uid", Generation: 1},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode:         kaosv1alpha1.ModelAPIModeHosted,
+				HostedConfig: &kaosv1alpha1.HostedConfig{Model: "smollm2:135m"},
+			},
+		}
+		r, c := newCachedModelAPIReconciler(modelapi)
+		ctx := context.Background()
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "ollama", Namespace: "default"}}
+
+		// Pods still pulling the model are polled, as init container restarts trigger no reconcile
+		result, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(modelPullPollInterval))
+
+		deployment := &appsv1.Deployment{}
+		Expect(c.Get(ctx, types.NamespacedName{Name: "modelapi-ollama", Namespace: "default"}, deployment)).To(Succeed())
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "modelapi-ollama-7d9f8-x2k4p", Namespace: "default", Labels: deployment.Spec.Selector.MatchLabels},
+			Status: corev1.PodStatus{
+				InitContainerStatuses: []corev1.ContainerStatus{{
+					Name:         "pull-model",
+					RestartCount: 3,
+					LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+						ExitCode: 1, Reason: "Error", Message: "pull model manifest: file does not exist",
+					}},
+				}},
+			},
+		}
+		Expect(c.Create(ctx, pod)).To(Succeed())
+
+		result, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(time.Minute))
+		Expect(c.Get(ctx, req.NamespacedName, modelapi)).To(Succeed())
+		cond := meta.FindStatusCondition(modelapi.Status.Conditions, kaosv1alpha1.ConditionDegraded)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.Reason).To(Equal(kaosv1alpha1.ReasonModelDownloadFailed))
+		Expect(cond.Message).To(Equal("Downloading model smollm2:135m from https://registry.ollama.ai/library/smollm2:135m failed 3 times: " +
+			"init container pull-model last exited with reason Error (exit code 1): pull model manifest: file does not exist"))
+
+		// Once the pod is ready the condition is cleared and polling stops
+		Expect(c.Delete(ctx, pod)).To(Succeed())
+		deployment.Status.Replicas = 1
+		deployment.Status.ReadyReplicas = 1
+		Expect(c.Status().Update(ctx, deployment)).To(Succeed())
+		result, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeZero())
+		Expect(c.Get(ctx, req.NamespacedName, modelapi)).To(Succeed())
+		Expect(meta.FindStatusCondition(modelapi.Status.Conditions, kaosv1alpha1.ConditionDegraded)).To(BeNil())
+	})
+
+	It("should back off while downloads keep failing", func() {
+		Expect(modelPullBackoff(2)).To(Equal(30 * time.Second))
+		Expect(modelPullBackoff(4)).To(Equal(2 * time.Minute))
+		Expect(modelPullBackoff(20)).To(Equal(5 * time.Minute))
+	})
+
+	It("should resolve where Ollama downloads a model from", func() {
+		Expect(ollamaModelURL("llama3.2:3b")).To(Equal("https://registry.ollama.ai/library/llama3.2:3b"))
+		Expect(ollamaModelURL("user/model:latest")).To(Equal("https://registry.ollama.ai/user/model:latest"))
+		Expect(ollamaModelURL("hf.co/bartowski/Llama-3.2-1B-Instruct-GGUF")).To(Equal("https://hf.co/bartowski/Llama-3.2-1B-Instruct-GGUF"))
+	})
+})
@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"fmt"
+	"os"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+const (
+	// rateLimiterContainerName is the name of the rate-limiting proxy sidecar
+	rateLimiterContainerName = "rate-limiter"
+
+	// rateLimiterPort is the port the sidecar listens on; the Service targets it instead of LiteLLM
+	rateLimiterPort int32 = 8080
+
+	// rateLimiterTemplateKey is the litellm-config ConfigMap key holding the nginx config template
+	rateLimiterTemplateKey = "ratelimit.conf.template"
+)
+
+// rateLimiterTemplate is rendered by the nginx image entrypoint, which substitutes the
+// RATE_LIMIT_* env vars. All requests share one zone so the limit applies to the pod as a
+// whole rather than per client, and excess requests are rejected with 429.
+const rateLimiterTemplate = `limit_req_zone $server_name zone=modelapi:1m rate=${RATE_LIMIT_RPS}r/s;
+limit_req_status 429;
+
+server {
+    listen ${RATE_LIMIT_PORT};
+
+    location / {
+        limit_req zone=modelapi burst=${RATE_LIMIT_BURST} nodelay;
+        proxy_pass http://127.0.0.1:8000;
+        proxy_http_version 1.1;
+        proxy_set_header Host $host;
+        proxy_buffering off;
+        proxy_read_timeout 600s;
+    }
+}
+`
+
+// rateLimited reports whether the ModelAPI runs the rate-limiting sidecar
+func rateLimited(modelapi *kaosv1alpha1.ModelAPI) bool {
+	return modelapi.Spec.RateLimit != nil &&
+		modelapi.Spec.Mode == kaosv1alpha1.ModelAPIModeProxy && modelapi.Spec.ProxyConfig != nil
+}
+
+// constructRateLimiterContainer creates the nginx sidecar that enforces spec.rateLimit
+// before requests reach LiteLLM
+func (r *ModelAPIReconciler) constructRateLimiterContainer(modelapi *kaosv1alpha1.ModelAPI) corev1.Container {
+	image := os.Getenv("DEFAULT_RATE_LIMITER_IMAGE")
+	if image == "" {
+		image = "nginx:1.27-alpine"
+	}
+
+	rateLimit := modelapi.Spec.RateLimit
+	burst := rateLimit.RequestsPerSecond
+	if rateLimit.Burst != nil {
+		burst = *rateLimit.Burst
+	}
+
+	return corev1.Container{
+		Name:            rateLimiterContainerName,
+		Image:           image,
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		Ports: []corev1.ContainerPort{
+			{
+				Name:          "http-ratelimit",
+				ContainerPort: rateLimiterPort,
+				Protocol:      corev1.ProtocolTCP,
+			},
+		},
+		Env: []corev1.EnvVar{
+			{Name: "RATE_LIMIT_RPS", Value: fmt.Sprintf("%d", rateLimit.RequestsPerSecond)},
+			{Name: "RATE_LIMIT_BURST", Value: fmt.Sprintf("%d", burst)},
+			{Name: "RATE_LIMIT_PORT", Value: fmt.Sprintf("%d", rateLimiterPort)},
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      "litellm-config",
+				MountPath: "/etc/nginx/templates/" + rateLimiterTemplateKey,
+				SubPath:   rateLimiterTemplateKey,
+			},
+		},
+		ReadinessProbe: &corev1.Probe{
+			ProbeHandler: corev1.ProbeHandler{
+				TCPSocket: &corev1.TCPSocketAction{
+					Port: intstr.FromInt(int(rateLimiterPort)),
+				},
+			},
+			PeriodSeconds: 5,
+		},
+	}
+}
@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+const (
+	// referencedMinReplicas is the fewest replicas a ModelAPI runs while Agents reference it
+	referencedMinReplicas int32 = 1
+
+	// referencedBaseMinReplicasAnnotation records, on a HorizontalPodAutoscaler whose
+	// minReplicas of 0 was raised while Agents reference the ModelAPI, the minReplicas to
+	// restore once the last reference is removed
+	referencedBaseMinReplicasAnnotation = "kaos.tools/referenced-base-min-replicas"
+)
+
+// referencingAgents returns the sorted names of the Agents, other than those being deleted,
+// that use the ModelAPI. Agents embedding their model do not call the ModelAPI's pods.
+func referencingAgents(ctx context.Context, c client.Reader, modelapi *kaosv1alpha1.ModelAPI) ([]string, error) {
+	agents := &kaosv1alpha1.AgentList{}
+	if err := c.List(ctx, agents, client.InNamespace(modelapi.Namespace)); err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, agent := range agents.Items {
+		if agentModelAPIName(&agent) == modelapi.Name && agent.DeletionTimestamp == nil && agent.Spec.EmbeddedModel == nil {
+			names = append(names, agent.Name)
+		}
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+// referencesObject stands in for the referencing Agents in the reconcile cache fingerprint,
+// so adding or removing a reference re-renders the ModelAPI while other Agent updates do not
+func referencesObject(agents []string) metav1.Object {
+	return &metav1.ObjectMeta{UID: "referencing-agents", ResourceVersion: strings.Join(agents, ",")}
+}
+
+// agentToModelAPI maps an Agent to the ModelAPI it references. Updates map both the old and
+// the new object, so the previous ModelAPI of an Agent that switched is reconciled as well.
+func agentToModelAPI(_ context.Context, obj client.Object) []ctrl.Request {
+	agent, ok := obj.(*kaosv1alpha1.Agent)
+	if !ok || agentModelAPIName(agent) == "" {
+		return nil
+	}
+	return []ctrl.Request{{NamespacedName: types.NamespacedName{Name: agentModelAPIName(agent), Namespace: agent.Namespace}}}
+}
+
+// floorLiveReplicas scales a Deployment that was scaled to zero outside the operator, for
+// example by an event-driven autoscaler, back up to referencedMinReplicas. It reports whether
+// the replicas were raised
+func floorLiveReplicas(deployment *appsv1.Deployment) bool {
+	if deployment.Spec.Replicas == nil || *deployment.Spec.Replicas >= referencedMinReplicas {
+		return false
+	}
+	replicas := referencedMinReplicas
+	deployment.Spec.Replicas = &replicas
+	return true
+}
+
+// holdReferencedMinReplicas raises a minReplicas of 0 on the ModelAPI's
+// HorizontalPodAutoscalers to referencedMinReplicas while Agents reference it, and restores
+// the recorded minReplicas once the last reference is removed
+func (r *ModelAPIReconciler) holdReferencedMinReplicas(ctx context.Context, modelapi *kaosv1alpha1.ModelAPI, referenced bool) error {
+	hpas, err := modelAPIHPAs(ctx, r.Client, modelapi)
+	if err != nil {
+		return err
+	}
+	for i := range hpas {
+		hpa := &hpas[i]
+		baseValue, held := hpa.Annotations[referencedBaseMinReplicasAnnotation]
+		switch {
+		case referenced && !held:
+			if hpa.Spec.MinReplicas == nil || *hpa.Spec.MinReplicas >= referencedMinReplicas {
+				continue
+			}
+			if hpa.Annotations == nil {
+				hpa.Annotations = map[string]string{}
+			}
+			hpa.Annotations[referencedBaseMinReplicasAnnotation] = strconv.Itoa(int(*hpa.Spec.MinReplicas))
+			minReplicas := referencedMinReplicas
+			hpa.Spec.MinReplicas = &minReplicas
+		case !referenced && held:
+			delete(hpa.Annotations, referencedBaseMinReplicasAnnotation)
+			// Leave minReplicas alone if it was changed since, e.g. by spec.surgeReplicas
+			if parsed, err := strconv.ParseInt(baseValue, 10, 32); err == nil &&
+				hpa.Spec.MinReplicas != nil && *hpa.Spec.MinReplicas == referencedMinReplicas {
+				base := int32(parsed)
+				hpa.Spec.MinReplicas = &base
+			}
+		default:
+			continue
+		}
+		if err := r.Update(ctx, hpa); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreMinReplicas restores the minReplicas that spec.surgeReplicas and the referencing
+// Agents raised on the ModelAPI's HorizontalPodAutoscalers, which outlive the ModelAPI. The
+// surge is ended first, so that a minReplicas held at referencedMinReplicas underneath it is
+// then restored as well.
+func (r *ModelAPIReconciler) restoreMinReplicas(ctx context.Context, modelapi *kaosv1alpha1.ModelAPI) error {
+	if err := r.applySurge(ctx, modelapi, false); err != nil {
+		return fmt.Errorf("surge: %w", err)
+	}
+	if err := r.holdReferencedMinReplicas(ctx, modelapi, false); err != nil {
+		return fmt.Errorf("referenced hold: %w", err)
+	}
+	return nil
+}
+
+// setMinReplicasHeldCondition sets the MinReplicasHeld condition naming the referencing
+// Agents while there are any, and removes it otherwise
+func setMinReplicasHeldCondition(conditions *[]metav1.Condition, generation int64, agents []string) {
+	if len(agents) == 0 {
+		meta.RemoveStatusCondition(conditions, kaosv1alpha1.ConditionMinReplicasHeld)
+		return
+	}
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               kaosv1alpha1.ConditionMinReplicasHeld,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             kaosv1alpha1.ReasonReferencedByAgents,
+		Message:            fmt.Sprintf("Held at %d replica or more while referenced by Agents %s", referencedMinReplicas, strings.Join(agents, ", ")),
+	})
+}
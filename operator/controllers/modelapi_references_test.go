@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+var _ = Describe("Referenced ModelAPI replicas", func() {
+	It("should hold minReplicas at 1 while an Agent references the ModelAPI", func() {
+		zero := int32(0)
+		modelapi := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "default", UID: "modelapi-uid", Generation: 1},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode:        kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig: &kaosv1alpha1.ProxyConfig{Models: []string{"openai/gpt-4"}},
+				Replicas:    &zero,
+			},
+		}
+		agent := &kaosv1alpha1.Agent{
+			ObjectMeta: metav1.ObjectMeta{Name: "writer", Namespace: "default"},
+			Spec:       kaosv1alpha1.AgentSpec{ModelAPI: "shared", Model: "openai/gpt-4"},
+		}
+		hpa := &autoscalingv2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "default"},
+			Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "modelapi-shared"},
+				MinReplicas:    &zero,
+				MaxReplicas:    4,
+			},
+		}
+		r, c := newCachedModelAPIReconciler(modelapi, agent, hpa)
+		ctx := context.Background()
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "shared", Namespace: "default"}}
+		deploymentKey := types.NamespacedName{Name: "modelapi-shared", Namespace: "default"}
+		hpaKey := types.NamespacedName{Name: "shared", Namespace: "default"}
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		deployment := &appsv1.Deployment{}
+		Expect(c.Get(ctx, deploymentKey, deployment)).To(Succeed())
+		Expect(*deployment.Spec.Replicas).To(Equal(int32(1)))
+		Expect(c.Get(ctx, hpaKey, hpa)).To(Succeed())
+		Expect(*hpa.Spec.MinReplicas).To(Equal(int32(1)))
+		Expect(hpa.Annotations).To(HaveKeyWithValue(referencedBaseMinReplicasAnnotation, "0"))
+		Expect(c.Get(ctx, req.NamespacedName, modelapi)).To(Succeed())
+		cond := meta.FindStatusCondition(modelapi.Status.Conditions, kaosv1alpha1.ConditionMinReplicasHeld)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.Reason).To(Equal(kaosv1alpha1.ReasonReferencedByAgents))
+		Expect(cond.Message).To(ContainSubstring("referenced by Agents writer"))
+
+		// A scale to zero outside the HPA is reverted while the reference exists
+		deployment.Spec.Replicas = &zero
+		Expect(c.Update(ctx, deployment)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, deploymentKey, deployment)).To(Succeed())
+		Expect(*deployment.Spec.Replicas).To(Equal(int32(1)))
+
+		// Removing the last reference restores the HPA and lifts the condition
+		Expect(c.Delete(ctx, agent)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, hpaKey, hpa)).To(Succeed())
+		Expect(*hpa.Spec.MinReplicas).To(Equal(int32(0)))
+		Expect(hpa.Annotations).NotTo(HaveKey(referencedBaseMinReplicasAnnotation))
+		Expect(c.Get(ctx, req.NamespacedName, modelapi)).To(Succeed())
+		Expect(meta.FindStatusCondition(modelapi.Status.Conditions, kaosv1alpha1.ConditionMinReplicasHeld)).To(BeNil())
+	})
+
+	It("should map an Agent to the ModelAPI it references", func() {
+		agent := &kaosv1alpha1.Agent{
+			ObjectMeta: metav1.ObjectMeta{Name: "writer", Namespace: "team-a"},
+			Spec:       kaosv1alpha1.AgentSpec{ModelAPI: "llm"},
+		}
+		Expect(agentToModelAPI(context.Background(), agent)).To(ConsistOf(
+			ctrl.Request{NamespacedName: types.NamespacedName{Name: "llm", Namespace: "team-a"}}))
+	})
+})
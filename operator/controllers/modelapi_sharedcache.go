@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+const (
+	// sharedCacheVolumeName is the pod volume of the shared model cache claim
+	sharedCacheVolumeName = "model-cache"
+
+	// sharedCacheMountPath is where Ollama looks for models, inside the ollama-data volume
+	sharedCacheMountPath = "/root/.ollama/models"
+
+	// managedCacheLabel marks a shared model cache claim provisioned for KAOS, e.g. by the Job
+	// populating it. deletePVCOnDelete only deletes claims labelled "true", never other claims.
+	managedCacheLabel = "kaos.tools/managed-cache"
+
+	// sharedCachePollInterval is how often the shared model cache claim is read for changes
+	sharedCachePollInterval = time.Minute
+)
+
+// sharedCacheClaim returns the shared model cache claim of a Hosted ModelAPI, or "" if it has none
+func sharedCacheClaim(modelapi *kaosv1alpha1.ModelAPI) string {
+	if modelapi.Spec.Mode != kaosv1alpha1.ModelAPIModeHosted || modelapi.Spec.HostedConfig == nil {
+		return ""
+	}
+	return modelapi.Spec.HostedConfig.SharedCacheClaim
+}
+
+// sharedCacheObjects returns the shared model cache claim to fingerprint, so that creating,
+// deleting or resizing the claim is not skipped by the reconcile cache. The claim is read from
+// the API server, as claims are not cached. A claim that cannot be read is fingerprinted as a
+// placeholder.
+func (r *ModelAPIReconciler) sharedCacheObjects(ctx context.Context, modelapi *kaosv1alpha1.ModelAPI) []metav1.Object {
+	claim := sharedCacheClaim(modelapi)
+	if claim == "" {
+		return nil
+	}
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := r.Get(ctx, types.NamespacedName{Name: claim, Namespace: modelapi.Namespace}, pvc); err != nil {
+		return []metav1.Object{&metav1.ObjectMeta{UID: "shared-cache-claim", ResourceVersion: "missing"}}
+	}
+	return []metav1.Object{pvc}
+}
+
+// sharedCacheRequeue returns how often a ModelAPI with a shared model cache claim is
+// reconciled to pick up changes to the claim, or 0 without one. The claim is created by the
+// user rather than the ModelAPI, and claims are read from the API server rather than cached,
+// so no watch notices them.
+func sharedCacheRequeue(modelapi *kaosv1alpha1.ModelAPI) time.Duration {
+	if sharedCacheClaim(modelapi) == "" {
+		return 0
+	}
+	return sharedCachePollInterval
+}
+
+// setSharedCacheCondition sets the SharedCacheReady condition from the shared model cache
+// claim: False while the claim is missing or cannot be mounted by pods on several nodes,
+// True otherwise. The condition is removed when no shared cache is configured.
+func (r *ModelAPIReconciler) setSharedCacheCondition(ctx context.Context, modelapi *kaosv1alpha1.ModelAPI, log logr.Logger) {
+	claim := sharedCacheClaim(modelapi)
+	if claim == "" {
+		meta.RemoveStatusCondition(&modelapi.Status.Conditions, kaosv1alpha1.ConditionSharedCacheReady)
+		return
+	}
+
+	condition := metav1.Condition{
+		Type:               kaosv1alpha1.ConditionSharedCacheReady,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: modelapi.Generation,
+		Reason:             kaosv1alpha1.ReasonClaimShared,
+		Message:            fmt.Sprintf("PersistentVolumeClaim %s is mounted read-only", claim),
+	}
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := r.Get(ctx, types.NamespacedName{Name: claim, Namespace: modelapi.Namespace}, pvc); apierrors.IsNotFound(err) {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = kaosv1alpha1.ReasonClaimNotFound
+		condition.Message = fmt.Sprintf("PersistentVolumeClaim %s not found", claim)
+	} else if err != nil {
+		log.Error(err, "failed to get shared cache PersistentVolumeClaim", "name", claim)
+		return
+	} else if !slices.ContainsFunc(pvc.Spec.AccessModes, func(mode corev1.PersistentVolumeAccessMode) bool {
+		return mode == corev1.ReadWriteMany || mode == corev1.ReadOnlyMany
+	}) {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = kaosv1alpha1.ReasonClaimNotShared
+		condition.Message = fmt.Sprintf("PersistentVolumeClaim %s has access modes %v; ReadWriteMany or ReadOnlyMany is required to share it across pods", claim, pvc.Spec.AccessModes)
+	}
+	meta.SetStatusCondition(&modelapi.Status.Conditions, condition)
+}
+
+// deleteSharedCacheClaim deletes the shared model cache claim of a ModelAPI being deleted
+// with hostedConfig.deletePVCOnDelete set. Only claims carrying the managed cache label are
+// deleted, and they are retained while another ModelAPI in the namespace that is not being
+// deleted still mounts them.
+func (r *ModelAPIReconciler) deleteSharedCacheClaim(ctx context.Context, modelapi *kaosv1alpha1.ModelAPI, log logr.Logger) error {
+	claim := sharedCacheClaim(modelapi)
+	if claim == "" || !modelapi.Spec.HostedConfig.DeletePVCOnDelete {
+		return nil
+	}
+
+	modelapis := &kaosv1alpha1.ModelAPIList{}
+	if err := r.List(ctx, modelapis, client.InNamespace(modelapi.Namespace)); err != nil {
+		return fmt.Errorf("failed to list ModelAPIs sharing claim %s: %w", claim, err)
+	}
+	for i := range modelapis.Items {
+		other := &modelapis.Items[i]
+		if other.Name != modelapi.Name && other.DeletionTimestamp == nil && sharedCacheClaim(other) == claim {
+			log.Info("Retaining shared cache PersistentVolumeClaim still in use", "name", claim, "modelapi", other.Name)
+			return nil
+		}
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := r.Get(ctx, types.NamespacedName{Name: claim, Namespace: modelapi.Namespace}, pvc); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if pvc.Labels[managedCacheLabel] != "true" {
+		log.Info("Retaining shared cache PersistentVolumeClaim without the managed cache label",
+			"name", claim, "label", managedCacheLabel)
+		return nil
+	}
+	if err := r.Delete(ctx, pvc, client.Preconditions{UID: &pvc.UID}); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to delete shared cache claim %s: %w", claim, err)
+	}
+	log.Info("Deleted shared cache PersistentVolumeClaim", "name", claim)
+	return nil
+}
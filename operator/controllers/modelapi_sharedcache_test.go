@@ -0,0 +1,180 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+var _ = Describe("ModelAPI shared model cache", func() {
+	ctx := context.Background()
+
+	reconcileWithClaim := func(accessModes ...corev1.PersistentVolumeAccessMode) (*kaosv1alpha1.ModelAPI, *appsv1.Deployment) {
+		modelapi := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{Name: "cached", Namespace: "default"},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode:         kaosv1alpha1.ModelAPIModeHosted,
+				HostedConfig: &kaosv1alpha1.HostedConfig{Model: "smollm2:135m", SharedCacheClaim: "models"},
+			},
+		}
+		pvc := &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "models", Namespace: "default"},
+			Spec:       corev1.PersistentVolumeClaimSpec{AccessModes: accessModes},
+		}
+		r, c := newCachedModelAPIReconciler(modelapi, pvc)
+
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "cached", Namespace: "default"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, types.NamespacedName{Name: "cached", Namespace: "default"}, modelapi)).To(Succeed())
+		deployment := &appsv1.Deployment{}
+		Expect(c.Get(ctx, types.NamespacedName{Name: "modelapi-cached", Namespace: "default"}, deployment)).To(Succeed())
+		return modelapi, deployment
+	}
+
+	It("should mount a ReadWriteMany claim read-only instead of pulling the model", func() {
+		modelapi, deployment := reconcileWithClaim(corev1.ReadWriteMany)
+
+		podSpec := deployment.Spec.Template.Spec
+		Expect(podSpec.InitContainers).To(BeEmpty())
+		Expect(podSpec.Volumes).To(ContainElement(corev1.Volume{
+			Name: "model-cache",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "models", ReadOnly: true},
+			},
+		}))
+		Expect(podSpec.Containers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{
+			Name: "model-cache", MountPath: "/root/.ollama/models", ReadOnly: true,
+		}))
+
+		condition := meta.FindStatusCondition(modelapi.Status.Conditions, kaosv1alpha1.ConditionSharedCacheReady)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+	})
+
+	It("should set SharedCacheReady to False when the claim is not ReadWriteMany", func() {
+		modelapi, _ := reconcileWithClaim(corev1.ReadWriteOnce)
+
+		condition := meta.FindStatusCondition(modelapi.Status.Conditions, kaosv1alpha1.ConditionSharedCacheReady)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(kaosv1alpha1.ReasonClaimNotShared))
+		Expect(condition.Message).To(ContainSubstring("ReadWriteOnce"))
+	})
+
+	It("should update SharedCacheReady when the claim changes after a cached reconcile", func() {
+		modelapi := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{Name: "cached", Namespace: "default"},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode:         kaosv1alpha1.ModelAPIModeHosted,
+				HostedConfig: &kaosv1alpha1.HostedConfig{Model: "smollm2:135m", SharedCacheClaim: "models"},
+			},
+		}
+		r, c := newCachedModelAPIReconciler(modelapi)
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cached", Namespace: "default"}}
+		readiness := func() string {
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.Get(ctx, req.NamespacedName, modelapi)).To(Succeed())
+			return meta.FindStatusCondition(modelapi.Status.Conditions, kaosv1alpha1.ConditionSharedCacheReady).Reason
+		}
+		Expect(readiness()).To(Equal(kaosv1alpha1.ReasonClaimNotFound))
+		Expect(readiness()).To(Equal(kaosv1alpha1.ReasonClaimNotFound))
+
+		Expect(c.Create(ctx, &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "models", Namespace: "default"},
+			Spec:       corev1.PersistentVolumeClaimSpec{AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}},
+		})).To(Succeed())
+		Expect(readiness()).To(Equal(kaosv1alpha1.ReasonClaimShared))
+	})
+
+	It("should poll for claim changes only when a shared cache is configured", func() {
+		cached := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{Name: "cached", Namespace: "default"},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode:         kaosv1alpha1.ModelAPIModeHosted,
+				HostedConfig: &kaosv1alpha1.HostedConfig{Model: "smollm2:135m", SharedCacheClaim: "models"},
+			},
+		}
+		r, _ := newCachedModelAPIReconciler(cached)
+		result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "cached", Namespace: "default"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+		Expect(result.RequeueAfter).To(BeNumerically("<=", sharedCachePollInterval))
+
+		cached.Spec.HostedConfig.SharedCacheClaim = ""
+		Expect(sharedCacheRequeue(cached)).To(BeZero())
+	})
+})
+
+var _ = Describe("hostedConfig.deletePVCOnDelete", func() {
+	ctx := context.Background()
+	claimKey := types.NamespacedName{Name: "models", Namespace: "default"}
+	managed := map[string]string{managedCacheLabel: "true"}
+
+	// deleteModelAPI reconciles a ModelAPI using the "models" claim, deletes it and
+	// reconciles the deletion, alongside any other ModelAPIs given
+	deleteModelAPI := func(deletePVCOnDelete bool, claimLabels map[string]string, others ...client.Object) client.Client {
+		modelapi := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{Name: "cached", Namespace: "default"},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode: kaosv1alpha1.ModelAPIModeHosted,
+				HostedConfig: &kaosv1alpha1.HostedConfig{
+					Model: "smollm2:135m", SharedCacheClaim: "models", DeletePVCOnDelete: deletePVCOnDelete,
+				},
+			},
+		}
+		pvc := &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "models", Namespace: "default", Labels: claimLabels},
+			Spec:       corev1.PersistentVolumeClaimSpec{AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}},
+		}
+		r, c := newCachedModelAPIReconciler(append(others, modelapi, pvc)...)
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cached", Namespace: "default"}}
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, req.NamespacedName, modelapi)).To(Succeed())
+		Expect(c.Delete(ctx, modelapi)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(apierrors.IsNotFound(c.Get(ctx, req.NamespacedName, modelapi))).To(BeTrue())
+		return c
+	}
+
+	It("should delete the claim with the ModelAPI when the flag is set", func() {
+		c := deleteModelAPI(true, managed)
+		Expect(apierrors.IsNotFound(c.Get(ctx, claimKey, &corev1.PersistentVolumeClaim{}))).To(BeTrue())
+	})
+
+	It("should retain the claim when the flag is not set", func() {
+		c := deleteModelAPI(false, managed)
+		Expect(c.Get(ctx, claimKey, &corev1.PersistentVolumeClaim{})).To(Succeed())
+	})
+
+	It("should retain a claim without the managed cache label", func() {
+		c := deleteModelAPI(true, nil)
+		Expect(c.Get(ctx, claimKey, &corev1.PersistentVolumeClaim{})).To(Succeed())
+	})
+
+	It("should retain the claim while another ModelAPI still uses it", func() {
+		other := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode:         kaosv1alpha1.ModelAPIModeHosted,
+				HostedConfig: &kaosv1alpha1.HostedConfig{Model: "smollm2:135m", SharedCacheClaim: "models"},
+			},
+		}
+		c := deleteModelAPI(true, managed, other)
+		Expect(c.Get(ctx, claimKey, &corev1.PersistentVolumeClaim{})).To(Succeed())
+	})
+})
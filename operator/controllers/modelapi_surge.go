@@ -0,0 +1,129 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+const (
+	// surgeBaseMinReplicasAnnotation records, on a HorizontalPodAutoscaler raised for
+	// spec.surgeReplicas, the minReplicas to restore when the surge window closes
+	surgeBaseMinReplicasAnnotation = "kaos.tools/surge-base-min-replicas"
+
+	// SurgeStartedReason is the event reason when minReplicas is raised for a surge window
+	SurgeStartedReason = "SurgeStarted"
+
+	// SurgeEndedReason is the event reason when minReplicas is restored after a surge window
+	SurgeEndedReason = "SurgeEnded"
+)
+
+// surgeWindow reports whether the spec.surgeReplicas window is open at now, and how long
+// until it next opens or closes, or 0 when it never changes again
+func surgeWindow(surge *kaosv1alpha1.SurgeReplicasConfig, now time.Time) (bool, time.Duration) {
+	if surge == nil || !now.Before(surge.End.Time) {
+		return false, 0
+	}
+	if surge.Start != nil && now.Before(surge.Start.Time) {
+		return false, surge.Start.Sub(now)
+	}
+	return true, surge.End.Sub(now)
+}
+
+// modelAPIHPAs lists the HorizontalPodAutoscalers scaling the ModelAPI through its scale
+// subresource, or its Deployment directly
+func modelAPIHPAs(ctx context.Context, c client.Reader, modelapi *kaosv1alpha1.ModelAPI) ([]autoscalingv2.HorizontalPodAutoscaler, error) {
+	hpas := &autoscalingv2.HorizontalPodAutoscalerList{}
+	if err := c.List(ctx, hpas, client.InNamespace(modelapi.Namespace)); err != nil {
+		return nil, err
+	}
+	var targeting []autoscalingv2.HorizontalPodAutoscaler
+	for _, hpa := range hpas.Items {
+		ref := hpa.Spec.ScaleTargetRef
+		scalesDeployment := ref.Kind == "Deployment" && ref.Name == fmt.Sprintf("modelapi-%s", modelapi.Name) && strings.HasPrefix(ref.APIVersion, "apps/")
+		scalesModelAPI := ref.Kind == "ModelAPI" && ref.Name == modelapi.Name && strings.HasPrefix(ref.APIVersion, kaosv1alpha1.GroupVersion.Group+"/")
+		if scalesDeployment || scalesModelAPI {
+			targeting = append(targeting, hpa)
+		}
+	}
+	return targeting, nil
+}
+
+// reconcileSurge raises the minReplicas of the ModelAPI's HorizontalPodAutoscalers by
+// spec.surgeReplicas.replicas while the surge window is open, capped at their maxReplicas,
+// and restores the recorded minReplicas once it closes or surgeReplicas is removed. It
+// returns how long until the window next opens or closes.
+func (r *ModelAPIReconciler) reconcileSurge(ctx context.Context, modelapi *kaosv1alpha1.ModelAPI) (time.Duration, error) {
+	c := r.Clock
+	if c == nil {
+		c = clock.RealClock{}
+	}
+	active, next := surgeWindow(modelapi.Spec.SurgeReplicas, c.Now())
+	return next, r.applySurge(ctx, modelapi, active)
+}
+
+// applySurge raises the minReplicas of the ModelAPI's HorizontalPodAutoscalers while active,
+// and otherwise restores the minReplicas recorded when they were raised
+func (r *ModelAPIReconciler) applySurge(ctx context.Context, modelapi *kaosv1alpha1.ModelAPI, active bool) error {
+	hpas, err := modelAPIHPAs(ctx, r.Client, modelapi)
+	if err != nil {
+		return err
+	}
+	for i := range hpas {
+		hpa := &hpas[i]
+		baseValue, surging := hpa.Annotations[surgeBaseMinReplicasAnnotation]
+		if !active && !surging {
+			continue
+		}
+
+		base := int32(1)
+		if hpa.Spec.MinReplicas != nil {
+			base = *hpa.Spec.MinReplicas
+		}
+		if surging {
+			if parsed, err := strconv.ParseInt(baseValue, 10, 32); err == nil {
+				base = int32(parsed)
+			}
+		}
+
+		if active {
+			desired := min(base+modelapi.Spec.SurgeReplicas.Replicas, hpa.Spec.MaxReplicas)
+			if surging && hpa.Spec.MinReplicas != nil && *hpa.Spec.MinReplicas == desired {
+				continue
+			}
+			if hpa.Annotations == nil {
+				hpa.Annotations = map[string]string{}
+			}
+			hpa.Annotations[surgeBaseMinReplicasAnnotation] = strconv.Itoa(int(base))
+			hpa.Spec.MinReplicas = &desired
+			if err := r.Update(ctx, hpa); err != nil {
+				return err
+			}
+			if !surging && r.Recorder != nil {
+				r.Recorder.Eventf(modelapi, corev1.EventTypeNormal, SurgeStartedReason,
+					"Raised minReplicas of HorizontalPodAutoscaler %s from %d to %d until %s", hpa.Name, base, desired, modelapi.Spec.SurgeReplicas.End.UTC().Format(time.RFC3339))
+			}
+			continue
+		}
+
+		delete(hpa.Annotations, surgeBaseMinReplicasAnnotation)
+		hpa.Spec.MinReplicas = &base
+		if err := r.Update(ctx, hpa); err != nil {
+			return err
+		}
+		if r.Recorder != nil {
+			r.Recorder.Eventf(modelapi, corev1.EventTypeNormal, SurgeEndedReason,
+				"Restored minReplicas of HorizontalPodAutoscaler %s to %d", hpa.Name, base)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clocktesting "k8s.io/utils/clock/testing"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+var _ = Describe("ModelAPI spec.surgeReplicas", func() {
+	var (
+		ctx       context.Context
+		r         *ModelAPIReconciler
+		c         client.Client
+		fakeClock *clocktesting.FakePassiveClock
+		start     time.Time
+		req       ctrl.Request
+	)
+
+	// hpaMinReplicas returns the minReplicas of the HorizontalPodAutoscaler in the fake cluster
+	hpaMinReplicas := func() int32 {
+		hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+		Expect(c.Get(ctx, types.NamespacedName{Name: "llm", Namespace: "default"}, hpa)).To(Succeed())
+		return *hpa.Spec.MinReplicas
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		start = time.Date(2026, 11, 27, 8, 0, 0, 0, time.UTC)
+		fakeClock = clocktesting.NewFakePassiveClock(start)
+
+		modelapi := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{Name: "llm", Namespace: "default", UID: "surge-uid"},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode:        kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig: &kaosv1alpha1.ProxyConfig{Models: []string{"openai/gpt-4"}},
+				SurgeReplicas: &kaosv1alpha1.SurgeReplicasConfig{
+					Replicas: 3,
+					Start:    &metav1.Time{Time: start.Add(time.Hour)},
+					End:      metav1.Time{Time: start.Add(3 * time.Hour)},
+				},
+			},
+		}
+		minReplicas := int32(2)
+		hpa := &autoscalingv2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "llm", Namespace: "default"},
+			Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{APIVersion: "kaos.tools/v1alpha1", Kind: "ModelAPI", Name: "llm"},
+				MinReplicas:    &minReplicas,
+				MaxReplicas:    10,
+			},
+		}
+		r, c = newCachedModelAPIReconciler(modelapi, hpa)
+		r.Clock = fakeClock
+		req = ctrl.Request{NamespacedName: types.NamespacedName{Name: "llm", Namespace: "default"}}
+	})
+
+	It("should bump the HPA minReplicas during the surge window and restore it afterwards", func() {
+		// Before the window the HPA is untouched and the reconcile wakes up when it opens
+		result, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hpaMinReplicas()).To(Equal(int32(2)))
+		Expect(result.RequeueAfter).To(Equal(time.Hour))
+
+		// Inside the window the minimum is raised, even though nothing else changed
+		fakeClock.SetTime(start.Add(90 * time.Minute))
+		result, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hpaMinReplicas()).To(Equal(int32(5)))
+		Expect(result.RequeueAfter).To(Equal(90 * time.Minute))
+
+		// Reconciling again during the window keeps the raised minimum
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hpaMinReplicas()).To(Equal(int32(5)))
+
+		// Once the window closes the original minimum is restored
+		fakeClock.SetTime(start.Add(3 * time.Hour))
+		result, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hpaMinReplicas()).To(Equal(int32(2)))
+		Expect(result.RequeueAfter).To(BeZero())
+		hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+		Expect(c.Get(ctx, types.NamespacedName{Name: "llm", Namespace: "default"}, hpa)).To(Succeed())
+		Expect(hpa.Annotations).NotTo(HaveKey(surgeBaseMinReplicasAnnotation))
+	})
+
+	It("should cap the raised minimum at maxReplicas and restore it when surgeReplicas is removed", func() {
+		fakeClock.SetTime(start.Add(2 * time.Hour))
+		hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+		Expect(c.Get(ctx, types.NamespacedName{Name: "llm", Namespace: "default"}, hpa)).To(Succeed())
+		hpa.Spec.MaxReplicas = 4
+		Expect(c.Update(ctx, hpa)).To(Succeed())
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hpaMinReplicas()).To(Equal(int32(4)))
+
+		modelapi := &kaosv1alpha1.ModelAPI{}
+		Expect(c.Get(ctx, req.NamespacedName, modelapi)).To(Succeed())
+		modelapi.Spec.SurgeReplicas = nil
+		Expect(c.Update(ctx, modelapi)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hpaMinReplicas()).To(Equal(int32(2)))
+	})
+
+	It("should restore the surge and the referenced hold when the ModelAPI is deleted", func() {
+		hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+		Expect(c.Get(ctx, types.NamespacedName{Name: "llm", Namespace: "default"}, hpa)).To(Succeed())
+		zero := int32(0)
+		hpa.Spec.MinReplicas = &zero
+		Expect(c.Update(ctx, hpa)).To(Succeed())
+		agent := &kaosv1alpha1.Agent{
+			ObjectMeta: metav1.ObjectMeta{Name: "writer", Namespace: "default"},
+			Spec:       kaosv1alpha1.AgentSpec{ModelAPI: "llm", Model: "openai/gpt-4"},
+		}
+		Expect(c.Create(ctx, agent)).To(Succeed())
+
+		// The reference holds minReplicas at 1, and the surge then raises it from there
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hpaMinReplicas()).To(Equal(int32(1)))
+		fakeClock.SetTime(start.Add(90 * time.Minute))
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hpaMinReplicas()).To(Equal(int32(4)))
+
+		// Deleting the ModelAPI mid-surge restores the HPA it leaves behind
+		modelapi := &kaosv1alpha1.ModelAPI{}
+		Expect(c.Get(ctx, req.NamespacedName, modelapi)).To(Succeed())
+		Expect(c.Delete(ctx, modelapi)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, req.NamespacedName, modelapi)).NotTo(Succeed())
+		Expect(c.Get(ctx, types.NamespacedName{Name: "llm", Namespace: "default"}, hpa)).To(Succeed())
+		Expect(*hpa.Spec.MinReplicas).To(Equal(int32(0)))
+		Expect(hpa.Annotations).NotTo(HaveKey(surgeBaseMinReplicasAnnotation))
+		Expect(hpa.Annotations).NotTo(HaveKey(referencedBaseMinReplicasAnnotation))
+	})
+})
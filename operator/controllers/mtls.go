@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+	"github.com/axsaucedo/kaos/operator/pkg/util"
+)
+
+const (
+	// mtlsVolumeName is the pod volume holding the spec.mtls Secret
+	mtlsVolumeName = "mtls"
+
+	// mtlsMountPath is where the spec.mtls Secret is mounted in the main container
+	mtlsMountPath = "/etc/kaos/mtls"
+
+	// mtlsCAKey is the Secret key of the CA bundle, as written by cert-manager
+	mtlsCAKey = "ca.crt"
+)
+
+// mtlsSecretName returns the Secret referenced by spec.mtls, or "" when mTLS is disabled
+func mtlsSecretName(config *kaosv1alpha1.MTLSConfig) string {
+	if config == nil {
+		return ""
+	}
+	return config.SecretName
+}
+
+// mtlsScheme returns the URL scheme the ModelAPI serves: https when spec.mtls is set
+func mtlsScheme(config *kaosv1alpha1.MTLSConfig) string {
+	if mtlsSecretName(config) == "" {
+		return "http"
+	}
+	return "https"
+}
+
+// mtlsProbeScheme returns the scheme of the model server's HTTP probes, which must follow
+// the server onto TLS. The kubelet does not verify the certificate.
+func mtlsProbeScheme(config *kaosv1alpha1.MTLSConfig) corev1.URIScheme {
+	if mtlsSecretName(config) == "" {
+		return corev1.URISchemeHTTP
+	}
+	return corev1.URISchemeHTTPS
+}
+
+// litellmTLSArgs makes LiteLLM serve TLS with the mounted certificate, or returns nothing
+// when mTLS is disabled
+func litellmTLSArgs(config *kaosv1alpha1.MTLSConfig) []string {
+	if mtlsSecretName(config) == "" {
+		return nil
+	}
+	return []string{
+		"--ssl_certfile_path", mtlsMountPath + "/" + corev1.TLSCertKey,
+		"--ssl_keyfile_path", mtlsMountPath + "/" + corev1.TLSPrivateKeyKey,
+	}
+}
+
+// validateMTLS reports an error unless the Agent and its ModelAPI either both leave mTLS
+// disabled or reference the same Secret, since either side alone fails every handshake
+func validateMTLS(agent *kaosv1alpha1.Agent, modelapi *kaosv1alpha1.ModelAPI) error {
+	agentSecret, modelAPISecret := mtlsSecretName(agent.Spec.MTLS), mtlsSecretName(modelapi.Spec.MTLS)
+	if agentSecret == modelAPISecret {
+		return nil
+	}
+	if modelAPISecret == "" {
+		return fmt.Errorf("mTLS secret %q is set but ModelAPI %s does not enable mTLS", agentSecret, modelapi.Name)
+	}
+	if agentSecret == "" {
+		return fmt.Errorf("ModelAPI %s requires mTLS with secret %q but spec.mtls is not set", modelapi.Name, modelAPISecret)
+	}
+	return fmt.Errorf("mTLS secret %q does not match secret %q of ModelAPI %s", agentSecret, modelAPISecret, modelapi.Name)
+}
+
+// mtlsEnvVars returns the paths of the mounted certificate files, or nothing when mTLS is
+// disabled
+func mtlsEnvVars(config *kaosv1alpha1.MTLSConfig) []corev1.EnvVar {
+	if mtlsSecretName(config) == "" {
+		return nil
+	}
+	return []corev1.EnvVar{
+		{Name: util.MTLSEnabledEnv, Value: "true"},
+		{Name: util.MTLSCertFileEnv, Value: mtlsMountPath + "/" + corev1.TLSCertKey},
+		{Name: util.MTLSKeyFileEnv, Value: mtlsMountPath + "/" + corev1.TLSPrivateKeyKey},
+		{Name: util.MTLSCAFileEnv, Value: mtlsMountPath + "/" + mtlsCAKey},
+	}
+}
+
+// mountMTLSSecret adds the spec.mtls Secret as a read-only volume of the named container
+func mountMTLSSecret(spec *corev1.PodSpec, containerName string, config *kaosv1alpha1.MTLSConfig) {
+	secretName := mtlsSecretName(config)
+	if secretName == "" {
+		return
+	}
+	spec.Volumes = append(spec.Volumes, corev1.Volume{
+		Name:         mtlsVolumeName,
+		VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: secretName}},
+	})
+	for i := range spec.Containers {
+		if spec.Containers[i].Name == containerName {
+			spec.Containers[i].VolumeMounts = append(spec.Containers[i].VolumeMounts, corev1.VolumeMount{
+				Name:      mtlsVolumeName,
+				MountPath: mtlsMountPath,
+				ReadOnly:  true,
+			})
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+var _ = Describe("spec.mtls", func() {
+	var (
+		agent    *kaosv1alpha1.Agent
+		modelapi *kaosv1alpha1.ModelAPI
+	)
+
+	BeforeEach(func() {
+		modelapi = &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode:        kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig: &kaosv1alpha1.ProxyConfig{Models: []string{"openai/gpt-4"}},
+				MTLS:        &kaosv1alpha1.MTLSConfig{SecretName: "agent-model-tls"},
+			},
+		}
+		agent = &kaosv1alpha1.Agent{
+			ObjectMeta: metav1.ObjectMeta{Name: "secure", Namespace: "default"},
+			Spec: kaosv1alpha1.AgentSpec{
+				ModelAPI: "api",
+				Model:    "openai/gpt-4",
+				MTLS:     &kaosv1alpha1.MTLSConfig{SecretName: "agent-model-tls"},
+			},
+		}
+	})
+
+	// expectMTLS checks that the Secret is mounted into the named container with the
+	// certificate paths in its environment
+	expectMTLS := func(spec corev1.PodSpec, containerName string) {
+		Expect(spec.Volumes).To(ContainElement(corev1.Volume{
+			Name:         mtlsVolumeName,
+			VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "agent-model-tls"}},
+		}))
+		var container *corev1.Container
+		for i := range spec.Containers {
+			if spec.Containers[i].Name == containerName {
+				container = &spec.Containers[i]
+			}
+		}
+		Expect(container).NotTo(BeNil())
+		Expect(container.VolumeMounts).To(ContainElement(corev1.VolumeMount{
+			Name: mtlsVolumeName, MountPath: "/etc/kaos/mtls", ReadOnly: true,
+		}))
+		Expect(container.Env).To(ContainElements(
+			corev1.EnvVar{Name: "MTLS_ENABLED", Value: "true"},
+			corev1.EnvVar{Name: "MTLS_CERT_FILE", Value: "/etc/kaos/mtls/tls.crt"},
+			corev1.EnvVar{Name: "MTLS_KEY_FILE", Value: "/etc/kaos/mtls/tls.key"},
+			corev1.EnvVar{Name: "MTLS_CA_FILE", Value: "/etc/kaos/mtls/ca.crt"},
+		))
+	}
+
+	It("should mount the shared Secret into both the agent and the ModelAPI pods", func() {
+		Expect(validateMTLS(agent, modelapi)).To(Succeed())
+
+		agentDeployment := (&AgentReconciler{}).constructDeployment(agent, modelapi, nil, nil, nil)
+		expectMTLS(agentDeployment.Spec.Template.Spec, "agent")
+
+		modelAPIDeployment := (&ModelAPIReconciler{}).constructDeployment(modelapi)
+		expectMTLS(modelAPIDeployment.Spec.Template.Spec, "model-api")
+	})
+
+	It("should serve the ModelAPI over HTTPS and point the agent at it", func() {
+		container := (&ModelAPIReconciler{}).constructContainer(modelapi)
+		Expect(container.Args).To(ContainElements(
+			"--ssl_certfile_path", "/etc/kaos/mtls/tls.crt",
+			"--ssl_keyfile_path", "/etc/kaos/mtls/tls.key",
+		))
+		Expect(container.LivenessProbe.HTTPGet.Scheme).To(Equal(corev1.URISchemeHTTPS))
+		Expect(container.ReadinessProbe.HTTPGet.Scheme).To(Equal(corev1.URISchemeHTTPS))
+
+		r, c := newCachedModelAPIReconciler(modelapi)
+		_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(modelapi)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(context.Background(), client.ObjectKeyFromObject(modelapi), modelapi)).To(Succeed())
+		Expect(modelapi.Status.Endpoint).To(Equal("https://modelapi-api.default.svc.cluster.local:8000"))
+
+		agentContainer := (&AgentReconciler{}).constructDeployment(agent, modelapi, nil, nil, nil).Spec.Template.Spec.Containers[0]
+		Expect(envValue(agentContainer, "MODEL_API_URL")).To(Equal(modelapi.Status.Endpoint))
+	})
+
+	It("should keep plain HTTP without mTLS", func() {
+		modelapi.Spec.MTLS = nil
+		container := (&ModelAPIReconciler{}).constructContainer(modelapi)
+		Expect(container.Args).NotTo(ContainElement("--ssl_certfile_path"))
+		Expect(container.ReadinessProbe.HTTPGet.Scheme).To(Equal(corev1.URISchemeHTTP))
+	})
+
+	It("should reject an Agent whose Secret differs from its ModelAPI's", func() {
+		agent.Spec.MTLS.SecretName = "other-tls"
+		Expect(validateMTLS(agent, modelapi)).To(MatchError(ContainSubstring(`does not match secret "agent-model-tls"`)))
+
+		agent.Spec.MTLS = nil
+		Expect(validateMTLS(agent, modelapi)).To(MatchError(ContainSubstring("requires mTLS")))
+
+		modelapi.Spec.MTLS = nil
+		Expect(validateMTLS(agent, modelapi)).To(Succeed())
+		Expect((&AgentReconciler{}).constructDeployment(agent, modelapi, nil, nil, nil).Spec.Template.Spec.Volumes).To(BeEmpty())
+	})
+})
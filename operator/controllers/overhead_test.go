@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+	"github.com/axsaucedo/kaos/operator/pkg/util"
+)
+
+var _ = Describe("spec.overhead", func() {
+	runtimeClass := "kata"
+	overhead := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("250m"),
+		corev1.ResourceMemory: resource.MustParse("160Mi"),
+	}
+	meta := metav1.ObjectMeta{Name: "sandboxed", Namespace: "default"}
+
+	It("should set the pod overhead of an Agent when configured", func() {
+		agent := &kaosv1alpha1.Agent{
+			ObjectMeta: meta,
+			Spec:       kaosv1alpha1.AgentSpec{ModelAPI: "api", Model: "mock-model", RuntimeClassName: &runtimeClass},
+		}
+		r := &AgentReconciler{}
+		deployment := r.constructDeployment(agent, &kaosv1alpha1.ModelAPI{}, nil, nil, nil)
+		Expect(deployment.Spec.Template.Spec.Overhead).To(BeNil())
+		hash := deployment.Spec.Template.Annotations[util.PodSpecHashAnnotation]
+
+		// Overhead changes roll the pods, as it only applies at admission
+		agent.Spec.Overhead = overhead
+		deployment = r.constructDeployment(agent, &kaosv1alpha1.ModelAPI{}, nil, nil, nil)
+		Expect(deployment.Spec.Template.Spec.Overhead).To(Equal(overhead))
+		Expect(*deployment.Spec.Template.Spec.RuntimeClassName).To(Equal("kata"))
+		Expect(deployment.Spec.Template.Annotations[util.PodSpecHashAnnotation]).NotTo(Equal(hash))
+	})
+
+	It("should set the pod overhead of a ModelAPI when configured", func() {
+		modelapi := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: meta,
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode:             kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig:      &kaosv1alpha1.ProxyConfig{Models: []string{"openai/gpt-4"}},
+				RuntimeClassName: &runtimeClass,
+				Overhead:         overhead,
+			},
+		}
+		Expect((&ModelAPIReconciler{}).constructDeployment(modelapi).Spec.Template.Spec.Overhead).To(Equal(overhead))
+	})
+
+	It("should set the pod overhead of an MCPServer when configured", func() {
+		mcpserver := &kaosv1alpha1.MCPServer{
+			ObjectMeta: meta,
+			Spec: kaosv1alpha1.MCPServerSpec{
+				Type: kaosv1alpha1.MCPServerTypePython,
+				Config: kaosv1alpha1.MCPServerConfig{
+					Tools: &kaosv1alpha1.MCPToolsConfig{FromPackage: "mcp-server-calculator"},
+				},
+				RuntimeClassName: &runtimeClass,
+				Overhead:         overhead,
+			},
+		}
+		Expect((&MCPServerReconciler{}).constructDeployment(mcpserver).Spec.Template.Spec.Overhead).To(Equal(overhead))
+	})
+})
@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+	"github.com/axsaucedo/kaos/operator/pkg/util"
+)
+
+var _ = Describe("Component pod labels", func() {
+	meta := metav1.ObjectMeta{Name: "labelled", Namespace: "default"}
+
+	// expectComponentLabels asserts that the pods carry the component labels and that the
+	// selector does not
+	expectComponentLabels := func(deployment *appsv1.Deployment, component string) {
+		Expect(deployment.Spec.Template.Labels).To(HaveKeyWithValue(util.ComponentLabel, component))
+		Expect(deployment.Spec.Template.Labels).To(HaveKeyWithValue(util.CRNameLabel, "labelled"))
+		Expect(deployment.Spec.Selector.MatchLabels).NotTo(HaveKey(util.ComponentLabel))
+		Expect(deployment.Spec.Selector.MatchLabels).NotTo(HaveKey(util.CRNameLabel))
+		Expect(deployment.Labels).NotTo(HaveKey(util.ComponentLabel))
+	}
+
+	It("should label agent pods", func() {
+		agent := &kaosv1alpha1.Agent{ObjectMeta: meta, Spec: kaosv1alpha1.AgentSpec{ModelAPI: "api", Model: "mock-model"}}
+		deployment := (&AgentReconciler{}).constructDeployment(agent, &kaosv1alpha1.ModelAPI{}, nil, nil, nil)
+		expectComponentLabels(deployment, "agent")
+		Expect(constructStatefulSet(agent, deployment).Spec.Template.Labels).To(HaveKeyWithValue(util.ComponentLabel, "agent"))
+	})
+
+	It("should label model-api pods", func() {
+		modelapi := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: meta,
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode:        kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig: &kaosv1alpha1.ProxyConfig{Models: []string{"openai/gpt-4"}},
+			},
+		}
+		expectComponentLabels((&ModelAPIReconciler{}).constructDeployment(modelapi), "modelapi")
+	})
+
+	It("should label mcp-server pods", func() {
+		mcpserver := &kaosv1alpha1.MCPServer{
+			ObjectMeta: meta,
+			Spec: kaosv1alpha1.MCPServerSpec{
+				Type: kaosv1alpha1.MCPServerTypePython,
+				Config: kaosv1alpha1.MCPServerConfig{
+					Tools: &kaosv1alpha1.MCPToolsConfig{FromPackage: "mcp-server-calculator"},
+				},
+			},
+		}
+		expectComponentLabels((&MCPServerReconciler{}).constructDeployment(mcpserver), "mcpserver")
+	})
+})
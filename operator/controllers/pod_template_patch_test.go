@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+	"github.com/axsaucedo/kaos/operator/pkg/util"
+)
+
+var _ = Describe("Global pod template patch", func() {
+	It("should add the patch's env var to the reconciled pod, over the user's podSpec", func() {
+		r, c := newCachedMCPServerReconciler(nil)
+		patch, err := util.NewPodTemplatePatch(util.PodTemplatePatchStrategic, `
+spec:
+  containers:
+  - name: mcp-server
+    env:
+    - name: LOG_SINK
+      value: fluentd.logging:24224
+  - name: log-shipper
+    image: fluent/fluent-bit:3.0
+`)
+		Expect(err).NotTo(HaveOccurred())
+		r.PodTemplatePatch = patch
+
+		ctx := context.Background()
+		mcpserver := &kaosv1alpha1.MCPServer{}
+		Expect(c.Get(ctx, types.NamespacedName{Name: "cached", Namespace: "default"}, mcpserver)).To(Succeed())
+		mcpserver.Spec.PodSpec = &corev1.PodSpec{Containers: []corev1.Container{{
+			Name: "mcp-server",
+			Env:  []corev1.EnvVar{{Name: "LOG_SINK", Value: "stdout"}},
+		}}}
+		Expect(c.Update(ctx, mcpserver)).To(Succeed())
+
+		_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "cached", Namespace: "default"}})
+		Expect(err).NotTo(HaveOccurred())
+
+		deployment := &appsv1.Deployment{}
+		Expect(c.Get(ctx, types.NamespacedName{Name: "mcpserver-cached", Namespace: "default"}, deployment)).To(Succeed())
+		containers := deployment.Spec.Template.Spec.Containers
+		Expect(containers).To(HaveLen(2))
+		Expect(containers[0].Name).To(Equal("mcp-server"))
+		Expect(containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "LOG_SINK", Value: "fluentd.logging:24224"}))
+		Expect(containers[0].Env).NotTo(ContainElement(corev1.EnvVar{Name: "LOG_SINK", Value: "stdout"}))
+		Expect(containers[1].Image).To(Equal("fluent/fluent-bit:3.0"))
+	})
+})
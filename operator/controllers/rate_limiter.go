@@ -0,0 +1,27 @@
+package controllers
+
+import (
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// DefaultRetryBaseDelay and DefaultRetryMaxDelay are controller-runtime's defaults for the
+	// backoff of failed reconciles
+	DefaultRetryBaseDelay = 5 * time.Millisecond
+	DefaultRetryMaxDelay  = 1000 * time.Second
+)
+
+// NewRateLimiter returns the workqueue rate limiter of the controllers: failed reconciles of a
+// resource are retried after baseDelay, doubling on every further failure up to maxDelay.
+// Like controller-runtime's default, an overall 10 qps, 100 burst bucket bounds the retries of
+// all resources together.
+func NewRateLimiter(baseDelay, maxDelay time.Duration) workqueue.TypedRateLimiter[reconcile.Request] {
+	return workqueue.NewTypedMaxOfRateLimiter(
+		workqueue.NewTypedItemExponentialFailureRateLimiter[reconcile.Request](baseDelay, maxDelay),
+		&workqueue.TypedBucketRateLimiter[reconcile.Request]{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
+	)
+}
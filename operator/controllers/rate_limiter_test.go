@@ -0,0 +1,28 @@
+package controllers
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var _ = Describe("NewRateLimiter", func() {
+	It("should retry failed reconciles after the base delay, backing off up to the max delay", func() {
+		limiter := NewRateLimiter(2*time.Second, 5*time.Second)
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "flaky", Namespace: "default"}}
+		other := ctrl.Request{NamespacedName: types.NamespacedName{Name: "other", Namespace: "default"}}
+
+		Expect(limiter.When(req)).To(Equal(2 * time.Second))
+		Expect(limiter.When(req)).To(Equal(4 * time.Second))
+		Expect(limiter.When(req)).To(Equal(5 * time.Second))
+		Expect(limiter.NumRequeues(req)).To(Equal(3))
+
+		// Each resource backs off on its own, and a successful reconcile resets it
+		Expect(limiter.When(other)).To(Equal(2 * time.Second))
+		limiter.Forget(req)
+		Expect(limiter.When(req)).To(Equal(2 * time.Second))
+	})
+})
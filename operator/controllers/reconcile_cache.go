@@ -0,0 +1,37 @@
+package controllers
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/axsaucedo/kaos/operator/pkg/gateway"
+	"github.com/axsaucedo/kaos/operator/pkg/util"
+)
+
+// observedFingerprint fetches the named child objects and fingerprints them together with
+// the resolved dependencies. Returns false if any child does not exist (yet), in which case
+// the reconcile must not be short-circuited.
+func observedFingerprint(ctx context.Context, c client.Reader, namespace string, children []client.Object, deps ...metav1.Object) (string, bool) {
+	objs := make([]metav1.Object, 0, len(children)+len(deps))
+	for _, child := range children {
+		if err := c.Get(ctx, types.NamespacedName{Name: child.GetName(), Namespace: namespace}, child); err != nil {
+			return "", false
+		}
+		objs = append(objs, child)
+	}
+	return util.Fingerprint(append(objs, deps...)...), true
+}
+
+// httpRouteChild returns the HTTPRoute child to fingerprint when Gateway API is enabled
+func httpRouteChild(resourceType gateway.ResourceType, resourceName string) []client.Object {
+	if !gateway.GetConfig().Enabled {
+		return nil
+	}
+	route := &gatewayv1.HTTPRoute{}
+	route.Name = gateway.HTTPRouteName(resourceType, resourceName)
+	return []client.Object{route}
+}
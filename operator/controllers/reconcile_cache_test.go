@@ -0,0 +1,98 @@
+package controllers
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+	"github.com/axsaucedo/kaos/operator/pkg/util"
+)
+
+// deployedImage returns the image of the MCP server container in the fake cluster
+func deployedImage(c client.Client) string {
+	deployment := &appsv1.Deployment{}
+	Expect(c.Get(context.Background(), types.NamespacedName{Name: "mcpserver-cached", Namespace: "default"}, deployment)).To(Succeed())
+	return deployment.Spec.Template.Spec.Containers[0].Image
+}
+
+var _ = Describe("ReconcileCache", func() {
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cached", Namespace: "default"}}
+
+	AfterEach(func() {
+		os.Unsetenv("DEFAULT_MCP_SERVER_IMAGE")
+	})
+
+	It("should skip rendering on a no-op reconcile until the generation changes", func() {
+		r, c := newCachedMCPServerReconciler(util.NewReconcileCache())
+		ctx := context.Background()
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deployedImage(c)).To(Equal("axsauze/kaos-agent:latest"))
+
+		// A changed default image would alter the rendered Deployment, so an unchanged
+		// image after reconciling proves the fast path returned before rendering
+		os.Setenv("DEFAULT_MCP_SERVER_IMAGE", "example.com/kaos-agent:v2")
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deployedImage(c)).To(Equal("axsauze/kaos-agent:latest"))
+
+		// A new generation invalidates the cache entry
+		mcpserver := &kaosv1alpha1.MCPServer{}
+		Expect(c.Get(ctx, req.NamespacedName, mcpserver)).To(Succeed())
+		mcpserver.Generation = 2
+		Expect(c.Update(ctx, mcpserver)).To(Succeed())
+
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deployedImage(c)).To(Equal("example.com/kaos-agent:v2"))
+	})
+
+	It("should render on every reconcile without a cache", func() {
+		r, c := newCachedMCPServerReconciler(nil)
+		ctx := context.Background()
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		os.Setenv("DEFAULT_MCP_SERVER_IMAGE", "example.com/kaos-agent:v2")
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deployedImage(c)).To(Equal("example.com/kaos-agent:v2"))
+	})
+})
+
+// BenchmarkMCPServerNoOpReconcile compares no-op reconciles with and without the cache
+func BenchmarkMCPServerNoOpReconcile(b *testing.B) {
+	RegisterTestingT(b)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cached", Namespace: "default"}}
+
+	for _, bc := range []struct {
+		name  string
+		cache *util.ReconcileCache
+	}{
+		{"Uncached", nil},
+		{"Cached", util.NewReconcileCache()},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			r, _ := newCachedMCPServerReconciler(bc.cache)
+			if _, err := r.Reconcile(context.Background(), req); err != nil {
+				b.Fatal(err)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := r.Reconcile(context.Background(), req); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
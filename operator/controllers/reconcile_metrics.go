@@ -0,0 +1,31 @@
+package controllers
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/axsaucedo/kaos/operator/pkg/util"
+)
+
+// observeReconcile counts the reconcile of the resource in the reconcile metrics, labelled
+// from the resource as it is after the reconcile, and stamps its last reconcile timestamp on
+// success. A deleted resource is counted without labels and its timestamp is dropped.
+func observeReconcile(ctx context.Context, c client.Reader, metrics *util.ReconcileMetrics, kind string, obj client.Object, key types.NamespacedName, err error) {
+	if metrics == nil {
+		return
+	}
+	getErr := c.Get(ctx, key, obj)
+	if getErr != nil {
+		obj.SetLabels(nil)
+	}
+	metrics.Observe(kind, obj.GetLabels(), err)
+	switch {
+	case apierrors.IsNotFound(getErr):
+		metrics.Forget(kind, key)
+	case err == nil:
+		metrics.MarkReconciled(kind, key)
+	}
+}
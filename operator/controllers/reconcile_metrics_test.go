@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+	"github.com/axsaucedo/kaos/operator/pkg/util"
+)
+
+var _ = Describe("Reconcile metrics", func() {
+	It("should label kaos_reconcile_total with the allowlisted labels of the resource", func() {
+		r, c := newCachedMCPServerReconciler(nil)
+		metrics, err := util.NewReconcileMetrics([]string{"team"})
+		Expect(err).NotTo(HaveOccurred())
+		r.Metrics = metrics
+		ctx := context.Background()
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cached", Namespace: "default"}}
+
+		mcpserver := &kaosv1alpha1.MCPServer{}
+		Expect(c.Get(ctx, req.NamespacedName, mcpserver)).To(Succeed())
+		mcpserver.Labels = map[string]string{"team": "search", "owner": "alice"}
+		Expect(c.Update(ctx, mcpserver)).To(Succeed())
+
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "deleted", Namespace: "default"}})
+		Expect(err).NotTo(HaveOccurred())
+
+		// Labels outside the allowlist are not exported
+		Expect(testutil.CollectAndCompare(r.Metrics, strings.NewReader(`
+# HELP kaos_reconcile_total Total number of reconciles per resource kind and result
+# TYPE kaos_reconcile_total counter
+kaos_reconcile_total{kind="MCPServer",result="success",team=""} 1
+kaos_reconcile_total{kind="MCPServer",result="success",team="search"} 1
+`), "kaos_reconcile_total")).To(Succeed())
+	})
+
+	It("should stamp the last reconcile timestamp on no-op reconciles and drop it on deletion", func() {
+		r, c := newCachedMCPServerReconciler(util.NewReconcileCache())
+		metrics, err := util.NewReconcileMetrics(nil)
+		Expect(err).NotTo(HaveOccurred())
+		r.Metrics = metrics
+		ctx := context.Background()
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cached", Namespace: "default"}}
+		lastReconcile := func() float64 {
+			registry := prometheus.NewRegistry()
+			Expect(registry.Register(r.Metrics)).To(Succeed())
+			families, err := registry.Gather()
+			Expect(err).NotTo(HaveOccurred())
+			for _, family := range families {
+				if family.GetName() == "kaos_last_reconcile_timestamp_seconds" {
+					Expect(family.Metric).To(HaveLen(1))
+					return family.Metric[0].GetGauge().GetValue()
+				}
+			}
+			return 0
+		}
+
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		first := lastReconcile()
+		Expect(first).To(BeNumerically(">", 0))
+
+		// The second reconcile takes the fast path and leaves status.lastReconcileTime alone,
+		// but still advances the gauge
+		time.Sleep(10 * time.Millisecond)
+		mcpserver := &kaosv1alpha1.MCPServer{}
+		Expect(c.Get(ctx, req.NamespacedName, mcpserver)).To(Succeed())
+		stamped := mcpserver.Status.LastReconcileTime
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, req.NamespacedName, mcpserver)).To(Succeed())
+		Expect(mcpserver.Status.LastReconcileTime).To(Equal(stamped))
+		Expect(lastReconcile()).To(BeNumerically(">", first))
+
+		Expect(c.Delete(ctx, mcpserver)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(testutil.CollectAndCount(r.Metrics, "kaos_last_reconcile_timestamp_seconds")).To(Equal(0))
+	})
+})
@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+	"github.com/axsaucedo/kaos/operator/pkg/util"
+)
+
+var _ = Describe("kaos.agentic/redeploy", func() {
+	It("should roll the pods when the annotation changes", func() {
+		r, c := newCachedMCPServerReconciler(util.NewReconcileCache())
+		ctx := context.Background()
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cached", Namespace: "default"}}
+		deploymentKey := types.NamespacedName{Name: "mcpserver-cached", Namespace: "default"}
+		template := func() map[string]string {
+			deployment := &appsv1.Deployment{}
+			Expect(c.Get(ctx, deploymentKey, deployment)).To(Succeed())
+			return deployment.Spec.Template.Annotations
+		}
+		redeploy := func(value string) {
+			mcpserver := &kaosv1alpha1.MCPServer{}
+			Expect(c.Get(ctx, req.NamespacedName, mcpserver)).To(Succeed())
+			mcpserver.Annotations = map[string]string{util.RedeployAnnotation: value}
+			Expect(c.Update(ctx, mcpserver)).To(Succeed())
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		initial := template()
+		Expect(initial).NotTo(HaveKey(util.RedeployAnnotation))
+
+		redeploy("2026-10-14T09:00:00Z")
+		first := template()
+		Expect(first).To(HaveKeyWithValue(util.RedeployAnnotation, "2026-10-14T09:00:00Z"))
+		Expect(first[util.PodSpecHashAnnotation]).NotTo(Equal(initial[util.PodSpecHashAnnotation]))
+
+		// Each new value rolls the pods again
+		redeploy("2026-10-14T10:00:00Z")
+		second := template()
+		Expect(second).To(HaveKeyWithValue(util.RedeployAnnotation, "2026-10-14T10:00:00Z"))
+		Expect(second[util.PodSpecHashAnnotation]).NotTo(Equal(first[util.PodSpecHashAnnotation]))
+	})
+})
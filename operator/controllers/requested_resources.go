@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+// requestedCPUDesc describes kaos_namespace_requested_cpu
+var requestedCPUDesc = prometheus.NewDesc("kaos_namespace_requested_cpu",
+	"CPU cores requested by the workloads of all resources of a kind in a namespace",
+	[]string{"namespace", "kind"}, nil)
+
+// RequestedResourcesCollector exports, for capacity planning, the resources requested by the
+// Deployments and StatefulSets of Agents, ModelAPIs and MCPServers, summed per namespace and
+// kind. They are computed from the workloads when scraped, so deleted resources drop out.
+type RequestedResourcesCollector struct {
+	Client client.Reader
+}
+
+var _ prometheus.Collector = &RequestedResourcesCollector{}
+
+// Describe implements prometheus.Collector
+func (c *RequestedResourcesCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- requestedCPUDesc
+}
+
+// Collect implements prometheus.Collector
+func (c *RequestedResourcesCollector) Collect(ch chan<- prometheus.Metric) {
+	type key struct{ namespace, kind string }
+	milliCPU := map[key]int64{}
+	add := func(obj metav1.Object, replicas *int32, podSpec *corev1.PodSpec) {
+		kind := kaosOwnerKind(obj)
+		if kind == "" {
+			return
+		}
+		count := int64(1)
+		if replicas != nil {
+			count = int64(*replicas)
+		}
+		request := podCPURequest(podSpec)
+		milliCPU[key{obj.GetNamespace(), kind}] += count * request.MilliValue()
+	}
+
+	ctx := context.Background()
+	deployments := &appsv1.DeploymentList{}
+	if err := c.Client.List(ctx, deployments); err != nil {
+		ch <- prometheus.NewInvalidMetric(requestedCPUDesc, err)
+		return
+	}
+	for i := range deployments.Items {
+		deployment := &deployments.Items[i]
+		add(deployment, deployment.Spec.Replicas, &deployment.Spec.Template.Spec)
+	}
+	statefulSets := &appsv1.StatefulSetList{}
+	if err := c.Client.List(ctx, statefulSets); err != nil {
+		ch <- prometheus.NewInvalidMetric(requestedCPUDesc, err)
+		return
+	}
+	for i := range statefulSets.Items {
+		statefulSet := &statefulSets.Items[i]
+		add(statefulSet, statefulSet.Spec.Replicas, &statefulSet.Spec.Template.Spec)
+	}
+
+	for k, milli := range milliCPU {
+		ch <- prometheus.MustNewConstMetric(requestedCPUDesc, prometheus.GaugeValue, float64(milli)/1000, k.namespace, k.kind)
+	}
+}
+
+// kaosOwnerKind returns the kind of the Agent, ModelAPI or MCPServer controlling a workload,
+// or "" for workloads the operator does not manage
+func kaosOwnerKind(obj metav1.Object) string {
+	owner := metav1.GetControllerOf(obj)
+	if owner == nil || owner.APIVersion != kaosv1alpha1.GroupVersion.String() {
+		return ""
+	}
+	switch owner.Kind {
+	case "Agent", "ModelAPI", "MCPServer":
+		return owner.Kind
+	}
+	return ""
+}
+
+// podCPURequest returns the CPU a pod requests from the scheduler: its containers and sidecar
+// init containers, or the largest regular init container if that is more, plus the pod overhead
+func podCPURequest(podSpec *corev1.PodSpec) resource.Quantity {
+	total := resource.Quantity{}
+	for _, container := range podSpec.Containers {
+		total.Add(*container.Resources.Requests.Cpu())
+	}
+	var largestInit resource.Quantity
+	for _, container := range podSpec.InitContainers {
+		request := *container.Resources.Requests.Cpu()
+		if container.RestartPolicy != nil && *container.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+			total.Add(request)
+		} else if request.Cmp(largestInit) > 0 {
+			largestInit = request
+		}
+	}
+	if largestInit.Cmp(total) > 0 {
+		total = largestInit
+	}
+	total.Add(*podSpec.Overhead.Cpu())
+	return total
+}
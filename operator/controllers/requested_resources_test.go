@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+var _ = Describe("kaos_namespace_requested_cpu", func() {
+	It("should sum the CPU requested by the resources of a kind in a namespace", func() {
+		r, c := newCachedMCPServerReconciler(nil)
+		ctx := context.Background()
+		requestCPU := func(name, cpu string) {
+			mcpserver := &kaosv1alpha1.MCPServer{}
+			Expect(c.Get(ctx, types.NamespacedName{Name: "cached", Namespace: "default"}, mcpserver)).To(Succeed())
+			if name != mcpserver.Name {
+				mcpserver = &kaosv1alpha1.MCPServer{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}, Spec: *mcpserver.Spec.DeepCopy()}
+			}
+			mcpserver.Spec.PodSpec = &corev1.PodSpec{Containers: []corev1.Container{{
+				Name:      "mcp-server",
+				Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)}},
+			}}}
+			if mcpserver.ResourceVersion == "" {
+				Expect(c.Create(ctx, mcpserver)).To(Succeed())
+			} else {
+				Expect(c.Update(ctx, mcpserver)).To(Succeed())
+			}
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: name, Namespace: "default"}})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		requestCPU("cached", "250m")
+		requestCPU("second", "500m")
+
+		// Workloads the operator does not manage are not counted
+		unmanaged := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "unmanaged", Namespace: "default"},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Name:      "app",
+				Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")}},
+			}}}}},
+		}
+		Expect(c.Create(ctx, unmanaged)).To(Succeed())
+
+		Expect(testutil.CollectAndCompare(&RequestedResourcesCollector{Client: c}, strings.NewReader(`
+# HELP kaos_namespace_requested_cpu CPU cores requested by the workloads of all resources of a kind in a namespace
+# TYPE kaos_namespace_requested_cpu gauge
+kaos_namespace_requested_cpu{kind="MCPServer",namespace="default"} 0.75
+`))).To(Succeed())
+	})
+
+	It("should count the largest init container and the pod overhead", func() {
+		cpu := func(value string) corev1.ResourceRequirements {
+			return corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(value)}}
+		}
+		always := corev1.ContainerRestartPolicyAlways
+		podSpec := &corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "main", Resources: cpu("200m")}},
+			InitContainers: []corev1.Container{
+				{Name: "sidecar", Resources: cpu("100m"), RestartPolicy: &always},
+				{Name: "pull-model", Resources: cpu("1")},
+			},
+			Overhead: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("50m")},
+		}
+		request := podCPURequest(podSpec)
+		Expect(request.MilliValue()).To(Equal(int64(1050)))
+
+		podSpec.InitContainers[1].Resources = cpu("100m")
+		request = podCPURequest(podSpec)
+		Expect(request.MilliValue()).To(Equal(int64(350)))
+	})
+})
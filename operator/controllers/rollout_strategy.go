@@ -0,0 +1,41 @@
+package controllers
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/axsaucedo/kaos/operator/pkg/util"
+)
+
+// rolloutStrategy returns the Deployment strategy of a resource. With the BlueGreenRollout
+// feature gate a full set of new pods must be ready before old pods are removed; otherwise
+// it is the Kubernetes default rolling update, which the API server would set anyway.
+func rolloutStrategy(obj client.Object) appsv1.DeploymentStrategy {
+	maxSurge, maxUnavailable := intstr.FromString("25%"), intstr.FromString("25%")
+	if util.FeatureGateEnabled(obj, util.FeatureGateBlueGreenRollout) {
+		maxSurge, maxUnavailable = intstr.FromString("100%"), intstr.FromInt32(0)
+	}
+	return appsv1.DeploymentStrategy{
+		Type: appsv1.RollingUpdateDeploymentStrategyType,
+		RollingUpdate: &appsv1.RollingUpdateDeployment{
+			MaxSurge:       &maxSurge,
+			MaxUnavailable: &maxUnavailable,
+		},
+	}
+}
+
+// rolloutStrategyChanged reports whether a live Deployment's strategy differs from the
+// desired one
+func rolloutStrategyChanged(live, desired appsv1.DeploymentStrategy) bool {
+	return !equality.Semantic.DeepEqual(live, desired)
+}
+
+// minReadySeconds returns the configured stability window of new pods, or 0
+func minReadySeconds(seconds *int32) int32 {
+	if seconds == nil {
+		return 0
+	}
+	return *seconds
+}
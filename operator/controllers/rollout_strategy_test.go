@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+	"github.com/axsaucedo/kaos/operator/pkg/util"
+)
+
+var _ = Describe("BlueGreenRollout feature gate", func() {
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cached", Namespace: "default"}}
+
+	// rollingUpdate returns the rolling update parameters of the MCPServer's Deployment
+	rollingUpdate := func(ctx context.Context, r *MCPServerReconciler) *appsv1.RollingUpdateDeployment {
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		deployment := &appsv1.Deployment{}
+		Expect(r.Get(ctx, types.NamespacedName{Name: "mcpserver-cached", Namespace: "default"}, deployment)).To(Succeed())
+		Expect(deployment.Spec.Strategy.Type).To(Equal(appsv1.RollingUpdateDeploymentStrategyType))
+		return deployment.Spec.Strategy.RollingUpdate
+	}
+	setFeatureGates := func(ctx context.Context, r *MCPServerReconciler, value string) {
+		mcpserver := &kaosv1alpha1.MCPServer{}
+		Expect(r.Get(ctx, req.NamespacedName, mcpserver)).To(Succeed())
+		mcpserver.Annotations = map[string]string{util.FeatureGatesAnnotation: value}
+		Expect(r.Update(ctx, mcpserver)).To(Succeed())
+	}
+
+	It("should switch the rollout strategy when the gate is toggled", func() {
+		r, _ := newCachedMCPServerReconciler(util.NewReconcileCache())
+		ctx := context.Background()
+		defaultStrategy := &appsv1.RollingUpdateDeployment{
+			MaxSurge: &intstr.IntOrString{Type: intstr.String, StrVal: "25%"}, MaxUnavailable: &intstr.IntOrString{Type: intstr.String, StrVal: "25%"},
+		}
+		Expect(rollingUpdate(ctx, r)).To(Equal(defaultStrategy))
+
+		setFeatureGates(ctx, r, "BlueGreenRollout=true,Canary=true")
+		Expect(rollingUpdate(ctx, r)).To(Equal(&appsv1.RollingUpdateDeployment{
+			MaxSurge: &intstr.IntOrString{Type: intstr.String, StrVal: "100%"}, MaxUnavailable: &intstr.IntOrString{Type: intstr.Int, IntVal: 0},
+		}))
+
+		setFeatureGates(ctx, r, "BlueGreenRollout=false")
+		Expect(rollingUpdate(ctx, r)).To(Equal(defaultStrategy))
+	})
+})
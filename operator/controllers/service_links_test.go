@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+var _ = Describe("enableServiceLinks", func() {
+	enabled := true
+	meta := metav1.ObjectMeta{Name: "links", Namespace: "default"}
+
+	It("should disable Service links on Agent pods unless enabled", func() {
+		agent := &kaosv1alpha1.Agent{
+			ObjectMeta: meta,
+			Spec:       kaosv1alpha1.AgentSpec{ModelAPI: "api", Model: "mock-model"},
+		}
+		r := &AgentReconciler{}
+		deployment := r.constructDeployment(agent, &kaosv1alpha1.ModelAPI{}, nil, nil, nil)
+		Expect(*deployment.Spec.Template.Spec.EnableServiceLinks).To(BeFalse())
+
+		agent.Spec.EnableServiceLinks = &enabled
+		deployment = r.constructDeployment(agent, &kaosv1alpha1.ModelAPI{}, nil, nil, nil)
+		Expect(*deployment.Spec.Template.Spec.EnableServiceLinks).To(BeTrue())
+	})
+
+	It("should disable Service links on ModelAPI pods unless enabled", func() {
+		modelapi := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: meta,
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode:        kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig: &kaosv1alpha1.ProxyConfig{Models: []string{"openai/gpt-4"}},
+			},
+		}
+		r := &ModelAPIReconciler{}
+		Expect(*r.constructDeployment(modelapi).Spec.Template.Spec.EnableServiceLinks).To(BeFalse())
+
+		modelapi.Spec.EnableServiceLinks = &enabled
+		Expect(*r.constructDeployment(modelapi).Spec.Template.Spec.EnableServiceLinks).To(BeTrue())
+	})
+
+	It("should disable Service links on MCPServer pods unless enabled", func() {
+		mcpserver := &kaosv1alpha1.MCPServer{
+			ObjectMeta: meta,
+			Spec: kaosv1alpha1.MCPServerSpec{
+				Type: kaosv1alpha1.MCPServerTypePython,
+				Config: kaosv1alpha1.MCPServerConfig{
+					Tools: &kaosv1alpha1.MCPToolsConfig{FromPackage: "mcp-server-calculator"},
+				},
+			},
+		}
+		r := &MCPServerReconciler{}
+		Expect(*r.constructDeployment(mcpserver).Spec.Template.Spec.EnableServiceLinks).To(BeFalse())
+
+		mcpserver.Spec.EnableServiceLinks = &enabled
+		Expect(*r.constructDeployment(mcpserver).Spec.Template.Spec.EnableServiceLinks).To(BeTrue())
+	})
+})
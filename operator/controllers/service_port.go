@@ -0,0 +1,55 @@
+package controllers
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+const (
+	// defaultServicePortName is the name of the generated Service port
+	defaultServicePortName = "http"
+
+	// appProtocolHTTP and appProtocolGRPC are the appProtocol defaults of the Service port
+	appProtocolHTTP = "http"
+	appProtocolGRPC = "grpc"
+)
+
+// servicePortName returns spec.servicePort.name, or the default port name
+func servicePortName(config *kaosv1alpha1.ServicePortConfig) string {
+	if config == nil || config.Name == "" {
+		return defaultServicePortName
+	}
+	return config.Name
+}
+
+// servicePortAppProtocol returns spec.servicePort.appProtocol, or the given default
+func servicePortAppProtocol(config *kaosv1alpha1.ServicePortConfig, fallback string) *string {
+	if config == nil || config.AppProtocol == "" {
+		return &fallback
+	}
+	return &config.AppProtocol
+}
+
+// modelAPIAppProtocol is the default appProtocol of the ModelAPI Service port: grpc when the
+// server is probed over gRPC on the port the Service routes to, and http otherwise, including
+// behind the HTTP rate-limiting proxy
+func modelAPIAppProtocol(modelapi *kaosv1alpha1.ModelAPI) string {
+	hc := modelapi.Spec.HealthCheck
+	if hc != nil && hc.Type == kaosv1alpha1.HealthCheckTypeGRPC && hc.Port == nil && !rateLimited(modelapi) {
+		return appProtocolGRPC
+	}
+	return appProtocolHTTP
+}
+
+// servicePortRenamed reports whether the live Service port's name or appProtocol differ
+// from the desired ones
+func servicePortRenamed(live, desired corev1.ServicePort) bool {
+	if live.Name != desired.Name {
+		return true
+	}
+	if live.AppProtocol == nil || desired.AppProtocol == nil {
+		return live.AppProtocol != desired.AppProtocol
+	}
+	return *live.AppProtocol != *desired.AppProtocol
+}
@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+var _ = Describe("Service port appProtocol", func() {
+	newModelAPI := func() *kaosv1alpha1.ModelAPI {
+		return &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{Name: "llm", Namespace: "default"},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode:        kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig: &kaosv1alpha1.ProxyConfig{Models: []string{"openai/gpt-4"}},
+			},
+		}
+	}
+	servicePort := func(modelapi *kaosv1alpha1.ModelAPI) corev1.ServicePort {
+		return (&ModelAPIReconciler{}).constructService(modelapi).Spec.Ports[0]
+	}
+
+	It("should default the ModelAPI port to http, or grpc for a gRPC-probed server", func() {
+		modelapi := newModelAPI()
+		Expect(servicePort(modelapi).Name).To(Equal("http"))
+		Expect(*servicePort(modelapi).AppProtocol).To(Equal("http"))
+
+		modelapi.Spec.HealthCheck = &kaosv1alpha1.HealthCheckConfig{Type: kaosv1alpha1.HealthCheckTypeGRPC}
+		Expect(*servicePort(modelapi).AppProtocol).To(Equal("grpc"))
+
+		// A separate gRPC health port says nothing about the serving port
+		healthPort := int32(8001)
+		modelapi.Spec.HealthCheck.Port = &healthPort
+		Expect(*servicePort(modelapi).AppProtocol).To(Equal("http"))
+	})
+
+	It("should set the configured name and appProtocol on the ModelAPI Service port", func() {
+		modelapi := newModelAPI()
+		modelapi.Spec.ServicePort = &kaosv1alpha1.ServicePortConfig{Name: "h2c-api", AppProtocol: "kubernetes.io/h2c"}
+		port := servicePort(modelapi)
+		Expect(port.Name).To(Equal("h2c-api"))
+		Expect(*port.AppProtocol).To(Equal("kubernetes.io/h2c"))
+	})
+
+	It("should update the appProtocol of an existing MCPServer Service", func() {
+		r, c := newCachedMCPServerReconciler(nil)
+		ctx := context.Background()
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cached", Namespace: "default"}}
+		serviceKey := types.NamespacedName{Name: "mcpserver-cached", Namespace: "default"}
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		service := &corev1.Service{}
+		Expect(c.Get(ctx, serviceKey, service)).To(Succeed())
+		Expect(*service.Spec.Ports[0].AppProtocol).To(Equal("http"))
+
+		mcpserver := &kaosv1alpha1.MCPServer{}
+		Expect(c.Get(ctx, req.NamespacedName, mcpserver)).To(Succeed())
+		mcpserver.Spec.ServicePort = &kaosv1alpha1.ServicePortConfig{AppProtocol: "h2c"}
+		Expect(c.Update(ctx, mcpserver)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, serviceKey, service)).To(Succeed())
+		Expect(service.Spec.Ports[0].Name).To(Equal("http"))
+		Expect(*service.Spec.Ports[0].AppProtocol).To(Equal("h2c"))
+	})
+})
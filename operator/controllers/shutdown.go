@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+// defaultShutdownTimeout bounds how long the ShutdownNotifier writes conditions once the
+// operator is stopping, well within the manager's 30 second graceful shutdown period
+const defaultShutdownTimeout = 10 * time.Second
+
+// ShutdownNotifier sets a ReconcilePaused condition on every Agent, ModelAPI and MCPServer
+// when the operator stops, so that observers do not mistake the pause in reconciliation for
+// the resource being stuck. It runs on the leader only, and the reconcilers remove the
+// condition when they next reconcile the resource.
+type ShutdownNotifier struct {
+	Client client.Client
+	Log    logr.Logger
+	// Timeout bounds the condition writes once the manager stops (default: 10s)
+	Timeout time.Duration
+}
+
+var _ manager.LeaderElectionRunnable = &ShutdownNotifier{}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable
+func (n *ShutdownNotifier) NeedLeaderElection() bool {
+	return true
+}
+
+// Start waits for the manager to stop and then marks the resources as paused. Failures are
+// logged rather than returned, so they never hold up the shutdown.
+func (n *ShutdownNotifier) Start(ctx context.Context) error {
+	<-ctx.Done()
+	timeout := n.Timeout
+	if timeout == 0 {
+		timeout = defaultShutdownTimeout
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	n.markPaused(shutdownCtx)
+	return nil
+}
+
+// markPaused sets the ReconcilePaused condition on every resource of the three kinds
+func (n *ShutdownNotifier) markPaused(ctx context.Context) {
+	agents := &kaosv1alpha1.AgentList{}
+	modelapis := &kaosv1alpha1.ModelAPIList{}
+	mcpservers := &kaosv1alpha1.MCPServerList{}
+	for _, list := range []client.ObjectList{agents, modelapis, mcpservers} {
+		if err := n.Client.List(ctx, list); err != nil {
+			n.Log.Error(err, "failed to list resources to mark as paused")
+		}
+	}
+
+	marked := 0
+	mark := func(obj client.Object, conditions *[]metav1.Condition) {
+		original := obj.DeepCopyObject().(client.Object)
+		meta.SetStatusCondition(conditions, metav1.Condition{
+			Type:               kaosv1alpha1.ConditionReconcilePaused,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: obj.GetGeneration(),
+			Reason:             kaosv1alpha1.ReasonOperatorShutdown,
+			Message:            "The operator is shutting down; reconciliation resumes once it restarts",
+		})
+		if err := n.Client.Status().Patch(ctx, obj, client.MergeFrom(original)); client.IgnoreNotFound(err) != nil {
+			n.Log.Error(err, "failed to mark resource as paused", "namespace", obj.GetNamespace(), "name", obj.GetName())
+			return
+		}
+		marked++
+	}
+	for i := range agents.Items {
+		mark(&agents.Items[i], &agents.Items[i].Status.Conditions)
+	}
+	for i := range modelapis.Items {
+		mark(&modelapis.Items[i], &modelapis.Items[i].Status.Conditions)
+	}
+	for i := range mcpservers.Items {
+		mark(&mcpservers.Items[i], &mcpservers.Items[i].Status.Conditions)
+	}
+	n.Log.Info("Marked resources as paused for shutdown", "count", marked)
+}
@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+var _ = Describe("ShutdownNotifier", func() {
+	It("should mark every resource as paused when the manager stops", func() {
+		scheme := newTestScheme()
+		objectMeta := func(name string) metav1.ObjectMeta {
+			return metav1.ObjectMeta{Name: name, Namespace: "default", Generation: 1}
+		}
+		agent := &kaosv1alpha1.Agent{ObjectMeta: objectMeta("agent"), Spec: kaosv1alpha1.AgentSpec{ModelAPI: "api", Model: "mock-model"}}
+		modelapi := &kaosv1alpha1.ModelAPI{ObjectMeta: objectMeta("api"), Spec: kaosv1alpha1.ModelAPISpec{Mode: kaosv1alpha1.ModelAPIModeProxy}}
+		mcpserver := &kaosv1alpha1.MCPServer{
+			ObjectMeta: objectMeta("cached"),
+			Spec: kaosv1alpha1.MCPServerSpec{
+				Type: kaosv1alpha1.MCPServerTypePython,
+				Config: kaosv1alpha1.MCPServerConfig{
+					Tools: &kaosv1alpha1.MCPToolsConfig{FromString: "def echo(x: str) -> str:\n    return x\n"},
+				},
+			},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(agent, modelapi, mcpserver).
+			WithStatusSubresource(&kaosv1alpha1.Agent{}, &kaosv1alpha1.ModelAPI{}, &kaosv1alpha1.MCPServer{}).Build()
+		notifier := &ShutdownNotifier{Client: c, Log: ctrl.Log}
+
+		ctx, stop := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() { done <- notifier.Start(ctx) }()
+		Consistently(done).ShouldNot(Receive())
+
+		stop()
+		Eventually(done).Should(Receive(BeNil()))
+		for _, obj := range []client.Object{agent, modelapi, mcpserver} {
+			Expect(c.Get(context.Background(), client.ObjectKeyFromObject(obj), obj)).To(Succeed())
+		}
+		for _, conditions := range [][]metav1.Condition{agent.Status.Conditions, modelapi.Status.Conditions, mcpserver.Status.Conditions} {
+			paused := meta.FindStatusCondition(conditions, kaosv1alpha1.ConditionReconcilePaused)
+			Expect(paused).NotTo(BeNil())
+			Expect(paused.Status).To(Equal(metav1.ConditionTrue))
+			Expect(paused.Reason).To(Equal(kaosv1alpha1.ReasonOperatorShutdown))
+		}
+
+		// The restarted operator removes the condition on its first reconcile
+		r := &MCPServerReconciler{Client: c, Scheme: scheme}
+		_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "cached", Namespace: "default"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(context.Background(), client.ObjectKeyFromObject(mcpserver), mcpserver)).To(Succeed())
+		Expect(meta.FindStatusCondition(mcpserver.Status.Conditions, kaosv1alpha1.ConditionReconcilePaused)).To(BeNil())
+	})
+})
@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+// expectSelectorOf asserts that a status.selector string is the Deployment's selector and
+// matches the generated pods
+func expectSelectorOf(selector string, deployment *appsv1.Deployment) {
+	Expect(selector).To(Equal(metav1.FormatLabelSelector(deployment.Spec.Selector)))
+	parsed, err := labels.Parse(selector)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(parsed.Matches(labels.Set(deployment.Spec.Template.Labels))).To(BeTrue())
+}
+
+var _ = Describe("status.selector", func() {
+	It("should report the ModelAPI Deployment selector for the scale subresource", func() {
+		modelapi := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: metav1.ObjectMeta{Name: "scaled", Namespace: "default", UID: "modelapi-uid"},
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode:        kaosv1alpha1.ModelAPIModeProxy,
+				ProxyConfig: &kaosv1alpha1.ProxyConfig{Models: []string{"openai/gpt-4"}},
+			},
+		}
+		r, c := newCachedModelAPIReconciler(modelapi)
+		ctx := context.Background()
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "scaled", Namespace: "default"}}
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		deployment := &appsv1.Deployment{}
+		Expect(c.Get(ctx, types.NamespacedName{Name: "modelapi-scaled", Namespace: "default"}, deployment)).To(Succeed())
+		Expect(c.Get(ctx, req.NamespacedName, modelapi)).To(Succeed())
+		Expect(modelapi.Status.Selector).To(Equal("app=modelapi,modelapi=scaled"))
+		expectSelectorOf(modelapi.Status.Selector, deployment)
+	})
+
+	It("should report the MCPServer Deployment selector", func() {
+		r, c := newCachedMCPServerReconciler(nil)
+		ctx := context.Background()
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cached", Namespace: "default"}}
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		deployment := &appsv1.Deployment{}
+		Expect(c.Get(ctx, types.NamespacedName{Name: "mcpserver-cached", Namespace: "default"}, deployment)).To(Succeed())
+		mcpserver := &kaosv1alpha1.MCPServer{}
+		Expect(c.Get(ctx, req.NamespacedName, mcpserver)).To(Succeed())
+		Expect(mcpserver.Status.Selector).NotTo(BeEmpty())
+		expectSelectorOf(mcpserver.Status.Selector, deployment)
+	})
+})
@@ -5,9 +5,95 @@ import (
 
 	"github.com/onsi/ginkgo/v2"
 	"github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+	"github.com/axsaucedo/kaos/operator/pkg/util"
 )
 
 func TestControllers(t *testing.T) {
 	gomega.RegisterFailHandler(ginkgo.Fail)
 	ginkgo.RunSpecs(t, "Controllers Suite")
 }
+
+// newTestScheme returns a scheme with the built-in, kaos and Gateway API types
+func newTestScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	gomega.Expect(clientgoscheme.AddToScheme(scheme)).To(gomega.Succeed())
+	gomega.Expect(kaosv1alpha1.AddToScheme(scheme)).To(gomega.Succeed())
+	gomega.Expect(gatewayv1.Install(scheme)).To(gomega.Succeed())
+	return scheme
+}
+
+// newCachedMCPServerReconciler returns an MCPServerReconciler backed by a fake client holding one MCPServer
+func newCachedMCPServerReconciler(cache *util.ReconcileCache) (*MCPServerReconciler, client.Client) {
+	scheme := newTestScheme()
+
+	mcpserver := &kaosv1alpha1.MCPServer{
+		ObjectMeta: metav1.ObjectMeta{Name: "cached", Namespace: "default", UID: "mcp-uid", Generation: 1},
+		Spec: kaosv1alpha1.MCPServerSpec{
+			Type: kaosv1alpha1.MCPServerTypePython,
+			Config: kaosv1alpha1.MCPServerConfig{
+				Tools: &kaosv1alpha1.MCPToolsConfig{FromString: "def echo(x: str) -> str:\n    return x\n"},
+			},
+		},
+	}
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(mcpserver).
+		WithIndex(&corev1.Event{}, eventInvolvedUIDField, eventInvolvedUID).
+		WithIndex(&kaosv1alpha1.MCPServer{}, toolsConfigMapField, toolsConfigMapName).
+		WithStatusSubresource(&kaosv1alpha1.MCPServer{}).
+		Build()
+	return &MCPServerReconciler{Client: c, Scheme: scheme, ReconcileCache: cache}, c
+}
+
+// newCachedModelAPIReconciler returns a ModelAPIReconciler with a reconcile cache and a fake
+// event recorder, backed by a fake client holding objs
+func newCachedModelAPIReconciler(objs ...client.Object) (*ModelAPIReconciler, client.Client) {
+	scheme := newTestScheme()
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objs...).
+		WithIndex(&corev1.Event{}, eventInvolvedUIDField, eventInvolvedUID).
+		WithStatusSubresource(&kaosv1alpha1.ModelAPI{}, &appsv1.Deployment{}).
+		Build()
+	return &ModelAPIReconciler{
+		Client:         c,
+		Scheme:         scheme,
+		Recorder:       record.NewFakeRecorder(100),
+		ReconcileCache: util.NewReconcileCache(),
+	}, c
+}
+
+// newCachedAgentReconciler returns an AgentReconciler with a reconcile cache and a fake event
+// recorder, backed by a fake client holding objs
+func newCachedAgentReconciler(objs ...client.Object) (*AgentReconciler, client.Client) {
+	scheme := newTestScheme()
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objs...).
+		WithIndex(&corev1.Event{}, eventInvolvedUIDField, eventInvolvedUID).
+		WithStatusSubresource(&kaosv1alpha1.Agent{}, &appsv1.Deployment{}).
+		Build()
+	return &AgentReconciler{
+		Client:         c,
+		Scheme:         scheme,
+		Recorder:       record.NewFakeRecorder(100),
+		ReconcileCache: util.NewReconcileCache(),
+	}, c
+}
+
+// eventInvolvedUID indexes events by involved object for the fake client, which only supports
+// field selectors on indexed fields
+func eventInvolvedUID(obj client.Object) []string {
+	return []string{string(obj.(*corev1.Event).InvolvedObject.UID)}
+}
@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+	"github.com/axsaucedo/kaos/operator/pkg/util"
+)
+
+var _ = Describe("Trace sample ratio annotation", func() {
+	// samplerArg returns the OTEL_TRACES_SAMPLER_ARG of the agent container, or ""
+	samplerArg := func(deployment *appsv1.Deployment) string {
+		for _, e := range deployment.Spec.Template.Spec.Containers[0].Env {
+			if e.Name == "OTEL_TRACES_SAMPLER_ARG" {
+				return e.Value
+			}
+		}
+		return ""
+	}
+
+	It("should update the sampler env and roll the pods when the annotation changes", func() {
+		agent := &kaosv1alpha1.Agent{
+			ObjectMeta: metav1.ObjectMeta{Name: "traced", Namespace: "default"},
+			Spec: kaosv1alpha1.AgentSpec{
+				ModelAPI:  "api",
+				Model:     "mock-model",
+				Telemetry: &kaosv1alpha1.TelemetryConfig{Enabled: true, Endpoint: "http://otel-collector:4317"},
+			},
+		}
+		r := &AgentReconciler{}
+		unset := r.constructDeployment(agent, &kaosv1alpha1.ModelAPI{}, nil, nil, nil)
+		Expect(samplerArg(unset)).To(BeEmpty())
+
+		agent.Annotations = map[string]string{kaosv1alpha1.TraceSampleRatioAnnotation: "0.1"}
+		sampled := r.constructDeployment(agent, &kaosv1alpha1.ModelAPI{}, nil, nil, nil)
+		Expect(samplerArg(sampled)).To(Equal("0.1"))
+
+		// Dialling tracing up during an incident changes the pod template hash, rolling the pods
+		agent.Annotations[kaosv1alpha1.TraceSampleRatioAnnotation] = "1"
+		incident := r.constructDeployment(agent, &kaosv1alpha1.ModelAPI{}, nil, nil, nil)
+		Expect(samplerArg(incident)).To(Equal("1"))
+		hashes := []string{
+			unset.Spec.Template.Annotations[util.PodSpecHashAnnotation],
+			sampled.Spec.Template.Annotations[util.PodSpecHashAnnotation],
+			incident.Spec.Template.Annotations[util.PodSpecHashAnnotation],
+		}
+		Expect(hashes[1]).NotTo(Equal(hashes[0]))
+		Expect(hashes[2]).NotTo(Equal(hashes[1]))
+	})
+})
@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+var _ = Describe("spec.workingDir", func() {
+	meta := metav1.ObjectMeta{Name: "workdir", Namespace: "default"}
+
+	It("should set the working directory of the agent container", func() {
+		agent := &kaosv1alpha1.Agent{
+			ObjectMeta: meta,
+			Spec:       kaosv1alpha1.AgentSpec{ModelAPI: "api", Model: "mock-model"},
+		}
+		r := &AgentReconciler{}
+		deployment := r.constructDeployment(agent, &kaosv1alpha1.ModelAPI{}, nil, nil, nil)
+		Expect(deployment.Spec.Template.Spec.Containers[0].WorkingDir).To(BeEmpty())
+
+		agent.Spec.WorkingDir = "/app"
+		deployment = r.constructDeployment(agent, &kaosv1alpha1.ModelAPI{}, nil, nil, nil)
+		Expect(deployment.Spec.Template.Spec.Containers[0].WorkingDir).To(Equal("/app"))
+	})
+
+	It("should set the working directory of the model-api container only", func() {
+		modelapi := &kaosv1alpha1.ModelAPI{
+			ObjectMeta: meta,
+			Spec: kaosv1alpha1.ModelAPISpec{
+				Mode:         kaosv1alpha1.ModelAPIModeHosted,
+				HostedConfig: &kaosv1alpha1.HostedConfig{Model: "smollm2:135m"},
+				WorkingDir:   "/models",
+			},
+		}
+		podSpec := (&ModelAPIReconciler{}).constructDeployment(modelapi).Spec.Template.Spec
+		Expect(podSpec.Containers[0].Name).To(Equal("model-api"))
+		Expect(podSpec.Containers[0].WorkingDir).To(Equal("/models"))
+		Expect(podSpec.InitContainers[0].WorkingDir).To(BeEmpty())
+	})
+
+	It("should set the working directory of the mcp-server container", func() {
+		mcpserver := &kaosv1alpha1.MCPServer{
+			ObjectMeta: meta,
+			Spec: kaosv1alpha1.MCPServerSpec{
+				Type: kaosv1alpha1.MCPServerTypePython,
+				Config: kaosv1alpha1.MCPServerConfig{
+					Tools: &kaosv1alpha1.MCPToolsConfig{FromPackage: "mcp-server-calculator"},
+				},
+				WorkingDir: "/srv",
+			},
+		}
+		Expect((&MCPServerReconciler{}).constructDeployment(mcpserver).Spec.Template.Spec.Containers[0].WorkingDir).To(Equal("/srv"))
+	})
+})
@@ -57,7 +57,8 @@ docker build -t "${REGISTRY}/litellm:${LITELLM_VERSION}" -f "${SCRIPT_DIR}/Docke
 # Pull and tag Ollama image (using alpine/ollama for smaller size)
 echo "Pulling and tagging Ollama image..."
 docker pull "alpine/ollama:${OLLAMA_TAG}"
-docker tag "alpine/ollama:${OLLAMA_TAG}" "${REGISTRY}/ollama:${OLLAMA_TAG}"
+# Tagged :dev locally since :latest images default to imagePullPolicy Always
+docker tag "alpine/ollama:${OLLAMA_TAG}" "${REGISTRY}/ollama:dev"
 
 # Load images into KIND cluster
 echo ""
@@ -66,7 +67,7 @@ kind load docker-image "${REGISTRY}/kaos-operator:${OPERATOR_TAG}" --name "${KIN
 kind load docker-image "${REGISTRY}/kaos-agent:${AGENT_TAG}" --name "${KIND_CLUSTER_NAME}"
 kind load docker-image "${REGISTRY}/kaos-mcp-server:${AGENT_TAG}" --name "${KIND_CLUSTER_NAME}"
 kind load docker-image "${REGISTRY}/litellm:${LITELLM_VERSION}" --name "${KIND_CLUSTER_NAME}"
-kind load docker-image "${REGISTRY}/ollama:${OLLAMA_TAG}" --name "${KIND_CLUSTER_NAME}"
+kind load docker-image "${REGISTRY}/ollama:dev" --name "${KIND_CLUSTER_NAME}"
 
 echo ""
 echo "All images built and loaded into KIND!"
@@ -10,7 +10,6 @@ VALUES_FILE="${SCRIPT_DIR}/kind-e2e-values.yaml"
 OPERATOR_TAG="${OPERATOR_TAG:-dev}"
 AGENT_TAG="${AGENT_TAG:-dev}"
 LITELLM_VERSION="${LITELLM_VERSION:-v1.56.5}"
-OLLAMA_TAG="${OLLAMA_TAG:-latest}"
 REGISTRY="${REGISTRY:-kind-local}"
 
 cat > "${VALUES_FILE}" << EOF
@@ -30,7 +29,7 @@ defaultImages:
   agentRuntime: ${REGISTRY}/kaos-agent:${AGENT_TAG}
   mcpServer: ${REGISTRY}/kaos-mcp-server:${AGENT_TAG}
   litellm: ${REGISTRY}/litellm:${LITELLM_VERSION}
-  ollama: ${REGISTRY}/ollama:${OLLAMA_TAG}
+  ollama: ${REGISTRY}/ollama:dev
 
 # Enable Gateway API for E2E tests
 gatewayAPI:
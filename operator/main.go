@@ -2,21 +2,39 @@ package main
 
 import (
 	"flag"
+	"fmt"
+	"net/http"
 	"os"
+	"regexp"
+	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/selection"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/discovery"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
 	"github.com/axsaucedo/kaos/operator/controllers"
+	"github.com/axsaucedo/kaos/operator/pkg/gateway"
+	"github.com/axsaucedo/kaos/operator/pkg/util"
+	"github.com/axsaucedo/kaos/operator/pkg/webhook"
 )
 
 var (
@@ -30,6 +48,7 @@ var (
 //+kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 //+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=serving.kserve.io,resources=inferenceservices,verbs=get;list;watch;create;update;patch;delete
 
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
@@ -41,12 +60,53 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var watchNamespace string
+	var defaultAgentEgress string
+	var enforceNamingConvention bool
+	var failureThreshold int
+	var kubeAPIQPS float64
+	var kubeAPIBurst int
+	var maxReplicasPerCR int
+	var enforceImageAllowlist bool
+	var retryBaseDelay time.Duration
+	var retryMaxDelay time.Duration
+	var conditionHistoryLimit int
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&watchNamespace, "watch-namespace", "",
+		"Comma-separated list of namespaces to watch. Watches all namespaces when empty.")
+	flag.StringVar(&defaultAgentEgress, "default-agent-egress", controllers.AgentEgressAllow,
+		"Baseline egress of Agent pods: allow, or deny to apply a default-deny egress NetworkPolicy "+
+			"that only permits DNS and the agent's ModelAPI, MCP servers and peer agents.")
+	flag.BoolVar(&enforceNamingConvention, "enforce-naming-convention", false,
+		"Reject new Agents, ModelAPIs and MCPServers whose names do not match the NAME_PATTERN "+
+			"regular expression. Requires the validating webhooks.")
+	flag.BoolVar(&enforceImageAllowlist, "enforce-image-allowlist", false,
+		"Reject Agents, ModelAPIs and MCPServers that set images from registries outside the "+
+			"ALLOWED_IMAGE_REGISTRIES prefixes. Requires the validating webhooks.")
+	flag.IntVar(&failureThreshold, "reconcile-failure-threshold", 5,
+		"Consecutive reconcile failures at the same generation after which a resource is only retried "+
+			"every 10 minutes and gets a CircuitOpen condition. 0 disables the circuit breaker.")
+	flag.Float64Var(&kubeAPIQPS, "kube-api-qps", 20,
+		"Maximum sustained queries per second from the manager's client to the Kubernetes API server.")
+	flag.IntVar(&kubeAPIBurst, "kube-api-burst", 30,
+		"Maximum burst of queries from the manager's client to the Kubernetes API server.")
+	flag.IntVar(&maxReplicasPerCR, "max-replicas-per-cr", 0,
+		"Maximum replicas of the Deployment generated for any Agent, ModelAPI or MCPServer, whether "+
+			"requested in spec.replicas or by an autoscaler. Capped resources get a ReplicasCapped "+
+			"condition. 0 disables the cap.")
+	flag.DurationVar(&retryBaseDelay, "reconcile-retry-base-delay", controllers.DefaultRetryBaseDelay,
+		"Delay before a failed reconcile of a resource is retried. It doubles on every further failure, "+
+			"up to --reconcile-retry-max-delay.")
+	flag.DurationVar(&retryMaxDelay, "reconcile-retry-max-delay", controllers.DefaultRetryMaxDelay,
+		"Maximum delay between retries of a resource whose reconciles keep failing.")
+	flag.IntVar(&conditionHistoryLimit, "condition-history-limit", util.DefaultConditionHistoryLimit,
+		"Number of condition transitions kept in the status.conditionHistory of every Agent, ModelAPI "+
+			"and MCPServer, at most 100. 0 disables the history.")
 
 	opts := zap.Options{
 		Development: true,
@@ -56,53 +116,207 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+	if defaultAgentEgress != controllers.AgentEgressAllow && defaultAgentEgress != controllers.AgentEgressDeny {
+		setupLog.Error(nil, "invalid --default-agent-egress, must be allow or deny", "value", defaultAgentEgress)
+		os.Exit(1)
+	}
+
+	if kubeAPIQPS <= 0 || kubeAPIBurst <= 0 {
+		setupLog.Error(nil, "--kube-api-qps and --kube-api-burst must be positive", "qps", kubeAPIQPS, "burst", kubeAPIBurst)
+		os.Exit(1)
+	}
+
+	if maxReplicasPerCR < 0 {
+		setupLog.Error(nil, "--max-replicas-per-cr must not be negative", "value", maxReplicasPerCR)
+		os.Exit(1)
+	}
+
+	if retryBaseDelay <= 0 || retryMaxDelay < retryBaseDelay {
+		setupLog.Error(nil, "--reconcile-retry-base-delay must be positive and at most --reconcile-retry-max-delay",
+			"baseDelay", retryBaseDelay, "maxDelay", retryMaxDelay)
+		os.Exit(1)
+	}
+
+	if conditionHistoryLimit < 0 || conditionHistoryLimit > util.MaxConditionHistoryLimit {
+		setupLog.Error(nil, "--condition-history-limit must be between 0 and 100", "value", conditionHistoryLimit)
+		os.Exit(1)
+	}
+
+	mgr, err := ctrl.NewManager(restConfig(ctrl.GetConfigOrDie(), kubeAPIQPS, kubeAPIBurst), ctrl.Options{
 		Scheme:                 scheme,
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "kaos-operator.kaos.tools",
+		Cache:                  cacheOptions(watchNamespace),
+		// Events are only read while a Deployment has unavailable replicas, which does not
+		// justify caching every event of the watched namespaces. Agent leader Leases are
+		// renewed every few seconds, so they are read from the API server rather than cached.
+		// Only the shared model cache claims named by ModelAPIs are read, so claims are not
+		// cached either.
+		Client: client.Options{Cache: &client.CacheOptions{
+			DisableFor: []client.Object{&corev1.Event{}, &coordinationv1.Lease{}, &corev1.PersistentVolumeClaim{}},
+		}},
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
+	// Registry client used to resolve image digests for spec.pinDigest
+	imageResolver := util.NewRegistryResolver()
+
+	// Clusters older than Kubernetes 1.24 have no native gRPC probes and those older than 1.30
+	// no preStop sleep actions, clusters without the Gateway API CRDs cannot serve
+	// spec.httpRoute, and clusters without the KServe CRDs run spec.backend KServe ModelAPIs
+	// from Deployments
+	legacyGRPCProbes, legacySleepAction, gatewayAPIAvailable, kserveAvailable := false, false, false, false
+	if discoveryClient, err := discovery.NewDiscoveryClientForConfig(mgr.GetConfig()); err != nil {
+		setupLog.Error(err, "unable to create discovery client, assuming native gRPC probes, no Gateway API and no KServe")
+	} else {
+		if serverVersion, err := discoveryClient.ServerVersion(); err != nil {
+			setupLog.Error(err, "unable to get server version, assuming native gRPC probes and preStop sleep actions")
+		} else {
+			legacyGRPCProbes = !util.SupportsGRPCProbes(serverVersion)
+			legacySleepAction = !util.SupportsSleepAction(serverVersion)
+		}
+		if gatewayAPIAvailable, err = gateway.APIAvailable(discoveryClient); err != nil {
+			setupLog.Error(err, "unable to discover the Gateway API, ignoring spec.httpRoute")
+		}
+		if kserveAvailable, err = controllers.KServeAvailable(discoveryClient); err != nil {
+			setupLog.Error(err, "unable to discover KServe, running ModelAPIs from Deployments")
+		}
+	}
+
+	// Reconcile counts, labelled with the resource labels allowed by METRICS_LABELS
+	reconcileMetrics, err := util.NewReconcileMetrics(util.MetricsLabelKeys())
+	if err != nil {
+		setupLog.Error(err, "unable to create the reconcile metrics")
+		os.Exit(1)
+	}
+	metrics.Registry.MustRegister(reconcileMetrics)
+	// CPU requested per namespace and kind, computed from the cached workloads when scraped
+	metrics.Registry.MustRegister(&controllers.RequestedResourcesCollector{Client: mgr.GetClient()})
+
+	// Platform-wide patch of every generated pod template, from POD_TEMPLATE_PATCH
+	podTemplatePatch, err := util.PodTemplatePatchFromEnv()
+	if err != nil {
+		setupLog.Error(err, "unable to parse the global pod template patch")
+		os.Exit(1)
+	}
+
 	// Setup controllers
 	if err = (&controllers.ModelAPIReconciler{
-		Client: mgr.GetClient(),
-		Log:    setupLog,
-		Scheme: mgr.GetScheme(),
+		Client:                mgr.GetClient(),
+		Log:                   setupLog,
+		Scheme:                mgr.GetScheme(),
+		ImageResolver:         imageResolver,
+		ReconcileCache:        util.NewReconcileCache(),
+		CircuitBreaker:        util.NewCircuitBreaker(failureThreshold, util.DefaultCircuitOpenInterval),
+		Metrics:               reconcileMetrics,
+		PodTemplatePatch:      podTemplatePatch,
+		MaxReplicas:           int32(maxReplicasPerCR),
+		Recorder:              mgr.GetEventRecorderFor("modelapi-controller"),
+		RateLimiter:           controllers.NewRateLimiter(retryBaseDelay, retryMaxDelay),
+		ConditionHistoryLimit: conditionHistoryLimit,
+		LegacyGRPCProbes:      legacyGRPCProbes,
+		LegacySleepAction:     legacySleepAction,
+		GatewayAPIAvailable:   gatewayAPIAvailable,
+		KServeAvailable:       kserveAvailable,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ModelAPI")
 		os.Exit(1)
 	}
 
 	if err = (&controllers.MCPServerReconciler{
-		Client: mgr.GetClient(),
-		Log:    setupLog,
-		Scheme: mgr.GetScheme(),
+		Client:                mgr.GetClient(),
+		Log:                   setupLog,
+		Scheme:                mgr.GetScheme(),
+		ImageResolver:         imageResolver,
+		ToolDiscoverer:        util.NewMCPClient(),
+		ReconcileCache:        util.NewReconcileCache(),
+		CircuitBreaker:        util.NewCircuitBreaker(failureThreshold, util.DefaultCircuitOpenInterval),
+		Metrics:               reconcileMetrics,
+		PodTemplatePatch:      podTemplatePatch,
+		MaxReplicas:           int32(maxReplicasPerCR),
+		Recorder:              mgr.GetEventRecorderFor("mcpserver-controller"),
+		RateLimiter:           controllers.NewRateLimiter(retryBaseDelay, retryMaxDelay),
+		ConditionHistoryLimit: conditionHistoryLimit,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "MCPServer")
 		os.Exit(1)
 	}
 
 	if err = (&controllers.AgentReconciler{
-		Client: mgr.GetClient(),
-		Log:    setupLog,
-		Scheme: mgr.GetScheme(),
+		Client:                mgr.GetClient(),
+		Log:                   setupLog,
+		Scheme:                mgr.GetScheme(),
+		ImageResolver:         imageResolver,
+		ReconcileCache:        util.NewReconcileCache(),
+		CircuitBreaker:        util.NewCircuitBreaker(failureThreshold, util.DefaultCircuitOpenInterval),
+		Metrics:               reconcileMetrics,
+		PodTemplatePatch:      podTemplatePatch,
+		MaxReplicas:           int32(maxReplicasPerCR),
+		DefaultAgentEgress:    defaultAgentEgress,
+		Recorder:              mgr.GetEventRecorderFor("agent-controller"),
+		RateLimiter:           controllers.NewRateLimiter(retryBaseDelay, retryMaxDelay),
+		ConditionHistoryLimit: conditionHistoryLimit,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Agent")
 		os.Exit(1)
 	}
 
-	// Webhooks not implemented yet in this version
-	// TODO: Add webhook setup when webhooks are needed
+	// Mark resources as paused while the operator is down, e.g. during an upgrade
+	if err = mgr.Add(&controllers.ShutdownNotifier{
+		Client: mgr.GetClient(),
+		Log:    ctrl.Log.WithName("shutdown"),
+	}); err != nil {
+		setupLog.Error(err, "unable to set up shutdown notifier")
+		os.Exit(1)
+	}
+
+	// Defaulting and validating webhooks require serving certificates, so they are opt-in
+	if os.Getenv("ENABLE_WEBHOOKS") == "true" {
+		var namingConvention *regexp.Regexp
+		if enforceNamingConvention {
+			if namingConvention, err = webhook.NamingConvention(); err != nil {
+				setupLog.Error(err, "unable to enforce naming convention")
+				os.Exit(1)
+			}
+		}
+		var allowedRegistries []string
+		if enforceImageAllowlist {
+			if allowedRegistries, err = webhook.AllowedImageRegistries(); err != nil {
+				setupLog.Error(err, "unable to enforce image allowlist")
+				os.Exit(1)
+			}
+		}
+		sizes, err := webhook.ReadSizePresets()
+		if err != nil {
+			setupLog.Error(err, "unable to read size presets")
+			os.Exit(1)
+		}
+		if err = webhook.SetupAgentWebhookWithManager(mgr, namingConvention, allowedRegistries, sizes); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Agent")
+			os.Exit(1)
+		}
+		if err = webhook.SetupModelAPIWebhookWithManager(mgr, namingConvention, allowedRegistries, sizes); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ModelAPI")
+			os.Exit(1)
+		}
+		if err = webhook.SetupMCPServerWebhookWithManager(mgr, namingConvention, allowedRegistries, sizes); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "MCPServer")
+			os.Exit(1)
+		}
+	}
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
 	}
-	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
+	// Not ready until the informer caches of the three resource types have synced, so a rolling
+	// operator upgrade keeps the old pod serving webhooks until the new one can
+	if err := mgr.AddReadyzCheck("readyz", cacheSyncCheck(mgr.GetCache(),
+		&kaosv1alpha1.Agent{}, &kaosv1alpha1.ModelAPI{}, &kaosv1alpha1.MCPServer{})); err != nil {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
@@ -113,3 +327,67 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// cacheSyncCheck reports ready once the informers of all the given object types have synced.
+// It never blocks on the sync, so probes fail fast while the caches are still filling.
+func cacheSyncCheck(informers cache.Informers, objs ...client.Object) healthz.Checker {
+	return func(req *http.Request) error {
+		for _, obj := range objs {
+			informer, err := informers.GetInformer(req.Context(), obj, cache.BlockUntilSynced(false))
+			if err != nil {
+				return fmt.Errorf("getting informer for %T: %w", obj, err)
+			}
+			if !informer.HasSynced() {
+				return fmt.Errorf("informer cache for %T has not synced", obj)
+			}
+		}
+		return nil
+	}
+}
+
+// restConfig applies the client-side rate limits to the API server config. Every client the
+// manager builds from it, including the cached client and discovery, shares these limits.
+func restConfig(cfg *rest.Config, qps float64, burst int) *rest.Config {
+	cfg.QPS = float32(qps)
+	cfg.Burst = burst
+	return cfg
+}
+
+// operatorPodSelector selects the pods of the workloads the operator generates, by their app
+// label. The controllers only watch and list these pods, so the Pod cache is limited to them
+// rather than holding every pod of the watched namespaces.
+func operatorPodSelector() labels.Selector {
+	req, err := labels.NewRequirement("app", selection.In, []string{"agent", "mcpserver", "modelapi"})
+	if err != nil {
+		panic(err)
+	}
+	return labels.NewSelector().Add(*req)
+}
+
+// modelAPIEndpointSliceSelector selects the EndpointSlices of ModelAPI Services, which inherit
+// the component label of the Service. They are the only slices the controllers read.
+func modelAPIEndpointSliceSelector() labels.Selector {
+	return labels.SelectorFromSet(labels.Set{util.ComponentLabel: "modelapi"})
+}
+
+// cacheOptions restricts the manager cache to the comma-separated namespaces in watchNamespace.
+// An empty value watches all namespaces.
+func cacheOptions(watchNamespace string) cache.Options {
+	opts := cache.Options{
+		ByObject: map[client.Object]cache.ByObject{
+			&corev1.Pod{}:                {Label: operatorPodSelector()},
+			&discoveryv1.EndpointSlice{}: {Label: modelAPIEndpointSliceSelector()},
+		},
+	}
+	for _, ns := range strings.Split(watchNamespace, ",") {
+		ns = strings.TrimSpace(ns)
+		if ns == "" {
+			continue
+		}
+		if opts.DefaultNamespaces == nil {
+			opts.DefaultNamespaces = map[string]cache.Config{}
+		}
+		opts.DefaultNamespaces[ns] = cache.Config{}
+	}
+	return opts
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+// fakeInformers serves informers whose sync state is set per object type
+type fakeInformers struct {
+	cache.Informers
+	synced map[string]bool
+}
+
+func (f *fakeInformers) GetInformer(_ context.Context, obj client.Object, _ ...cache.InformerGetOption) (cache.Informer, error) {
+	return fakeInformer{synced: f.synced[fmt.Sprintf("%T", obj)]}, nil
+}
+
+// fakeInformer is an informer that only reports its sync state
+type fakeInformer struct {
+	cache.Informer
+	synced bool
+}
+
+func (f fakeInformer) HasSynced() bool {
+	return f.synced
+}
+
+// podKey returns the ByObject key of the Pod type in opts
+func podKey(opts cache.Options) client.Object {
+	for obj := range opts.ByObject {
+		if _, ok := obj.(*corev1.Pod); ok {
+			return obj
+		}
+	}
+	return nil
+}
+
+var _ = Describe("cacheOptions", func() {
+	It("should watch all namespaces when --watch-namespace is empty", func() {
+		Expect(cacheOptions("").DefaultNamespaces).To(BeNil())
+	})
+
+	It("should only cache the pods of operator workloads", func() {
+		opts := cacheOptions("")
+		selector := opts.ByObject[podKey(opts)].Label
+		Expect(selector).NotTo(BeNil())
+		Expect(selector.Matches(labels.Set{"app": "agent", "agent": "my-agent"})).To(BeTrue())
+		Expect(selector.Matches(labels.Set{"app": "mcpserver", "mcpserver": "tools"})).To(BeTrue())
+		Expect(selector.Matches(labels.Set{"app": "nginx"})).To(BeFalse())
+		Expect(selector.Matches(labels.Set{})).To(BeFalse())
+	})
+
+	It("should only cache the EndpointSlices of ModelAPI Services", func() {
+		opts := cacheOptions("")
+		var selector labels.Selector
+		for obj, byObject := range opts.ByObject {
+			if _, ok := obj.(*discoveryv1.EndpointSlice); ok {
+				selector = byObject.Label
+			}
+		}
+		Expect(selector).NotTo(BeNil())
+		Expect(selector.Matches(labels.Set{"kaos.agentic/component": "modelapi", discoveryv1.LabelServiceName: "modelapi-llm"})).To(BeTrue())
+		Expect(selector.Matches(labels.Set{discoveryv1.LabelServiceName: "kubernetes"})).To(BeFalse())
+	})
+
+	It("should cache the ModelAPI pods whose serving readiness gate is managed", func() {
+		opts := cacheOptions("team-a")
+		selector := opts.ByObject[podKey(opts)].Label
+		Expect(selector.Matches(labels.Set{"app": "modelapi", "modelapi": "my-model"})).To(BeTrue())
+		Expect(opts.ByObject[podKey(opts)].Namespaces).To(BeNil(), "pods follow the default namespaces")
+	})
+
+	It("should restrict the cache to the namespaces in --watch-namespace", func() {
+		opts := cacheOptions("team-a, team-b,,team-a")
+		Expect(opts.DefaultNamespaces).To(Equal(map[string]cache.Config{
+			"team-a": {},
+			"team-b": {},
+		}))
+	})
+})
+
+var _ = Describe("restConfig", func() {
+	It("should apply --kube-api-qps and --kube-api-burst to the client config", func() {
+		cfg := restConfig(&rest.Config{Host: "https://example.com"}, 50, 100)
+		Expect(cfg.QPS).To(Equal(float32(50)))
+		Expect(cfg.Burst).To(Equal(100))
+		Expect(cfg.Host).To(Equal("https://example.com"))
+	})
+})
+
+var _ = Describe("cacheSyncCheck", func() {
+	It("should report not ready until every informer cache has synced", func() {
+		informers := &fakeInformers{synced: map[string]bool{"*v1alpha1.Agent": true}}
+		check := cacheSyncCheck(informers, &kaosv1alpha1.Agent{}, &kaosv1alpha1.ModelAPI{}, &kaosv1alpha1.MCPServer{})
+		req := httptest.NewRequest("GET", "/readyz", nil)
+
+		Expect(check(req)).To(MatchError(ContainSubstring("*v1alpha1.ModelAPI has not synced")))
+
+		informers.synced["*v1alpha1.ModelAPI"] = true
+		Expect(check(req)).To(MatchError(ContainSubstring("*v1alpha1.MCPServer has not synced")))
+
+		informers.synced["*v1alpha1.MCPServer"] = true
+		Expect(check(req)).To(Succeed())
+	})
+})
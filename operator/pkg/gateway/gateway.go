@@ -11,9 +11,12 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/axsaucedo/kaos/operator/pkg/util"
 )
 
 // Config holds Gateway API configuration from environment
@@ -46,6 +49,23 @@ func GetConfig() Config {
 	}
 }
 
+// APIAvailable reports whether the cluster serves the Gateway API v1 HTTPRoute kind,
+// i.e. whether the Gateway API CRDs are installed
+func APIAvailable(client discovery.DiscoveryInterface) (bool, error) {
+	resources, err := client.ServerResourcesForGroupVersion(gatewayv1.GroupVersion.String())
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Kind == "HTTPRoute" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // getEnvOrDefault returns the value of an environment variable or a default value
 func getEnvOrDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -200,6 +220,7 @@ func ReconcileHTTPRoute(
 	}
 
 	httpRoute := constructHTTPRoute(params, config)
+	util.PropagateLabels(httpRoute, owner, util.PropagatedLabelKeys())
 
 	existing := &gatewayv1.HTTPRoute{}
 	err := c.Get(ctx, types.NamespacedName{Name: httpRoute.Name, Namespace: httpRoute.Namespace}, existing)
@@ -215,5 +236,32 @@ func ReconcileHTTPRoute(
 	}
 
 	existing.Spec = httpRoute.Spec
+	util.PropagateLabels(existing, owner, util.PropagatedLabelKeys())
 	return c.Update(ctx, existing)
 }
+
+// DeleteHTTPRoute removes the HTTPRoute of a resource, e.g. once it is no longer exposed.
+// It is a no-op when Gateway API is disabled or the route does not exist.
+func DeleteHTTPRoute(
+	ctx context.Context,
+	c client.Client,
+	owner client.Object,
+	resourceType ResourceType,
+	log logr.Logger,
+) error {
+	if !GetConfig().Enabled {
+		return nil
+	}
+
+	existing := &gatewayv1.HTTPRoute{}
+	name := HTTPRouteName(resourceType, owner.GetName())
+	if err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: owner.GetNamespace()}, existing); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !metav1.IsControlledBy(existing, owner) {
+		return nil
+	}
+
+	log.Info("Deleting HTTPRoute", "name", existing.Name)
+	return client.IgnoreNotFound(c.Delete(ctx, existing))
+}
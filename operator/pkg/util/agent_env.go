@@ -0,0 +1,55 @@
+package util
+
+import (
+	"regexp"
+	"slices"
+)
+
+// Env vars the operator sets on agent pods from the Agent spec and its dependencies
+const (
+	AgentNameEnv               = "AGENT_NAME"
+	ModelAPIURLEnv             = "MODEL_API_URL"
+	ModelNameEnv               = "MODEL_NAME"
+	AgenticLoopMaxStepsEnv     = "AGENTIC_LOOP_MAX_STEPS"
+	AgentMaxConcurrencyEnv     = "AGENT_MAX_CONCURRENCY"
+	AgentRequestTimeoutEnv     = "AGENT_REQUEST_TIMEOUT"
+	MemoryEnabledEnv           = "MEMORY_ENABLED"
+	MemoryTypeEnv              = "MEMORY_TYPE"
+	MemoryContextLimitEnv      = "MEMORY_CONTEXT_LIMIT"
+	MemoryMaxSessionsEnv       = "MEMORY_MAX_SESSIONS"
+	MemoryMaxSessionEventsEnv  = "MEMORY_MAX_SESSION_EVENTS"
+	MCPServersEnv              = "MCP_SERVERS"
+	AvailableToolsEnv          = "AVAILABLE_TOOLS"
+	PeerAgentsEnv              = "PEER_AGENTS"
+	AgentLeaderLeaseEnv        = "AGENT_LEADER_LEASE"
+	ServiceAccountTokenFileEnv = "SERVICE_ACCOUNT_TOKEN_FILE"
+	ModelAPISocketEnv          = "MODEL_API_SOCKET"
+	MTLSEnabledEnv             = "MTLS_ENABLED"
+	MTLSCertFileEnv            = "MTLS_CERT_FILE"
+	MTLSKeyFileEnv             = "MTLS_KEY_FILE"
+	MTLSCAFileEnv              = "MTLS_CA_FILE"
+)
+
+// ReservedAgentEnv are the env vars the operator sets on agent pods, including the telemetry
+// ones. The admission webhook rejects config.env entries with these names, as the operator's
+// value would silently win.
+var ReservedAgentEnv = []string{
+	AgentNameEnv, ModelAPIURLEnv, ModelNameEnv,
+	AgenticLoopMaxStepsEnv, AgentMaxConcurrencyEnv, AgentRequestTimeoutEnv,
+	MemoryEnabledEnv, MemoryTypeEnv, MemoryContextLimitEnv, MemoryMaxSessionsEnv, MemoryMaxSessionEventsEnv,
+	MCPServersEnv, AvailableToolsEnv, PeerAgentsEnv, AgentLeaderLeaseEnv, ServiceAccountTokenFileEnv,
+	ModelAPISocketEnv, MTLSEnabledEnv, MTLSCertFileEnv, MTLSKeyFileEnv, MTLSCAFileEnv,
+	OTelServiceNameEnv, OTelResourceAttributesEnv, K8sPodNameEnv, K8sNamespaceNameEnv, K8sNodeNameEnv,
+	OTLPEndpointEnv, OTLPTracesEndpointEnv, OTLPMetricsEndpointEnv, OTLPLogsEndpointEnv, OTLPHeadersEnv,
+	OTLPTimeoutEnv, BSPScheduleDelayEnv, BSPExportTimeoutEnv, BSPMaxQueueSizeEnv, BSPMaxExportBatchSizeEnv,
+	TracesSamplerEnv, TracesSamplerArgEnv,
+}
+
+// reservedAgentEnvPattern matches the per-dependency MCP_SERVER_<NAME>_URL and
+// PEER_AGENT_<NAME>_CARD_URL env vars
+var reservedAgentEnvPattern = regexp.MustCompile(`^(MCP_SERVER_.+_URL|PEER_AGENT_.+_CARD_URL)$`)
+
+// IsReservedAgentEnv reports whether the operator sets the named env var on agent pods
+func IsReservedAgentEnv(name string) bool {
+	return slices.Contains(ReservedAgentEnv, name) || reservedAgentEnvPattern.MatchString(name)
+}
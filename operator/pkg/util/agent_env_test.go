@@ -0,0 +1,17 @@
+package util
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("IsReservedAgentEnv", func() {
+	It("should match the operator's env vars and the per-dependency ones", func() {
+		for _, name := range []string{"MODEL_API_URL", "OTEL_EXPORTER_OTLP_HEADERS", "OTEL_BSP_MAX_QUEUE_SIZE",
+			"OTEL_TRACES_SAMPLER_ARG", "MEMORY_TYPE", "AGENT_REQUEST_TIMEOUT", "MCP_SERVER_SEARCH_URL", "PEER_AGENT_WORKER_CARD_URL"} {
+			Expect(IsReservedAgentEnv(name)).To(BeTrue(), name)
+		}
+		Expect(IsReservedAgentEnv("OPENAI_API_KEY")).To(BeFalse())
+		Expect(IsReservedAgentEnv("MCP_SERVER_")).To(BeFalse())
+	})
+})
@@ -0,0 +1,67 @@
+package util
+
+import (
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ReservedAnnotationPrefix marks annotations managed by the operator; user-provided
+// annotations with this prefix are ignored so they cannot overwrite managed ones
+const ReservedAnnotationPrefix = "kaos.tools/"
+
+// AppliedAnnotationsAnnotation records the user-provided annotation keys last applied to
+// an object, so keys removed from the spec can be removed from the object as well
+const AppliedAnnotationsAnnotation = ReservedAnnotationPrefix + "applied-annotations"
+
+// ApplyUserAnnotations merges user-provided annotations onto the object. Keys in the
+// reserved kaos.tools/ domain are skipped, annotations set by other controllers are
+// preserved, and keys applied previously but no longer requested are removed.
+// Returns whether the object's annotations changed.
+func ApplyUserAnnotations(obj metav1.Object, user map[string]string) bool {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	changed := false
+
+	keys := make([]string, 0, len(user))
+	for key, value := range user {
+		if strings.HasPrefix(key, ReservedAnnotationPrefix) {
+			continue
+		}
+		keys = append(keys, key)
+		if current, ok := annotations[key]; !ok || current != value {
+			annotations[key] = value
+			changed = true
+		}
+	}
+	sort.Strings(keys)
+
+	// Drop keys that were applied before but are no longer requested
+	if previous := annotations[AppliedAnnotationsAnnotation]; previous != "" {
+		for _, key := range strings.Split(previous, ",") {
+			if _, ok := user[key]; !ok {
+				delete(annotations, key)
+				changed = true
+			}
+		}
+	}
+
+	applied := strings.Join(keys, ",")
+	if applied != annotations[AppliedAnnotationsAnnotation] {
+		changed = true
+	}
+	if applied == "" {
+		delete(annotations, AppliedAnnotationsAnnotation)
+	} else {
+		annotations[AppliedAnnotationsAnnotation] = applied
+	}
+
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	obj.SetAnnotations(annotations)
+	return changed
+}
@@ -0,0 +1,47 @@
+package util
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("ApplyUserAnnotations", func() {
+	It("should leave an object without user annotations unchanged", func() {
+		service := &corev1.Service{}
+		Expect(ApplyUserAnnotations(service, nil)).To(BeFalse())
+		Expect(service.Annotations).To(BeNil())
+	})
+
+	It("should merge user annotations and preserve foreign and managed ones", func() {
+		service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+			"cloud.example.com/lb-id":   "lb-123",
+			PodSpecHashAnnotation:       "abc",
+			"service.example.com/owner": "old",
+		}}}
+		changed := ApplyUserAnnotations(service, map[string]string{
+			"service.example.com/owner": "team-a",
+			PodSpecHashAnnotation:       "user-value",
+		})
+		Expect(changed).To(BeTrue())
+		Expect(service.Annotations).To(HaveKeyWithValue("service.example.com/owner", "team-a"))
+		Expect(service.Annotations).To(HaveKeyWithValue("cloud.example.com/lb-id", "lb-123"))
+		Expect(service.Annotations).To(HaveKeyWithValue(PodSpecHashAnnotation, "abc"))
+		Expect(service.Annotations).To(HaveKeyWithValue(AppliedAnnotationsAnnotation, "service.example.com/owner"))
+
+		// Re-applying the same annotations is a no-op
+		Expect(ApplyUserAnnotations(service, map[string]string{"service.example.com/owner": "team-a"})).To(BeFalse())
+	})
+
+	It("should remove previously applied keys that are no longer requested", func() {
+		service := &corev1.Service{}
+		ApplyUserAnnotations(service, map[string]string{"a.example.com/x": "1", "b.example.com/y": "2"})
+		Expect(ApplyUserAnnotations(service, map[string]string{"b.example.com/y": "2"})).To(BeTrue())
+		Expect(service.Annotations).NotTo(HaveKey("a.example.com/x"))
+		Expect(service.Annotations).To(HaveKeyWithValue(AppliedAnnotationsAnnotation, "b.example.com/y"))
+
+		Expect(ApplyUserAnnotations(service, nil)).To(BeTrue())
+		Expect(service.Annotations).To(BeNil())
+	})
+})
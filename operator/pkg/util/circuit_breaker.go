@@ -0,0 +1,119 @@
+package util
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// DefaultCircuitOpenInterval is how long an open circuit holds off reconciles before the
+// next attempt is let through
+const DefaultCircuitOpenInterval = 10 * time.Minute
+
+// CircuitBreaker counts consecutive reconcile failures per resource. Once threshold failures
+// happen at the same generation its circuit opens: reconciles are held off, and a single
+// attempt is let through per interval, until a reconcile succeeds or the generation or the
+// reset token (the value of the reset-circuit annotation) changes. A nil breaker or a zero
+// threshold never opens.
+type CircuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	interval  time.Duration
+	now       func() time.Time
+	entries   map[types.NamespacedName]*circuitState
+}
+
+// circuitState tracks the failures of one resource at one generation and reset token
+type circuitState struct {
+	generation  int64
+	reset       string
+	failures    int
+	lastFailure time.Time
+	// heldOff is set while the last Allow held the resource off, so that Record does not
+	// count the held-off reconcile as a success
+	heldOff bool
+}
+
+// NewCircuitBreaker creates a breaker that opens after threshold consecutive failures and
+// then lets one reconcile through per interval
+func NewCircuitBreaker(threshold int, interval time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		threshold: threshold,
+		interval:  interval,
+		now:       time.Now,
+		entries:   map[types.NamespacedName]*circuitState{},
+	}
+}
+
+// Interval returns how long an open circuit holds off reconciles
+func (b *CircuitBreaker) Interval() time.Duration {
+	if b == nil {
+		return 0
+	}
+	return b.interval
+}
+
+// Allow reports whether the resource may be reconciled at its current generation and reset
+// token. A changed generation or reset token closes the circuit and clears the failure count.
+func (b *CircuitBreaker) Allow(key types.NamespacedName, generation int64, reset string) bool {
+	if b == nil || b.threshold <= 0 {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	state, ok := b.entries[key]
+	if !ok || state.generation != generation || state.reset != reset {
+		b.entries[key] = &circuitState{generation: generation, reset: reset}
+		return true
+	}
+	state.heldOff = state.failures >= b.threshold && b.now().Sub(state.lastFailure) < b.interval
+	return !state.heldOff
+}
+
+// Record counts a failed reconcile, or clears the count after a successful one. Only
+// resources that passed Allow are tracked, and a reconcile that Allow held off is not counted.
+func (b *CircuitBreaker) Record(key types.NamespacedName, err error) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	state, ok := b.entries[key]
+	if !ok {
+		return
+	}
+	if state.heldOff {
+		state.heldOff = false
+		return
+	}
+	if err == nil {
+		state.failures = 0
+		return
+	}
+	state.failures++
+	state.lastFailure = b.now()
+}
+
+// Failures returns the number of consecutive failures recorded for the resource
+func (b *CircuitBreaker) Failures(key types.NamespacedName) int {
+	if b == nil {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if state, ok := b.entries[key]; ok {
+		return state.failures
+	}
+	return 0
+}
+
+// Forget drops the state of the resource, e.g. once it is deleted
+func (b *CircuitBreaker) Forget(key types.NamespacedName) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.entries, key)
+}
@@ -0,0 +1,87 @@
+package util
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("CircuitBreaker", func() {
+	key := types.NamespacedName{Name: "flaky", Namespace: "default"}
+	failure := errors.New("boom")
+
+	It("should open after consecutive failures at the same generation", func() {
+		b := NewCircuitBreaker(3, time.Minute)
+		for i := 0; i < 3; i++ {
+			Expect(b.Allow(key, 1, "")).To(BeTrue())
+			b.Record(key, failure)
+		}
+		Expect(b.Allow(key, 1, "")).To(BeFalse())
+		Expect(b.Failures(key)).To(Equal(3))
+
+		// A new generation or reset token closes it again
+		Expect(b.Allow(key, 2, "")).To(BeTrue())
+		Expect(b.Failures(key)).To(Equal(0))
+		for i := 0; i < 3; i++ {
+			b.Record(key, failure)
+		}
+		Expect(b.Allow(key, 2, "")).To(BeFalse())
+		Expect(b.Allow(key, 2, "2026-01-01T00:00:00Z")).To(BeTrue())
+	})
+
+	It("should clear the count after a success", func() {
+		b := NewCircuitBreaker(2, time.Minute)
+		Expect(b.Allow(key, 1, "")).To(BeTrue())
+		b.Record(key, failure)
+		b.Record(key, nil)
+		b.Record(key, failure)
+		Expect(b.Allow(key, 1, "")).To(BeTrue())
+	})
+
+	It("should not count a held-off reconcile as a success", func() {
+		b := NewCircuitBreaker(2, time.Minute)
+		for i := 0; i < 2; i++ {
+			Expect(b.Allow(key, 1, "")).To(BeTrue())
+			b.Record(key, failure)
+		}
+		Expect(b.Allow(key, 1, "")).To(BeFalse())
+		b.Record(key, nil)
+		Expect(b.Failures(key)).To(Equal(2))
+		Expect(b.Allow(key, 1, "")).To(BeFalse())
+	})
+
+	It("should never open when nil or disabled", func() {
+		var nilBreaker *CircuitBreaker
+		nilBreaker.Record(key, failure)
+		Expect(nilBreaker.Allow(key, 1, "")).To(BeTrue())
+
+		disabled := NewCircuitBreaker(0, time.Minute)
+		for i := 0; i < 10; i++ {
+			Expect(disabled.Allow(key, 1, "")).To(BeTrue())
+			disabled.Record(key, failure)
+		}
+	})
+
+	It("should let one attempt through per interval while open", func() {
+		now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+		b := NewCircuitBreaker(1, time.Minute)
+		b.now = func() time.Time { return now }
+
+		Expect(b.Allow(key, 1, "")).To(BeTrue())
+		b.Record(key, failure)
+		Expect(b.Allow(key, 1, "")).To(BeFalse())
+
+		now = now.Add(time.Minute)
+		Expect(b.Allow(key, 1, "")).To(BeTrue())
+		b.Record(key, failure)
+		Expect(b.Allow(key, 1, "")).To(BeFalse())
+
+		now = now.Add(time.Minute)
+		Expect(b.Allow(key, 1, "")).To(BeTrue())
+		b.Record(key, nil)
+		Expect(b.Allow(key, 1, "")).To(BeTrue())
+	})
+})
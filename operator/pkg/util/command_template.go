@@ -0,0 +1,127 @@
+package util
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// CommandTemplateData holds the values spec.command and spec.args of an Agent may reference
+type CommandTemplateData struct {
+	// ModelEndpoint is the URL of the Agent's ModelAPI
+	ModelEndpoint string
+	// MCPEndpoints is the comma-separated list of MCPServer URLs, ordered by server name
+	MCPEndpoints string
+}
+
+// commandPlaceholders are the fields of CommandTemplateData a template may reference
+var commandPlaceholders = []string{"ModelEndpoint", "MCPEndpoints"}
+
+// HostedCommandTemplateData holds the values spec.hostedConfig.command and args of a Hosted
+// ModelAPI may reference
+type HostedCommandTemplateData struct {
+	// Model is the Ollama model of spec.hostedConfig.model
+	Model string
+	// ModelsDir is the directory the pulled or shared models are stored in
+	ModelsDir string
+}
+
+// hostedCommandPlaceholders are the fields of HostedCommandTemplateData a template may reference
+var hostedCommandPlaceholders = []string{"Model", "ModelsDir"}
+
+// NewCommandTemplateData builds the template data from the resolved references
+func NewCommandTemplateData(modelEndpoint string, mcpServers map[string]string) CommandTemplateData {
+	names := make([]string, 0, len(mcpServers))
+	for name := range mcpServers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	endpoints := make([]string, 0, len(names))
+	for _, name := range names {
+		endpoints = append(endpoints, mcpServers[name])
+	}
+	return CommandTemplateData{ModelEndpoint: modelEndpoint, MCPEndpoints: strings.Join(endpoints, ",")}
+}
+
+// ValidateCommandTemplate checks that value only uses {{ .ModelEndpoint }} and
+// {{ .MCPEndpoints }}. Anything else in braces, including functions, pipelines and
+// control structures, is rejected rather than evaluated.
+func ValidateCommandTemplate(value string) error {
+	_, err := parseCommandTemplate(value, commandPlaceholders)
+	return err
+}
+
+// ExpandCommandTemplate replaces the placeholders in value with the template data
+func ExpandCommandTemplate(value string, data CommandTemplateData) (string, error) {
+	return expandCommandTemplate(value, commandPlaceholders, data)
+}
+
+// ValidateHostedCommandTemplate checks that value only uses {{ .Model }} and {{ .ModelsDir }},
+// so a custom launch cannot reference a model path the operator does not provide
+func ValidateHostedCommandTemplate(value string) error {
+	_, err := parseCommandTemplate(value, hostedCommandPlaceholders)
+	return err
+}
+
+// ExpandHostedCommandTemplate replaces the placeholders in value with the Hosted template data
+func ExpandHostedCommandTemplate(value string, data HostedCommandTemplateData) (string, error) {
+	return expandCommandTemplate(value, hostedCommandPlaceholders, data)
+}
+
+// expandCommandTemplate parses value against the placeholders and executes it with data
+func expandCommandTemplate(value string, placeholders []string, data any) (string, error) {
+	tmpl, err := parseCommandTemplate(value, placeholders)
+	if err != nil {
+		return "", err
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// parseCommandTemplate parses value and walks its tree, allowing only text and actions
+// that print one of the placeholders
+func parseCommandTemplate(value string, placeholders []string) (*template.Template, error) {
+	tmpl, err := template.New("command").Option("missingkey=error").Parse(value)
+	if err != nil {
+		return nil, err
+	}
+	if tmpl.Tree == nil {
+		return tmpl, nil
+	}
+	for _, node := range tmpl.Tree.Root.Nodes {
+		switch node := node.(type) {
+		case *parse.TextNode:
+		case *parse.ActionNode:
+			if !isPlaceholder(node.Pipe, placeholders) {
+				return nil, fmt.Errorf("unsupported placeholder %s: %s", node, allowedPlaceholders(placeholders))
+			}
+		default:
+			return nil, fmt.Errorf("unsupported template %s: %s", node, allowedPlaceholders(placeholders))
+		}
+	}
+	return tmpl, nil
+}
+
+// allowedPlaceholders describes the placeholders, e.g. "only {{ .A }} and {{ .B }} are allowed"
+func allowedPlaceholders(placeholders []string) string {
+	names := make([]string, len(placeholders))
+	for i, name := range placeholders {
+		names[i] = "{{ ." + name + " }}"
+	}
+	return "only " + strings.Join(names, " and ") + " are allowed"
+}
+
+// isPlaceholder reports whether the pipeline is a bare reference to one of the placeholders
+func isPlaceholder(pipe *parse.PipeNode, placeholders []string) bool {
+	if len(pipe.Decl) > 0 || len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return false
+	}
+	field, ok := pipe.Cmds[0].Args[0].(*parse.FieldNode)
+	return ok && len(field.Ident) == 1 && slices.Contains(placeholders, field.Ident[0])
+}
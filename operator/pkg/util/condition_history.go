@@ -0,0 +1,71 @@
+package util
+
+import (
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+const (
+	// DefaultConditionHistoryLimit is the default number of condition transitions kept in
+	// status.conditionHistory
+	DefaultConditionHistoryLimit = 10
+	// MaxConditionHistoryLimit bounds status.conditionHistory, so it cannot bloat the status
+	MaxConditionHistoryLimit = 100
+)
+
+// RecordConditionTransitions appends to the history the conditions whose current status it
+// does not record yet, ordered by transition time, and drops the oldest entries beyond limit.
+// A full history ignores transitions no newer than its oldest entry, as those were
+// already dropped. A limit of 0 clears the history. It returns whether the history changed.
+func RecordConditionTransitions(history []kaosv1alpha1.ConditionTransition, conditions []metav1.Condition, limit int) ([]kaosv1alpha1.ConditionTransition, bool) {
+	if limit <= 0 {
+		return nil, len(history) > 0
+	}
+
+	var added []kaosv1alpha1.ConditionTransition
+	for _, condition := range conditions {
+		if conditionRecorded(history, condition) {
+			continue
+		}
+		if len(history) >= limit && !history[0].LastTransitionTime.Before(&condition.LastTransitionTime) {
+			continue
+		}
+		added = append(added, kaosv1alpha1.ConditionTransition{
+			Type:               condition.Type,
+			Status:             condition.Status,
+			Reason:             condition.Reason,
+			Message:            condition.Message,
+			LastTransitionTime: condition.LastTransitionTime,
+		})
+	}
+	sort.SliceStable(added, func(i, j int) bool {
+		if !added[i].LastTransitionTime.Equal(&added[j].LastTransitionTime) {
+			return added[i].LastTransitionTime.Before(&added[j].LastTransitionTime)
+		}
+		return added[i].Type < added[j].Type
+	})
+
+	history = append(history, added...)
+	changed := len(added) > 0
+	if len(history) > limit {
+		history = append([]kaosv1alpha1.ConditionTransition(nil), history[len(history)-limit:]...)
+		changed = true
+	}
+	return history, changed
+}
+
+// conditionRecorded reports whether the history has an entry for the condition's current
+// status and transition time
+func conditionRecorded(history []kaosv1alpha1.ConditionTransition, condition metav1.Condition) bool {
+	for i := range history {
+		entry := &history[i]
+		if entry.Type == condition.Type && entry.Status == condition.Status &&
+			entry.LastTransitionTime.Equal(&condition.LastTransitionTime) {
+			return true
+		}
+	}
+	return false
+}
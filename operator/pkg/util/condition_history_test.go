@@ -0,0 +1,64 @@
+package util
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+var _ = Describe("RecordConditionTransitions", func() {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	ready := func(status metav1.ConditionStatus, reason string, minute int) metav1.Condition {
+		return metav1.Condition{Type: "Ready", Status: status, Reason: reason,
+			LastTransitionTime: metav1.NewTime(start.Add(time.Duration(minute) * time.Minute))}
+	}
+	summary := func(history []kaosv1alpha1.ConditionTransition) []string {
+		var entries []string
+		for _, entry := range history {
+			entries = append(entries, entry.Type+"="+string(entry.Status)+"/"+entry.Reason)
+		}
+		return entries
+	}
+
+	It("should record transitions in order and cap the history at the limit", func() {
+		var history []kaosv1alpha1.ConditionTransition
+		degraded := metav1.Condition{Type: "Degraded", Status: metav1.ConditionFalse, Reason: "DeploymentReady",
+			LastTransitionTime: metav1.NewTime(start)}
+
+		history, changed := RecordConditionTransitions(history, []metav1.Condition{ready(metav1.ConditionFalse, "DeploymentNotReady", 0), degraded}, 3)
+		Expect(changed).To(BeTrue())
+		Expect(summary(history)).To(Equal([]string{"Degraded=False/DeploymentReady", "Ready=False/DeploymentNotReady"}))
+
+		// An unchanged condition is not recorded again
+		_, changed = RecordConditionTransitions(history, []metav1.Condition{ready(metav1.ConditionFalse, "DeploymentNotReady", 0), degraded}, 3)
+		Expect(changed).To(BeFalse())
+
+		// A flapping condition fills the history, which keeps the latest transitions only
+		history, _ = RecordConditionTransitions(history, []metav1.Condition{ready(metav1.ConditionTrue, "DeploymentReady", 1), degraded}, 3)
+		history, changed = RecordConditionTransitions(history, []metav1.Condition{ready(metav1.ConditionFalse, "EndpointsNotReady", 2), degraded}, 3)
+		Expect(changed).To(BeTrue())
+		Expect(summary(history)).To(Equal([]string{
+			"Ready=False/DeploymentNotReady",
+			"Ready=True/DeploymentReady",
+			"Ready=False/EndpointsNotReady",
+		}))
+
+		// The dropped transition of the unchanged Degraded condition is not recorded again
+		_, changed = RecordConditionTransitions(history, []metav1.Condition{ready(metav1.ConditionFalse, "EndpointsNotReady", 2), degraded}, 3)
+		Expect(changed).To(BeFalse())
+	})
+
+	It("should clear the history when disabled", func() {
+		history := []kaosv1alpha1.ConditionTransition{{Type: "Ready", Status: metav1.ConditionTrue}}
+		history, changed := RecordConditionTransitions(history, nil, 0)
+		Expect(changed).To(BeTrue())
+		Expect(history).To(BeNil())
+
+		_, changed = RecordConditionTransitions(nil, []metav1.Condition{ready(metav1.ConditionTrue, "DeploymentReady", 0)}, 0)
+		Expect(changed).To(BeFalse())
+	})
+})
@@ -1,8 +1,13 @@
 package util
 
 import (
+	"strings"
+	"time"
+
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 
 	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
 )
@@ -34,3 +39,92 @@ func CopyDeploymentStatus(deployment *appsv1.Deployment) *kaosv1alpha1.Deploymen
 
 	return status
 }
+
+// CopyStatefulSetStatus creates a DeploymentStatus from a Kubernetes StatefulSet's status,
+// so StatefulSet-backed resources report rollout progress in the same shape
+func CopyStatefulSetStatus(statefulSet *appsv1.StatefulSet) *kaosv1alpha1.DeploymentStatus {
+	if statefulSet == nil {
+		return nil
+	}
+
+	status := &kaosv1alpha1.DeploymentStatus{
+		Replicas:          statefulSet.Status.Replicas,
+		ReadyReplicas:     statefulSet.Status.ReadyReplicas,
+		AvailableReplicas: statefulSet.Status.AvailableReplicas,
+		UpdatedReplicas:   statefulSet.Status.UpdatedReplicas,
+	}
+
+	for _, cond := range statefulSet.Status.Conditions {
+		status.Conditions = append(status.Conditions, metav1.Condition{
+			Type:               string(cond.Type),
+			Status:             metav1.ConditionStatus(cond.Status),
+			LastTransitionTime: cond.LastTransitionTime,
+			Reason:             cond.Reason,
+			Message:            cond.Message,
+		})
+	}
+
+	return status
+}
+
+// quotaRejections are fragments of the admission errors returned when a ResourceQuota or
+// LimitRange rejects a pod, e.g. "exceeded quota: compute" or "maximum cpu usage per Container is 1"
+var quotaRejections = []string{"exceeded quota", "failed quota", "usage per ", "limit to request ratio per "}
+
+// QuotaRejection returns the message of a ReplicaFailure condition caused by a ResourceQuota
+// or LimitRange rejecting pod creation, checking the Deployment first and then its
+// ReplicaSets. Returns "" if pod creation is not being rejected.
+func QuotaRejection(deployment *appsv1.Deployment, replicaSets []appsv1.ReplicaSet) string {
+	for _, cond := range deployment.Status.Conditions {
+		if cond.Type == appsv1.DeploymentReplicaFailure && cond.Status == corev1.ConditionTrue && isQuotaRejection(cond.Message) {
+			return cond.Message
+		}
+	}
+	for _, rs := range replicaSets {
+		for _, cond := range rs.Status.Conditions {
+			if cond.Type == appsv1.ReplicaSetReplicaFailure && cond.Status == corev1.ConditionTrue && isQuotaRejection(cond.Message) {
+				return cond.Message
+			}
+		}
+	}
+	return ""
+}
+
+// isQuotaRejection reports whether a pod creation failure message comes from quota or limit-range admission
+func isQuotaRejection(message string) bool {
+	for _, fragment := range quotaRejections {
+		if strings.Contains(message, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// LatestWarningEvent returns the most recent Warning event about one of the objects with the
+// given UIDs, or nil if there is none
+func LatestWarningEvent(events []corev1.Event, uids map[types.UID]bool) *corev1.Event {
+	var latest *corev1.Event
+	for i := range events {
+		event := &events[i]
+		if event.Type != corev1.EventTypeWarning || !uids[event.InvolvedObject.UID] {
+			continue
+		}
+		if latest == nil || eventTime(event).After(eventTime(latest)) {
+			latest = event
+		}
+	}
+	return latest
+}
+
+// eventTime returns when an event last occurred: its lastTimestamp, its eventTime for events
+// recorded through the events.k8s.io API, or its creation time
+func eventTime(event *corev1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	default:
+		return event.CreationTimestamp.Time
+	}
+}
@@ -0,0 +1,68 @@
+package util
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("QuotaRejection", func() {
+	failure := func(message string) appsv1.DeploymentCondition {
+		return appsv1.DeploymentCondition{
+			Type: appsv1.DeploymentReplicaFailure, Status: corev1.ConditionTrue, Reason: "FailedCreate", Message: message,
+		}
+	}
+
+	It("should recognise quota and limit-range rejections on the Deployment", func() {
+		for _, message := range []string{
+			`pods "a" is forbidden: exceeded quota: compute, requested: cpu=2, used: cpu=4, limited: cpu=4`,
+			`pods "a" is forbidden: failed quota: compute: must specify limits.cpu`,
+			`pods "a" is forbidden: maximum memory usage per Container is 1Gi, but limit is 2Gi`,
+			`pods "a" is forbidden: cpu max limit to request ratio per Container is 2, but provided ratio is 4.000000`,
+		} {
+			deployment := &appsv1.Deployment{Status: appsv1.DeploymentStatus{Conditions: []appsv1.DeploymentCondition{failure(message)}}}
+			Expect(QuotaRejection(deployment, nil)).To(Equal(message))
+		}
+	})
+
+	It("should ignore other failures and resolved conditions", func() {
+		other := failure(`pods "a" is forbidden: violates PodSecurity "restricted:latest"`)
+		resolved := failure(`pods "a" is forbidden: exceeded quota: compute`)
+		resolved.Status = corev1.ConditionFalse
+		deployment := &appsv1.Deployment{Status: appsv1.DeploymentStatus{Conditions: []appsv1.DeploymentCondition{other, resolved}}}
+		Expect(QuotaRejection(deployment, nil)).To(BeEmpty())
+	})
+})
+
+var _ = Describe("LatestWarningEvent", func() {
+	warning := func(uid types.UID, reason string, at time.Time) corev1.Event {
+		return corev1.Event{
+			InvolvedObject: corev1.ObjectReference{UID: uid},
+			Type:           corev1.EventTypeWarning,
+			Reason:         reason,
+			LastTimestamp:  metav1.NewTime(at),
+		}
+	}
+
+	It("should pick the most recent Warning event of the given objects", func() {
+		now := time.Now()
+		normal := warning("pod", "Scheduled", now.Add(time.Hour))
+		normal.Type = corev1.EventTypeNormal
+		recorded := corev1.Event{InvolvedObject: corev1.ObjectReference{UID: "rs"}, Type: corev1.EventTypeWarning, Reason: "FailedCreate",
+			EventTime: metav1.NewMicroTime(now.Add(time.Minute))}
+		events := []corev1.Event{
+			warning("pod", "FailedScheduling", now),
+			recorded,
+			normal,
+			warning("other", "BackOff", now.Add(time.Hour)),
+		}
+		Expect(LatestWarningEvent(events, map[types.UID]bool{"pod": true, "rs": true}).Reason).To(Equal("FailedCreate"))
+		Expect(LatestWarningEvent(events, map[types.UID]bool{"pod": true}).Reason).To(Equal("FailedScheduling"))
+		Expect(LatestWarningEvent(events, map[types.UID]bool{"deployment": true})).To(BeNil())
+	})
+})
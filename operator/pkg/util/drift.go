@@ -0,0 +1,81 @@
+package util
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+)
+
+// AppliedReplicasAnnotation records the replica count the operator last applied to a
+// Deployment, so replicas changed by someone else can be told apart from spec changes
+const AppliedReplicasAnnotation = ReservedAnnotationPrefix + "applied-replicas"
+
+// SetAppliedReplicas records the Deployment's replica count in its AppliedReplicasAnnotation
+func SetAppliedReplicas(deployment *appsv1.Deployment) {
+	if deployment.Spec.Replicas == nil {
+		return
+	}
+	if deployment.Annotations == nil {
+		deployment.Annotations = map[string]string{}
+	}
+	deployment.Annotations[AppliedReplicasAnnotation] = strconv.Itoa(int(*deployment.Spec.Replicas))
+}
+
+// ReplicasDrifted reports whether the live Deployment was scaled away from the replica count
+// the operator last applied, while that count is still the desired one
+func ReplicasDrifted(live, desired *appsv1.Deployment) bool {
+	if live.Spec.Replicas == nil || desired.Spec.Replicas == nil || *live.Spec.Replicas == *desired.Spec.Replicas {
+		return false
+	}
+	return live.Annotations[AppliedReplicasAnnotation] == strconv.Itoa(int(*desired.Spec.Replicas))
+}
+
+// TemplateDrift lists the containers of the live pod template that no longer match the
+// desired template: added or removed containers, and changed images, commands, args, env or
+// resources. Fields the API server defaults are only compared where the desired template
+// sets them, and template annotations such as kubectl's restartedAt are ignored.
+func TemplateDrift(desired, live *corev1.PodTemplateSpec) []string {
+	var drift []string
+	drift = append(drift, containerDrift("initContainers", desired.Spec.InitContainers, live.Spec.InitContainers)...)
+	drift = append(drift, containerDrift("containers", desired.Spec.Containers, live.Spec.Containers)...)
+	return drift
+}
+
+// containerDrift compares desired and live containers by name
+func containerDrift(field string, desired, live []corev1.Container) []string {
+	var drift []string
+	for _, want := range desired {
+		path := fmt.Sprintf("%s[%s]", field, want.Name)
+		i := slices.IndexFunc(live, func(c corev1.Container) bool { return c.Name == want.Name })
+		if i < 0 {
+			drift = append(drift, path)
+			continue
+		}
+		got := live[i]
+		if got.Image != want.Image {
+			drift = append(drift, path+".image")
+		}
+		if !slices.Equal(got.Command, want.Command) {
+			drift = append(drift, path+".command")
+		}
+		if !slices.Equal(got.Args, want.Args) {
+			drift = append(drift, path+".args")
+		}
+		if len(got.Env) != len(want.Env) || !equality.Semantic.DeepDerivative(want.Env, got.Env) {
+			drift = append(drift, path+".env")
+		}
+		if !equality.Semantic.DeepDerivative(want.Resources, got.Resources) {
+			drift = append(drift, path+".resources")
+		}
+	}
+	for _, got := range live {
+		if !slices.ContainsFunc(desired, func(c corev1.Container) bool { return c.Name == got.Name }) {
+			drift = append(drift, fmt.Sprintf("%s[%s]", field, got.Name))
+		}
+	}
+	return drift
+}
@@ -0,0 +1,67 @@
+package util
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("TemplateDrift", func() {
+	desired := func() *corev1.PodTemplateSpec {
+		return &corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Name:  "agent",
+			Image: "example.com/agent:1",
+			Env:   []corev1.EnvVar{{Name: "AGENT_NAME", Value: "a"}},
+			Resources: corev1.ResourceRequirements{Limits: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("256Mi"),
+			}},
+		}}}}
+	}
+
+	It("should ignore defaulted fields and template annotations", func() {
+		live := desired()
+		live.Annotations = map[string]string{"kubectl.kubernetes.io/restartedAt": "now"}
+		live.Spec.Containers[0].ImagePullPolicy = corev1.PullIfNotPresent
+		live.Spec.Containers[0].TerminationMessagePath = "/dev/termination-log"
+		live.Spec.Containers[0].Resources.Requests = corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")}
+		Expect(TemplateDrift(desired(), live)).To(BeEmpty())
+	})
+
+	It("should list edited, added and removed containers", func() {
+		live := desired()
+		live.Spec.Containers[0].Image = "example.com/agent:hotfix"
+		live.Spec.Containers[0].Env = append(live.Spec.Containers[0].Env, corev1.EnvVar{Name: "DEBUG", Value: "1"})
+		live.Spec.Containers = append(live.Spec.Containers, corev1.Container{Name: "debug"})
+		Expect(TemplateDrift(desired(), live)).To(Equal([]string{
+			"containers[agent].image", "containers[agent].env", "containers[debug]",
+		}))
+
+		live.Spec.Containers = nil
+		Expect(TemplateDrift(desired(), live)).To(Equal([]string{"containers[agent]"}))
+	})
+})
+
+var _ = Describe("ReplicasDrifted", func() {
+	deployment := func(replicas int32, applied string) *appsv1.Deployment {
+		d := &appsv1.Deployment{Spec: appsv1.DeploymentSpec{Replicas: &replicas}}
+		if applied != "" {
+			d.ObjectMeta = metav1.ObjectMeta{Annotations: map[string]string{AppliedReplicasAnnotation: applied}}
+		}
+		return d
+	}
+
+	It("should only report replicas scaled away from the applied count", func() {
+		desired := deployment(2, "")
+		SetAppliedReplicas(desired)
+		Expect(desired.Annotations[AppliedReplicasAnnotation]).To(Equal("2"))
+
+		Expect(ReplicasDrifted(deployment(5, "2"), desired)).To(BeTrue())
+		Expect(ReplicasDrifted(deployment(2, "2"), desired)).To(BeFalse())
+		// A spec change is a regular update rather than drift
+		Expect(ReplicasDrifted(deployment(1, "1"), desired)).To(BeFalse())
+		Expect(ReplicasDrifted(deployment(5, ""), desired)).To(BeFalse())
+	})
+})
@@ -0,0 +1,83 @@
+package util
+
+import (
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// StableEnv returns env in a deterministic order, so that equivalent input in a different
+// order renders the same pod template and does not trigger a rollout. Duplicate names are
+// collapsed to their last definition, which is the one Kubernetes applies, so sources
+// appended later keep precedence. Variables are sorted by name, except that a variable
+// referencing another via $(NAME) is kept after it, as references only resolve to
+// variables defined earlier in the list.
+func StableEnv(env []corev1.EnvVar) []corev1.EnvVar {
+	if len(env) == 0 {
+		return env
+	}
+
+	byName := make(map[string]corev1.EnvVar, len(env))
+	for _, e := range env {
+		byName[e.Name] = e
+	}
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := make([]corev1.EnvVar, 0, len(names))
+	emitted := make(map[string]bool, len(names))
+	for len(result) < len(names) {
+		next := ""
+		for _, name := range names {
+			if emitted[name] {
+				continue
+			}
+			if next == "" {
+				// Fallback for reference cycles, which cannot be ordered
+				next = name
+			}
+			if dependenciesEmitted(byName[name].Value, name, byName, emitted) {
+				next = name
+				break
+			}
+		}
+		emitted[next] = true
+		result = append(result, byName[next])
+	}
+	return result
+}
+
+// dependenciesEmitted reports whether every variable referenced by value is already emitted
+func dependenciesEmitted(value, self string, byName map[string]corev1.EnvVar, emitted map[string]bool) bool {
+	for _, ref := range envReferences(value) {
+		if _, ok := byName[ref]; ok && ref != self && !emitted[ref] {
+			return false
+		}
+	}
+	return true
+}
+
+// envReferences returns the names referenced as $(NAME) in an env var value; $$ escapes a reference
+func envReferences(value string) []string {
+	var refs []string
+	for i := 0; i < len(value)-1; i++ {
+		if value[i] != '$' {
+			continue
+		}
+		if value[i+1] == '$' {
+			i++
+			continue
+		}
+		if value[i+1] == '(' {
+			if end := strings.IndexByte(value[i+2:], ')'); end >= 0 {
+				refs = append(refs, value[i+2:i+2+end])
+				i += end + 2
+			}
+		}
+	}
+	return refs
+}
@@ -0,0 +1,45 @@
+package util
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// envNames returns the names of env in order
+func envNames(env []corev1.EnvVar) []string {
+	names := make([]string, 0, len(env))
+	for _, e := range env {
+		names = append(names, e.Name)
+	}
+	return names
+}
+
+var _ = Describe("StableEnv", func() {
+	It("should sort by name regardless of input order", func() {
+		a := StableEnv([]corev1.EnvVar{{Name: "B", Value: "2"}, {Name: "A", Value: "1"}, {Name: "C", Value: "3"}})
+		b := StableEnv([]corev1.EnvVar{{Name: "C", Value: "3"}, {Name: "B", Value: "2"}, {Name: "A", Value: "1"}})
+		Expect(a).To(Equal(b))
+		Expect(envNames(a)).To(Equal([]string{"A", "B", "C"}))
+	})
+
+	It("should keep the last definition of a duplicate name", func() {
+		env := StableEnv([]corev1.EnvVar{{Name: "MODEL", Value: "user"}, {Name: "MODEL", Value: "operator"}})
+		Expect(env).To(Equal([]corev1.EnvVar{{Name: "MODEL", Value: "operator"}}))
+	})
+
+	It("should order referenced variables before their dependents", func() {
+		env := StableEnv([]corev1.EnvVar{
+			{Name: "A_URL", Value: "http://$(Z_HOST):$(PORT)/$$(LITERAL)"},
+			{Name: "PORT", Value: "8000"},
+			{Name: "Z_HOST", Value: "localhost"},
+			{Name: "LITERAL", Value: "x"},
+		})
+		Expect(envNames(env)).To(Equal([]string{"LITERAL", "PORT", "Z_HOST", "A_URL"}))
+	})
+
+	It("should still order reference cycles deterministically", func() {
+		env := StableEnv([]corev1.EnvVar{{Name: "B", Value: "$(A)"}, {Name: "A", Value: "$(B)"}})
+		Expect(envNames(env)).To(Equal([]string{"A", "B"}))
+	})
+})
@@ -0,0 +1,52 @@
+package util
+
+import (
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// FeatureGatesAnnotation toggles experimental behaviors of a single resource, as
+// comma-separated Gate=true|false pairs in the style of the Kubernetes --feature-gates flag
+const FeatureGatesAnnotation = "kaos.agentic/feature-gates"
+
+// FeatureGate names an experimental behavior that can be enabled per resource
+type FeatureGate string
+
+const (
+	// FeatureGateBlueGreenRollout rolls out a new pod template by bringing up a full set of
+	// new pods before any old pod is removed
+	FeatureGateBlueGreenRollout FeatureGate = "BlueGreenRollout"
+)
+
+// knownFeatureGates are the gates the operator recognizes; all are disabled by default
+var knownFeatureGates = []FeatureGate{FeatureGateBlueGreenRollout}
+
+// FeatureGates parses the annotation into the recognized gates it sets. Unknown gates and
+// entries that are not Gate=bool pairs are ignored, so resources annotated for a newer
+// operator keep reconciling.
+func FeatureGates(obj client.Object) map[FeatureGate]bool {
+	gates := map[FeatureGate]bool{}
+	for _, entry := range strings.Split(obj.GetAnnotations()[FeatureGatesAnnotation], ",") {
+		name, value, found := strings.Cut(strings.TrimSpace(entry), "=")
+		if !found {
+			continue
+		}
+		enabled, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			continue
+		}
+		for _, gate := range knownFeatureGates {
+			if string(gate) == strings.TrimSpace(name) {
+				gates[gate] = enabled
+			}
+		}
+	}
+	return gates
+}
+
+// FeatureGateEnabled reports whether the annotation enables the gate on the object
+func FeatureGateEnabled(obj client.Object, gate FeatureGate) bool {
+	return FeatureGates(obj)[gate]
+}
@@ -0,0 +1,24 @@
+package util
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("FeatureGates", func() {
+	It("should parse recognized gates and ignore unknown or malformed entries", func() {
+		obj := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+			FeatureGatesAnnotation: " BlueGreenRollout = true ,Canary=true,Shadow,BlueGreenRollout=maybe",
+		}}}
+		Expect(FeatureGates(obj)).To(Equal(map[FeatureGate]bool{FeatureGateBlueGreenRollout: true}))
+		Expect(FeatureGateEnabled(obj, FeatureGateBlueGreenRollout)).To(BeTrue())
+
+		obj.Annotations[FeatureGatesAnnotation] = "BlueGreenRollout=false"
+		Expect(FeatureGateEnabled(obj, FeatureGateBlueGreenRollout)).To(BeFalse())
+
+		obj.Annotations = nil
+		Expect(FeatureGates(obj)).To(BeEmpty())
+	})
+})
@@ -0,0 +1,49 @@
+package util
+
+import (
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// ExtraFinalizersAnnotation lists, comma-separated, the finalizers of external controllers
+// that hook the deletion of a resource. The operator adds them to the resource and only
+// runs its own cleanup once they have all been removed again.
+const ExtraFinalizersAnnotation = "kaos.agentic/extra-finalizers"
+
+// ExtraFinalizers returns the finalizer names registered through the annotation, skipping
+// names in the operator's reserved kaos.tools/ domain
+func ExtraFinalizers(obj client.Object) []string {
+	var finalizers []string
+	for _, name := range strings.Split(obj.GetAnnotations()[ExtraFinalizersAnnotation], ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || strings.HasPrefix(name, ReservedAnnotationPrefix) || slices.Contains(finalizers, name) {
+			continue
+		}
+		finalizers = append(finalizers, name)
+	}
+	return finalizers
+}
+
+// AddExtraFinalizers adds the registered extra finalizers missing from the object.
+// Returns whether any finalizer was added.
+func AddExtraFinalizers(obj client.Object) bool {
+	added := false
+	for _, name := range ExtraFinalizers(obj) {
+		added = controllerutil.AddFinalizer(obj, name) || added
+	}
+	return added
+}
+
+// PendingExtraFinalizers returns the registered extra finalizers still present on the object
+func PendingExtraFinalizers(obj client.Object) []string {
+	var pending []string
+	for _, name := range ExtraFinalizers(obj) {
+		if controllerutil.ContainsFinalizer(obj, name) {
+			pending = append(pending, name)
+		}
+	}
+	return pending
+}
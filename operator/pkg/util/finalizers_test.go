@@ -0,0 +1,24 @@
+package util
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("ExtraFinalizers", func() {
+	It("should parse the annotation and skip reserved and duplicate names", func() {
+		obj := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+			ExtraFinalizersAnnotation: " backup.example.com/snapshot,,kaos.tools/agent-finalizer,dns.example.com/records,backup.example.com/snapshot",
+		}}}
+		Expect(ExtraFinalizers(obj)).To(Equal([]string{"backup.example.com/snapshot", "dns.example.com/records"}))
+
+		Expect(AddExtraFinalizers(obj)).To(BeTrue())
+		Expect(AddExtraFinalizers(obj)).To(BeFalse())
+		Expect(obj.Finalizers).To(Equal([]string{"backup.example.com/snapshot", "dns.example.com/records"}))
+
+		obj.Finalizers = []string{"dns.example.com/records", "other.example.com/x"}
+		Expect(PendingExtraFinalizers(obj)).To(Equal([]string{"dns.example.com/records"}))
+	})
+})
@@ -0,0 +1,232 @@
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ImageResolver resolves a tagged image reference to the digest it currently points at.
+type ImageResolver interface {
+	// ResolveDigest returns the manifest digest (e.g. "sha256:abc...") for the image.
+	ResolveDigest(ctx context.Context, image string) (string, error)
+}
+
+// manifestAcceptHeaders lists the manifest media types accepted when resolving digests.
+// Index types come first so multi-arch images resolve to the index digest.
+var manifestAcceptHeaders = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// RegistryResolver resolves image digests using the OCI distribution (registry v2) API.
+// Anonymous bearer token authentication is supported for public registries.
+type RegistryResolver struct {
+	Client *http.Client
+}
+
+// NewRegistryResolver creates a RegistryResolver with a default HTTP client
+func NewRegistryResolver() *RegistryResolver {
+	return &RegistryResolver{
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// ResolveDigest queries the registry for the manifest digest of the image
+func (r *RegistryResolver) ResolveDigest(ctx context.Context, image string) (string, error) {
+	registry, repository, reference := ParseImageReference(image)
+	manifestURL := fmt.Sprintf("https://%s/v2/%s/manifests/%s", registry, repository, reference)
+
+	resp, err := r.headManifest(ctx, manifestURL, "")
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		token, err := r.fetchToken(ctx, resp.Header.Get("WWW-Authenticate"))
+		if err != nil {
+			return "", fmt.Errorf("failed to authenticate with registry %s: %w", registry, err)
+		}
+		resp, err = r.headManifest(ctx, manifestURL, token)
+		if err != nil {
+			return "", err
+		}
+		resp.Body.Close()
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("registry %s returned %s for %s", registry, resp.Status, image)
+	}
+
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		return "", fmt.Errorf("registry %s did not return a digest for %s", registry, image)
+	}
+	return digest, nil
+}
+
+// headManifest issues a HEAD request for a manifest, optionally with a bearer token
+func (r *RegistryResolver) headManifest(ctx context.Context, manifestURL, token string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, manifestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", strings.Join(manifestAcceptHeaders, ", "))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return r.Client.Do(req)
+}
+
+// fetchToken obtains an anonymous bearer token from the challenge in a WWW-Authenticate header
+func (r *RegistryResolver) fetchToken(ctx context.Context, challenge string) (string, error) {
+	if !strings.HasPrefix(challenge, "Bearer ") {
+		return "", fmt.Errorf("unsupported auth challenge %q", challenge)
+	}
+
+	params := map[string]string{}
+	for _, part := range strings.Split(strings.TrimPrefix(challenge, "Bearer "), ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(part), "=")
+		if found {
+			params[key] = strings.Trim(value, `"`)
+		}
+	}
+	if params["realm"] == "" {
+		return "", fmt.Errorf("auth challenge has no realm")
+	}
+
+	query := url.Values{}
+	if params["service"] != "" {
+		query.Set("service", params["service"])
+	}
+	if params["scope"] != "" {
+		query.Set("scope", params["scope"])
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, params["realm"]+"?"+query.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := r.Client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token endpoint returned %s", resp.Status)
+	}
+
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", err
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	return body.AccessToken, nil
+}
+
+// ParseImageReference splits an image into registry host, repository and tag (or digest).
+// Images without a registry host default to Docker Hub, and images without a tag to "latest".
+func ParseImageReference(image string) (registry, repository, reference string) {
+	name := image
+	if at := strings.Index(name, "@"); at >= 0 {
+		reference = name[at+1:]
+		name = name[:at]
+	}
+	if colon := strings.LastIndex(name, ":"); colon > strings.LastIndex(name, "/") {
+		if reference == "" {
+			reference = name[colon+1:]
+		}
+		name = name[:colon]
+	}
+	if reference == "" {
+		reference = "latest"
+	}
+
+	registry = "registry-1.docker.io"
+	repository = name
+	if slash := strings.Index(name, "/"); slash >= 0 {
+		host := name[:slash]
+		if strings.ContainsAny(host, ".:") || host == "localhost" {
+			registry = host
+			repository = name[slash+1:]
+		}
+	}
+	if registry == "registry-1.docker.io" && !strings.Contains(repository, "/") {
+		repository = "library/" + repository
+	}
+
+	return registry, repository, reference
+}
+
+// DefaultPullPolicy returns the pull policy Kubernetes itself defaults to: Always for
+// untagged or :latest images, whose content can change between pulls, and IfNotPresent
+// for pinned tags and digests
+func DefaultPullPolicy(image string) corev1.PullPolicy {
+	if strings.Contains(image, "@") {
+		return corev1.PullIfNotPresent
+	}
+	if _, _, reference := ParseImageReference(image); reference == "latest" {
+		return corev1.PullAlways
+	}
+	return corev1.PullIfNotPresent
+}
+
+// PullPolicy returns the configured pull policy, or the default for the image when unset
+func PullPolicy(policy corev1.PullPolicy, image string) corev1.PullPolicy {
+	if policy != "" {
+		return policy
+	}
+	return DefaultPullPolicy(image)
+}
+
+// PinImageDigest rewrites the image of the named container in the pod template to its
+// digest-pinned form (<image>@<digest>) and refreshes the pod spec hash annotation.
+// The previously recorded reference is reused as long as the image tag is unchanged,
+// so the registry is only queried when the tag changes.
+// Returns the pinned image reference that should be recorded in status.
+func PinImageDigest(ctx context.Context, resolver ImageResolver, template *corev1.PodTemplateSpec, containerName, recorded string) (string, error) {
+	for i := range template.Spec.Containers {
+		container := &template.Spec.Containers[i]
+		if container.Name != containerName {
+			continue
+		}
+
+		// Image already pinned by the user - nothing to resolve
+		if strings.Contains(container.Image, "@") {
+			return container.Image, nil
+		}
+
+		pinned := recorded
+		if !strings.HasPrefix(recorded, container.Image+"@") {
+			if resolver == nil {
+				return "", fmt.Errorf("no image resolver configured to pin %s", container.Image)
+			}
+			digest, err := resolver.ResolveDigest(ctx, container.Image)
+			if err != nil {
+				return "", fmt.Errorf("failed to resolve digest for %s: %w", container.Image, err)
+			}
+			pinned = fmt.Sprintf("%s@%s", container.Image, digest)
+		}
+
+		container.Image = pinned
+		SetPodTemplateHash(template)
+		return pinned, nil
+	}
+
+	return "", fmt.Errorf("container %q not found in pod template", containerName)
+}
@@ -0,0 +1,25 @@
+package util
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("PullPolicy", func() {
+	It("should default to Always for :latest and untagged images", func() {
+		Expect(PullPolicy("", "axsauze/kaos-agent:latest")).To(Equal(corev1.PullAlways))
+		Expect(PullPolicy("", "ghcr.io/org/image")).To(Equal(corev1.PullAlways))
+		Expect(PullPolicy("", "localhost:5000/kaos-agent")).To(Equal(corev1.PullAlways))
+	})
+
+	It("should default to IfNotPresent for pinned tags and digests", func() {
+		Expect(PullPolicy("", "axsauze/kaos-agent:v0.3.1")).To(Equal(corev1.PullIfNotPresent))
+		Expect(PullPolicy("", "axsauze/kaos-agent:latest@sha256:abc")).To(Equal(corev1.PullIfNotPresent))
+	})
+
+	It("should prefer the configured policy", func() {
+		Expect(PullPolicy(corev1.PullNever, "axsauze/kaos-agent:latest")).To(Equal(corev1.PullNever))
+		Expect(PullPolicy(corev1.PullAlways, "axsauze/kaos-agent:v0.3.1")).To(Equal(corev1.PullAlways))
+	})
+})
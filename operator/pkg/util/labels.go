@@ -0,0 +1,87 @@
+package util
+
+import (
+	"os"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PropagatedLabelsEnvVar is the operator setting listing, comma-separated, the owner label
+// keys (e.g. team, cost-center) copied onto every object generated for a resource
+const PropagatedLabelsEnvVar = "PROPAGATED_LABELS"
+
+// ComponentLabel and CRNameLabel are set on every generated pod, so NetworkPolicies,
+// ServiceMonitors and mesh policies can select the pods of any kind by the same keys. They
+// are not part of the workload selectors, which cannot change on existing workloads.
+// ModelAPI Services also carry ComponentLabel, which their EndpointSlices inherit.
+const (
+	// ComponentLabel is the kind of resource the pod runs: agent, modelapi or mcpserver
+	ComponentLabel = "kaos.agentic/component"
+	// CRNameLabel is the name of the Agent, ModelAPI or MCPServer the pod runs
+	CRNameLabel = "kaos.agentic/cr-name"
+)
+
+// operatorLabelKeys are the labels set by the operator itself, used as selectors or on every
+// generated pod, so they are never propagated from the owner
+var operatorLabelKeys = map[string]bool{
+	"app": true, "agent": true, "modelapi": true, "mcpserver": true,
+	ComponentLabel: true, CRNameLabel: true,
+}
+
+// PodLabels returns the labels of a generated pod: the workload's selector labels plus the
+// component labels. The selector map is copied, not modified.
+func PodLabels(selector map[string]string, component, name string) map[string]string {
+	labels := make(map[string]string, len(selector)+2)
+	for key, value := range selector {
+		labels[key] = value
+	}
+	labels[ComponentLabel] = component
+	labels[CRNameLabel] = name
+	return labels
+}
+
+// PropagatedLabelKeys returns the label keys configured in PROPAGATED_LABELS, skipping
+// operator-managed keys
+func PropagatedLabelKeys() []string {
+	var keys []string
+	for _, key := range strings.Split(os.Getenv(PropagatedLabelsEnvVar), ",") {
+		key = strings.TrimSpace(key)
+		if key == "" || operatorLabelKeys[key] || strings.HasPrefix(key, ReservedAnnotationPrefix) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// PropagateLabels copies the owner's labels listed in keys onto the object, and removes
+// listed keys the owner no longer carries. The label map is replaced rather than mutated,
+// since generated objects share it with their selectors. Returns whether the labels changed.
+func PropagateLabels(obj, owner metav1.Object, keys []string) bool {
+	current := obj.GetLabels()
+	ownerLabels := owner.GetLabels()
+
+	labels := make(map[string]string, len(current)+len(keys))
+	for key, value := range current {
+		labels[key] = value
+	}
+	changed := false
+	for _, key := range keys {
+		value, ok := ownerLabels[key]
+		existing, had := labels[key]
+		switch {
+		case ok && (!had || existing != value):
+			labels[key] = value
+			changed = true
+		case !ok && had:
+			delete(labels, key)
+			changed = true
+		}
+	}
+
+	if changed {
+		obj.SetLabels(labels)
+	}
+	return changed
+}
@@ -0,0 +1,50 @@
+package util
+
+import (
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("PropagateLabels", func() {
+	It("should read configured keys and skip operator-managed ones", func() {
+		os.Setenv(PropagatedLabelsEnvVar, " team, cost-center,app,kaos.tools/x,kaos.agentic/component,")
+		defer os.Unsetenv(PropagatedLabelsEnvVar)
+
+		Expect(PropagatedLabelKeys()).To(Equal([]string{"team", "cost-center"}))
+	})
+
+	It("should copy, update and remove propagated owner labels", func() {
+		selector := map[string]string{"app": "agent", "agent": "a"}
+		owner := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{
+			"team": "search", "cost-center": "cc-1", "unlisted": "x",
+		}}}
+		obj := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Labels: selector}}
+		keys := []string{"team", "cost-center"}
+
+		Expect(PropagateLabels(obj, owner, keys)).To(BeTrue())
+		Expect(obj.Labels).To(Equal(map[string]string{
+			"app": "agent", "agent": "a", "team": "search", "cost-center": "cc-1",
+		}))
+		// The shared selector map is left untouched
+		Expect(selector).To(HaveLen(2))
+		Expect(PropagateLabels(obj, owner, keys)).To(BeFalse())
+
+		owner.Labels = map[string]string{"team": "ads"}
+		Expect(PropagateLabels(obj, owner, keys)).To(BeTrue())
+		Expect(obj.Labels).To(Equal(map[string]string{"app": "agent", "agent": "a", "team": "ads"}))
+	})
+})
+
+var _ = Describe("PodLabels", func() {
+	It("should add the component labels without changing the selector", func() {
+		selector := map[string]string{"app": "modelapi", "modelapi": "api"}
+		Expect(PodLabels(selector, "modelapi", "api")).To(Equal(map[string]string{
+			"app": "modelapi", "modelapi": "api", ComponentLabel: "modelapi", CRNameLabel: "api",
+		}))
+		Expect(selector).To(HaveLen(2))
+	})
+})
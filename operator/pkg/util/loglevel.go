@@ -0,0 +1,26 @@
+package util
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// LogLevelEnvVar is the well-known env var through which spec.logLevel reaches the pods
+const LogLevelEnvVar = "LOG_LEVEL"
+
+// WithLogLevel sets LOG_LEVEL to the upper-cased level, replacing any value already in env
+// so the spec field takes precedence over user-provided env vars. An empty level leaves env as-is.
+func WithLogLevel(env []corev1.EnvVar, level string) []corev1.EnvVar {
+	if level == "" {
+		return env
+	}
+	level = strings.ToUpper(level)
+	for i := range env {
+		if env[i].Name == LogLevelEnvVar {
+			env[i] = corev1.EnvVar{Name: LogLevelEnvVar, Value: level}
+			return env
+		}
+	}
+	return append(env, corev1.EnvVar{Name: LogLevelEnvVar, Value: level})
+}
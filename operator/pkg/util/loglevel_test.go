@@ -0,0 +1,25 @@
+package util
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("WithLogLevel", func() {
+	It("should leave env untouched when no level is set", func() {
+		env := []corev1.EnvVar{{Name: "FOO", Value: "bar"}}
+		Expect(WithLogLevel(env, "")).To(Equal(env))
+	})
+
+	It("should append the upper-cased level", func() {
+		env := WithLogLevel([]corev1.EnvVar{{Name: "FOO", Value: "bar"}}, "debug")
+		Expect(envMap(env)).To(HaveKeyWithValue("LOG_LEVEL", "DEBUG"))
+		Expect(env).To(HaveLen(2))
+	})
+
+	It("should override a user-provided LOG_LEVEL", func() {
+		env := WithLogLevel([]corev1.EnvVar{{Name: "LOG_LEVEL", Value: "INFO"}}, "WARNING")
+		Expect(env).To(Equal([]corev1.EnvVar{{Name: "LOG_LEVEL", Value: "WARNING"}}))
+	})
+})
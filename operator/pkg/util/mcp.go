@@ -0,0 +1,195 @@
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+// mcpProtocolVersion is the MCP protocol revision announced during initialization
+const mcpProtocolVersion = "2025-03-26"
+
+// mcpSessionHeader carries the session id assigned by Streamable HTTP servers
+const mcpSessionHeader = "Mcp-Session-Id"
+
+// ToolDiscoverer lists the tools advertised by a running MCP server.
+type ToolDiscoverer interface {
+	// ListTools returns the tools exposed by the MCP server at the given endpoint.
+	ListTools(ctx context.Context, endpoint string) ([]kaosv1alpha1.DiscoveredTool, error)
+}
+
+// MCPClient discovers tools using the MCP Streamable HTTP transport (JSON-RPC over POST /mcp).
+type MCPClient struct {
+	Client *http.Client
+}
+
+// NewMCPClient creates an MCPClient with a default HTTP client
+func NewMCPClient() *MCPClient {
+	return &MCPClient{
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// jsonRPCRequest is a JSON-RPC 2.0 request or notification (notifications have no ID)
+type jsonRPCRequest struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      int         `json:"id,omitempty"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// jsonRPCResponse is a JSON-RPC 2.0 response
+type jsonRPCResponse struct {
+	ID     int             `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// ListTools performs the MCP initialize handshake and returns the result of tools/list.
+// The endpoint is the server base URL; "/mcp" is appended if not already present.
+func (c *MCPClient) ListTools(ctx context.Context, endpoint string) ([]kaosv1alpha1.DiscoveredTool, error) {
+	mcpURL := strings.TrimSuffix(endpoint, "/")
+	if !strings.HasSuffix(mcpURL, "/mcp") {
+		mcpURL += "/mcp"
+	}
+
+	_, sessionID, err := c.call(ctx, mcpURL, "", jsonRPCRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "initialize",
+		Params: map[string]interface{}{
+			"protocolVersion": mcpProtocolVersion,
+			"capabilities":    map[string]interface{}{},
+			"clientInfo":      map[string]string{"name": "kaos-operator", "version": "v1alpha1"},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("initialize failed: %w", err)
+	}
+
+	if _, _, err := c.call(ctx, mcpURL, sessionID, jsonRPCRequest{
+		JSONRPC: "2.0",
+		Method:  "notifications/initialized",
+	}); err != nil {
+		return nil, fmt.Errorf("initialized notification failed: %w", err)
+	}
+
+	result, _, err := c.call(ctx, mcpURL, sessionID, jsonRPCRequest{
+		JSONRPC: "2.0",
+		ID:      2,
+		Method:  "tools/list",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("tools/list failed: %w", err)
+	}
+
+	var list struct {
+		Tools []struct {
+			Name        string          `json:"name"`
+			Description string          `json:"description"`
+			InputSchema json.RawMessage `json:"inputSchema"`
+		} `json:"tools"`
+	}
+	if err := json.Unmarshal(result, &list); err != nil {
+		return nil, fmt.Errorf("invalid tools/list result: %w", err)
+	}
+
+	tools := make([]kaosv1alpha1.DiscoveredTool, 0, len(list.Tools))
+	for _, t := range list.Tools {
+		tool := kaosv1alpha1.DiscoveredTool{Name: t.Name, Description: t.Description}
+		if len(t.InputSchema) > 0 {
+			tool.InputSchema = &runtime.RawExtension{Raw: t.InputSchema}
+		}
+		tools = append(tools, tool)
+	}
+	return tools, nil
+}
+
+// call posts a JSON-RPC message and returns the result and the session id of the response.
+// Notifications return an empty result. Both JSON and SSE response bodies are supported.
+func (c *MCPClient) call(ctx context.Context, mcpURL, sessionID string, msg jsonRPCRequest) (json.RawMessage, string, error) {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return nil, "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, mcpURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	if sessionID != "" {
+		req.Header.Set(mcpSessionHeader, sessionID)
+	}
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, "", fmt.Errorf("server returned %s", resp.Status)
+	}
+	if sessionID == "" {
+		sessionID = resp.Header.Get(mcpSessionHeader)
+	}
+	if msg.ID == 0 {
+		return nil, sessionID, nil
+	}
+
+	var payload []byte
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		payload, err = readSSEResponse(resp.Body, msg.ID)
+	} else {
+		payload, err = io.ReadAll(resp.Body)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	var rpcResp jsonRPCResponse
+	if err := json.Unmarshal(payload, &rpcResp); err != nil {
+		return nil, "", fmt.Errorf("invalid JSON-RPC response: %w", err)
+	}
+	if rpcResp.Error != nil {
+		return nil, "", fmt.Errorf("JSON-RPC error %d: %s", rpcResp.Error.Code, rpcResp.Error.Message)
+	}
+	return rpcResp.Result, sessionID, nil
+}
+
+// readSSEResponse returns the data of the first SSE event carrying the response for the request id
+func readSSEResponse(r io.Reader, id int) ([]byte, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		var probe struct {
+			ID int `json:"id"`
+		}
+		if json.Unmarshal([]byte(data), &probe) == nil && probe.ID == id {
+			return []byte(strings.TrimSpace(data)), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("no response for request %d in event stream", id)
+}
@@ -0,0 +1,90 @@
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// mockMCPServer serves a minimal MCP Streamable HTTP endpoint advertising two tools.
+// When sse is set, responses are sent as a text/event-stream like FastMCP does.
+func mockMCPServer(sse bool) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/mcp", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     int    `json:"id"`
+			Method string `json:"method"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var result interface{}
+		switch req.Method {
+		case "initialize":
+			w.Header().Set(mcpSessionHeader, "session-1")
+			result = map[string]interface{}{"protocolVersion": mcpProtocolVersion}
+		case "notifications/initialized":
+			w.WriteHeader(http.StatusAccepted)
+			return
+		case "tools/list":
+			if r.Header.Get(mcpSessionHeader) != "session-1" {
+				http.Error(w, "missing session", http.StatusBadRequest)
+				return
+			}
+			result = map[string]interface{}{"tools": []map[string]interface{}{
+				{"name": "echo", "description": "Echo the message back", "inputSchema": map[string]interface{}{"type": "object"}},
+				{"name": "add", "description": "Add two numbers"},
+			}}
+		}
+
+		body, _ := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+		if sse {
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", body)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	})
+	return httptest.NewServer(mux)
+}
+
+var _ = Describe("MCPClient", func() {
+	It("should list the tools advertised by a JSON MCP endpoint", func() {
+		server := mockMCPServer(false)
+		defer server.Close()
+
+		tools, err := NewMCPClient().ListTools(context.Background(), server.URL)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tools).To(HaveLen(2))
+		Expect(tools[0].Name).To(Equal("echo"))
+		Expect(tools[0].Description).To(Equal("Echo the message back"))
+		Expect(string(tools[0].InputSchema.Raw)).To(MatchJSON(`{"type":"object"}`))
+		Expect(tools[1].Name).To(Equal("add"))
+		Expect(tools[1].InputSchema).To(BeNil())
+	})
+
+	It("should list the tools advertised by an SSE MCP endpoint", func() {
+		server := mockMCPServer(true)
+		defer server.Close()
+
+		tools, err := NewMCPClient().ListTools(context.Background(), server.URL+"/mcp")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tools).To(HaveLen(2))
+	})
+
+	It("should return an error when the endpoint fails", func() {
+		server := httptest.NewServer(http.NotFoundHandler())
+		defer server.Close()
+
+		_, err := NewMCPClient().ListTools(context.Background(), server.URL)
+		Expect(err).To(HaveOccurred())
+	})
+})
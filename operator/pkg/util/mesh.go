@@ -0,0 +1,36 @@
+package util
+
+import (
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+// Service meshes supported by MeshInjectionAnnotations
+const (
+	MeshTypeIstio   = "istio"
+	MeshTypeLinkerd = "linkerd"
+)
+
+// MeshInjectionAnnotations returns the pod annotations that toggle sidecar injection for the
+// given mesh type (default: istio). Returns nil when injection is unset or the mesh is unknown.
+func MeshInjectionAnnotations(injection kaosv1alpha1.MeshInjection, meshType string) map[string]string {
+	if injection == "" {
+		return nil
+	}
+	enabled := injection == kaosv1alpha1.MeshInjectionEnabled
+
+	switch meshType {
+	case "", MeshTypeIstio:
+		value := "false"
+		if enabled {
+			value = "true"
+		}
+		return map[string]string{"sidecar.istio.io/inject": value}
+	case MeshTypeLinkerd:
+		value := "disabled"
+		if enabled {
+			value = "enabled"
+		}
+		return map[string]string{"linkerd.io/inject": value}
+	}
+	return nil
+}
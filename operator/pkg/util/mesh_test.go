@@ -0,0 +1,57 @@
+package util
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+var _ = Describe("MeshInjectionAnnotations", func() {
+	It("should return nil when injection is not configured", func() {
+		Expect(MeshInjectionAnnotations("", MeshTypeIstio)).To(BeNil())
+	})
+
+	It("should set the Istio inject annotation, defaulting to Istio", func() {
+		Expect(MeshInjectionAnnotations(kaosv1alpha1.MeshInjectionEnabled, "")).To(Equal(map[string]string{"sidecar.istio.io/inject": "true"}))
+		Expect(MeshInjectionAnnotations(kaosv1alpha1.MeshInjectionDisabled, MeshTypeIstio)).To(Equal(map[string]string{"sidecar.istio.io/inject": "false"}))
+	})
+
+	It("should set the Linkerd inject annotation", func() {
+		Expect(MeshInjectionAnnotations(kaosv1alpha1.MeshInjectionEnabled, MeshTypeLinkerd)).To(Equal(map[string]string{"linkerd.io/inject": "enabled"}))
+		Expect(MeshInjectionAnnotations(kaosv1alpha1.MeshInjectionDisabled, MeshTypeLinkerd)).To(Equal(map[string]string{"linkerd.io/inject": "disabled"}))
+	})
+
+	It("should return nil for an unknown mesh", func() {
+		Expect(MeshInjectionAnnotations(kaosv1alpha1.MeshInjectionEnabled, "consul")).To(BeNil())
+	})
+})
+
+var _ = Describe("SetPodTemplateHash", func() {
+	It("should fold mesh annotations into the pod template hash", func() {
+		plain := corev1.PodTemplateSpec{}
+		SetPodTemplateHash(&plain)
+		Expect(plain.Annotations[PodSpecHashAnnotation]).To(Equal(ComputePodSpecHash(plain.Spec)))
+
+		injected := corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: MeshInjectionAnnotations(kaosv1alpha1.MeshInjectionEnabled, MeshTypeLinkerd),
+			},
+		}
+		SetPodTemplateHash(&injected)
+		Expect(injected.Annotations).To(HaveKeyWithValue("linkerd.io/inject", "enabled"))
+		Expect(injected.Annotations[PodSpecHashAnnotation]).NotTo(Equal(plain.Annotations[PodSpecHashAnnotation]))
+	})
+
+	It("should fold the component labels, but not the selector labels, into the hash", func() {
+		plain := corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "agent"}}}
+		SetPodTemplateHash(&plain)
+		Expect(plain.Annotations[PodSpecHashAnnotation]).To(Equal(ComputePodSpecHash(plain.Spec)))
+
+		labelled := corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: PodLabels(plain.Labels, "agent", "a")}}
+		SetPodTemplateHash(&labelled)
+		Expect(labelled.Annotations[PodSpecHashAnnotation]).NotTo(Equal(plain.Annotations[PodSpecHashAnnotation]))
+	})
+})
@@ -0,0 +1,93 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// PodTemplatePatchEnvVar holds the global patch applied to every generated pod template
+	PodTemplatePatchEnvVar = "POD_TEMPLATE_PATCH"
+
+	// PodTemplatePatchTypeEnvVar selects how PodTemplatePatchEnvVar is applied
+	PodTemplatePatchTypeEnvVar = "POD_TEMPLATE_PATCH_TYPE"
+
+	// PodTemplatePatchStrategic is a strategic merge patch of the pod template (the default)
+	PodTemplatePatchStrategic = "strategic"
+
+	// PodTemplatePatchJSON is an RFC 6902 JSON patch of the pod template
+	PodTemplatePatchJSON = "json"
+)
+
+// PodTemplatePatch is a platform-wide patch of the pod templates the operator generates,
+// e.g. to add a logging sidecar. It is applied after the resource's own podSpec, so it
+// takes precedence over user fields. A nil *PodTemplatePatch applies nothing.
+type PodTemplatePatch struct {
+	strategic []byte
+	json      jsonpatch.Patch
+}
+
+// PodTemplatePatchFromEnv parses POD_TEMPLATE_PATCH, written as JSON or YAML, according
+// to POD_TEMPLATE_PATCH_TYPE. It returns nil when no patch is set.
+func PodTemplatePatchFromEnv() (*PodTemplatePatch, error) {
+	return NewPodTemplatePatch(os.Getenv(PodTemplatePatchTypeEnvVar), os.Getenv(PodTemplatePatchEnvVar))
+}
+
+// NewPodTemplatePatch parses a patch of the given type, or returns nil for an empty patch
+func NewPodTemplatePatch(patchType, patch string) (*PodTemplatePatch, error) {
+	if patch == "" {
+		return nil, nil
+	}
+	data, err := yaml.YAMLToJSON([]byte(patch))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", PodTemplatePatchEnvVar, err)
+	}
+	switch patchType {
+	case "", PodTemplatePatchStrategic:
+		var template corev1.PodTemplateSpec
+		if err := json.Unmarshal(data, &template); err != nil {
+			return nil, fmt.Errorf("invalid %s: not a pod template: %w", PodTemplatePatchEnvVar, err)
+		}
+		return &PodTemplatePatch{strategic: data}, nil
+	case PodTemplatePatchJSON:
+		ops, err := jsonpatch.DecodePatch(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", PodTemplatePatchEnvVar, err)
+		}
+		return &PodTemplatePatch{json: ops}, nil
+	default:
+		return nil, fmt.Errorf("invalid %s %q: must be %s or %s", PodTemplatePatchTypeEnvVar, patchType, PodTemplatePatchStrategic, PodTemplatePatchJSON)
+	}
+}
+
+// Apply patches the template in place. On error the template is left unchanged.
+func (p *PodTemplatePatch) Apply(template *corev1.PodTemplateSpec) error {
+	if p == nil {
+		return nil
+	}
+	original, err := json.Marshal(template)
+	if err != nil {
+		return err
+	}
+	var patched []byte
+	if p.json != nil {
+		patched, err = p.json.Apply(original)
+	} else {
+		patched, err = strategicpatch.StrategicMergePatch(original, p.strategic, corev1.PodTemplateSpec{})
+	}
+	if err != nil {
+		return err
+	}
+	var result corev1.PodTemplateSpec
+	if err := json.Unmarshal(patched, &result); err != nil {
+		return err
+	}
+	*template = result
+	return nil
+}
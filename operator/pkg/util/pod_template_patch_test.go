@@ -0,0 +1,71 @@
+package util
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("PodTemplatePatch", func() {
+	newTemplate := func() *corev1.PodTemplateSpec {
+		return &corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "agent"}},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "agent", Image: "agent:v1"}}},
+		}
+	}
+
+	It("should apply nothing when unset", func() {
+		GinkgoT().Setenv(PodTemplatePatchEnvVar, "")
+		patch, err := PodTemplatePatchFromEnv()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(patch).To(BeNil())
+
+		template := newTemplate()
+		Expect(patch.Apply(template)).To(Succeed())
+		Expect(template).To(Equal(newTemplate()))
+	})
+
+	It("should merge a strategic patch by container name", func() {
+		GinkgoT().Setenv(PodTemplatePatchEnvVar, `{"metadata":{"annotations":{"team":"platform"}},"spec":{"containers":[{"name":"agent","env":[{"name":"LOG_FORMAT","value":"json"}]}]}}`)
+		patch, err := PodTemplatePatchFromEnv()
+		Expect(err).NotTo(HaveOccurred())
+
+		template := newTemplate()
+		Expect(patch.Apply(template)).To(Succeed())
+		Expect(template.Annotations).To(HaveKeyWithValue("team", "platform"))
+		Expect(template.Labels).To(HaveKeyWithValue("app", "agent"))
+		Expect(template.Spec.Containers).To(HaveLen(1))
+		Expect(template.Spec.Containers[0].Image).To(Equal("agent:v1"))
+		Expect(template.Spec.Containers[0].Env).To(Equal([]corev1.EnvVar{{Name: "LOG_FORMAT", Value: "json"}}))
+	})
+
+	It("should apply a JSON patch", func() {
+		patch, err := NewPodTemplatePatch(PodTemplatePatchJSON, `
+- op: add
+  path: /spec/priorityClassName
+  value: platform-critical
+`)
+		Expect(err).NotTo(HaveOccurred())
+
+		template := newTemplate()
+		Expect(patch.Apply(template)).To(Succeed())
+		Expect(template.Spec.PriorityClassName).To(Equal("platform-critical"))
+
+		// A patch that does not apply leaves the template unchanged
+		patch, err = NewPodTemplatePatch(PodTemplatePatchJSON, `[{"op":"remove","path":"/spec/nodeSelector"}]`)
+		Expect(err).NotTo(HaveOccurred())
+		template = newTemplate()
+		Expect(patch.Apply(template)).NotTo(Succeed())
+		Expect(template).To(Equal(newTemplate()))
+	})
+
+	It("should reject malformed patches and unknown types", func() {
+		_, err := NewPodTemplatePatch("merge", `{"spec":{}}`)
+		Expect(err).To(MatchError(ContainSubstring(PodTemplatePatchTypeEnvVar)))
+		_, err = NewPodTemplatePatch(PodTemplatePatchStrategic, `{"spec":{"containers":"agent"}}`)
+		Expect(err).To(MatchError(ContainSubstring("not a pod template")))
+		_, err = NewPodTemplatePatch(PodTemplatePatchJSON, `{"op":"add"}`)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -5,14 +5,21 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
 )
 
 // PodSpecHashAnnotation is the annotation key used to store the pod spec hash
 const PodSpecHashAnnotation = "kaos.tools/pod-spec-hash"
 
+// RedeployAnnotation, set on an Agent, ModelAPI or MCPServer to a new value such as the
+// current time, rolls the resource's pods without a spec change, e.g. to re-pull a mutable
+// image tag such as :latest with imagePullPolicy Always
+const RedeployAnnotation = "kaos.agentic/redeploy"
+
 // MergePodSpec merges a patch PodSpec into a base PodSpec using strategic merge patch.
 // This allows users to override specific fields (like resources, replicas via podSpec)
 // while preserving the base configuration.
@@ -52,3 +59,91 @@ func ComputePodSpecHash(spec corev1.PodSpec) string {
 	// Use first 16 chars for brevity
 	return hex.EncodeToString(hash[:])[:16]
 }
+
+// SetPodTemplateHash stores the hash of the pod template in its PodSpecHashAnnotation.
+// Other template annotations (e.g. mesh injection) and the component labels are folded into
+// the hash so that changing them also triggers a rolling update; without them it equals
+// ComputePodSpecHash.
+func SetPodTemplateHash(template *corev1.PodTemplateSpec) {
+	annotations := map[string]string{}
+	for k, v := range template.Annotations {
+		if k != PodSpecHashAnnotation {
+			annotations[k] = v
+		}
+	}
+	componentLabels := map[string]string{}
+	for _, k := range []string{ComponentLabel, CRNameLabel} {
+		if v, ok := template.Labels[k]; ok {
+			componentLabels[k] = v
+		}
+	}
+
+	hash := ComputePodSpecHash(template.Spec)
+	if len(annotations) > 0 || len(componentLabels) > 0 {
+		data, _ := json.Marshal([]interface{}{hash, annotations, componentLabels})
+		sum := sha256.Sum256(data)
+		hash = hex.EncodeToString(sum[:])[:16]
+	}
+
+	if template.Annotations == nil {
+		template.Annotations = map[string]string{}
+	}
+	template.Annotations[PodSpecHashAnnotation] = hash
+}
+
+// SetRedeployAnnotation copies the resource's RedeployAnnotation onto the pod template. It
+// must run before SetPodTemplateHash, so a new value changes the hash and rolls the pods.
+func SetRedeployAnnotation(template *corev1.PodTemplateSpec, obj metav1.Object) {
+	value, ok := obj.GetAnnotations()[RedeployAnnotation]
+	if !ok {
+		return
+	}
+	if template.Annotations == nil {
+		template.Annotations = map[string]string{}
+	}
+	template.Annotations[RedeployAnnotation] = value
+}
+
+// EnableServiceLinks returns the pod's enableServiceLinks setting, defaulting to false
+// rather than the Kubernetes default of true
+func EnableServiceLinks(enabled *bool) *bool {
+	if enabled != nil {
+		return enabled
+	}
+	disabled := false
+	return &disabled
+}
+
+// AutomountServiceAccountToken returns the pod's automountServiceAccountToken setting,
+// defaulting to false rather than the Kubernetes default of true, unless the pods call the
+// Kubernetes API
+func AutomountServiceAccountToken(automount *bool, callsAPI bool) *bool {
+	if automount != nil {
+		return automount
+	}
+	return &callsAPI
+}
+
+// ArchitectureNodeSelector returns the node selector pinning pods to nodes of the given CPU
+// architecture, or nil for any architecture
+func ArchitectureNodeSelector(architecture string) map[string]string {
+	if architecture == "" {
+		return nil
+	}
+	return map[string]string{corev1.LabelArchStable: architecture}
+}
+
+// RequestsGPU reports whether any container requests or is limited to a GPU resource,
+// i.e. an extended resource named "<vendor>/gpu" such as nvidia.com/gpu
+func RequestsGPU(spec corev1.PodSpec) bool {
+	for _, container := range append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...) {
+		for _, list := range []corev1.ResourceList{container.Resources.Requests, container.Resources.Limits} {
+			for name, quantity := range list {
+				if strings.HasSuffix(string(name), "/gpu") && !quantity.IsZero() {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,33 @@
+package util
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var _ = Describe("RequestsGPU", func() {
+	It("should detect a GPU limit on any container", func() {
+		spec := corev1.PodSpec{Containers: []corev1.Container{
+			{Name: "model-api"},
+			{Name: "gpu", Resources: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{"nvidia.com/gpu": resource.MustParse("1")},
+			}},
+		}}
+		Expect(RequestsGPU(spec)).To(BeTrue())
+	})
+
+	It("should ignore CPU, memory and zero GPU quantities", func() {
+		spec := corev1.PodSpec{Containers: []corev1.Container{
+			{Name: "model-api", Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("2"),
+					corev1.ResourceMemory: resource.MustParse("4Gi"),
+				},
+				Limits: corev1.ResourceList{"amd.com/gpu": resource.MustParse("0")},
+			}},
+		}}
+		Expect(RequestsGPU(spec)).To(BeFalse())
+	})
+})
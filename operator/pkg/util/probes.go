@@ -0,0 +1,53 @@
+package util
+
+import (
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/version"
+)
+
+// ProbeTimings are the timings of a probe, in seconds
+type ProbeTimings struct {
+	InitialDelaySeconds int32
+	PeriodSeconds       int32
+	TimeoutSeconds      int32
+}
+
+var (
+	// ModelAPILivenessTimings are the liveness probe timings of the model server that
+	// spec.healthCheck does not override
+	ModelAPILivenessTimings = ProbeTimings{InitialDelaySeconds: 30, PeriodSeconds: 10, TimeoutSeconds: 5}
+
+	// ModelAPIReadinessTimings are the readiness probe timings of the model server that
+	// spec.healthCheck does not override. The timeout is shorter than the period so that a
+	// probe never outlives it.
+	ModelAPIReadinessTimings = ProbeTimings{InitialDelaySeconds: 15, PeriodSeconds: 5, TimeoutSeconds: 3}
+)
+
+// SupportsGRPCProbes reports whether a cluster of the given version has native gRPC probes,
+// which are enabled by default from Kubernetes 1.24. Unparseable versions, e.g. of
+// development builds, are assumed to support them.
+func SupportsGRPCProbes(info *version.Info) bool {
+	return atLeastMinor(info, 24)
+}
+
+// SupportsSleepAction reports whether a cluster of the given version accepts sleep lifecycle
+// hooks, which the PodLifecycleSleepAction feature enables by default from Kubernetes 1.30.
+// Unparseable versions are assumed to accept them.
+func SupportsSleepAction(info *version.Info) bool {
+	return atLeastMinor(info, 30)
+}
+
+// atLeastMinor reports whether the version is Kubernetes 1.<minor> or later
+func atLeastMinor(info *version.Info, minor int) bool {
+	major, err := strconv.Atoi(strings.TrimSuffix(info.Major, "+"))
+	if err != nil {
+		return true
+	}
+	serverMinor, err := strconv.Atoi(strings.TrimSuffix(info.Minor, "+"))
+	if err != nil {
+		return true
+	}
+	return major > 1 || (major == 1 && serverMinor >= minor)
+}
@@ -0,0 +1,24 @@
+package util
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/version"
+)
+
+var _ = Describe("SupportsGRPCProbes", func() {
+	It("should require Kubernetes 1.24 or later", func() {
+		Expect(SupportsGRPCProbes(&version.Info{Major: "1", Minor: "23"})).To(BeFalse())
+		Expect(SupportsGRPCProbes(&version.Info{Major: "1", Minor: "24"})).To(BeTrue())
+		Expect(SupportsGRPCProbes(&version.Info{Major: "1", Minor: "29+"})).To(BeTrue())
+		Expect(SupportsGRPCProbes(&version.Info{Major: "", Minor: ""})).To(BeTrue())
+	})
+})
+
+var _ = Describe("SupportsSleepAction", func() {
+	It("should require Kubernetes 1.30 or later", func() {
+		Expect(SupportsSleepAction(&version.Info{Major: "1", Minor: "29"})).To(BeFalse())
+		Expect(SupportsSleepAction(&version.Info{Major: "1", Minor: "30+"})).To(BeTrue())
+		Expect(SupportsSleepAction(&version.Info{Major: "", Minor: ""})).To(BeTrue())
+	})
+})
@@ -0,0 +1,80 @@
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ReconcileCache remembers the last successfully reconciled state of each resource, keyed
+// by UID and generation, so that reconciles which observe no changes can return before
+// rendering and diffing child objects. A generation change always invalidates the entry,
+// and so does a label or annotation change, since those do not bump the generation.
+type ReconcileCache struct {
+	mu      sync.Mutex
+	entries map[types.UID]reconcileCacheEntry
+}
+
+// reconcileCacheEntry is the state recorded after a successful reconcile
+type reconcileCacheEntry struct {
+	generation  int64
+	fingerprint string
+}
+
+// NewReconcileCache creates an empty ReconcileCache
+func NewReconcileCache() *ReconcileCache {
+	return &ReconcileCache{entries: map[types.UID]reconcileCacheEntry{}}
+}
+
+// Unchanged reports whether the object was last reconciled at its current generation with
+// the same fingerprint. A nil cache never reports a hit.
+func (c *ReconcileCache) Unchanged(obj metav1.Object, fingerprint string) bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[obj.GetUID()]
+	return ok && entry.generation == obj.GetGeneration() && entry.fingerprint == withMetadata(obj, fingerprint)
+}
+
+// Record stores the fingerprint observed after a successful reconcile of the object
+func (c *ReconcileCache) Record(obj metav1.Object, fingerprint string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[obj.GetUID()] = reconcileCacheEntry{generation: obj.GetGeneration(), fingerprint: withMetadata(obj, fingerprint)}
+}
+
+// withMetadata extends the fingerprint with a hash of the object's labels and annotations
+func withMetadata(obj metav1.Object, fingerprint string) string {
+	data, _ := json.Marshal([]map[string]string{obj.GetLabels(), obj.GetAnnotations()})
+	sum := sha256.Sum256(data)
+	return fingerprint + "#" + hex.EncodeToString(sum[:8])
+}
+
+// Forget drops the entry for the object, e.g. once it is deleted
+func (c *ReconcileCache) Forget(obj metav1.Object) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, obj.GetUID())
+}
+
+// Fingerprint identifies the observed state of the given objects by UID and resource version
+func Fingerprint(objs ...metav1.Object) string {
+	parts := make([]string, 0, len(objs))
+	for _, obj := range objs {
+		parts = append(parts, string(obj.GetUID())+"@"+obj.GetResourceVersion())
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,36 @@
+package util
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("ReconcileCache", func() {
+	It("should invalidate entries on generation and annotation changes", func() {
+		cache := NewReconcileCache()
+		obj := &metav1.ObjectMeta{UID: "uid-1", Generation: 1}
+		cache.Record(obj, "fp")
+		Expect(cache.Unchanged(obj, "fp")).To(BeTrue())
+		Expect(cache.Unchanged(obj, "other")).To(BeFalse())
+
+		obj.Annotations = map[string]string{"example.com/redeploy": "1"}
+		Expect(cache.Unchanged(obj, "fp")).To(BeFalse())
+		cache.Record(obj, "fp")
+		Expect(cache.Unchanged(obj, "fp")).To(BeTrue())
+
+		obj.Generation = 2
+		Expect(cache.Unchanged(obj, "fp")).To(BeFalse())
+
+		cache.Forget(obj)
+		obj.Generation = 1
+		Expect(cache.Unchanged(obj, "fp")).To(BeFalse())
+	})
+
+	It("should never report a hit when nil", func() {
+		var cache *ReconcileCache
+		obj := &metav1.ObjectMeta{UID: "uid-1"}
+		cache.Record(obj, "fp")
+		Expect(cache.Unchanged(obj, "fp")).To(BeFalse())
+	})
+})
@@ -0,0 +1,124 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// MetricsLabelsEnvVar is the operator setting listing, comma-separated, the resource label
+// keys (e.g. team) added as labels to the reconcile metrics. Every key adds a dimension,
+// so only an allowlist of low-cardinality keys is accepted.
+const MetricsLabelsEnvVar = "METRICS_LABELS"
+
+// invalidMetricLabelChars matches the characters a label key may hold but a Prometheus
+// label name may not
+var invalidMetricLabelChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// ReconcileMetrics counts reconciles in kaos_reconcile_total by kind, result and the
+// configured resource labels, and records in kaos_last_reconcile_timestamp_seconds when each
+// resource was last reconciled successfully. A nil ReconcileMetrics records nothing.
+type ReconcileMetrics struct {
+	keys          []string
+	total         *prometheus.CounterVec
+	lastReconcile *prometheus.GaugeVec
+}
+
+var _ prometheus.Collector = &ReconcileMetrics{}
+
+// MetricsLabelKeys returns the label keys configured in METRICS_LABELS
+func MetricsLabelKeys() []string {
+	var keys []string
+	for _, key := range strings.Split(os.Getenv(MetricsLabelsEnvVar), ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// NewReconcileMetrics creates the reconcile metrics with one metric label per resource label
+// key. Keys are turned into valid Prometheus label names, e.g. cost-center into cost_center.
+// It fails on a key whose name Prometheus reserves, with a leading __, or that collides with
+// kind, result or an earlier key, since registering the metric would panic.
+func NewReconcileMetrics(keys []string) (*ReconcileMetrics, error) {
+	names := []string{"kind", "result"}
+	seen := map[string]string{"kind": "the kind label", "result": "the result label"}
+	m := &ReconcileMetrics{}
+	for _, key := range keys {
+		if key == "" {
+			return nil, fmt.Errorf("invalid %s: empty label key", MetricsLabelsEnvVar)
+		}
+		name := invalidMetricLabelChars.ReplaceAllString(key, "_")
+		if name[0] >= '0' && name[0] <= '9' {
+			name = "_" + name
+		}
+		if strings.HasPrefix(name, "__") {
+			return nil, fmt.Errorf("invalid %s: label key %q becomes %s, which Prometheus reserves", MetricsLabelsEnvVar, key, name)
+		}
+		if other, ok := seen[name]; ok {
+			return nil, fmt.Errorf("invalid %s: label key %q becomes %s, which collides with %s", MetricsLabelsEnvVar, key, name, other)
+		}
+		seen[name] = fmt.Sprintf("label key %q", key)
+		names = append(names, name)
+		m.keys = append(m.keys, key)
+	}
+	m.total = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kaos_reconcile_total",
+		Help: "Total number of reconciles per resource kind and result",
+	}, names)
+	m.lastReconcile = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kaos_last_reconcile_timestamp_seconds",
+		Help: "Unix time of the last successful reconcile per resource, including no-op reconciles",
+	}, []string{"kind", "namespace", "name"})
+	return m, nil
+}
+
+// Observe counts one reconcile of a resource of the given kind carrying the given labels.
+// Resources without one of the configured labels are counted with an empty value.
+func (m *ReconcileMetrics) Observe(kind string, labels map[string]string, err error) {
+	if m == nil {
+		return
+	}
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	values := append(make([]string, 0, len(m.keys)+2), kind, result)
+	for _, key := range m.keys {
+		values = append(values, labels[key])
+	}
+	m.total.WithLabelValues(values...).Inc()
+}
+
+// MarkReconciled sets the last reconcile timestamp of the resource to now
+func (m *ReconcileMetrics) MarkReconciled(kind string, key types.NamespacedName) {
+	if m == nil {
+		return
+	}
+	m.lastReconcile.WithLabelValues(kind, key.Namespace, key.Name).SetToCurrentTime()
+}
+
+// Forget drops the last reconcile timestamp of a deleted resource
+func (m *ReconcileMetrics) Forget(kind string, key types.NamespacedName) {
+	if m == nil {
+		return
+	}
+	m.lastReconcile.DeleteLabelValues(kind, key.Namespace, key.Name)
+}
+
+// Describe implements prometheus.Collector
+func (m *ReconcileMetrics) Describe(ch chan<- *prometheus.Desc) {
+	m.total.Describe(ch)
+	m.lastReconcile.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (m *ReconcileMetrics) Collect(ch chan<- prometheus.Metric) {
+	m.total.Collect(ch)
+	m.lastReconcile.Collect(ch)
+}
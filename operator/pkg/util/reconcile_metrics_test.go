@@ -0,0 +1,51 @@
+package util
+
+import (
+	"errors"
+	"os"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("ReconcileMetrics", func() {
+	It("should read the allowlisted keys from METRICS_LABELS", func() {
+		os.Setenv(MetricsLabelsEnvVar, " team, cost-center,,")
+		defer os.Unsetenv(MetricsLabelsEnvVar)
+
+		Expect(MetricsLabelKeys()).To(Equal([]string{"team", "cost-center"}))
+	})
+
+	It("should turn label keys into metric label names", func() {
+		m, err := NewReconcileMetrics([]string{"kaos.tools/team", "2fa"})
+		Expect(err).NotTo(HaveOccurred())
+		m.Observe("Agent", map[string]string{"kaos.tools/team": "search", "2fa": "on"}, errors.New("boom"))
+
+		Expect(testutil.CollectAndCompare(m, strings.NewReader(`
+# HELP kaos_reconcile_total Total number of reconciles per resource kind and result
+# TYPE kaos_reconcile_total counter
+kaos_reconcile_total{_2fa="on",kaos_tools_team="search",kind="Agent",result="error"} 1
+`), "kaos_reconcile_total")).To(Succeed())
+	})
+
+	It("should reject label keys that are reserved or collide once turned into label names", func() {
+		for keys, message := range map[string]string{
+			"__meta":                          `label key "__meta" becomes __meta, which Prometheus reserves`,
+			"--internal":                      `label key "--internal" becomes __internal, which Prometheus reserves`,
+			"result":                          `label key "result" becomes result, which collides with the result label`,
+			"kaos.tools/team,kaos_tools_team": `label key "kaos_tools_team" becomes kaos_tools_team, which collides with label key "kaos.tools/team"`,
+		} {
+			_, err := NewReconcileMetrics(strings.Split(keys, ","))
+			Expect(err).To(MatchError(ContainSubstring(message)), keys)
+		}
+	})
+
+	It("should ignore observations on a nil ReconcileMetrics", func() {
+		var m *ReconcileMetrics
+		Expect(func() { m.Observe("Agent", nil, nil) }).NotTo(Panic())
+		Expect(func() { m.MarkReconciled("Agent", types.NamespacedName{Name: "a"}) }).NotTo(Panic())
+	})
+})
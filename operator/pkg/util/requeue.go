@@ -0,0 +1,28 @@
+package util
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// RequeueJitterFactor is the fraction of the interval by which JitteredRequeue may extend it
+const RequeueJitterFactor = 0.2
+
+// JitteredRequeue returns a RequeueAfter between interval and interval*(1+RequeueJitterFactor),
+// so resources that failed together, e.g. while a shared dependency was down, do not all
+// retry against the API server in the same instant
+func JitteredRequeue(interval time.Duration) time.Duration {
+	return wait.Jitter(interval, RequeueJitterFactor)
+}
+
+// SoonestRequeue returns the shortest of the non-zero requeue intervals, or 0 when none is set
+func SoonestRequeue(intervals ...time.Duration) time.Duration {
+	var soonest time.Duration
+	for _, interval := range intervals {
+		if interval > 0 && (soonest == 0 || interval < soonest) {
+			soonest = interval
+		}
+	}
+	return soonest
+}
@@ -0,0 +1,32 @@
+package util
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("JitteredRequeue", func() {
+	It("should stay within the jitter band", func() {
+		interval := 30 * time.Second
+		upper := time.Duration(float64(interval) * (1 + RequeueJitterFactor))
+		seen := map[time.Duration]bool{}
+		for i := 0; i < 1000; i++ {
+			d := JitteredRequeue(interval)
+			Expect(d).To(BeNumerically(">=", interval))
+			Expect(d).To(BeNumerically("<", upper))
+			seen[d] = true
+		}
+		// The retries are actually spread out
+		Expect(len(seen)).To(BeNumerically(">", 1))
+	})
+})
+
+var _ = Describe("SoonestRequeue", func() {
+	It("should return the shortest interval that is set", func() {
+		Expect(SoonestRequeue(0, time.Minute, 30*time.Second)).To(Equal(30 * time.Second))
+		Expect(SoonestRequeue(time.Minute, 0)).To(Equal(time.Minute))
+		Expect(SoonestRequeue(0, 0)).To(BeZero())
+	})
+})
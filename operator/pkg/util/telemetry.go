@@ -0,0 +1,152 @@
+package util
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+// OpenTelemetry SDK env vars the operator sets from spec.telemetry, and the downward API env
+// vars OTEL_RESOURCE_ATTRIBUTES references
+const (
+	OTelServiceNameEnv        = "OTEL_SERVICE_NAME"
+	OTelResourceAttributesEnv = "OTEL_RESOURCE_ATTRIBUTES"
+	K8sPodNameEnv             = "K8S_POD_NAME"
+	K8sNamespaceNameEnv       = "K8S_NAMESPACE_NAME"
+	K8sNodeNameEnv            = "K8S_NODE_NAME"
+	OTLPEndpointEnv           = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	OTLPTracesEndpointEnv     = "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"
+	OTLPMetricsEndpointEnv    = "OTEL_EXPORTER_OTLP_METRICS_ENDPOINT"
+	OTLPLogsEndpointEnv       = "OTEL_EXPORTER_OTLP_LOGS_ENDPOINT"
+	OTLPHeadersEnv            = "OTEL_EXPORTER_OTLP_HEADERS"
+	OTLPTimeoutEnv            = "OTEL_EXPORTER_OTLP_TIMEOUT"
+	BSPScheduleDelayEnv       = "OTEL_BSP_SCHEDULE_DELAY"
+	BSPExportTimeoutEnv       = "OTEL_BSP_EXPORT_TIMEOUT"
+	BSPMaxQueueSizeEnv        = "OTEL_BSP_MAX_QUEUE_SIZE"
+	BSPMaxExportBatchSizeEnv  = "OTEL_BSP_MAX_EXPORT_BATCH_SIZE"
+	TracesSamplerEnv          = "OTEL_TRACES_SAMPLER"
+	TracesSamplerArgEnv       = "OTEL_TRACES_SAMPLER_ARG"
+)
+
+// Export settings applied when telemetry.failFast is false. Timeouts are in milliseconds.
+// The batch span processor exports asynchronously and drops spans once its queue is full,
+// so together these keep a down collector from blocking or exhausting the application.
+const (
+	degradedOTLPTimeout       = "2000"
+	degradedBSPScheduleDelay  = "5000"
+	degradedBSPExportTimeout  = "2000"
+	degradedBSPMaxQueueSize   = "2048"
+	degradedBSPMaxExportBatch = "512"
+)
+
+// podResourceAttributes maps OpenTelemetry resource attributes to the env var carrying
+// the pod field they are read from through the downward API
+var podResourceAttributes = []struct{ attribute, envName, fieldPath string }{
+	{"k8s.pod.name", K8sPodNameEnv, "metadata.name"},
+	{"k8s.namespace.name", K8sNamespaceNameEnv, "metadata.namespace"},
+	{"k8s.node.name", K8sNodeNameEnv, "spec.nodeName"},
+}
+
+// downwardAPIResourceAttributes returns env vars exposing the pod's name, namespace and node
+// through the downward API, and an OTEL_RESOURCE_ATTRIBUTES that references them with
+// $(NAME), since a valueFrom cannot be embedded in a larger value
+func downwardAPIResourceAttributes() []corev1.EnvVar {
+	env := make([]corev1.EnvVar, 0, len(podResourceAttributes)+1)
+	attributes := make([]string, 0, len(podResourceAttributes))
+	for _, attr := range podResourceAttributes {
+		env = append(env, corev1.EnvVar{
+			Name:      attr.envName,
+			ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: attr.fieldPath}},
+		})
+		attributes = append(attributes, attr.attribute+"=$("+attr.envName+")")
+	}
+	return append(env, corev1.EnvVar{Name: OTelResourceAttributesEnv, Value: strings.Join(attributes, ",")})
+}
+
+// BuildTelemetryEnvVars returns the OTEL_* environment variables for the telemetry config.
+// Returns nil when telemetry is not configured or disabled.
+func BuildTelemetryEnvVars(telemetry *kaosv1alpha1.TelemetryConfig, serviceName string) []corev1.EnvVar {
+	if telemetry == nil || !telemetry.Enabled {
+		return nil
+	}
+
+	env := []corev1.EnvVar{
+		{Name: OTelServiceNameEnv, Value: serviceName},
+	}
+	env = append(env, downwardAPIResourceAttributes()...)
+	if telemetry.Endpoint != "" {
+		env = append(env, corev1.EnvVar{Name: OTLPEndpointEnv, Value: telemetry.Endpoint})
+	}
+	// Signals without an override fall back to OTEL_EXPORTER_OTLP_ENDPOINT in the SDK
+	for _, signal := range []struct{ name, endpoint string }{
+		{OTLPTracesEndpointEnv, telemetry.TracesEndpoint},
+		{OTLPMetricsEndpointEnv, telemetry.MetricsEndpoint},
+		{OTLPLogsEndpointEnv, telemetry.LogsEndpoint},
+	} {
+		if signal.endpoint != "" {
+			env = append(env, corev1.EnvVar{Name: signal.name, Value: signal.endpoint})
+		}
+	}
+
+	if len(telemetry.Headers) > 0 {
+		env = append(env, corev1.EnvVar{Name: OTLPHeadersEnv, Value: OTLPHeaders(telemetry.Headers)})
+	}
+
+	if !telemetry.FailFast {
+		env = append(env,
+			corev1.EnvVar{Name: OTLPTimeoutEnv, Value: degradedOTLPTimeout},
+			corev1.EnvVar{Name: BSPScheduleDelayEnv, Value: degradedBSPScheduleDelay},
+			corev1.EnvVar{Name: BSPExportTimeoutEnv, Value: degradedBSPExportTimeout},
+			corev1.EnvVar{Name: BSPMaxQueueSizeEnv, Value: degradedBSPMaxQueueSize},
+			corev1.EnvVar{Name: BSPMaxExportBatchSizeEnv, Value: degradedBSPMaxExportBatch},
+		)
+	}
+
+	return env
+}
+
+// OTLPHeaders serializes headers as the comma-separated key=value list read from
+// OTEL_EXPORTER_OTLP_HEADERS, sorted by name so the pod template stays stable
+func OTLPHeaders(headers map[string]string) string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		pairs = append(pairs, name+"="+headers[name])
+	}
+	return strings.Join(pairs, ",")
+}
+
+// ParseTraceSampleRatio parses a trace-sample-ratio annotation value, a number between 0 and 1
+func ParseTraceSampleRatio(value string) (float64, error) {
+	ratio, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || ratio < 0 || ratio > 1 {
+		return 0, fmt.Errorf("must be a number between 0 and 1")
+	}
+	return ratio, nil
+}
+
+// TraceSamplerEnvVars returns the OTEL_TRACES_SAMPLER env vars sampling the given ratio of new
+// traces while following the sampling decision of incoming requests. Returns nil when
+// telemetry is disabled or the ratio is unset or invalid, leaving the SDK default.
+func TraceSamplerEnvVars(telemetry *kaosv1alpha1.TelemetryConfig, ratio string) []corev1.EnvVar {
+	if telemetry == nil || !telemetry.Enabled || ratio == "" {
+		return nil
+	}
+	parsed, err := ParseTraceSampleRatio(ratio)
+	if err != nil {
+		return nil
+	}
+	return []corev1.EnvVar{
+		{Name: TracesSamplerEnv, Value: "parentbased_traceidratio"},
+		{Name: TracesSamplerArgEnv, Value: strconv.FormatFloat(parsed, 'f', -1, 64)},
+	}
+}
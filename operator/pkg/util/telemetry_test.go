@@ -0,0 +1,128 @@
+package util
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+// envMap indexes env vars by name
+func envMap(env []corev1.EnvVar) map[string]string {
+	m := make(map[string]string, len(env))
+	for _, e := range env {
+		m[e.Name] = e.Value
+	}
+	return m
+}
+
+var _ = Describe("BuildTelemetryEnvVars", func() {
+	It("should return nothing when telemetry is disabled", func() {
+		Expect(BuildTelemetryEnvVars(nil, "agent")).To(BeEmpty())
+		Expect(BuildTelemetryEnvVars(&kaosv1alpha1.TelemetryConfig{Endpoint: "http://otel:4317"}, "agent")).To(BeEmpty())
+	})
+
+	It("should emit timeout and batch settings when failFast is false", func() {
+		env := envMap(BuildTelemetryEnvVars(&kaosv1alpha1.TelemetryConfig{
+			Enabled:  true,
+			Endpoint: "http://otel:4317",
+		}, "my-agent"))
+		Expect(env).To(HaveKeyWithValue("OTEL_SERVICE_NAME", "my-agent"))
+		Expect(env).To(HaveKeyWithValue("OTEL_EXPORTER_OTLP_ENDPOINT", "http://otel:4317"))
+		Expect(env).To(HaveKeyWithValue("OTEL_EXPORTER_OTLP_TIMEOUT", "2000"))
+		Expect(env).To(HaveKeyWithValue("OTEL_BSP_SCHEDULE_DELAY", "5000"))
+		Expect(env).To(HaveKeyWithValue("OTEL_BSP_EXPORT_TIMEOUT", "2000"))
+		Expect(env).To(HaveKeyWithValue("OTEL_BSP_MAX_QUEUE_SIZE", "2048"))
+		Expect(env).To(HaveKeyWithValue("OTEL_BSP_MAX_EXPORT_BATCH_SIZE", "512"))
+	})
+
+	It("should leave SDK export defaults when failFast is true", func() {
+		env := envMap(BuildTelemetryEnvVars(&kaosv1alpha1.TelemetryConfig{
+			Enabled:  true,
+			FailFast: true,
+		}, "my-agent"))
+		Expect(env).To(HaveKey("OTEL_SERVICE_NAME"))
+		Expect(env).NotTo(HaveKey("OTEL_EXPORTER_OTLP_TIMEOUT"))
+		Expect(env).NotTo(HaveKey("OTEL_BSP_SCHEDULE_DELAY"))
+	})
+
+	It("should emit per-signal endpoints that override the base endpoint", func() {
+		env := envMap(BuildTelemetryEnvVars(&kaosv1alpha1.TelemetryConfig{
+			Enabled:        true,
+			Endpoint:       "http://otel:4317",
+			TracesEndpoint: "http://tempo:4317",
+			LogsEndpoint:   "http://loki:4317",
+		}, "my-agent"))
+		Expect(env).To(HaveKeyWithValue("OTEL_EXPORTER_OTLP_ENDPOINT", "http://otel:4317"))
+		Expect(env).To(HaveKeyWithValue("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "http://tempo:4317"))
+		Expect(env).To(HaveKeyWithValue("OTEL_EXPORTER_OTLP_LOGS_ENDPOINT", "http://loki:4317"))
+		// Metrics fall back to the base endpoint
+		Expect(env).NotTo(HaveKey("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT"))
+	})
+
+	It("should only emit the base endpoint without per-signal overrides", func() {
+		env := envMap(BuildTelemetryEnvVars(&kaosv1alpha1.TelemetryConfig{
+			Enabled:  true,
+			Endpoint: "http://otel:4317",
+		}, "my-agent"))
+		Expect(env).To(HaveKeyWithValue("OTEL_EXPORTER_OTLP_ENDPOINT", "http://otel:4317"))
+		Expect(env).NotTo(HaveKey("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"))
+		Expect(env).NotTo(HaveKey("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT"))
+		Expect(env).NotTo(HaveKey("OTEL_EXPORTER_OTLP_LOGS_ENDPOINT"))
+	})
+
+	It("should serialize headers in name order", func() {
+		env := envMap(BuildTelemetryEnvVars(&kaosv1alpha1.TelemetryConfig{
+			Enabled: true,
+			Headers: map[string]string{"x-tenant": "acme", "Authorization": "Bearer token"},
+		}, "my-agent"))
+		Expect(env).To(HaveKeyWithValue("OTEL_EXPORTER_OTLP_HEADERS", "Authorization=Bearer token,x-tenant=acme"))
+	})
+
+	It("should expose pod metadata as resource attributes through the downward API", func() {
+		env := BuildTelemetryEnvVars(&kaosv1alpha1.TelemetryConfig{Enabled: true}, "my-agent")
+		fieldPaths := map[string]string{}
+		for _, e := range env {
+			if e.ValueFrom != nil && e.ValueFrom.FieldRef != nil {
+				fieldPaths[e.Name] = e.ValueFrom.FieldRef.FieldPath
+			}
+		}
+		Expect(fieldPaths).To(Equal(map[string]string{
+			"K8S_POD_NAME":       "metadata.name",
+			"K8S_NAMESPACE_NAME": "metadata.namespace",
+			"K8S_NODE_NAME":      "spec.nodeName",
+		}))
+		Expect(envMap(env)).To(HaveKeyWithValue("OTEL_RESOURCE_ATTRIBUTES",
+			"k8s.pod.name=$(K8S_POD_NAME),k8s.namespace.name=$(K8S_NAMESPACE_NAME),k8s.node.name=$(K8S_NODE_NAME)"))
+
+		// References only resolve to variables defined earlier, which StableEnv preserves
+		names := []string{}
+		for _, e := range StableEnv(env) {
+			names = append(names, e.Name)
+		}
+		Expect(names).To(ContainElements("K8S_POD_NAME", "OTEL_RESOURCE_ATTRIBUTES"))
+		index := func(name string) int {
+			for i, n := range names {
+				if n == name {
+					return i
+				}
+			}
+			return -1
+		}
+		Expect(index("K8S_POD_NAME")).To(BeNumerically("<", index("OTEL_RESOURCE_ATTRIBUTES")))
+		Expect(index("K8S_NODE_NAME")).To(BeNumerically("<", index("OTEL_RESOURCE_ATTRIBUTES")))
+	})
+
+	It("should sample the trace ratio only when telemetry is enabled and the ratio is valid", func() {
+		telemetry := &kaosv1alpha1.TelemetryConfig{Enabled: true}
+		Expect(envMap(TraceSamplerEnvVars(telemetry, "0.50"))).To(Equal(map[string]string{
+			"OTEL_TRACES_SAMPLER":     "parentbased_traceidratio",
+			"OTEL_TRACES_SAMPLER_ARG": "0.5",
+		}))
+		Expect(TraceSamplerEnvVars(telemetry, "")).To(BeNil())
+		Expect(TraceSamplerEnvVars(telemetry, "1.5")).To(BeNil())
+		Expect(TraceSamplerEnvVars(telemetry, "all")).To(BeNil())
+		Expect(TraceSamplerEnvVars(&kaosv1alpha1.TelemetryConfig{}, "0.5")).To(BeNil())
+	})
+})
@@ -0,0 +1,236 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+	"github.com/axsaucedo/kaos/operator/pkg/util"
+)
+
+//+kubebuilder:webhook:path=/validate-kaos-tools-v1alpha1-agent,mutating=false,failurePolicy=fail,sideEffects=None,groups=kaos.tools,resources=agents,verbs=create;update,versions=v1alpha1,name=vagent.kaos.tools,admissionReviewVersions=v1
+
+// defaultDebugAdminGroups are allowed to set the debug-image annotation when DEBUG_ADMIN_GROUPS is unset
+const defaultDebugAdminGroups = "system:masters"
+
+// AgentValidator validates Agent resources on create and update
+type AgentValidator struct {
+	// DebugAdminGroups lists the user groups allowed to set or change the debug-image annotation
+	DebugAdminGroups []string
+	// NamingConvention, when set, is a regular expression new Agent names must match
+	NamingConvention *regexp.Regexp
+	// AllowedRegistries, when set, are the registry prefixes the Agent's images must come from
+	AllowedRegistries []string
+}
+
+var _ admission.CustomValidator = &AgentValidator{}
+
+// SetupAgentWebhookWithManager registers the Agent defaulting and validating webhooks with the manager
+func SetupAgentWebhookWithManager(mgr ctrl.Manager, namingConvention *regexp.Regexp, allowedRegistries []string, sizes SizePresets) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&kaosv1alpha1.Agent{}).
+		WithDefaulter(&AgentDefaulter{Sizes: sizes}).
+		WithValidator(&AgentValidator{DebugAdminGroups: debugAdminGroups(), NamingConvention: namingConvention, AllowedRegistries: allowedRegistries}).
+		Complete()
+}
+
+// debugAdminGroups reads the comma-separated DEBUG_ADMIN_GROUPS environment variable
+func debugAdminGroups() []string {
+	value := os.Getenv("DEBUG_ADMIN_GROUPS")
+	if value == "" {
+		value = defaultDebugAdminGroups
+	}
+	var groups []string
+	for _, group := range strings.Split(value, ",") {
+		if group = strings.TrimSpace(group); group != "" {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// ValidateCreate validates a new Agent
+func (v *AgentValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	agent, ok := obj.(*kaosv1alpha1.Agent)
+	if !ok {
+		return nil, fmt.Errorf("expected an Agent but got %T", obj)
+	}
+	if err := validateName(v.NamingConvention, kaosv1alpha1.GroupVersion.WithKind("Agent").GroupKind(), agent.Name); err != nil {
+		return nil, err
+	}
+	if err := v.validateDebugAnnotation(ctx, "", agent); err != nil {
+		return nil, err
+	}
+	if err := validateImages(v.AllowedRegistries, kaosv1alpha1.GroupVersion.WithKind("Agent").GroupKind(), agent.Name, agentImages(agent)); err != nil {
+		return nil, err
+	}
+	return telemetryWarnings(field.NewPath("spec", "telemetry"), agent.Spec.Telemetry), validateAgent(agent)
+}
+
+// ValidateUpdate validates an updated Agent
+func (v *AgentValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	agent, ok := newObj.(*kaosv1alpha1.Agent)
+	if !ok {
+		return nil, fmt.Errorf("expected an Agent but got %T", newObj)
+	}
+	oldAgent, ok := oldObj.(*kaosv1alpha1.Agent)
+	if !ok {
+		return nil, fmt.Errorf("expected an Agent but got %T", oldObj)
+	}
+	if err := v.validateDebugAnnotation(ctx, oldAgent.Annotations[kaosv1alpha1.DebugImageAnnotation], agent); err != nil {
+		return nil, err
+	}
+	if err := validateImages(v.AllowedRegistries, kaosv1alpha1.GroupVersion.WithKind("Agent").GroupKind(), agent.Name, agentImages(agent)); err != nil {
+		return nil, err
+	}
+	return telemetryWarnings(field.NewPath("spec", "telemetry"), agent.Spec.Telemetry), validateAgent(agent)
+}
+
+// agentImages lists the images an Agent sets: its podSpec containers and the debug image
+func agentImages(agent *kaosv1alpha1.Agent) []imageRef {
+	images := podSpecImages(field.NewPath("spec", "podSpec"), agent.Spec.PodSpec)
+	return append(images, imageRef{
+		path:  field.NewPath("metadata", "annotations").Key(kaosv1alpha1.DebugImageAnnotation),
+		image: agent.Annotations[kaosv1alpha1.DebugImageAnnotation],
+	})
+}
+
+// ValidateDelete allows all deletions
+func (v *AgentValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateDebugAnnotation rejects setting or changing the debug-image annotation unless the
+// requesting user belongs to one of the debug admin groups. Clearing it is always allowed.
+func (v *AgentValidator) validateDebugAnnotation(ctx context.Context, oldImage string, agent *kaosv1alpha1.Agent) error {
+	newImage := agent.Annotations[kaosv1alpha1.DebugImageAnnotation]
+	if newImage == "" || newImage == oldImage {
+		return nil
+	}
+
+	if req, err := admission.RequestFromContext(ctx); err == nil {
+		for _, group := range req.UserInfo.Groups {
+			if slices.Contains(v.DebugAdminGroups, group) {
+				return nil
+			}
+		}
+	}
+
+	path := field.NewPath("metadata", "annotations").Key(kaosv1alpha1.DebugImageAnnotation)
+	return apierrors.NewForbidden(kaosv1alpha1.GroupVersion.WithResource("agents").GroupResource(), agent.Name,
+		field.Forbidden(path, fmt.Sprintf("only members of %v may set the debug image", v.DebugAdminGroups)))
+}
+
+// validateAgent returns an Invalid error listing every spec violation, or nil if the Agent is valid
+func validateAgent(agent *kaosv1alpha1.Agent) error {
+	var errs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	errs = append(errs, validateModelAPISelector(specPath, agent)...)
+
+	if len(agent.Spec.VolumeClaimTemplates) > 0 && agent.Spec.WorkloadType != kaosv1alpha1.AgentWorkloadTypeStatefulSet {
+		errs = append(errs, field.Forbidden(specPath.Child("volumeClaimTemplates"), "requires workloadType StatefulSet"))
+	}
+	if agent.Spec.PodManagementPolicy != "" && agent.Spec.WorkloadType != kaosv1alpha1.AgentWorkloadTypeStatefulSet {
+		errs = append(errs, field.Forbidden(specPath.Child("podManagementPolicy"), "requires workloadType StatefulSet"))
+	}
+
+	if agent.Spec.Config != nil {
+		envPath := specPath.Child("config", "env")
+		errs = append(errs, validateEnv(envPath, agent.Spec.Config.Env)...)
+		if agent.Annotations[kaosv1alpha1.AllowReservedEnvAnnotation] != "true" {
+			errs = append(errs, validateReservedEnv(envPath, agent.Spec.Config.Env)...)
+		}
+	}
+	errs = append(errs, validateSecretKeyMappings(specPath.Child("secretKeyMappings"), agent)...)
+	errs = append(errs, validateTelemetry(specPath.Child("telemetry"), agent.Spec.Telemetry)...)
+	if ratio, ok := agent.Annotations[kaosv1alpha1.TraceSampleRatioAnnotation]; ok {
+		if _, err := util.ParseTraceSampleRatio(ratio); err != nil {
+			errs = append(errs, field.Invalid(field.NewPath("metadata", "annotations").Key(kaosv1alpha1.TraceSampleRatioAnnotation), ratio, err.Error()))
+		}
+	}
+	errs = append(errs, validateHostAliases(specPath.Child("hostAliases"), agent.Spec.HostAliases)...)
+	errs = append(errs, validatePodSpecResources(specPath.Child("podSpec"), agent.Spec.PodSpec)...)
+	errs = append(errs, validatePodSpecNames(specPath.Child("podSpec"), agentContainers(agent), agent.Spec.PodSpec)...)
+	errs = append(errs, validateCommandTemplates(specPath.Child("command"), agent.Spec.Command, util.ValidateCommandTemplate)...)
+	errs = append(errs, validateCommandTemplates(specPath.Child("args"), agent.Spec.Args, util.ValidateCommandTemplate)...)
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(kaosv1alpha1.GroupVersion.WithKind("Agent").GroupKind(), agent.Name, errs)
+}
+
+// reservedEnvError returns the error for an env var named after one the operator sets, or nil
+func reservedEnvError(path *field.Path, name string) *field.Error {
+	if !util.IsReservedAgentEnv(name) {
+		return nil
+	}
+	return field.Invalid(path, name,
+		fmt.Sprintf("is set by the operator; set the %s annotation to \"true\" to override it", kaosv1alpha1.AllowReservedEnvAnnotation))
+}
+
+// validateReservedEnv rejects env entries named after env vars the operator sets
+func validateReservedEnv(path *field.Path, env []corev1.EnvVar) field.ErrorList {
+	var errs field.ErrorList
+	for i, e := range env {
+		if err := reservedEnvError(path.Index(i).Child("name"), e.Name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// validateModelAPISelector checks that spec.modelAPISelector is a valid label selector. That
+// exactly one of modelAPI and modelAPISelector is set is part of the CRD schema.
+func validateModelAPISelector(specPath *field.Path, agent *kaosv1alpha1.Agent) field.ErrorList {
+	selector := agent.Spec.ModelAPISelector
+	if selector == nil {
+		return nil
+	}
+	if _, err := metav1.LabelSelectorAsSelector(selector); err != nil {
+		return field.ErrorList{field.Invalid(specPath.Child("modelAPISelector"), selector, err.Error())}
+	}
+	return nil
+}
+
+// validateSecretKeyMappings rejects mappings that set the same env var twice or, without the
+// escape annotation, an env var the operator sets
+func validateSecretKeyMappings(path *field.Path, agent *kaosv1alpha1.Agent) field.ErrorList {
+	var errs field.ErrorList
+	allowReserved := agent.Annotations[kaosv1alpha1.AllowReservedEnvAnnotation] == "true"
+	seen := map[string]bool{}
+	for i, mapping := range agent.Spec.SecretKeyMappings {
+		envPath := path.Index(i).Child("envName")
+		if seen[mapping.EnvName] {
+			errs = append(errs, field.Duplicate(envPath, mapping.EnvName))
+		}
+		seen[mapping.EnvName] = true
+		if err := reservedEnvError(envPath, mapping.EnvName); err != nil && !allowReserved {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// agentContainers lists the names and ports of the containers the controller generates for
+// an Agent pod
+func agentContainers(agent *kaosv1alpha1.Agent) []corev1.Container {
+	containers := []corev1.Container{{Name: "agent", Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 8000}}}}
+	if agent.Spec.EmbeddedModel != nil {
+		containers = append(containers, corev1.Container{Name: "model-api", Ports: []corev1.ContainerPort{{Name: "model-http"}}})
+	}
+	return containers
+}
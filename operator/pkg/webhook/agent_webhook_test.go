@@ -0,0 +1,386 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+// newAgent returns a minimal valid Agent for validation tests
+func newAgent() *kaosv1alpha1.Agent {
+	return &kaosv1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-agent", Namespace: "default"},
+		Spec: kaosv1alpha1.AgentSpec{
+			ModelAPI: "test-modelapi",
+			Model:    "openai/gpt-4",
+		},
+	}
+}
+
+var _ = Describe("AgentValidator", func() {
+	validator := &AgentValidator{}
+
+	It("should accept an agent without runtime configuration", func() {
+		_, err := validator.ValidateCreate(context.Background(), newAgent())
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should reject objects that are not Agents", func() {
+		_, err := validator.ValidateCreate(context.Background(), &kaosv1alpha1.ModelAPI{})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should allow deletions", func() {
+		_, err := validator.ValidateDelete(context.Background(), newAgent())
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should reject host aliases with an invalid IP", func() {
+		agent := newAgent()
+		agent.Spec.HostAliases = []corev1.HostAlias{
+			{IP: "10.0.0.5", Hostnames: []string{"api.internal"}},
+			{IP: "not-an-ip", Hostnames: []string{"db.internal"}},
+		}
+		_, err := validator.ValidateCreate(context.Background(), agent)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.hostAliases[1].ip"))
+	})
+
+	It("should only allow debug admins to set the debug image annotation", func() {
+		validator := &AgentValidator{DebugAdminGroups: []string{"kaos-admins"}}
+		agent := newAgent()
+		agent.Annotations = map[string]string{kaosv1alpha1.DebugImageAnnotation: "busybox:1.36"}
+
+		userCtx := admission.NewContextWithRequest(context.Background(), admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{UserInfo: authenticationv1.UserInfo{Groups: []string{"developers"}}},
+		})
+		_, err := validator.ValidateUpdate(userCtx, newAgent(), agent)
+		Expect(apierrors.IsForbidden(err)).To(BeTrue())
+
+		adminCtx := admission.NewContextWithRequest(context.Background(), admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{UserInfo: authenticationv1.UserInfo{Groups: []string{"kaos-admins"}}},
+		})
+		_, err = validator.ValidateUpdate(adminCtx, newAgent(), agent)
+		Expect(err).NotTo(HaveOccurred())
+
+		// Clearing the annotation or leaving it unchanged is always allowed
+		_, err = validator.ValidateUpdate(userCtx, agent, newAgent())
+		Expect(err).NotTo(HaveOccurred())
+		_, err = validator.ValidateUpdate(userCtx, agent, agent)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should reject a podSpec CPU request greater than its limit", func() {
+		agent := newAgent()
+		agent.Spec.PodSpec = &corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name: "agent",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+					Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")},
+				},
+			}},
+		}
+		_, err := validator.ValidateCreate(context.Background(), agent)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.podSpec.containers[0].resources.requests[cpu]"))
+	})
+
+	It("should accept podSpec requests within their limits", func() {
+		agent := newAgent()
+		agent.Spec.PodSpec = &corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name: "agent",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("500m"),
+						corev1.ResourceMemory: resource.MustParse("256Mi"),
+					},
+					Limits: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("500m"),
+						corev1.ResourceMemory: resource.MustParse("1Gi"),
+					},
+				},
+			}},
+		}
+		_, err := validator.ValidateCreate(context.Background(), agent)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should only accept volumeClaimTemplates for StatefulSet agents", func() {
+		agent := newAgent()
+		agent.Spec.VolumeClaimTemplates = []corev1.PersistentVolumeClaim{{ObjectMeta: metav1.ObjectMeta{Name: "state"}}}
+		_, err := validator.ValidateCreate(context.Background(), agent)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.volumeClaimTemplates"))
+
+		agent.Spec.WorkloadType = kaosv1alpha1.AgentWorkloadTypeStatefulSet
+		_, err = validator.ValidateCreate(context.Background(), agent)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should only accept podManagementPolicy for StatefulSet agents", func() {
+		agent := newAgent()
+		agent.Spec.PodManagementPolicy = appsv1.ParallelPodManagement
+		_, err := validator.ValidateCreate(context.Background(), agent)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.podManagementPolicy: Forbidden: requires workloadType StatefulSet"))
+
+		agent.Spec.WorkloadType = kaosv1alpha1.AgentWorkloadTypeStatefulSet
+		_, err = validator.ValidateCreate(context.Background(), agent)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should only accept the supported placeholders in command and args", func() {
+		agent := newAgent()
+		agent.Spec.Command = []string{"python", "-m", "agent"}
+		agent.Spec.Args = []string{"--model-url={{ .ModelEndpoint }}", "--mcp={{.MCPEndpoints}}"}
+		_, err := validator.ValidateCreate(context.Background(), agent)
+		Expect(err).NotTo(HaveOccurred())
+
+		agent.Spec.Args = []string{"--model-url={{ .ModelEndpoint }}", "--key={{ .APIKey }}", `{{ env "HOME" }}`}
+		_, err = validator.ValidateCreate(context.Background(), agent)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.args[1]"))
+		Expect(err.Error()).To(ContainSubstring("spec.args[2]"))
+		Expect(err.Error()).NotTo(ContainSubstring("spec.args[0]"))
+
+		agent.Spec.Args = nil
+		agent.Spec.Command = []string{"{{ if .ModelEndpoint }}agent{{ end }}"}
+		_, err = validator.ValidateCreate(context.Background(), agent)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.command[0]"))
+	})
+
+	It("should reject config.env entries named after operator env vars", func() {
+		agent := newAgent()
+		agent.Spec.Config = &kaosv1alpha1.AgentConfig{Env: []corev1.EnvVar{
+			{Name: "CUSTOM_VAR", Value: "ok"},
+			{Name: "MODEL_API_URL", Value: "http://elsewhere:8000"},
+			{Name: "MCP_SERVER_SEARCH_URL", Value: "http://search:8000"},
+			{Name: "MEMORY_TYPE", Value: "redis"},
+			{Name: "OTEL_EXPORTER_OTLP_HEADERS", Value: "x-tenant=a"},
+		}}
+		_, err := validator.ValidateCreate(context.Background(), agent)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		for i := 1; i <= 4; i++ {
+			Expect(err.Error()).To(ContainSubstring(fmt.Sprintf("spec.config.env[%d].name", i)))
+		}
+		Expect(err.Error()).To(ContainSubstring(kaosv1alpha1.AllowReservedEnvAnnotation))
+		Expect(err.Error()).NotTo(ContainSubstring("spec.config.env[0]"))
+	})
+
+	It("should accept reserved config.env names with the escape annotation", func() {
+		agent := newAgent()
+		agent.Annotations = map[string]string{kaosv1alpha1.AllowReservedEnvAnnotation: "true"}
+		agent.Spec.Config = &kaosv1alpha1.AgentConfig{Env: []corev1.EnvVar{{Name: "MODEL_API_URL", Value: "http://elsewhere:8000"}}}
+		_, err := validator.ValidateCreate(context.Background(), agent)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = validator.ValidateUpdate(context.Background(), agent, agent)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should require the key of secret and configmap env references", func() {
+		agent := newAgent()
+		agent.Spec.Config = &kaosv1alpha1.AgentConfig{Env: []corev1.EnvVar{
+			{Name: "TOKEN", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "creds"},
+			}}},
+			{Name: "REGION", ValueFrom: &corev1.EnvVarSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "settings"},
+			}}},
+		}}
+		_, err := validator.ValidateCreate(context.Background(), agent)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.config.env[0].valueFrom.secretKeyRef.key: Required value"))
+		Expect(err.Error()).To(ContainSubstring("spec.config.env[1].valueFrom.configMapKeyRef.key: Required value"))
+
+		agent.Spec.Config.Env[0].ValueFrom.SecretKeyRef.Key = "token"
+		agent.Spec.Config.Env[1].ValueFrom.ConfigMapKeyRef.Key = "region"
+		_, err = validator.ValidateCreate(context.Background(), agent)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should reject telemetry headers that break OTEL_EXPORTER_OTLP_HEADERS", func() {
+		agent := newAgent()
+		agent.Spec.Telemetry = &kaosv1alpha1.TelemetryConfig{
+			Enabled: true,
+			Headers: map[string]string{"Authorization": "Bearer token"},
+		}
+		_, err := validator.ValidateCreate(context.Background(), agent)
+		Expect(err).NotTo(HaveOccurred())
+
+		agent.Spec.Telemetry.Headers = map[string]string{"x tenant": "acme"}
+		_, err = validator.ValidateCreate(context.Background(), agent)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.telemetry.headers[x tenant]"))
+		Expect(err.Error()).To(ContainSubstring("must be a valid HTTP header name"))
+
+		agent.Spec.Telemetry.Headers = map[string]string{"x-tenant": "acme,evil=1"}
+		_, err = validator.ValidateCreate(context.Background(), agent)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.telemetry.headers[x-tenant]"))
+		Expect(err.Error()).To(ContainSubstring("may not contain commas or newlines"))
+	})
+
+	It("should warn about telemetry settings that are ignored while telemetry is disabled", func() {
+		agent := newAgent()
+		agent.Spec.Telemetry = &kaosv1alpha1.TelemetryConfig{
+			Endpoint: "http://otel-collector:4317",
+			Headers:  map[string]string{"Authorization": "Bearer token"},
+		}
+		warnings, err := validator.ValidateCreate(context.Background(), agent)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(ConsistOf("spec.telemetry.endpoint, spec.telemetry.headers set but spec.telemetry.enabled " +
+			"is false, so telemetry is not exported and they are ignored"))
+
+		warnings, err = validator.ValidateUpdate(context.Background(), agent, agent)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(HaveLen(1))
+
+		agent.Spec.Telemetry.Enabled = true
+		warnings, err = validator.ValidateCreate(context.Background(), agent)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(BeEmpty())
+
+		agent.Spec.Telemetry = &kaosv1alpha1.TelemetryConfig{FailFast: true}
+		warnings, err = validator.ValidateCreate(context.Background(), agent)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(BeEmpty())
+	})
+
+	It("should reject a trace-sample-ratio annotation outside 0 to 1", func() {
+		agent := newAgent()
+		agent.Annotations = map[string]string{kaosv1alpha1.TraceSampleRatioAnnotation: "1"}
+		_, err := validator.ValidateCreate(context.Background(), agent)
+		Expect(err).NotTo(HaveOccurred())
+
+		agent.Annotations[kaosv1alpha1.TraceSampleRatioAnnotation] = "10%"
+		_, err = validator.ValidateCreate(context.Background(), agent)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("metadata.annotations[kaos.agentic/trace-sample-ratio]"))
+		Expect(err.Error()).To(ContainSubstring("must be a number between 0 and 1"))
+	})
+
+	It("should enforce the configured naming convention on create", func() {
+		GinkgoT().Setenv("NAME_PATTERN", "^team-[a-z]+-")
+		convention, err := NamingConvention()
+		Expect(err).NotTo(HaveOccurred())
+		named := &AgentValidator{NamingConvention: convention}
+
+		agent := newAgent()
+		agent.Name = "team-search-agent"
+		_, err = named.ValidateCreate(context.Background(), agent)
+		Expect(err).NotTo(HaveOccurred())
+
+		agent.Name = "search-agent"
+		_, err = named.ValidateCreate(context.Background(), agent)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring(`metadata.name: Invalid value: "search-agent": must match the naming convention "^team-[a-z]+-"`))
+
+		// Existing resources predating the convention can still be updated
+		_, err = named.ValidateUpdate(context.Background(), agent, agent)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should reject an invalid naming convention", func() {
+		GinkgoT().Setenv("NAME_PATTERN", "team-[")
+		_, err := NamingConvention()
+		Expect(err).To(MatchError(ContainSubstring("invalid NAME_PATTERN")))
+
+		GinkgoT().Setenv("NAME_PATTERN", "")
+		_, err = NamingConvention()
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should reject a debug image outside the allowed registries", func() {
+		restricted := &AgentValidator{DebugAdminGroups: []string{"kaos-admins"}, AllowedRegistries: []string{"ghcr.io/acme"}}
+		adminCtx := admission.NewContextWithRequest(context.Background(), admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{UserInfo: authenticationv1.UserInfo{Groups: []string{"kaos-admins"}}},
+		})
+		agent := newAgent()
+		agent.Annotations = map[string]string{kaosv1alpha1.DebugImageAnnotation: "ghcr.io/acme/debug:v1"}
+		_, err := restricted.ValidateCreate(adminCtx, agent)
+		Expect(err).NotTo(HaveOccurred())
+
+		// Even debug admins may only use allowed images
+		agent.Annotations[kaosv1alpha1.DebugImageAnnotation] = "busybox"
+		_, err = restricted.ValidateUpdate(adminCtx, newAgent(), agent)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring(`image "busybox" is not from an allowed registry`))
+	})
+
+	It("should reject duplicate container names in the pod", func() {
+		agent := newAgent()
+		agent.Spec.PodSpec = &corev1.PodSpec{
+			Containers:     []corev1.Container{{Name: "agent"}, {Name: "proxy"}, {Name: "proxy"}},
+			InitContainers: []corev1.Container{{Name: "agent"}},
+		}
+		_, err := validator.ValidateCreate(context.Background(), agent)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring(`spec.podSpec.containers[2].name: Duplicate value: "proxy"`))
+		Expect(err.Error()).To(ContainSubstring(`spec.podSpec.initContainers[0].name: Duplicate value: "agent"`))
+		Expect(err.Error()).NotTo(ContainSubstring("containers[0]"))
+	})
+
+	It("should reject duplicate container port names in the pod", func() {
+		agent := newAgent()
+		agent.Spec.PodSpec = &corev1.PodSpec{
+			Containers: []corev1.Container{
+				// Restating the agent's own port merges into it
+				{Name: "agent", Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 8000}}},
+				{Name: "proxy", Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 9000}}},
+			},
+		}
+		_, err := validator.ValidateCreate(context.Background(), agent)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring(`spec.podSpec.containers[1].ports[0].name: Duplicate value: "http"`))
+		Expect(err.Error()).NotTo(ContainSubstring("containers[0]"))
+
+		agent.Spec.PodSpec.Containers[1].Ports[0].Name = "proxy-http"
+		_, err = validator.ValidateCreate(context.Background(), agent)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should reject secretKeyMappings that set an env var twice or a reserved one", func() {
+		agent := newAgent()
+		agent.Spec.SecretKeyMappings = []kaosv1alpha1.SecretKeyMapping{
+			{SecretName: "provider", Key: "api-key", EnvName: "OPENAI_API_KEY"},
+			{SecretName: "provider", Key: "key", EnvName: "OPENAI_API_KEY"},
+			{SecretName: "provider", Key: "url", EnvName: "MODEL_API_URL"},
+		}
+		_, err := validator.ValidateCreate(context.Background(), agent)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring(`spec.secretKeyMappings[1].envName: Duplicate value: "OPENAI_API_KEY"`))
+		Expect(err.Error()).To(ContainSubstring(`spec.secretKeyMappings[2].envName: Invalid value: "MODEL_API_URL": is set by the operator`))
+
+		agent.Spec.SecretKeyMappings = agent.Spec.SecretKeyMappings[:1]
+		_, err = validator.ValidateCreate(context.Background(), agent)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should reject an invalid modelAPISelector", func() {
+		agent := newAgent()
+		agent.Spec.ModelAPI = ""
+		agent.Spec.ModelAPISelector = &metav1.LabelSelector{MatchLabels: map[string]string{"team": "search"}}
+		_, err := validator.ValidateCreate(context.Background(), agent)
+		Expect(err).NotTo(HaveOccurred())
+
+		agent.Spec.ModelAPISelector.MatchExpressions = []metav1.LabelSelectorRequirement{{Key: "tier", Operator: "Near"}}
+		_, err = validator.ValidateCreate(context.Background(), agent)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.modelAPISelector"))
+	})
+})
@@ -0,0 +1,96 @@
+package webhook
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// imageRef is an image a resource sets, with the field that sets it
+type imageRef struct {
+	path  *field.Path
+	image string
+}
+
+// AllowedImageRegistries reads the comma-separated registry prefixes, such as
+// ghcr.io/acme/ or registry.internal:5000, that images set on Agent, ModelAPI and MCPServer
+// resources must come from, from the ALLOWED_IMAGE_REGISTRIES operator setting
+func AllowedImageRegistries() ([]string, error) {
+	var registries []string
+	for _, registry := range strings.Split(os.Getenv("ALLOWED_IMAGE_REGISTRIES"), ",") {
+		if registry = strings.TrimSpace(registry); registry != "" {
+			registries = append(registries, registry)
+		}
+	}
+	if len(registries) == 0 {
+		return nil, fmt.Errorf("ALLOWED_IMAGE_REGISTRIES must be set to enforce an image allowlist")
+	}
+	return registries, nil
+}
+
+// podSpecImages lists the images of the containers and init containers of a podSpec override
+func podSpecImages(path *field.Path, podSpec *corev1.PodSpec) []imageRef {
+	if podSpec == nil {
+		return nil
+	}
+	var images []imageRef
+	for i, container := range podSpec.InitContainers {
+		images = append(images, imageRef{path: path.Child("initContainers").Index(i).Child("image"), image: container.Image})
+	}
+	for i, container := range podSpec.Containers {
+		images = append(images, imageRef{path: path.Child("containers").Index(i).Child("image"), image: container.Image})
+	}
+	return images
+}
+
+// normalizeImage expands Docker Hub short names, such as nginx or acme/agent, to their full
+// docker.io/library/nginx or docker.io/acme/agent reference
+func normalizeImage(image string) string {
+	first, rest, found := strings.Cut(image, "/")
+	if !found {
+		return "docker.io/library/" + image
+	}
+	if strings.ContainsAny(first, ".:") || first == "localhost" {
+		return image
+	}
+	return "docker.io/" + first + "/" + rest
+}
+
+// imageAllowed reports whether the image comes from one of the registry prefixes. Prefixes
+// match whole path segments, so ghcr.io/acme allows ghcr.io/acme/agent but not
+// ghcr.io/acme-fork/agent.
+func imageAllowed(allowed []string, image string) bool {
+	image = normalizeImage(image)
+	for _, prefix := range allowed {
+		prefix = strings.TrimSuffix(prefix, "/")
+		if image == prefix || strings.HasPrefix(image, prefix+"/") ||
+			strings.HasPrefix(image, prefix+":") || strings.HasPrefix(image, prefix+"@") {
+			return true
+		}
+	}
+	return false
+}
+
+// validateImages rejects images that do not come from an allowed registry. A nil allowlist
+// accepts every image, and unset images fall back to the operator defaults, which are trusted.
+func validateImages(allowed []string, kind schema.GroupKind, name string, images []imageRef) error {
+	if allowed == nil {
+		return nil
+	}
+	var errs field.ErrorList
+	for _, ref := range images {
+		if ref.image != "" && !imageAllowed(allowed, ref.image) {
+			errs = append(errs, field.Forbidden(ref.path,
+				fmt.Sprintf("image %q is not from an allowed registry (%s)", ref.image, strings.Join(allowed, ", "))))
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(kind, name, errs)
+}
@@ -0,0 +1,135 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+//+kubebuilder:webhook:path=/validate-kaos-tools-v1alpha1-mcpserver,mutating=false,failurePolicy=fail,sideEffects=None,groups=kaos.tools,resources=mcpservers,verbs=create;update,versions=v1alpha1,name=vmcpserver.kaos.tools,admissionReviewVersions=v1
+
+// MCPServerValidator validates MCPServer resources on create and update
+type MCPServerValidator struct {
+	// NamingConvention, when set, is a regular expression new MCPServer names must match
+	NamingConvention *regexp.Regexp
+	// AllowedRegistries, when set, are the registry prefixes the MCPServer's images must come from
+	AllowedRegistries []string
+}
+
+var _ admission.CustomValidator = &MCPServerValidator{}
+
+// mcpServerPort and stdioBridgePort are the pod ports the controller gives the MCP server and
+// the stdio bridge sidecar
+const (
+	mcpServerPort   int32 = 8000
+	stdioBridgePort int32 = 8080
+)
+
+// SetupMCPServerWebhookWithManager registers the MCPServer defaulting and validating webhooks with the manager
+func SetupMCPServerWebhookWithManager(mgr ctrl.Manager, namingConvention *regexp.Regexp, allowedRegistries []string, sizes SizePresets) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&kaosv1alpha1.MCPServer{}).
+		WithDefaulter(&MCPServerDefaulter{Sizes: sizes}).
+		WithValidator(&MCPServerValidator{NamingConvention: namingConvention, AllowedRegistries: allowedRegistries}).
+		Complete()
+}
+
+// ValidateCreate validates a new MCPServer
+func (v *MCPServerValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	mcpserver, ok := obj.(*kaosv1alpha1.MCPServer)
+	if !ok {
+		return nil, fmt.Errorf("expected an MCPServer but got %T", obj)
+	}
+	if err := validateName(v.NamingConvention, kaosv1alpha1.GroupVersion.WithKind("MCPServer").GroupKind(), mcpserver.Name); err != nil {
+		return nil, err
+	}
+	if err := v.validateImages(mcpserver); err != nil {
+		return nil, err
+	}
+	return nil, validateMCPServer(mcpserver)
+}
+
+// ValidateUpdate validates an updated MCPServer
+func (v *MCPServerValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	mcpserver, ok := newObj.(*kaosv1alpha1.MCPServer)
+	if !ok {
+		return nil, fmt.Errorf("expected an MCPServer but got %T", newObj)
+	}
+	if err := v.validateImages(mcpserver); err != nil {
+		return nil, err
+	}
+	return nil, validateMCPServer(mcpserver)
+}
+
+// validateImages rejects podSpec container and stdio bridge images that are not from an
+// allowed registry
+func (v *MCPServerValidator) validateImages(mcpserver *kaosv1alpha1.MCPServer) error {
+	images := podSpecImages(field.NewPath("spec", "podSpec"), mcpserver.Spec.PodSpec)
+	if bridge := mcpserver.Spec.Config.StdioBridge; bridge != nil {
+		images = append(images, imageRef{path: field.NewPath("spec", "config", "stdioBridge", "image"), image: bridge.Image})
+	}
+	return validateImages(v.AllowedRegistries, kaosv1alpha1.GroupVersion.WithKind("MCPServer").GroupKind(), mcpserver.Name, images)
+}
+
+// ValidateDelete allows all deletions
+func (v *MCPServerValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateMCPServer returns an Invalid error listing every spec violation, or nil if the MCPServer is valid
+func validateMCPServer(mcpserver *kaosv1alpha1.MCPServer) error {
+	var errs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	errs = append(errs, validateEnv(specPath.Child("config", "env"), mcpserver.Spec.Config.Env)...)
+	errs = append(errs, validatePodSpecResources(specPath.Child("podSpec"), mcpserver.Spec.PodSpec)...)
+	errs = append(errs, validatePodSpecNames(specPath.Child("podSpec"), mcpServerContainers(mcpserver), mcpserver.Spec.PodSpec)...)
+
+	// Only packages are run as stdio servers; fromString/fromSecretKeyRef tools are served over HTTP
+	if bridge := mcpserver.Spec.Config.StdioBridge; bridge != nil && bridge.Enabled {
+		if tools := mcpserver.Spec.Config.Tools; tools == nil || tools.FromPackage == "" {
+			errs = append(errs, field.Forbidden(specPath.Child("config", "stdioBridge"), "requires config.tools.fromPackage"))
+		}
+	}
+
+	// Only SSE sessions behind the stdio bridge are pinned to a pod
+	if mcpserver.Spec.SessionAffinityTimeout != nil {
+		if bridge := mcpserver.Spec.Config.StdioBridge; bridge == nil || !bridge.Enabled {
+			errs = append(errs, field.Forbidden(specPath.Child("sessionAffinityTimeout"), "requires config.stdioBridge.enabled"))
+		}
+	}
+
+	// The metrics port shares the pod with the MCP port and, when bridged, the bridge's SSE port
+	if port := mcpserver.Spec.MetricsPort; port != nil && (*port == mcpServerPort || *port == stdioBridgePort) {
+		errs = append(errs, field.Invalid(specPath.Child("metricsPort"), *port,
+			fmt.Sprintf("must differ from the MCP server port %d and the stdio bridge port %d", mcpServerPort, stdioBridgePort)))
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(kaosv1alpha1.GroupVersion.WithKind("MCPServer").GroupKind(), mcpserver.Name, errs)
+}
+
+// mcpServerContainers lists the names and ports of the containers the controller generates
+// for an MCPServer pod
+func mcpServerContainers(mcpserver *kaosv1alpha1.MCPServer) []corev1.Container {
+	server := corev1.Container{Name: "mcp-server", Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: mcpServerPort}}}
+	if port := mcpserver.Spec.MetricsPort; port != nil {
+		server.Ports = append(server.Ports, corev1.ContainerPort{Name: "metrics", ContainerPort: *port})
+	}
+	containers := []corev1.Container{server}
+	if bridge := mcpserver.Spec.Config.StdioBridge; bridge != nil && bridge.Enabled {
+		containers = append(containers, corev1.Container{Name: "stdio-bridge", Ports: []corev1.ContainerPort{{Name: "sse", ContainerPort: stdioBridgePort}}})
+	}
+	return containers
+}
@@ -0,0 +1,79 @@
+package webhook
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+// newMCPServer returns a minimal valid python-runtime MCPServer for validation tests
+func newMCPServer() *kaosv1alpha1.MCPServer {
+	return &kaosv1alpha1.MCPServer{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-mcpserver", Namespace: "default"},
+		Spec: kaosv1alpha1.MCPServerSpec{
+			Type: kaosv1alpha1.MCPServerTypePython,
+			Config: kaosv1alpha1.MCPServerConfig{
+				Tools: &kaosv1alpha1.MCPToolsConfig{FromPackage: "test-mcp-echo-server"},
+			},
+		},
+	}
+}
+
+var _ = Describe("MCPServerValidator", func() {
+	validator := &MCPServerValidator{}
+
+	It("should only allow the stdio bridge for package-based servers", func() {
+		mcpserver := newMCPServer()
+		mcpserver.Spec.Config.StdioBridge = &kaosv1alpha1.StdioBridgeConfig{Enabled: true}
+		_, err := validator.ValidateCreate(context.Background(), mcpserver)
+		Expect(err).NotTo(HaveOccurred())
+
+		mcpserver.Spec.Config.Tools = &kaosv1alpha1.MCPToolsConfig{FromString: "def echo(x: str) -> str: return x"}
+		_, err = validator.ValidateCreate(context.Background(), mcpserver)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.config.stdioBridge"))
+	})
+
+	It("should reject a stdio bridge image outside the allowed registries", func() {
+		restricted := &MCPServerValidator{AllowedRegistries: []string{"registry.internal:5000"}}
+		mcpserver := newMCPServer()
+		mcpserver.Spec.Config.StdioBridge = &kaosv1alpha1.StdioBridgeConfig{Enabled: true, Image: "registry.internal:5000/mcp-bridge:v2"}
+		_, err := restricted.ValidateCreate(context.Background(), mcpserver)
+		Expect(err).NotTo(HaveOccurred())
+
+		mcpserver.Spec.Config.StdioBridge.Image = "supercorp/mcp-bridge:v2"
+		_, err = restricted.ValidateCreate(context.Background(), mcpserver)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.config.stdioBridge.image"))
+	})
+	It("should reject a metrics port that collides with the server ports", func() {
+		mcpserver := newMCPServer()
+		port := int32(9090)
+		mcpserver.Spec.MetricsPort = &port
+		_, err := validator.ValidateCreate(context.Background(), mcpserver)
+		Expect(err).NotTo(HaveOccurred())
+
+		port = 8000
+		_, err = validator.ValidateCreate(context.Background(), mcpserver)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.metricsPort: Invalid value: 8000"))
+	})
+
+	It("should only accept sessionAffinityTimeout for servers behind the stdio bridge", func() {
+		mcpserver := newMCPServer()
+		timeout := int32(600)
+		mcpserver.Spec.SessionAffinityTimeout = &timeout
+		_, err := validator.ValidateCreate(context.Background(), mcpserver)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.sessionAffinityTimeout: Forbidden: requires config.stdioBridge.enabled"))
+
+		mcpserver.Spec.Config.StdioBridge = &kaosv1alpha1.StdioBridgeConfig{Enabled: true}
+		_, err = validator.ValidateCreate(context.Background(), mcpserver)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
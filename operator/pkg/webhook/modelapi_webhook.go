@@ -0,0 +1,237 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+	"github.com/axsaucedo/kaos/operator/pkg/util"
+)
+
+//+kubebuilder:webhook:path=/validate-kaos-tools-v1alpha1-modelapi,mutating=false,failurePolicy=fail,sideEffects=None,groups=kaos.tools,resources=modelapis,verbs=create;update,versions=v1alpha1,name=vmodelapi.kaos.tools,admissionReviewVersions=v1
+
+// ModelAPIValidator validates ModelAPI resources on create and update
+type ModelAPIValidator struct {
+	// NamingConvention, when set, is a regular expression new ModelAPI names must match
+	NamingConvention *regexp.Regexp
+	// AllowedRegistries, when set, are the registry prefixes the ModelAPI's images must come from
+	AllowedRegistries []string
+}
+
+var _ admission.CustomValidator = &ModelAPIValidator{}
+
+// SetupModelAPIWebhookWithManager registers the ModelAPI defaulting and validating webhooks with the manager
+func SetupModelAPIWebhookWithManager(mgr ctrl.Manager, namingConvention *regexp.Regexp, allowedRegistries []string, sizes SizePresets) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&kaosv1alpha1.ModelAPI{}).
+		WithDefaulter(&ModelAPIDefaulter{Sizes: sizes}).
+		WithValidator(&ModelAPIValidator{NamingConvention: namingConvention, AllowedRegistries: allowedRegistries}).
+		Complete()
+}
+
+// ValidateCreate validates a new ModelAPI
+func (v *ModelAPIValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	modelapi, ok := obj.(*kaosv1alpha1.ModelAPI)
+	if !ok {
+		return nil, fmt.Errorf("expected a ModelAPI but got %T", obj)
+	}
+	if err := validateName(v.NamingConvention, kaosv1alpha1.GroupVersion.WithKind("ModelAPI").GroupKind(), modelapi.Name); err != nil {
+		return nil, err
+	}
+	if err := v.validateImages(modelapi); err != nil {
+		return nil, err
+	}
+	return nil, validateModelAPI(modelapi)
+}
+
+// ValidateUpdate validates an updated ModelAPI
+func (v *ModelAPIValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	modelapi, ok := newObj.(*kaosv1alpha1.ModelAPI)
+	if !ok {
+		return nil, fmt.Errorf("expected a ModelAPI but got %T", newObj)
+	}
+	oldModelAPI, ok := oldObj.(*kaosv1alpha1.ModelAPI)
+	if !ok {
+		return nil, fmt.Errorf("expected a ModelAPI but got %T", oldObj)
+	}
+	if err := validateModeChange(oldModelAPI, modelapi); err != nil {
+		return nil, err
+	}
+	if err := v.validateImages(modelapi); err != nil {
+		return nil, err
+	}
+	return nil, validateModelAPI(modelapi)
+}
+
+// validateImages rejects podSpec container images that are not from an allowed registry
+func (v *ModelAPIValidator) validateImages(modelapi *kaosv1alpha1.ModelAPI) error {
+	return validateImages(v.AllowedRegistries, kaosv1alpha1.GroupVersion.WithKind("ModelAPI").GroupKind(), modelapi.Name,
+		podSpecImages(field.NewPath("spec", "podSpec"), modelapi.Spec.PodSpec))
+}
+
+// validateModeChange rejects a spec.mode change unless the ModelAPI carries the
+// allow-mode-migration annotation, which makes the operator recreate its children
+func validateModeChange(oldModelAPI, modelapi *kaosv1alpha1.ModelAPI) error {
+	if oldModelAPI.Spec.Mode == modelapi.Spec.Mode || modelapi.Annotations[kaosv1alpha1.AllowModeMigrationAnnotation] == "true" {
+		return nil
+	}
+	return apierrors.NewInvalid(kaosv1alpha1.GroupVersion.WithKind("ModelAPI").GroupKind(), modelapi.Name, field.ErrorList{
+		field.Invalid(field.NewPath("spec", "mode"), modelapi.Spec.Mode,
+			fmt.Sprintf("is immutable; set the %s annotation to \"true\" to migrate from %s", kaosv1alpha1.AllowModeMigrationAnnotation, oldModelAPI.Spec.Mode)),
+	})
+}
+
+// ValidateDelete allows all deletions
+func (v *ModelAPIValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateModelAPI returns an Invalid error listing every spec violation, or nil if the ModelAPI is valid
+func validateModelAPI(modelapi *kaosv1alpha1.ModelAPI) error {
+	var errs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if modelapi.Spec.ProxyConfig != nil {
+		errs = append(errs, validateEnv(specPath.Child("proxyConfig", "env"), modelapi.Spec.ProxyConfig.Env)...)
+	}
+	if hosted := modelapi.Spec.HostedConfig; hosted != nil {
+		hostedPath := specPath.Child("hostedConfig")
+		errs = append(errs, validateEnv(hostedPath.Child("env"), hosted.Env)...)
+		errs = append(errs, validateCommandTemplates(hostedPath.Child("command"), hosted.Command, util.ValidateHostedCommandTemplate)...)
+		errs = append(errs, validateCommandTemplates(hostedPath.Child("args"), hosted.Args, util.ValidateHostedCommandTemplate)...)
+	}
+	errs = append(errs, validateHostAliases(specPath.Child("hostAliases"), modelapi.Spec.HostAliases)...)
+	errs = append(errs, validatePodSpecResources(specPath.Child("podSpec"), modelapi.Spec.PodSpec)...)
+	errs = append(errs, validatePodSpecNames(specPath.Child("podSpec"), modelAPIContainers(modelapi), modelapi.Spec.PodSpec)...)
+
+	if proxy := modelapi.Spec.ProxyConfig; proxy != nil && proxy.Upstreams != nil {
+		errs = append(errs, validateUpstreams(specPath.Child("proxyConfig"), proxy)...)
+	}
+
+	if modelapi.Spec.RateLimit != nil && modelapi.Spec.Mode != kaosv1alpha1.ModelAPIModeProxy {
+		errs = append(errs, field.Forbidden(specPath.Child("rateLimit"), "only supported in Proxy mode"))
+	}
+
+	if hc := modelapi.Spec.HealthCheck; hc != nil && hc.Type == kaosv1alpha1.HealthCheckTypeGRPC && modelapi.Spec.Mode != kaosv1alpha1.ModelAPIModeHosted {
+		errs = append(errs, field.Forbidden(specPath.Child("healthCheck", "type"), "grpc is only supported in Hosted mode"))
+	}
+	errs = append(errs, validateHealthCheckTimings(specPath.Child("healthCheck"), modelapi.Spec.HealthCheck)...)
+	errs = append(errs, validateMinReadySeconds(specPath, modelapi.Spec.MinReadySeconds, modelapi.Spec.ProgressDeadlineSeconds)...)
+	errs = append(errs, validateArchitecture(specPath, modelapi.Spec.Architecture, modelapi.Spec.PodSpec)...)
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(kaosv1alpha1.GroupVersion.WithKind("ModelAPI").GroupKind(), modelapi.Name, errs)
+}
+
+// validateMinReadySeconds rejects a stability window that is not shorter than the progress
+// deadline, which the API server would refuse when the Deployment is created
+func validateMinReadySeconds(specPath *field.Path, minReady, progressDeadline *int32) field.ErrorList {
+	if minReady == nil {
+		return nil
+	}
+	deadline := int32(600)
+	if progressDeadline != nil {
+		deadline = *progressDeadline
+	}
+	if *minReady >= deadline {
+		return field.ErrorList{field.Invalid(specPath.Child("minReadySeconds"), *minReady,
+			fmt.Sprintf("must be less than progressDeadlineSeconds (%d)", deadline))}
+	}
+	return nil
+}
+
+// validateArchitecture rejects a podSpec node selector for another architecture than
+// spec.architecture, which would leave the pods unschedulable
+func validateArchitecture(specPath *field.Path, architecture string, podSpec *corev1.PodSpec) field.ErrorList {
+	if architecture == "" || podSpec == nil {
+		return nil
+	}
+	if selected, ok := podSpec.NodeSelector[corev1.LabelArchStable]; ok && selected != architecture {
+		return field.ErrorList{field.Invalid(specPath.Child("podSpec", "nodeSelector").Key(corev1.LabelArchStable), selected,
+			fmt.Sprintf("conflicts with %s %s", specPath.Child("architecture"), architecture))}
+	}
+	return nil
+}
+
+// validateUpstreams checks that a failover upstream list names at least one absolute
+// http(s) URL and is not combined with apiBase
+func validateUpstreams(path *field.Path, proxy *kaosv1alpha1.ProxyConfig) field.ErrorList {
+	var errs field.ErrorList
+	upstreamsPath := path.Child("upstreams")
+	if len(proxy.Upstreams) == 0 {
+		errs = append(errs, field.Required(upstreamsPath, "at least one upstream is required"))
+	}
+	for i, upstream := range proxy.Upstreams {
+		if u, err := url.Parse(upstream); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, field.Invalid(upstreamsPath.Index(i), upstream, "must be an absolute http or https URL"))
+		}
+	}
+	if proxy.APIBase != "" {
+		errs = append(errs, field.Forbidden(path.Child("apiBase"), "may not be set together with upstreams"))
+	}
+	return errs
+}
+
+// validateHealthCheckTimings checks that both probes time out before the next one is due, since
+// misconfigured probes cause silent crash loops. The CRD schema keeps the timings positive.
+// Each field applies to both probes, so the check uses the defaults of the unset ones.
+func validateHealthCheckTimings(path *field.Path, hc *kaosv1alpha1.HealthCheckConfig) field.ErrorList {
+	if hc == nil {
+		return nil
+	}
+	for _, probe := range []struct {
+		name     string
+		defaults util.ProbeTimings
+	}{
+		{"liveness", util.ModelAPILivenessTimings},
+		{"readiness", util.ModelAPIReadinessTimings},
+	} {
+		period, timeout := probe.defaults.PeriodSeconds, probe.defaults.TimeoutSeconds
+		if hc.PeriodSeconds != nil {
+			period = *hc.PeriodSeconds
+		}
+		if hc.TimeoutSeconds != nil {
+			timeout = *hc.TimeoutSeconds
+		}
+		if timeout < period {
+			continue
+		}
+		switch {
+		case hc.TimeoutSeconds == nil:
+			return field.ErrorList{field.Invalid(path.Child("periodSeconds"), period,
+				fmt.Sprintf("must be greater than the default timeoutSeconds (%d) of the %s probe", timeout, probe.name))}
+		case hc.PeriodSeconds == nil:
+			return field.ErrorList{field.Invalid(path.Child("timeoutSeconds"), timeout,
+				fmt.Sprintf("must be less than the default periodSeconds (%d) of the %s probe", period, probe.name))}
+		default:
+			return field.ErrorList{field.Invalid(path.Child("timeoutSeconds"), timeout,
+				fmt.Sprintf("must be less than periodSeconds (%d)", period))}
+		}
+	}
+	return nil
+}
+
+// modelAPIContainers lists the names and ports of the containers the controller generates
+// for a ModelAPI pod
+func modelAPIContainers(modelapi *kaosv1alpha1.ModelAPI) []corev1.Container {
+	port := int32(8000)
+	if modelapi.Spec.Mode == kaosv1alpha1.ModelAPIModeHosted {
+		port = 11434
+	}
+	containers := []corev1.Container{{Name: "model-api", Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: port}}}}
+	if modelapi.Spec.RateLimit != nil && modelapi.Spec.Mode == kaosv1alpha1.ModelAPIModeProxy && modelapi.Spec.ProxyConfig != nil {
+		containers = append(containers, corev1.Container{Name: "rate-limiter", Ports: []corev1.ContainerPort{{Name: "http-ratelimit", ContainerPort: 8080}}})
+	}
+	return containers
+}
@@ -0,0 +1,233 @@
+package webhook
+
+import (
+	"context"
+	"regexp"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+// newModelAPI returns a minimal valid Proxy ModelAPI for validation tests
+func newModelAPI() *kaosv1alpha1.ModelAPI {
+	return &kaosv1alpha1.ModelAPI{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-modelapi", Namespace: "default"},
+		Spec: kaosv1alpha1.ModelAPISpec{
+			Mode: kaosv1alpha1.ModelAPIModeProxy,
+			ProxyConfig: &kaosv1alpha1.ProxyConfig{
+				Models: []string{"openai/gpt-4"},
+			},
+		},
+	}
+}
+
+var _ = Describe("ModelAPIValidator", func() {
+	validator := &ModelAPIValidator{}
+
+	It("should accept a ModelAPI with valid host aliases", func() {
+		modelapi := newModelAPI()
+		modelapi.Spec.HostAliases = []corev1.HostAlias{
+			{IP: "192.168.1.10", Hostnames: []string{"llm.internal"}},
+			{IP: "fd00::10", Hostnames: []string{"llm6.internal"}},
+		}
+		_, err := validator.ValidateCreate(context.Background(), modelapi)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should reject host aliases with an invalid IP or no hostnames", func() {
+		modelapi := newModelAPI()
+		modelapi.Spec.HostAliases = []corev1.HostAlias{{IP: "999.1.1.1"}}
+		_, err := validator.ValidateUpdate(context.Background(), newModelAPI(), modelapi)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.hostAliases[0].ip"))
+		Expect(err.Error()).To(ContainSubstring("spec.hostAliases[0].hostnames"))
+	})
+
+	It("should reject rateLimit outside of Proxy mode", func() {
+		modelapi := newModelAPI()
+		modelapi.Spec.RateLimit = &kaosv1alpha1.RateLimitConfig{RequestsPerSecond: 5}
+		_, err := validator.ValidateCreate(context.Background(), modelapi)
+		Expect(err).NotTo(HaveOccurred())
+
+		modelapi.Spec.Mode = kaosv1alpha1.ModelAPIModeHosted
+		modelapi.Spec.ProxyConfig = nil
+		modelapi.Spec.HostedConfig = &kaosv1alpha1.HostedConfig{Model: "smollm2:135m"}
+		_, err = validator.ValidateCreate(context.Background(), modelapi)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.rateLimit"))
+	})
+
+	It("should only accept grpc health checks in Hosted mode", func() {
+		modelapi := newModelAPI()
+		modelapi.Spec.HealthCheck = &kaosv1alpha1.HealthCheckConfig{Type: kaosv1alpha1.HealthCheckTypeGRPC}
+		_, err := validator.ValidateCreate(context.Background(), modelapi)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.healthCheck.type"))
+
+		modelapi.Spec.Mode = kaosv1alpha1.ModelAPIModeHosted
+		modelapi.Spec.ProxyConfig = nil
+		modelapi.Spec.HostedConfig = &kaosv1alpha1.HostedConfig{Model: "smollm2:135m"}
+		_, err = validator.ValidateCreate(context.Background(), modelapi)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should reject a health check timeout that is not less than its period", func() {
+		modelapi := newModelAPI()
+		period, timeout := int32(5), int32(5)
+		modelapi.Spec.HealthCheck = &kaosv1alpha1.HealthCheckConfig{PeriodSeconds: &period, TimeoutSeconds: &timeout}
+		_, err := validator.ValidateCreate(context.Background(), modelapi)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.healthCheck.timeoutSeconds"))
+		Expect(err.Error()).To(ContainSubstring("must be less than periodSeconds (5)"))
+
+		// Unset timings are checked with the defaults of both probes filled in
+		modelapi.Spec.HealthCheck = &kaosv1alpha1.HealthCheckConfig{TimeoutSeconds: &timeout}
+		_, err = validator.ValidateCreate(context.Background(), modelapi)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.healthCheck.timeoutSeconds: Invalid value: 5: must be less than the default periodSeconds (5) of the readiness probe"))
+
+		period = 4
+		modelapi.Spec.HealthCheck = &kaosv1alpha1.HealthCheckConfig{PeriodSeconds: &period}
+		_, err = validator.ValidateCreate(context.Background(), modelapi)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.healthCheck.periodSeconds: Invalid value: 4: must be greater than the default timeoutSeconds (5) of the liveness probe"))
+
+		timeout = 4
+		modelapi.Spec.HealthCheck = &kaosv1alpha1.HealthCheckConfig{TimeoutSeconds: &timeout}
+		_, err = validator.ValidateCreate(context.Background(), modelapi)
+		Expect(err).NotTo(HaveOccurred())
+
+		timeout = 3
+		_, err = validator.ValidateCreate(context.Background(), modelapi)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should reject a minReadySeconds that is not less than the progress deadline", func() {
+		modelapi := newModelAPI()
+		minReady := int32(600)
+		modelapi.Spec.MinReadySeconds = &minReady
+		_, err := validator.ValidateCreate(context.Background(), modelapi)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.minReadySeconds: Invalid value: 600: must be less than progressDeadlineSeconds (600)"))
+
+		deadline := int32(900)
+		modelapi.Spec.ProgressDeadlineSeconds = &deadline
+		_, err = validator.ValidateCreate(context.Background(), modelapi)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should reject a podSpec architecture node selector that conflicts with spec.architecture", func() {
+		modelapi := newModelAPI()
+		modelapi.Spec.Architecture = "arm64"
+		modelapi.Spec.PodSpec = &corev1.PodSpec{NodeSelector: map[string]string{"kubernetes.io/arch": "arm64", "pool": "models"}}
+		_, err := validator.ValidateCreate(context.Background(), modelapi)
+		Expect(err).NotTo(HaveOccurred())
+
+		modelapi.Spec.PodSpec.NodeSelector["kubernetes.io/arch"] = "amd64"
+		_, err = validator.ValidateCreate(context.Background(), modelapi)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.podSpec.nodeSelector[kubernetes.io/arch]: Invalid value: \"amd64\": conflicts with spec.architecture arm64"))
+	})
+
+	It("should only accept the Hosted placeholders in a custom command", func() {
+		modelapi := newModelAPI()
+		modelapi.Spec.Mode = kaosv1alpha1.ModelAPIModeHosted
+		modelapi.Spec.ProxyConfig = nil
+		modelapi.Spec.HostedConfig = &kaosv1alpha1.HostedConfig{
+			Model:   "smollm2:135m",
+			Command: []string{"my-server"},
+			Args:    []string{"--model={{ .Model }}", "--models-dir={{ .ModelsDir }}"},
+		}
+		_, err := validator.ValidateCreate(context.Background(), modelapi)
+		Expect(err).NotTo(HaveOccurred())
+
+		modelapi.Spec.HostedConfig.Args = []string{"--model={{ .ModelPath }}"}
+		_, err = validator.ValidateCreate(context.Background(), modelapi)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.hostedConfig.args[0]"))
+		Expect(err.Error()).To(ContainSubstring("only {{ .Model }} and {{ .ModelsDir }} are allowed"))
+	})
+
+	It("should only allow a mode change with the migration annotation", func() {
+		oldModelAPI := newModelAPI()
+		modelapi := newModelAPI()
+		modelapi.Spec.Mode = kaosv1alpha1.ModelAPIModeHosted
+		modelapi.Spec.ProxyConfig = nil
+		modelapi.Spec.HostedConfig = &kaosv1alpha1.HostedConfig{Model: "smollm2:135m"}
+		_, err := validator.ValidateUpdate(context.Background(), oldModelAPI, modelapi)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.mode"))
+		Expect(err.Error()).To(ContainSubstring(kaosv1alpha1.AllowModeMigrationAnnotation))
+
+		modelapi.Annotations = map[string]string{kaosv1alpha1.AllowModeMigrationAnnotation: "true"}
+		_, err = validator.ValidateUpdate(context.Background(), oldModelAPI, modelapi)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should reject a podSpec memory request greater than its limit", func() {
+		modelapi := newModelAPI()
+		modelapi.Spec.PodSpec = &corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name: "model-api",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2Gi")},
+					Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("512Mi")},
+				},
+			}},
+		}
+		_, err := validator.ValidateUpdate(context.Background(), newModelAPI(), modelapi)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.podSpec.containers[0].resources.requests[memory]"))
+	})
+
+	It("should require at least one valid failover upstream", func() {
+		modelapi := newModelAPI()
+		modelapi.Spec.ProxyConfig.Upstreams = []string{"https://primary.example.com/v1", "http://10.0.0.5:8000"}
+		_, err := validator.ValidateCreate(context.Background(), modelapi)
+		Expect(err).NotTo(HaveOccurred())
+
+		modelapi.Spec.ProxyConfig.Upstreams = []string{}
+		_, err = validator.ValidateCreate(context.Background(), modelapi)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.proxyConfig.upstreams: Required value"))
+
+		modelapi.Spec.ProxyConfig.Upstreams = []string{"primary.example.com"}
+		modelapi.Spec.ProxyConfig.APIBase = "http://other:8000"
+		_, err = validator.ValidateCreate(context.Background(), modelapi)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.proxyConfig.upstreams[0]"))
+		Expect(err.Error()).To(ContainSubstring("spec.proxyConfig.apiBase"))
+	})
+
+	It("should reject names outside the naming convention", func() {
+		named := &ModelAPIValidator{NamingConvention: regexp.MustCompile("^team-a-")}
+		modelapi := newModelAPI()
+		_, err := named.ValidateCreate(context.Background(), modelapi)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("metadata.name"))
+
+		modelapi.Name = "team-a-" + modelapi.Name
+		_, err = named.ValidateCreate(context.Background(), modelapi)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should only accept images from the allowed registries", func() {
+		restricted := &ModelAPIValidator{AllowedRegistries: []string{"ghcr.io/acme", "docker.io/litellm/"}}
+		modelapi := newModelAPI()
+		modelapi.Spec.PodSpec = &corev1.PodSpec{Containers: []corev1.Container{{Name: "model-api", Image: "litellm/litellm:v1.55"}}}
+		_, err := restricted.ValidateCreate(context.Background(), modelapi)
+		Expect(err).NotTo(HaveOccurred())
+
+		modelapi.Spec.PodSpec.InitContainers = []corev1.Container{{Name: "fetch", Image: "ghcr.io/acme-fork/fetch:latest"}}
+		_, err = restricted.ValidateUpdate(context.Background(), newModelAPI(), modelapi)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.podSpec.initContainers[0].image"))
+		Expect(err.Error()).To(ContainSubstring(`image "ghcr.io/acme-fork/fetch:latest" is not from an allowed registry`))
+	})
+})
@@ -0,0 +1,35 @@
+package webhook
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// NamingConvention reads the regular expression that new Agent, ModelAPI and MCPServer names
+// must match from the NAME_PATTERN operator setting
+func NamingConvention() (*regexp.Regexp, error) {
+	pattern := os.Getenv("NAME_PATTERN")
+	if pattern == "" {
+		return nil, fmt.Errorf("NAME_PATTERN must be set to enforce a naming convention")
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NAME_PATTERN %q: %w", pattern, err)
+	}
+	return re, nil
+}
+
+// validateName rejects a name that does not match the naming convention. A nil convention
+// accepts every name. Names are immutable, so this only needs checking on create.
+func validateName(convention *regexp.Regexp, kind schema.GroupKind, name string) error {
+	if convention == nil || convention.MatchString(name) {
+		return nil
+	}
+	return apierrors.NewInvalid(kind, name, field.ErrorList{field.Invalid(field.NewPath("metadata", "name"), name,
+		fmt.Sprintf("must match the naming convention %q", convention.String()))})
+}
@@ -0,0 +1,170 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+//+kubebuilder:webhook:path=/mutate-kaos-tools-v1alpha1-agent,mutating=true,failurePolicy=fail,sideEffects=None,groups=kaos.tools,resources=agents,verbs=create;update,versions=v1alpha1,name=magent.kaos.tools,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/mutate-kaos-tools-v1alpha1-modelapi,mutating=true,failurePolicy=fail,sideEffects=None,groups=kaos.tools,resources=modelapis,verbs=create;update,versions=v1alpha1,name=mmodelapi.kaos.tools,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/mutate-kaos-tools-v1alpha1-mcpserver,mutating=true,failurePolicy=fail,sideEffects=None,groups=kaos.tools,resources=mcpservers,verbs=create;update,versions=v1alpha1,name=mmcpserver.kaos.tools,admissionReviewVersions=v1
+
+// SizePresets maps each spec.size to the resources of the main container
+type SizePresets map[kaosv1alpha1.Size]corev1.ResourceRequirements
+
+// DefaultSizePresets are the spec.size resources that SIZE_PRESETS does not override
+func DefaultSizePresets() SizePresets {
+	return SizePresets{
+		kaosv1alpha1.SizeSmall:  sizeResources("100m", "128Mi", "500m", "512Mi"),
+		kaosv1alpha1.SizeMedium: sizeResources("500m", "512Mi", "1", "1Gi"),
+		kaosv1alpha1.SizeLarge:  sizeResources("1", "2Gi", "2", "4Gi"),
+	}
+}
+
+// sizeResources builds the CPU and memory requests and limits of a size preset
+func sizeResources(cpuRequest, memoryRequest, cpuLimit, memoryLimit string) corev1.ResourceRequirements {
+	return corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(cpuRequest),
+			corev1.ResourceMemory: resource.MustParse(memoryRequest),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(cpuLimit),
+			corev1.ResourceMemory: resource.MustParse(memoryLimit),
+		},
+	}
+}
+
+// ReadSizePresets reads the spec.size presets from the SIZE_PRESETS operator setting, a JSON
+// object mapping small, medium or large to container resources. Sizes it omits keep their
+// DefaultSizePresets resources.
+func ReadSizePresets() (SizePresets, error) {
+	presets := DefaultSizePresets()
+	value := os.Getenv("SIZE_PRESETS")
+	if value == "" {
+		return presets, nil
+	}
+	var overrides map[kaosv1alpha1.Size]corev1.ResourceRequirements
+	if err := json.Unmarshal([]byte(value), &overrides); err != nil {
+		return nil, fmt.Errorf("invalid SIZE_PRESETS: %w", err)
+	}
+	for size, resources := range overrides {
+		if _, ok := presets[size]; !ok {
+			return nil, fmt.Errorf("invalid SIZE_PRESETS: unknown size %q, must be small, medium or large", size)
+		}
+		for name, request := range resources.Requests {
+			if limit, ok := resources.Limits[name]; ok && request.Cmp(limit) > 0 {
+				return nil, fmt.Errorf("invalid SIZE_PRESETS: %s %s request %s exceeds its limit %s",
+					size, name, request.String(), limit.String())
+			}
+		}
+		presets[size] = resources
+	}
+	return presets, nil
+}
+
+// applySize sets the resources of the size preset on the named container of the podSpec
+// override. Resources the container sets win, except those equal to a preset: those were
+// set by an earlier expansion, so a changed size replaces them.
+func (presets SizePresets) applySize(size kaosv1alpha1.Size, podSpec **corev1.PodSpec, name string) {
+	resources, ok := presets[size]
+	if !ok {
+		return
+	}
+	if *podSpec == nil {
+		*podSpec = &corev1.PodSpec{}
+	}
+	spec := *podSpec
+	for i := range spec.Containers {
+		if spec.Containers[i].Name != name {
+			continue
+		}
+		if presets.defaulted(spec.Containers[i].Resources) {
+			spec.Containers[i].Resources = *resources.DeepCopy()
+		}
+		return
+	}
+	// The podSpec override is a strategic merge patch, so a container with only a name and
+	// resources merges into the generated one
+	spec.Containers = append(spec.Containers, corev1.Container{Name: name, Resources: *resources.DeepCopy()})
+}
+
+// defaulted reports whether container resources are unset or were expanded from a preset
+func (presets SizePresets) defaulted(resources corev1.ResourceRequirements) bool {
+	if len(resources.Requests) == 0 && len(resources.Limits) == 0 && len(resources.Claims) == 0 {
+		return true
+	}
+	for _, preset := range presets {
+		if equality.Semantic.DeepEqual(resources, preset) {
+			return true
+		}
+	}
+	return false
+}
+
+// AgentDefaulter expands spec.size on Agent resources
+type AgentDefaulter struct {
+	Sizes SizePresets
+}
+
+var _ admission.CustomDefaulter = &AgentDefaulter{}
+
+// Default sets the agent container resources from spec.size
+func (d *AgentDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	agent, ok := obj.(*kaosv1alpha1.Agent)
+	if !ok {
+		return fmt.Errorf("expected an Agent but got %T", obj)
+	}
+	if agent.Spec.Size != "" {
+		d.Sizes.applySize(agent.Spec.Size, &agent.Spec.PodSpec, "agent")
+	}
+	return nil
+}
+
+// ModelAPIDefaulter expands spec.size on ModelAPI resources
+type ModelAPIDefaulter struct {
+	Sizes SizePresets
+}
+
+var _ admission.CustomDefaulter = &ModelAPIDefaulter{}
+
+// Default sets the model-api container resources from spec.size
+func (d *ModelAPIDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	modelapi, ok := obj.(*kaosv1alpha1.ModelAPI)
+	if !ok {
+		return fmt.Errorf("expected a ModelAPI but got %T", obj)
+	}
+	if modelapi.Spec.Size != "" {
+		d.Sizes.applySize(modelapi.Spec.Size, &modelapi.Spec.PodSpec, "model-api")
+	}
+	return nil
+}
+
+// MCPServerDefaulter expands spec.size on MCPServer resources
+type MCPServerDefaulter struct {
+	Sizes SizePresets
+}
+
+var _ admission.CustomDefaulter = &MCPServerDefaulter{}
+
+// Default sets the mcp-server container resources from spec.size
+func (d *MCPServerDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	mcpserver, ok := obj.(*kaosv1alpha1.MCPServer)
+	if !ok {
+		return fmt.Errorf("expected an MCPServer but got %T", obj)
+	}
+	if mcpserver.Spec.Size != "" {
+		d.Sizes.applySize(mcpserver.Spec.Size, &mcpserver.Spec.PodSpec, "mcp-server")
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package webhook
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+var _ = Describe("spec.size defaulting", func() {
+	sizes := DefaultSizePresets()
+
+	It("should expand medium into the agent container resources", func() {
+		agent := newAgent()
+		agent.Spec.Size = kaosv1alpha1.SizeMedium
+		Expect((&AgentDefaulter{Sizes: sizes}).Default(context.Background(), agent)).To(Succeed())
+
+		Expect(agent.Spec.PodSpec.Containers).To(HaveLen(1))
+		container := agent.Spec.PodSpec.Containers[0]
+		Expect(container.Name).To(Equal("agent"))
+		Expect(container.Resources.Requests.Cpu().String()).To(Equal("500m"))
+		Expect(container.Resources.Requests.Memory().String()).To(Equal("512Mi"))
+		Expect(container.Resources.Limits.Cpu().String()).To(Equal("1"))
+		Expect(container.Resources.Limits.Memory().String()).To(Equal("1Gi"))
+
+		// The expansion passes validation, including the request and limit checks
+		_, err := (&AgentValidator{}).ValidateCreate(context.Background(), agent)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should keep resources set explicitly in podSpec", func() {
+		explicit := corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("3Gi")},
+		}
+		modelapi := newModelAPI()
+		modelapi.Spec.Size = kaosv1alpha1.SizeMedium
+		modelapi.Spec.PodSpec = &corev1.PodSpec{Containers: []corev1.Container{{Name: "model-api", Resources: explicit}}}
+		Expect((&ModelAPIDefaulter{Sizes: sizes}).Default(context.Background(), modelapi)).To(Succeed())
+
+		Expect(modelapi.Spec.PodSpec.Containers).To(HaveLen(1))
+		Expect(modelapi.Spec.PodSpec.Containers[0].Resources).To(Equal(explicit))
+	})
+
+	It("should replace expanded resources when the size changes", func() {
+		mcpserver := newMCPServer()
+		mcpserver.Spec.Size = kaosv1alpha1.SizeSmall
+		defaulter := &MCPServerDefaulter{Sizes: sizes}
+		Expect(defaulter.Default(context.Background(), mcpserver)).To(Succeed())
+		Expect(mcpserver.Spec.PodSpec.Containers[0].Resources).To(Equal(sizes[kaosv1alpha1.SizeSmall]))
+
+		mcpserver.Spec.Size = kaosv1alpha1.SizeLarge
+		Expect(defaulter.Default(context.Background(), mcpserver)).To(Succeed())
+		Expect(mcpserver.Spec.PodSpec.Containers).To(HaveLen(1))
+		Expect(mcpserver.Spec.PodSpec.Containers[0].Name).To(Equal("mcp-server"))
+		Expect(mcpserver.Spec.PodSpec.Containers[0].Resources).To(Equal(sizes[kaosv1alpha1.SizeLarge]))
+	})
+
+	It("should leave resources without spec.size alone", func() {
+		agent := newAgent()
+		Expect((&AgentDefaulter{Sizes: sizes}).Default(context.Background(), agent)).To(Succeed())
+		Expect(agent.Spec.PodSpec).To(BeNil())
+	})
+
+	It("should read size overrides from SIZE_PRESETS", func() {
+		GinkgoT().Setenv("SIZE_PRESETS", `{"medium": {"requests": {"cpu": "2"}, "limits": {"cpu": "4"}}}`)
+		presets, err := ReadSizePresets()
+		Expect(err).NotTo(HaveOccurred())
+		medium := presets[kaosv1alpha1.SizeMedium]
+		Expect(medium.Requests.Cpu().String()).To(Equal("2"))
+		Expect(medium.Requests.Memory().IsZero()).To(BeTrue())
+		Expect(presets[kaosv1alpha1.SizeSmall]).To(Equal(sizes[kaosv1alpha1.SizeSmall]))
+
+		GinkgoT().Setenv("SIZE_PRESETS", `{"huge": {}}`)
+		_, err = ReadSizePresets()
+		Expect(err).To(MatchError(ContainSubstring(`unknown size "huge"`)))
+
+		GinkgoT().Setenv("SIZE_PRESETS", `{"small": {"requests": {"cpu": "2"}, "limits": {"cpu": "1"}}}`)
+		_, err = ReadSizePresets()
+		Expect(err).To(MatchError(ContainSubstring("small cpu request 2 exceeds its limit 1")))
+	})
+})
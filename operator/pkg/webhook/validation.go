@@ -0,0 +1,215 @@
+package webhook
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	kaosv1alpha1 "github.com/axsaucedo/kaos/operator/api/v1alpha1"
+)
+
+// validateHostAliases checks that every host alias has a valid IP and at least one hostname
+func validateHostAliases(path *field.Path, aliases []corev1.HostAlias) field.ErrorList {
+	var errs field.ErrorList
+	for i, alias := range aliases {
+		if net.ParseIP(alias.IP) == nil {
+			errs = append(errs, field.Invalid(path.Index(i).Child("ip"), alias.IP, "must be a valid IPv4 or IPv6 address"))
+		}
+		if len(alias.Hostnames) == 0 {
+			errs = append(errs, field.Required(path.Index(i).Child("hostnames"), "at least one hostname is required"))
+		}
+	}
+	return errs
+}
+
+// validateEnv checks that every secretKeyRef and configMapKeyRef in an env list names a key.
+// Kubernetes accepts the resource, but the pods would only fail once they are created.
+func validateEnv(path *field.Path, env []corev1.EnvVar) field.ErrorList {
+	var errs field.ErrorList
+	for i, e := range env {
+		if e.ValueFrom == nil {
+			continue
+		}
+		valueFromPath := path.Index(i).Child("valueFrom")
+		if ref := e.ValueFrom.SecretKeyRef; ref != nil && ref.Key == "" {
+			errs = append(errs, field.Required(valueFromPath.Child("secretKeyRef", "key"), fmt.Sprintf("key of secret %q is required for %s", ref.Name, e.Name)))
+		}
+		if ref := e.ValueFrom.ConfigMapKeyRef; ref != nil && ref.Key == "" {
+			errs = append(errs, field.Required(valueFromPath.Child("configMapKeyRef", "key"), fmt.Sprintf("key of configmap %q is required for %s", ref.Name, e.Name)))
+		}
+	}
+	return errs
+}
+
+// validatePodSpecResources checks that no container in the podSpec override requests more
+// of a resource than its limit, which the API server would only reject at pod creation
+func validatePodSpecResources(path *field.Path, podSpec *corev1.PodSpec) field.ErrorList {
+	if podSpec == nil {
+		return nil
+	}
+	var errs field.ErrorList
+	errs = append(errs, validateContainerResources(path.Child("initContainers"), podSpec.InitContainers)...)
+	errs = append(errs, validateContainerResources(path.Child("containers"), podSpec.Containers)...)
+	return errs
+}
+
+// validateContainerResources reports every request that exceeds the matching limit
+func validateContainerResources(path *field.Path, containers []corev1.Container) field.ErrorList {
+	var errs field.ErrorList
+	for i, container := range containers {
+		requestsPath := path.Index(i).Child("resources", "requests")
+		names := make([]corev1.ResourceName, 0, len(container.Resources.Requests))
+		for name := range container.Resources.Requests {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			request := container.Resources.Requests[name]
+			limit, ok := container.Resources.Limits[name]
+			if ok && request.Cmp(limit) > 0 {
+				errs = append(errs, field.Invalid(requestsPath.Key(string(name)), request.String(),
+					fmt.Sprintf("must be less than or equal to %s limit of %s", name, limit.String())))
+			}
+		}
+	}
+	return errs
+}
+
+// validateTelemetry checks that telemetry.headers can be serialized into OTEL_EXPORTER_OTLP_HEADERS
+func validateTelemetry(path *field.Path, telemetry *kaosv1alpha1.TelemetryConfig) field.ErrorList {
+	if telemetry == nil {
+		return nil
+	}
+	var errs field.ErrorList
+	headersPath := path.Child("headers")
+	names := make([]string, 0, len(telemetry.Headers))
+	for name := range telemetry.Headers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if !validHeaderName(name) {
+			errs = append(errs, field.Invalid(headersPath.Key(name), name, "must be a valid HTTP header name"))
+		}
+		if value := telemetry.Headers[name]; strings.ContainsAny(value, ",\r\n") {
+			errs = append(errs, field.Invalid(headersPath.Key(name), value, "may not contain commas or newlines"))
+		}
+	}
+	return errs
+}
+
+// telemetryWarnings warns when export settings are set while telemetry is disabled, which
+// usually means enabled was forgotten. The settings are ignored, so this is not an error.
+func telemetryWarnings(path *field.Path, telemetry *kaosv1alpha1.TelemetryConfig) admission.Warnings {
+	if telemetry == nil || telemetry.Enabled {
+		return nil
+	}
+	var set []string
+	for _, setting := range []struct {
+		name string
+		set  bool
+	}{
+		{"endpoint", telemetry.Endpoint != ""},
+		{"tracesEndpoint", telemetry.TracesEndpoint != ""},
+		{"metricsEndpoint", telemetry.MetricsEndpoint != ""},
+		{"logsEndpoint", telemetry.LogsEndpoint != ""},
+		{"headers", len(telemetry.Headers) > 0},
+	} {
+		if setting.set {
+			set = append(set, path.Child(setting.name).String())
+		}
+	}
+	if len(set) == 0 {
+		return nil
+	}
+	return admission.Warnings{fmt.Sprintf("%s set but %s is false, so telemetry is not exported and they are ignored",
+		strings.Join(set, ", "), path.Child("enabled"))}
+}
+
+// validHeaderName reports whether name is a non-empty RFC 9110 token
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.ContainsRune("!#$%&'*+-.^_`|~", c)) {
+			return false
+		}
+	}
+	return true
+}
+
+// validateCommandTemplates checks that every command or args entry only references the
+// placeholders accepted by validate
+func validateCommandTemplates(path *field.Path, values []string, validate func(string) error) field.ErrorList {
+	var errs field.ErrorList
+	for i, value := range values {
+		if err := validate(value); err != nil {
+			errs = append(errs, field.Invalid(path.Index(i), value, err.Error()))
+		}
+	}
+	return errs
+}
+
+// validatePodSpecNames rejects containers and init containers that share a name, and container
+// ports that share a name, across the podSpec override and the containers the operator
+// generates. Entries of podSpec.containers named after a generated container are merged into
+// it, so they may restate its ports.
+func validatePodSpecNames(path *field.Path, generated []corev1.Container, podSpec *corev1.PodSpec) field.ErrorList {
+	if podSpec == nil {
+		return nil
+	}
+	var errs field.ErrorList
+	containerNames := map[string]bool{}
+	generatedPorts := map[string]map[string]int32{}
+	portNames := map[string]bool{}
+	for _, container := range generated {
+		containerNames[container.Name] = true
+		generatedPorts[container.Name] = map[string]int32{}
+		for _, port := range container.Ports {
+			generatedPorts[container.Name][port.Name] = port.ContainerPort
+			portNames[port.Name] = true
+		}
+	}
+
+	checkPorts := func(containerPath *field.Path, container corev1.Container) {
+		for j, port := range container.Ports {
+			if port.Name == "" {
+				continue
+			}
+			// A generated port without a number takes it from elsewhere, such as a ModelAPI template
+			if number, ok := generatedPorts[container.Name][port.Name]; ok && (number == 0 || number == port.ContainerPort) {
+				continue
+			}
+			if portNames[port.Name] {
+				errs = append(errs, field.Duplicate(containerPath.Child("ports").Index(j).Child("name"), port.Name))
+			}
+			portNames[port.Name] = true
+		}
+	}
+
+	podSpecNames := map[string]bool{}
+	for i, container := range podSpec.Containers {
+		containerPath := path.Child("containers").Index(i)
+		if podSpecNames[container.Name] {
+			errs = append(errs, field.Duplicate(containerPath.Child("name"), container.Name))
+		}
+		podSpecNames[container.Name] = true
+		containerNames[container.Name] = true
+		checkPorts(containerPath, container)
+	}
+	for i, container := range podSpec.InitContainers {
+		containerPath := path.Child("initContainers").Index(i)
+		if containerNames[container.Name] {
+			errs = append(errs, field.Duplicate(containerPath.Child("name"), container.Name))
+		}
+		containerNames[container.Name] = true
+		checkPorts(containerPath, container)
+	}
+	return errs
+}